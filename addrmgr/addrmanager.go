@@ -164,7 +164,8 @@ const (
 	getAddrPercent = 23
 
 	// serialisationVersion is the current version of the on-disk format.
-	serialisationVersion = 2
+	// Version 3 may contain Tor v3 and I2P addresses.
+	serialisationVersion = 3
 )
 
 // updateAddress is a helper function to either update an address already known
@@ -728,8 +729,13 @@ func (a *AddrManager) reset() {
 }
 
 // HostToNetAddress returns a netaddress given a host address.
-// If the host is not an IP address it will be resolved
+// If the host is not an IP address, Tor v3 onion address or I2P address it will
+// be resolved
 func (a *AddrManager) HostToNetAddress(host string, port uint16, services protocol.ServiceFlag) (*wire.NetAddress, er.R) {
+	if na, err := wire.NewNetAddressHost(host, port, services); err == nil {
+		return na, nil
+	}
+
 	var ip net.IP
 	if ip = net.ParseIP(host); ip == nil {
 		ips, err := a.lookupFunc(host)
@@ -745,13 +751,14 @@ func (a *AddrManager) HostToNetAddress(host string, port uint16, services protoc
 	return wire.NewNetAddressIPPort(ip, port, services), nil
 }
 
-// ipString returns a string for the ip from the provided NetAddress.
+// ipString returns a string for the ip from the provided NetAddress.  Addresses
+// on networks which do not use IPs are returned as their host names.
 func ipString(na *wire.NetAddress) string {
-	return na.IP.String()
+	return na.Host()
 }
 
-// NetAddressKey returns a string key in the form of ip:port for IPv4 addresses
-// or [ip]:port for IPv6 addresses.
+// NetAddressKey returns a string key in the form of ip:port for IPv4 addresses,
+// [ip]:port for IPv6 addresses or host:port for Tor v3 and I2P addresses.
 func NetAddressKey(na *wire.NetAddress) string {
 	port := strconv.FormatUint(uint64(na.Port), 10)
 
@@ -999,7 +1006,7 @@ func (a *AddrManager) SetServices(addr *wire.NetAddress, services protocol.Servi
 // with the given priority.
 func (a *AddrManager) AddLocalAddress(na *wire.NetAddress, priority AddressPriority) er.R {
 	if !IsRoutable(na) {
-		return er.Errorf("address %s is not routable", na.IP)
+		return er.Errorf("address %s is not routable", ipString(na))
 	}
 
 	a.lamtx.Lock()
@@ -1037,6 +1044,21 @@ func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddress) int {
 		return Unreachable
 	}
 
+	// Addresses on networks which do not use IPs are best for peers on the
+	// same network, but can still be offered to any other peer.
+	if localAddr.IP == nil {
+		if localAddr.Network == remoteAddr.Network {
+			return Private
+		}
+		return Default
+	}
+	if remoteAddr.IP == nil {
+		if IsRoutable(localAddr) && IsIPv4(localAddr) {
+			return Ipv4
+		}
+		return Default
+	}
+
 	if IsRFC4380(remoteAddr) {
 		if !IsRoutable(localAddr) {
 			return Default
@@ -1106,11 +1128,10 @@ func (a *AddrManager) GetBestLocalAddress(remoteAddr *wire.NetAddress) *wire.Net
 		}
 	}
 	if bestAddress != nil {
-		log.Debugf("Suggesting address %s:%d for %s:%d", bestAddress.IP,
-			bestAddress.Port, remoteAddr.IP, remoteAddr.Port)
+		log.Debugf("Suggesting address %s for %s",
+			NetAddressKey(bestAddress), NetAddressKey(remoteAddr))
 	} else {
-		log.Debugf("No worthy address for %s:%d", remoteAddr.IP,
-			remoteAddr.Port)
+		log.Debugf("No worthy address for %s", NetAddressKey(remoteAddr))
 
 		// Send something unroutable if nothing suitable.
 		var ip net.IP
@@ -215,3 +215,54 @@ func TestAddrManagerV1ToV2(t *testing.T) {
 	addrMgr.loadPeers()
 	assertAddrs(t, addrMgr, expectedAddrs)
 }
+
+// TestAddrManagerAddrV2Serialization ensures that addresses on networks which
+// do not use IPs, such as Tor v3 and I2P, survive being persisted to disk.
+func TestAddrManagerAddrV2Serialization(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "addrmgr")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	addrMgr := New(tempDir, nil)
+
+	hosts := []string{
+		"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion",
+		"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p",
+	}
+	expectedAddrs := make(map[string]*wire.NetAddress, len(hosts))
+	for _, host := range hosts {
+		addr, err := wire.NewNetAddressHost(host, 8333,
+			protocol.SFNodeNetwork)
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		expectedAddrs[NetAddressKey(addr)] = addr
+		addrMgr.AddAddress(addr, randAddr(t))
+	}
+	if addrMgr.NumAddresses() != len(hosts) {
+		t.Fatalf("expected %d addresses, found %d", len(hosts),
+			addrMgr.NumAddresses())
+	}
+
+	addrMgr.savePeers()
+	addrMgr = New(tempDir, nil)
+	addrMgr.loadPeers()
+
+	if addrMgr.NumAddresses() != len(hosts) {
+		t.Fatalf("expected %d addresses after reload, found %d",
+			len(hosts), addrMgr.NumAddresses())
+	}
+	for key, expected := range expectedAddrs {
+		ka, ok := addrMgr.addrIndex[key]
+		if !ok {
+			t.Fatalf("expected to find address %v", key)
+		}
+		assertAddr(t, ka.na, expected)
+		if ka.na.NetworkID() != expected.NetworkID() {
+			t.Fatalf("expected network %v for %v, got %v",
+				expected.NetworkID(), key, ka.na.NetworkID())
+		}
+	}
+}
@@ -5,6 +5,7 @@
 package addrmgr
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/pkt-cash/pktd/wire"
@@ -144,6 +145,17 @@ func IsCjdns(na *wire.NetAddress) bool {
 	return cjdnsNet.Contains(na.IP)
 }
 
+// IsTorV3 returns whether or not the passed address is a Tor v3 onion service
+// address.
+func IsTorV3(na *wire.NetAddress) bool {
+	return na.IP == nil && na.Network == wire.NetTorV3
+}
+
+// IsI2P returns whether or not the passed address is an I2P address.
+func IsI2P(na *wire.NetAddress) bool {
+	return na.IP == nil && na.Network == wire.NetI2P
+}
+
 // IsRFC4380 returns whether or not the passed address is part of the IPv6
 // teredo tunneling over UDP range as defined by RFC4380 (2001::/32).
 func IsRFC4380(na *wire.NetAddress) bool {
@@ -198,6 +210,12 @@ func IsRFC6598(na *wire.NetAddress) bool {
 // IPv4: It is either a zero or all bits set address.
 // IPv6: It is either a zero or RFC3849 documentation address.
 func IsValid(na *wire.NetAddress) bool {
+	// Addresses on networks which do not use IPs are valid when they are
+	// of a known network and length.
+	if na.IP == nil {
+		return (IsTorV3(na) || IsI2P(na)) && len(na.Addr) == 32
+	}
+
 	// IsUnspecified returns if address is 0, so only all bits set, and
 	// RFC3849 need to be explicitly checked.
 	return na.IP != nil && !(na.IP.IsUnspecified() ||
@@ -215,9 +233,10 @@ func IsRoutable(na *wire.NetAddress) bool {
 }
 
 // GroupKey returns a string representing the network group an address is part
-// of.  This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
-// "local" for a local address, and the string "unroutable" for an 
-// unroutable address.
+// of.  This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the network
+// name and first four bits of the address for Tor v3 and I2P, the string
+// "local" for a local address, and the string "unroutable" for an unroutable
+// address.
 func GroupKey(na *wire.NetAddress) string {
 	if IsLocal(na) {
 		return "local"
@@ -225,6 +244,11 @@ func GroupKey(na *wire.NetAddress) string {
 	if !IsRoutable(na) {
 		return "unroutable"
 	}
+	if na.IP == nil {
+		// Addresses on networks which do not use IPs are grouped by
+		// the first four bits of the address, the same as bitcoind.
+		return fmt.Sprintf("%v:%d", na.Network, na.Addr[0]>>4)
+	}
 	if IsIPv4(na) {
 		return na.IP.Mask(net.CIDRMask(16, 32)).String()
 	}
@@ -187,12 +187,19 @@ func TestGroupKey(t *testing.T) {
 		{name: "ipv6 normal 2", ip: "2602:0100::1234", expected: "2602:100::"},
 		{name: "ipv6 hurricane electric", ip: "2001:470:1f10:a1::2", expected: "2001:470:1000::"},
 		{name: "ipv6 hurricane electric 2", ip: "2001:0470:1f10:a1::2", expected: "2001:470:1000::"},
+
+		// Networks which do not use IPs.
+		{name: "tor v3", ip: "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion", expected: "torv3:7"},
+		{name: "i2p", ip: "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p", expected: "i2p:10"},
 	}
 
 	for i, test := range tests {
-		nip := net.ParseIP(test.ip)
-		na := *wire.NewNetAddressIPPort(nip, 8333, protocol.SFNodeNetwork)
-		if key := addrmgr.GroupKey(&na); key != test.expected {
+		na, err := wire.NewNetAddressHost(test.ip, 8333, protocol.SFNodeNetwork)
+		if err != nil {
+			t.Errorf("TestGroupKey #%d (%s): %v", i, test.name, err)
+			continue
+		}
+		if key := addrmgr.GroupKey(na); key != test.expected {
 			t.Errorf("TestGroupKey #%d (%s): unexpected group key "+
 				"- got '%s', want '%s'", i, test.name,
 				key, test.expected)
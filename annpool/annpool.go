@@ -0,0 +1,381 @@
+// Package annpool provides a pool of validated PacketCrypt announcements, which
+// are relayed between the peers which support it and used by block miners.
+//
+// The pool is size bounded: when it is full, a new announcement replaces the
+// announcement with the least work, and announcements are pruned as soon as
+// they have expired or their parent block is no longer in the main chain.
+package annpool
+
+import (
+	"container/heap"
+	"runtime"
+	"sync"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/randhash/util"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// DefaultMaxAnns is the default maximum number of announcements in the pool.
+const DefaultMaxAnns = 1 << 16
+
+// Config is a descriptor containing the announcement pool configuration.
+type Config struct {
+	// MaxAnns is the maximum number of announcements in the pool.
+	MaxAnns int
+
+	// BestHeight returns the height of the current best block.
+	BestHeight func() int32
+
+	// BlockHashByHeight returns the hash of the block at the passed height
+	// in the main chain.
+	BlockHashByHeight func(height int32) (*chainhash.Hash, er.R)
+}
+
+// PacketCryptVersion returns the PacketCrypt version of the block at the
+// passed height, the highest allowed version.
+func PacketCryptVersion(height int32) int {
+	if globalcfg.IsPacketCryptAllowedVersion(2, height) {
+		return 2
+	}
+	return 1
+}
+
+// AnnTarget returns the target which an announcement is valued at in a block
+// at the passed height, as block.ValidatePcProof computes it.
+func AnnTarget(ann *wire.PacketCryptAnn, blockHeight int32) uint32 {
+	if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
+		return ann.GetWorkTarget()
+	}
+	return difficulty.GetAgedAnnTarget(ann.GetWorkTarget(),
+		uint32(blockHeight)-ann.GetParentBlockHeight(),
+		PacketCryptVersion(blockHeight))
+}
+
+// IsAnnExpired returns whether an announcement has aged so much that it can
+// no longer be used in a block at the passed height or after.  Announcements
+// which are not ready yet are not expired.
+func IsAnnExpired(ann *wire.PacketCryptAnn, blockHeight int32) bool {
+	age := int64(blockHeight) - int64(ann.GetParentBlockHeight())
+	if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD ||
+		age <= util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
+		return false
+	}
+	return AnnTarget(ann, blockHeight) == 0xffffffff
+}
+
+// AnnHash returns the hash which identifies an announcement.
+func AnnHash(ann *wire.PacketCryptAnn) chainhash.Hash {
+	var hash chainhash.Hash
+	pcutil.HashCompress(hash[:], ann.Header[:])
+	return hash
+}
+
+// annEntry is an announcement in the pool.
+type annEntry struct {
+	ann        *wire.PacketCryptAnn
+	hash       chainhash.Hash
+	parentHash chainhash.Hash
+	work       float64
+
+	// index is the index of the entry in the eviction heap.
+	index int
+}
+
+// annHeap is a min-heap of the announcements of the pool which is used to
+// evict the announcements with the least work, and the oldest of them, first.
+type annHeap []*annEntry
+
+func (h annHeap) Len() int { return len(h) }
+
+func (h annHeap) Less(i, j int) bool { return lessWork(h[i], h[j]) }
+
+func (h annHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *annHeap) Push(x interface{}) {
+	entry := x.(*annEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *annHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// lessWork returns whether the announcement a has less work than b, or the same
+// work and an older parent block.
+func lessWork(a, b *annEntry) bool {
+	if a.work != b.work {
+		return a.work < b.work
+	}
+	return a.ann.GetParentBlockHeight() < b.ann.GetParentBlockHeight()
+}
+
+// AnnPool is a pool of validated PacketCrypt announcements, indexed by their
+// hash and by the height of their parent block.
+type AnnPool struct {
+	cfg Config
+
+	mtx       sync.Mutex
+	anns      map[chainhash.Hash]*annEntry
+	byParent  map[uint32]map[chainhash.Hash]*annEntry
+	evict     annHeap
+	prunedFor int32
+}
+
+// New returns a new announcement pool for the passed configuration.
+func New(cfg *Config) *AnnPool {
+	return &AnnPool{
+		cfg:      *cfg,
+		anns:     make(map[chainhash.Hash]*annEntry),
+		byParent: make(map[uint32]map[chainhash.Hash]*annEntry),
+	}
+}
+
+// removeAnn removes an announcement from the pool.
+//
+// This function MUST be called with the pool lock held (for writes).
+func (p *AnnPool) removeAnn(entry *annEntry) {
+	delete(p.anns, entry.hash)
+	height := entry.ann.GetParentBlockHeight()
+	delete(p.byParent[height], entry.hash)
+	if len(p.byParent[height]) == 0 {
+		delete(p.byParent, height)
+	}
+	heap.Remove(&p.evict, entry.index)
+}
+
+// prune removes the announcements which have expired for the next block, and
+// those whose parent block is no longer in the main chain.  It is only done
+// once for each best block.
+//
+// This function MUST be called with the pool lock held (for writes).
+func (p *AnnPool) prune() {
+	nextHeight := p.cfg.BestHeight() + 1
+	if nextHeight == p.prunedFor {
+		return
+	}
+	p.prunedFor = nextHeight
+
+	var removed []*annEntry
+	for height, entries := range p.byParent {
+		parentHash, err := p.cfg.BlockHashByHeight(int32(height))
+		for _, entry := range entries {
+			if err != nil || !parentHash.IsEqual(&entry.parentHash) ||
+				IsAnnExpired(entry.ann, nextHeight) {
+
+				removed = append(removed, entry)
+			}
+		}
+	}
+	for _, entry := range removed {
+		p.removeAnn(entry)
+	}
+}
+
+// checkAnn checks whether an announcement could be added to the pool, before
+// it is validated, and returns the hash of its parent block.
+func (p *AnnPool) checkAnn(ann *wire.PacketCryptAnn, nextHeight int32,
+	parents map[uint32]*chainhash.Hash) (*chainhash.Hash, er.R) {
+
+	if ann.HasSigningKey() {
+		return nil, ErrSigned.Default()
+	}
+	if !difficulty.IsAnnMinDiffOk(ann.GetWorkTarget(),
+		PacketCryptVersion(nextHeight)) {
+
+		return nil, ErrTarget.Default()
+	}
+	parentHeight := ann.GetParentBlockHeight()
+	if IsAnnExpired(ann, nextHeight) {
+		return nil, ErrExpired.Default()
+	}
+	parentHash, ok := parents[parentHeight]
+	if !ok {
+		// An unknown parent is cached as nil.
+		if parentHeight < uint32(nextHeight) {
+			parentHash, _ = p.cfg.BlockHashByHeight(int32(parentHeight))
+		}
+		parents[parentHeight] = parentHash
+	}
+	if parentHash == nil {
+		return nil, announce.ErrUnknownParent.Default()
+	}
+	return parentHash, nil
+}
+
+// ProcessAnns validates the passed announcements in parallel and adds those
+// which are valid to the pool.  It returns the hashes of the announcements
+// which were added, and the error of each announcement, nil for those which
+// were added.
+func (p *AnnPool) ProcessAnns(anns []*wire.PacketCryptAnn) ([]chainhash.Hash, []er.R) {
+	nextHeight := p.cfg.BestHeight() + 1
+	version := PacketCryptVersion(nextHeight)
+	hashes := make([]chainhash.Hash, len(anns))
+	parentHashes := make([]*chainhash.Hash, len(anns))
+	errs := make([]er.R, len(anns))
+
+	// Skip the announcements which are already known before the more
+	// expensive checks.
+	p.mtx.Lock()
+	for i, ann := range anns {
+		hashes[i] = AnnHash(ann)
+		if _, ok := p.anns[hashes[i]]; ok {
+			errs[i] = ErrDuplicate.Default()
+		}
+	}
+	p.mtx.Unlock()
+	parents := make(map[uint32]*chainhash.Hash)
+	for i, ann := range anns {
+		if errs[i] == nil {
+			parentHashes[i], errs[i] = p.checkAnn(ann, nextHeight, parents)
+		}
+	}
+
+	var wg sync.WaitGroup
+	threads := runtime.NumCPU()
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			for i := t; i < len(anns); i += threads {
+				if errs[i] != nil {
+					continue
+				}
+				_, errs[i] = packetcrypt.ValidatePcAnn(anns[i],
+					parentHashes[i], version)
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	var accepted []chainhash.Hash
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.prune()
+	for i, ann := range anns {
+		if errs[i] != nil {
+			continue
+		}
+		if _, ok := p.anns[hashes[i]]; ok {
+			errs[i] = ErrDuplicate.Default()
+			continue
+		}
+		entry := &annEntry{
+			ann:        ann,
+			hash:       hashes[i],
+			parentHash: *parentHashes[i],
+		}
+		entry.work, _ = difficulty.WorkForTarget(difficulty.CompactToBig(
+			ann.GetWorkTarget())).Float64()
+		if len(p.anns) >= p.cfg.MaxAnns {
+			if len(p.evict) == 0 || !lessWork(p.evict[0], entry) {
+				errs[i] = ErrPoolFull.Default()
+				continue
+			}
+			p.removeAnn(p.evict[0])
+		}
+		p.anns[entry.hash] = entry
+		height := ann.GetParentBlockHeight()
+		if p.byParent[height] == nil {
+			p.byParent[height] = make(map[chainhash.Hash]*annEntry)
+		}
+		p.byParent[height][entry.hash] = entry
+		heap.Push(&p.evict, entry)
+		accepted = append(accepted, entry.hash)
+	}
+	return accepted, errs
+}
+
+// Count returns the number of announcements in the pool.
+func (p *AnnPool) Count() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.prune()
+	return len(p.anns)
+}
+
+// HaveAnn returns whether the announcement with the passed hash is in the
+// pool.
+func (p *AnnPool) HaveAnn(hash *chainhash.Hash) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	_, ok := p.anns[*hash]
+	return ok
+}
+
+// FetchAnn returns the announcement with the passed hash, or nil if it is not
+// in the pool.
+func (p *AnnPool) FetchAnn(hash *chainhash.Hash) *wire.PacketCryptAnn {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if entry, ok := p.anns[*hash]; ok {
+		return entry.ann
+	}
+	return nil
+}
+
+// AnnHashes returns the hashes of all the announcements in the pool.
+func (p *AnnPool) AnnHashes() []chainhash.Hash {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.prune()
+	hashes := make([]chainhash.Hash, 0, len(p.anns))
+	for hash := range p.anns {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// AnnsAtHeight returns the announcements of the pool whose parent block is at
+// the passed height.
+func (p *AnnPool) AnnsAtHeight(parentHeight uint32) []*wire.PacketCryptAnn {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.prune()
+	anns := make([]*wire.PacketCryptAnn, 0, len(p.byParent[parentHeight]))
+	for _, entry := range p.byParent[parentHeight] {
+		anns = append(anns, entry.ann)
+	}
+	return anns
+}
+
+// Anns returns all the announcements of the pool.
+func (p *AnnPool) Anns() []*wire.PacketCryptAnn {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.prune()
+	anns := make([]*wire.PacketCryptAnn, 0, len(p.anns))
+	for _, entry := range p.anns {
+		anns = append(anns, entry.ann)
+	}
+	return anns
+}
+
+// AnnsForBlock returns the announcements of the pool which can be used to mine
+// the block at the passed height.
+func (p *AnnPool) AnnsForBlock(height int32) []*wire.PacketCryptAnn {
+	version := PacketCryptVersion(height)
+	var anns []*wire.PacketCryptAnn
+	for _, ann := range p.Anns() {
+		if difficulty.IsAnnMinDiffOk(AnnTarget(ann, height), version) {
+			anns = append(anns, ann)
+		}
+	}
+	return anns
+}
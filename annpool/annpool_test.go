@@ -0,0 +1,155 @@
+package annpool
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// fakeChain is a chain of blocks for the tests, the hash of each block is its
+// height plus a salt which is changed to simulate a reorganization.
+type fakeChain struct {
+	height int32
+	salt   byte
+}
+
+func (c *fakeChain) bestHeight() int32 {
+	return c.height
+}
+
+func (c *fakeChain) blockHashByHeight(height int32) (*chainhash.Hash, er.R) {
+	if height > c.height {
+		return nil, er.Errorf("no block at height %d", height)
+	}
+	if height == 0 {
+		return chaincfg.SimNetParams.GenesisHash, nil
+	}
+	return &chainhash.Hash{byte(height), c.salt}, nil
+}
+
+// mineAnns mines announcements on the block at the passed height.
+func mineAnns(t *testing.T, c *fakeChain, height uint32, target uint32, count int) []*wire.PacketCryptAnn {
+	parentHash, err := c.blockHashByHeight(int32(height))
+	if err != nil {
+		t.Fatalf("blockHashByHeight: %v", err)
+	}
+	m, err := annminer.New(&annminer.Request{
+		ParentBlockHash:   *parentHash,
+		ParentBlockHeight: height,
+		WorkTarget:        target,
+	}, 0)
+	if err != nil {
+		t.Fatalf("annminer.New: %v", err)
+	}
+	anns, err := m.Mine(count, nil)
+	if err != nil {
+		t.Fatalf("Mine: %v", err)
+	}
+	return anns
+}
+
+// TestProcessAnns ensures valid announcements are added to the pool and the
+// others are reported with the reason they were rejected.
+func TestProcessAnns(t *testing.T) {
+	c := &fakeChain{height: 2}
+	p := New(&Config{
+		MaxAnns:           DefaultMaxAnns,
+		BestHeight:        c.bestHeight,
+		BlockHashByHeight: c.blockHashByHeight,
+	})
+
+	anns := mineAnns(t, c, 1, 0x207fffff, 3)
+	tampered := *anns[0]
+	tampered.Header[100] ^= 1
+	future := *anns[1]
+	future.Header[12] = 5
+
+	hashes, errs := p.ProcessAnns([]*wire.PacketCryptAnn{anns[0], anns[1],
+		anns[2], anns[0], &tampered, &future})
+	if len(hashes) != 3 {
+		t.Fatalf("ProcessAnns: %d accepted, want 3: %v", len(hashes), errs)
+	}
+	for i := 0; i < 3; i++ {
+		if errs[i] != nil || hashes[i] != AnnHash(anns[i]) {
+			t.Fatalf("ProcessAnns: announcement %d: %v", i, errs[i])
+		}
+	}
+	if !ErrDuplicate.Is(errs[3]) {
+		t.Errorf("ProcessAnns: duplicate: %v", errs[3])
+	}
+	if !announce.Err.Is(errs[4]) {
+		t.Errorf("ProcessAnns: tampered: %v", errs[4])
+	}
+	if !announce.ErrUnknownParent.Is(errs[5]) {
+		t.Errorf("ProcessAnns: unknown parent: %v", errs[5])
+	}
+
+	if p.Count() != 3 || !p.HaveAnn(&hashes[1]) ||
+		p.FetchAnn(&hashes[1]) != anns[1] || len(p.AnnHashes()) != 3 {
+		t.Fatalf("unexpected pool content")
+	}
+	if len(p.AnnsAtHeight(1)) != 3 || len(p.AnnsAtHeight(0)) != 0 {
+		t.Fatalf("unexpected announcements by parent height")
+	}
+	// They can only be used once they have waited for the wait period.
+	if len(p.AnnsForBlock(3)) != 0 || len(p.AnnsForBlock(5)) != 3 {
+		t.Fatalf("AnnsForBlock: unexpected announcements")
+	}
+
+	// Processing them again only yields duplicates.
+	if dups, errs := p.ProcessAnns(anns); len(dups) != 0 ||
+		!ErrDuplicate.Is(errs[0]) {
+
+		t.Fatalf("ProcessAnns: duplicates accepted")
+	}
+
+	// The announcements are pruned when their parent block is no longer in
+	// the main chain.
+	c.salt = 1
+	c.height = 3
+	if p.Count() != 0 || p.HaveAnn(&hashes[0]) {
+		t.Fatalf("announcements of a stale parent block were not pruned")
+	}
+}
+
+// TestAnnPoolFull ensures a full pool only accepts announcements with more
+// work than the least of its announcements, which they replace.
+func TestAnnPoolFull(t *testing.T) {
+	c := &fakeChain{height: 1}
+	p := New(&Config{
+		MaxAnns:           2,
+		BestHeight:        c.bestHeight,
+		BlockHashByHeight: c.blockHashByHeight,
+	})
+
+	easy := mineAnns(t, c, 0, 0x207fffff, 3)
+	hashes, errs := p.ProcessAnns(easy)
+	if len(hashes) != 2 || !ErrPoolFull.Is(errs[2]) {
+		t.Fatalf("ProcessAnns: %d accepted: %v", len(hashes), errs)
+	}
+
+	hard := mineAnns(t, c, 0, 0x2007ffff, 1)
+	hashes, errs = p.ProcessAnns(hard)
+	if len(hashes) != 1 {
+		t.Fatalf("ProcessAnns: announcement with more work rejected: %v",
+			errs[0])
+	}
+	if p.Count() != 2 || !p.HaveAnn(&hashes[0]) {
+		t.Fatalf("unexpected pool content")
+	}
+	evicted := 0
+	for _, ann := range easy[:2] {
+		hash := AnnHash(ann)
+		if !p.HaveAnn(&hash) {
+			evicted++
+		}
+	}
+	if evicted != 1 {
+		t.Fatalf("%d announcements evicted, want 1", evicted)
+	}
+}
@@ -0,0 +1,31 @@
+package annpool
+
+import (
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// Err identifies a kind of error of the announcement pool.  Announcements
+// which fail validation are reported with the announce.Err codes instead.
+var Err er.ErrorType = er.NewErrorType("annpool.Err")
+
+// These constants are used to identify a specific announcement pool Error.
+var (
+	// ErrDuplicate indicates the announcement is already in the pool.
+	ErrDuplicate = Err.Code("ErrDuplicate")
+
+	// ErrSigned indicates the announcement has a signing key, so it can only
+	// be used in the blocks of its signer and is not worth relaying.
+	ErrSigned = Err.Code("ErrSigned")
+
+	// ErrExpired indicates the announcement is too old to be used in the next
+	// block.
+	ErrExpired = Err.Code("ErrExpired")
+
+	// ErrTarget indicates the work target of the announcement is too easy
+	// for the announcement to ever be used in a block.
+	ErrTarget = Err.Code("ErrTarget")
+
+	// ErrPoolFull indicates the pool is full of announcements which have
+	// more work than the announcement.
+	ErrPoolFull = Err.Code("ErrPoolFull")
+)
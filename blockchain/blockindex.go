@@ -306,6 +306,43 @@ func (bi *blockIndex) SetStatusFlags(node *blockNode, flags blockStatus) {
 	bi.Unlock()
 }
 
+// UnsetStatusFlags flips the provided status flags on the block node to off,
+// regardless of whether they were on or off previously.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) UnsetStatusFlags(node *blockNode, flags blockStatus) {
+	bi.Lock()
+	node.status &^= flags
+	bi.dirty[node] = struct{}{}
+	bi.Unlock()
+}
+
+// Descendants returns every node in the index which has the provided node as
+// an ancestor.  The provided node itself is not included.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) Descendants(node *blockNode) []*blockNode {
+	bi.RLock()
+	defer bi.RUnlock()
+
+	children := make(map[*blockNode][]*blockNode)
+	for _, n := range bi.index {
+		if n.parent != nil && n.height > node.height {
+			children[n.parent] = append(children[n.parent], n)
+		}
+	}
+
+	var descendants []*blockNode
+	queue := children[node]
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		descendants = append(descendants, n)
+		queue = append(queue, children[n]...)
+	}
+	return descendants
+}
+
 // flushToDB writes all dirty block nodes to the database. If all writes
 // succeed, this clears the dirty set.
 func (bi *blockIndex) flushToDB() er.R {
@@ -109,6 +109,8 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	pruneDepth          int32
+	beenPruned          bool
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -661,7 +663,9 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 			}
 		}
 
-		return nil
+		// Delete the data for blocks which are now deep enough to be
+		// pruned.
+		return b.pruneBlocks(dbTx, node)
 	})
 	if err != nil {
 		return err
@@ -1789,6 +1793,16 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// PruneDepth is the number of blocks below the best chain tip which
+	// must have their data retained.  The data for older blocks is deleted
+	// from the database as new blocks are connected.  It must be at least
+	// MinPruneDepth.
+	//
+	// This field can be zero if the caller does not wish to prune block
+	// data.  However, a database which has already been pruned can not be
+	// used without pruning.
+	PruneDepth uint32
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1803,6 +1817,10 @@ func New(config *Config) (*BlockChain, er.R) {
 	if config.TimeSource == nil {
 		return nil, AssertError("blockchain.New timesource is nil")
 	}
+	if config.PruneDepth != 0 && config.PruneDepth < MinPruneDepth {
+		return nil, er.Errorf("prune depth %d is less than the "+
+			"minimum of %d", config.PruneDepth, MinPruneDepth)
+	}
 
 	// Generate a checkpoint by height map from the provided checkpoints
 	// and assert the provided checkpoints are sorted by height as required.
@@ -1839,6 +1857,7 @@ func New(config *Config) (*BlockChain, er.R) {
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
 		hashCache:           config.HashCache,
+		pruneDepth:          int32(config.PruneDepth),
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
@@ -1858,6 +1877,22 @@ func New(config *Config) (*BlockChain, er.R) {
 		return nil, err
 	}
 
+	// Block data which has been pruned can not be recovered, so refuse to
+	// run without pruning once the database has been pruned.
+	err := b.db.View(func(dbTx database.Tx) er.R {
+		var err er.R
+		b.beenPruned, err = dbTx.BeenPruned()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.beenPruned && b.pruneDepth == 0 {
+		return nil, er.New("the database has been pruned, pruning can " +
+			"not be disabled without deleting the database and " +
+			"downloading the chain again")
+	}
+
 	// Initialize and catch up all of the currently active optional indexes
 	// as needed.
 	if config.IndexManager != nil {
@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"sort"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TipStatus describes the validation state of the block at the end of a
+// branch of the block tree.
+type TipStatus int
+
+const (
+	// TipActive indicates the tip is the end of the main chain.
+	TipActive TipStatus = iota
+
+	// TipInvalid indicates that the tip or at least one block in the
+	// branch leading up to it is known to be invalid.
+	TipInvalid
+
+	// TipValidFork indicates that every block in the branch has been fully
+	// validated, but the branch is not part of the main chain.
+	TipValidFork
+
+	// TipValidHeaders indicates that all blocks in the branch are stored,
+	// but at least one of them has not been fully validated.
+	TipValidHeaders
+
+	// TipHeadersOnly indicates that the payload of the tip block is not
+	// available.
+	TipHeadersOnly
+)
+
+// tipStatusStrings is a map of tip statuses back to their constant names for
+// pretty printing.
+var tipStatusStrings = map[TipStatus]string{
+	TipActive:       "active",
+	TipInvalid:      "invalid",
+	TipValidFork:    "valid-fork",
+	TipValidHeaders: "valid-headers",
+	TipHeadersOnly:  "headers-only",
+}
+
+// String returns the TipStatus in the human-readable form used by the
+// getchaintips RPC.
+func (s TipStatus) String() string {
+	if str, ok := tipStatusStrings[s]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+// ChainTip describes the block at the end of one branch of the block tree.
+type ChainTip struct {
+	// Height is the height of the tip block.
+	Height int32
+
+	// Hash is the hash of the tip block.
+	Hash chainhash.Hash
+
+	// BranchLen is the number of blocks between the tip and the point at
+	// which its branch forks from the main chain.  It is zero for the
+	// main chain tip.
+	BranchLen int32
+
+	// Status is the validation state of the branch.
+	Status TipStatus
+}
+
+// tipStatus returns the validation state of the branch which ends at the
+// provided node and forks from the main chain at forkNode.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) tipStatus(tip, forkNode *blockNode) TipStatus {
+	if tip == b.bestChain.Tip() {
+		return TipActive
+	}
+
+	status := TipValidFork
+	for n := tip; n != nil && n != forkNode; n = n.parent {
+		nodeStatus := b.index.NodeStatus(n)
+		switch {
+		case nodeStatus.KnownInvalid():
+			return TipInvalid
+		case nodeStatus&statusDataStored == 0:
+			if n == tip {
+				status = TipHeadersOnly
+			} else if status == TipValidFork {
+				status = TipValidHeaders
+			}
+		case !nodeStatus.KnownValid():
+			if status == TipValidFork {
+				status = TipValidHeaders
+			}
+		}
+	}
+	return status
+}
+
+// ChainTips returns information about the tips of every known branch of the
+// block tree, including the main chain.  Tips are returned ordered by height,
+// highest first.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// A tip is any node which no other node builds on, with the exception of
+	// the main chain tip which is always reported even when an unconnected
+	// block extends it.
+	bestTip := b.bestChain.Tip()
+	b.index.RLock()
+	hasChildren := make(map[*blockNode]struct{}, len(b.index.index))
+	for _, node := range b.index.index {
+		if node.parent != nil {
+			hasChildren[node.parent] = struct{}{}
+		}
+	}
+	tipNodes := []*blockNode{bestTip}
+	for _, node := range b.index.index {
+		if _, ok := hasChildren[node]; ok || node == bestTip {
+			continue
+		}
+		tipNodes = append(tipNodes, node)
+	}
+	b.index.RUnlock()
+
+	tips := make([]ChainTip, 0, len(tipNodes))
+	for _, node := range tipNodes {
+		forkNode := b.bestChain.FindFork(node)
+		var branchLen int32
+		if forkNode != nil {
+			branchLen = node.height - forkNode.height
+		}
+		tips = append(tips, ChainTip{
+			Height:    node.height,
+			Hash:      node.hash,
+			BranchLen: branchLen,
+			Status:    b.tipStatus(node, forkNode),
+		})
+	}
+
+	sort.SliceStable(tips, func(i, j int) bool {
+		return tips[i].Height > tips[j].Height
+	})
+	return tips
+}
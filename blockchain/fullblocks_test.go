@@ -139,9 +139,25 @@ func TestFullBlocks(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to generate tests: %v", err)
 	}
+	runFullBlockTests(t, "fullblocktest", tests)
+}
+
+// TestFullBlocksInvalidation ensures all tests generated by the
+// fullblocktests package for manually invalidating, reconsidering and
+// prioritizing blocks have the expected result.
+func TestFullBlocksInvalidation(t *testing.T) {
+	tests, err := fullblocktests.GenerateInvalidation()
+	if err != nil {
+		t.Fatalf("failed to generate tests: %v", err)
+	}
+	runFullBlockTests(t, "fullblockinvalidationtest", tests)
+}
 
+// runFullBlockTests processes the provided tests against a fresh chain
+// instance and ensures each test instance has the expected result.
+func runFullBlockTests(t *testing.T, dbName string, tests [][]fullblocktests.TestInstance) {
 	// Create a new database and chain instance to run tests against.
-	chain, teardownFunc, err := chainSetup("fullblocktest",
+	chain, teardownFunc, err := chainSetup(dbName,
 		&chaincfg.RegressionNetParams)
 	if err != nil {
 		t.Errorf("Failed to setup chain instance: %v", err)
@@ -289,6 +305,22 @@ func TestFullBlocks(t *testing.T) {
 		}
 	}
 
+	// testManipulatedBlock looks up the hash of the block in the provided
+	// test instance and passes it to the provided chain manipulation
+	// function, ensuring that it succeeds.
+	testManipulatedBlock := func(action, name string, msgBlock *wire.MsgBlock,
+		height int32, fn func(*chainhash.Hash) er.R) {
+
+		hash := msgBlock.BlockHash()
+		t.Logf("Testing %s block %s (hash %s, height %d)", action,
+			name, hash, height)
+
+		if err := fn(&hash); err != nil {
+			t.Fatalf("block %q (hash %s, height %d) could not be "+
+				"%s: %v", name, hash, height, action, err)
+		}
+	}
+
 	for testNum, test := range tests {
 		for itemNum, item := range test {
 			switch item := item.(type) {
@@ -302,6 +334,15 @@ func TestFullBlocks(t *testing.T) {
 				testOrphanOrRejectedBlock(item)
 			case fullblocktests.ExpectedTip:
 				testExpectedTip(item)
+			case fullblocktests.InvalidatedBlock:
+				testManipulatedBlock("invalidated", item.Name,
+					item.Block, item.Height, chain.InvalidateBlock)
+			case fullblocktests.ReconsideredBlock:
+				testManipulatedBlock("reconsidered", item.Name,
+					item.Block, item.Height, chain.ReconsiderBlock)
+			case fullblocktests.PreciousBlock:
+				testManipulatedBlock("marked precious", item.Name,
+					item.Block, item.Height, chain.PreciousBlock)
 			default:
 				t.Fatalf("test #%d, item #%d is not one of "+
 					"the supported test instance types -- "+
@@ -156,6 +156,59 @@ type RejectedNonCanonicalBlock struct {
 // This implements the TestInstance interface.
 func (b RejectedNonCanonicalBlock) FullBlockTestInstance() {}
 
+// InvalidatedBlock defines a test instance that expects a block to be
+// manually marked as invalid, as done by the invalidateblock RPC, without
+// error.
+type InvalidatedBlock struct {
+	Name   string
+	Block  *wire.MsgBlock
+	Height int32
+}
+
+// Ensure InvalidatedBlock implements the TestInstance interface.
+var _ TestInstance = InvalidatedBlock{}
+
+// FullBlockTestInstance only exists to allow InvalidatedBlock to be treated
+// as a TestInstance.
+//
+// This implements the TestInstance interface.
+func (b InvalidatedBlock) FullBlockTestInstance() {}
+
+// ReconsideredBlock defines a test instance that expects a block to have its
+// invalid status cleared, as done by the reconsiderblock RPC, without error.
+type ReconsideredBlock struct {
+	Name   string
+	Block  *wire.MsgBlock
+	Height int32
+}
+
+// Ensure ReconsideredBlock implements the TestInstance interface.
+var _ TestInstance = ReconsideredBlock{}
+
+// FullBlockTestInstance only exists to allow ReconsideredBlock to be treated
+// as a TestInstance.
+//
+// This implements the TestInstance interface.
+func (b ReconsideredBlock) FullBlockTestInstance() {}
+
+// PreciousBlock defines a test instance that expects a block to be preferred
+// over competing blocks with equal work, as done by the preciousblock RPC,
+// without error.
+type PreciousBlock struct {
+	Name   string
+	Block  *wire.MsgBlock
+	Height int32
+}
+
+// Ensure PreciousBlock implements the TestInstance interface.
+var _ TestInstance = PreciousBlock{}
+
+// FullBlockTestInstance only exists to allow PreciousBlock to be treated as
+// a TestInstance.
+//
+// This implements the TestInstance interface.
+func (b PreciousBlock) FullBlockTestInstance() {}
+
 // spendableOut represents a transaction output that is spendable along with
 // additional metadata such as the block its in and how much it pays.
 type spendableOut struct {
@@ -394,6 +447,16 @@ func additionalCoinbase(amount btcutil.Amount) func(*wire.MsgBlock) {
 	}
 }
 
+// uniqueCoinbase returns a function that itself takes a block and modifies it
+// by adding an OP_RETURN output with random data to the coinbase.  This
+// ensures blocks built at the same height on different branches do not end up
+// with the same hash.
+func uniqueCoinbase() func(*wire.MsgBlock) {
+	return func(b *wire.MsgBlock) {
+		b.Transactions[0].AddTxOut(wire.NewTxOut(0, uniqueOpReturnScript()))
+	}
+}
+
 // additionalSpendFee returns a function that itself takes a block and modifies
 // it by adding the provided fee to the spending transaction.
 //
@@ -0,0 +1,176 @@
+package fullblocktests
+
+import (
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/ruleerror"
+)
+
+// GenerateInvalidation returns a slice of tests that can be used to exercise
+// manual manipulation of the best chain, as done by the invalidateblock,
+// reconsiderblock and preciousblock RPCs.  Unlike the tests returned by
+// Generate, these tests can only be run directly against the blockchain code
+// since the manipulation is not something which happens over the
+// peer-to-peer network.
+func GenerateInvalidation() (tests [][]TestInstance, err er.R) {
+	// See Generate for why panics are used internally.
+	defer func() {
+		if r := recover(); r != nil {
+			tests = nil
+
+			switch rt := r.(type) {
+			case string:
+				err = er.New(rt)
+			case er.R:
+				err = rt
+			default:
+				err = er.Errorf("Unknown panic [%v]", r)
+			}
+		}
+	}()
+
+	g, err := makeTestGenerator(regressionNetParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Define some convenience helper functions to append test instances
+	// for the named block to the tests slice.
+	//
+	// accepted expects the current tip to be accepted to the main chain.
+	//
+	// acceptedToSideChainWithExpectedTip expects the current tip to be
+	// accepted to a side chain with the named block remaining the tip.
+	//
+	// rejected expects the current tip to be rejected with the provided
+	// code.
+	//
+	// rejectedWithExpectedTip expects the current tip to be rejected with the
+	// provided code and the named block to remain the tip.
+	//
+	// invalidated, reconsidered and precious manipulate the named block and
+	// then expect the second named block to be the tip.
+	instance := func(blockName string) (*wire.MsgBlock, int32) {
+		return g.blocksByName[blockName], g.blockHeights[blockName]
+	}
+	expectTipBlock := func(blockName string) TestInstance {
+		block, height := instance(blockName)
+		return ExpectedTip{blockName, block, height}
+	}
+	accepted := func() {
+		tests = append(tests, []TestInstance{
+			AcceptedBlock{g.tipName, g.tip, g.tipHeight, true, false},
+		})
+	}
+	acceptedToSideChainWithExpectedTip := func(tipName string) {
+		tests = append(tests, []TestInstance{
+			AcceptedBlock{g.tipName, g.tip, g.tipHeight, false, false},
+			expectTipBlock(tipName),
+		})
+	}
+	rejectedWithExpectedTip := func(code *er.ErrorCode, tipName string) {
+		tests = append(tests, []TestInstance{
+			RejectedBlock{g.tipName, g.tip, g.tipHeight, code},
+			expectTipBlock(tipName),
+		})
+	}
+	invalidated := func(blockName, tipName string) {
+		block, height := instance(blockName)
+		tests = append(tests, []TestInstance{
+			InvalidatedBlock{blockName, block, height},
+			expectTipBlock(tipName),
+		})
+	}
+	reconsidered := func(blockName, tipName string) {
+		block, height := instance(blockName)
+		tests = append(tests, []TestInstance{
+			ReconsideredBlock{blockName, block, height},
+			expectTipBlock(tipName),
+		})
+	}
+	precious := func(blockName, tipName string) {
+		block, height := instance(blockName)
+		tests = append(tests, []TestInstance{
+			PreciousBlock{blockName, block, height},
+			expectTipBlock(tipName),
+		})
+	}
+
+	// Build a main chain and a side chain with the same amount of work.
+	//
+	//   genesis -> a1 -> a2 -> a3
+	//                 \-> b2 -> b3
+	g.nextBlock("a1", nil, uniqueCoinbase())
+	accepted()
+	g.nextBlock("a2", nil, uniqueCoinbase())
+	accepted()
+	g.nextBlock("a3", nil, uniqueCoinbase())
+	accepted()
+
+	g.setTip("a1")
+	g.nextBlock("b2", nil, uniqueCoinbase())
+	acceptedToSideChainWithExpectedTip("a3")
+	g.nextBlock("b3", nil, uniqueCoinbase())
+	acceptedToSideChainWithExpectedTip("a3")
+
+	// Marking a side chain tip with equal work as precious makes it the
+	// tip, and marking the original tip precious switches back.  Marking a
+	// block which is already part of the main chain does nothing.
+	precious("b3", "b3")
+	precious("a3", "a3")
+	precious("a2", "a3")
+
+	// Invalidating a block in the main chain reorganizes to the side chain
+	// and further blocks building on the invalidated block are rejected.
+	//
+	//   genesis -> a1 -> a2 -> a3 -> a4
+	//                 \-> b2 -> b3
+	invalidated("a2", "b3")
+	g.setTip("a3")
+	g.nextBlock("a4", nil, uniqueCoinbase())
+	rejectedWithExpectedTip(ruleerror.ErrInvalidAncestorBlock, "b3")
+
+	// Invalidating the side chain as well leaves only the common ancestor.
+	invalidated("b2", "a1")
+
+	// Reconsidering a descendant of the invalidated block clears the
+	// ancestors too, so the original chain becomes the tip again.
+	reconsidered("a3", "a3")
+
+	// Reconsidering the other branch does not cause a reorganize since it
+	// has the same amount of work as the main chain.
+	reconsidered("b2", "a3")
+
+	// Extending the side chain makes it the main chain.
+	//
+	//   genesis -> a1 -> a2 -> a3
+	//                 \-> b2 -> b3 -> b4
+	g.setTip("b3")
+	g.nextBlock("b4", nil, uniqueCoinbase())
+	accepted()
+
+	// Create a branch with more work which contains a block that is only
+	// discovered to be invalid when it is connected.
+	//
+	//   genesis -> a1 -> a2 -> a3 -> c4(bad) -> c5
+	//                 \-> b2 -> b3 -> b4
+	g.setTip("a3")
+	g.nextBlock("c4", nil, uniqueCoinbase(), additionalCoinbase(1))
+	acceptedToSideChainWithExpectedTip("b4")
+	g.nextBlock("c5", nil, uniqueCoinbase())
+	rejectedWithExpectedTip(ruleerror.ErrBadCoinbaseValue, "b4")
+
+	// Invalidating the tip must skip the known bad branch and fall back to
+	// the best remaining valid block, preferring the current chain when
+	// work is equal.
+	invalidated("b4", "b3")
+
+	// Reconsidering the bad branch retries it, finds it is still invalid and
+	// leaves the tip alone.
+	reconsidered("c5", "b3")
+
+	// Reconsidering the invalidated tip restores it.
+	reconsidered("b4", "b4")
+
+	return tests, nil
+}
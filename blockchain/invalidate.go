@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire/ruleerror"
+)
+
+// bestValidNode returns the node with the most cumulative work out of all
+// stored nodes in the block index which are not known to be invalid and do not
+// have an ancestor which is known to be invalid.  When multiple nodes have the
+// same amount of work, a node which is already part of the main chain is
+// preferred so that no reorganize is triggered.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) bestValidNode() *blockNode {
+	b.index.RLock()
+	defer b.index.RUnlock()
+
+	// usable caches whether each node visited so far, along with all of its
+	// ancestors, is stored and not known to be invalid.
+	usable := make(map[*blockNode]bool, len(b.index.index))
+	isUsable := func(node *blockNode) bool {
+		var path []*blockNode
+		result := true
+		for n := node; n != nil; n = n.parent {
+			if ok, cached := usable[n]; cached {
+				result = ok
+				break
+			}
+			if n.status.KnownInvalid() || n.status&statusDataStored == 0 {
+				result = false
+				path = append(path, n)
+				break
+			}
+			path = append(path, n)
+		}
+		for _, n := range path {
+			usable[n] = result
+		}
+		return result
+	}
+
+	var best *blockNode
+	for _, node := range b.index.index {
+		if !isUsable(node) {
+			continue
+		}
+		if best == nil {
+			best = node
+			continue
+		}
+		switch node.workSum.Cmp(best.workSum) {
+		case 1:
+			best = node
+		case 0:
+			if !b.bestChain.Contains(best) && b.bestChain.Contains(node) {
+				best = node
+			}
+		}
+	}
+	return best
+}
+
+// activateBestChain reorganizes the main chain so that it ends at the valid
+// node with the most cumulative work.  Should a block on the selected branch
+// fail validation while connecting, it is marked invalid and the next best
+// branch is tried.
+//
+// This function may modify node statuses in the block index without flushing.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) activateBestChain() er.R {
+	for {
+		best := b.bestValidNode()
+		if best == nil {
+			return AssertError("no valid block to use as the chain tip")
+		}
+		if best == b.bestChain.Tip() {
+			return nil
+		}
+
+		detachNodes, attachNodes := b.getReorganizeNodes(best)
+		if detachNodes.Len() == 0 && attachNodes.Len() == 0 {
+			// The branch was found to contain an invalid block which
+			// has now been marked, so pick again.
+			continue
+		}
+
+		log.Infof("REORGANIZE: Activating best valid chain ending at %v "+
+			"(height %v)", best.hash, best.height)
+		err := b.reorganizeChain(detachNodes, attachNodes)
+		if err == nil {
+			return nil
+		}
+
+		// A rule violation on the new branch marks the offending block and
+		// its descendants as invalid so the next iteration will select a
+		// different branch.  Anything else, or a failure which did not
+		// result in the branch being marked, is returned to the caller.
+		if !ruleerror.Err.Is(err) || !b.index.NodeStatus(best).KnownInvalid() {
+			return err
+		}
+		log.Warnf("Unable to activate chain ending at %v: %v", best.hash, err)
+	}
+}
+
+// InvalidateBlock marks the block identified by the passed hash, along with
+// all of its descendants, as invalid.  If the block is part of the main chain,
+// the chain is reorganized onto the valid branch with the most cumulative work
+// which may be as short as the parent of the invalidated block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) er.R {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return er.Errorf("block %s is not known", hash)
+	}
+	if node.parent == nil {
+		return er.Errorf("block %s is the genesis block and cannot be "+
+			"invalidated", hash)
+	}
+
+	b.index.SetStatusFlags(node, statusValidateFailed)
+	for _, n := range b.index.Descendants(node) {
+		b.index.SetStatusFlags(n, statusInvalidAncestor)
+	}
+
+	var err er.R
+	if b.bestChain.Contains(node) {
+		err = b.activateBestChain()
+	}
+
+	// Write the updated statuses regardless of whether the reorganize
+	// succeeded so the invalidation survives a restart.
+	if writeErr := b.index.flushToDB(); writeErr != nil {
+		if err == nil {
+			return writeErr
+		}
+		log.Warnf("Error flushing block index changes to disk: %v", writeErr)
+	}
+	return err
+}
+
+// ReconsiderBlock removes the invalid status from the block identified by the
+// passed hash along with its ancestors and descendants, undoing the effects of
+// InvalidateBlock.  If a branch which is now considered valid has more
+// cumulative work than the main chain, the chain is reorganized onto it.  Blocks
+// which are not actually valid will be marked invalid again when the chain
+// attempts to connect them.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) er.R {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return er.Errorf("block %s is not known", hash)
+	}
+
+	const invalidFlags = statusValidateFailed | statusInvalidAncestor
+	for n := node; n != nil; n = n.parent {
+		if b.index.NodeStatus(n)&invalidFlags != 0 {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+	for _, n := range b.index.Descendants(node) {
+		if b.index.NodeStatus(n)&invalidFlags != 0 {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil {
+		if err == nil {
+			return writeErr
+		}
+		log.Warnf("Error flushing block index changes to disk: %v", writeErr)
+	}
+	return err
+}
+
+// PreciousBlock treats the block identified by the passed hash as if it had
+// been received before any competing block with the same amount of cumulative
+// work.  If the block is on a side chain with as much work as the main chain,
+// the chain is reorganized so that it becomes the tip.  Blocks with less work
+// than the current tip and blocks which are already in the main chain are left
+// alone.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PreciousBlock(hash *chainhash.Hash) er.R {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return er.Errorf("block %s is not known", hash)
+	}
+	if b.index.NodeStatus(node).KnownInvalid() {
+		return er.Errorf("block %s is known to be invalid", hash)
+	}
+	if b.bestChain.Contains(node) ||
+		node.workSum.Cmp(b.bestChain.Tip().workSum) < 0 {
+
+		return nil
+	}
+
+	detachNodes, attachNodes := b.getReorganizeNodes(node)
+	log.Infof("REORGANIZE: Block %v was marked precious.", node.hash)
+	err := b.reorganizeChain(detachNodes, attachNodes)
+	if writeErr := b.index.flushToDB(); writeErr != nil {
+		log.Warnf("Error flushing block index changes to disk: %v", writeErr)
+	}
+	return err
+}
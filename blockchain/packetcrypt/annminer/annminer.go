@@ -0,0 +1,449 @@
+// Package annminer implements a PacketCrypt announcement miner.
+//
+// For each hard nonce, the miner builds the table of announcement items and
+// its merkle tree, then searches the soft nonces for announcements whose work
+// hash meets the work target.  The announcements it produces are valid
+// according to packetcrypt.ValidatePcAnn.
+package annminer
+
+import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dchest/blake2b"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/cryptocycle"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/randhash/util"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// itemSize is the size of an item of the announcement table.
+	itemSize = 1024
+
+	// tableSize is the number of items in the announcement table.
+	tableSize = int(announce.AnnounceTableSz)
+
+	// maxSoftNonce is the highest soft nonce of a version 0 announcement,
+	// which is only limited by its 3 bytes.
+	maxSoftNonce = 0x00ffffff
+)
+
+// ErrBadProgram is returned when the random hash program of a hard nonce is
+// invalid, another hard nonce must then be used.
+var ErrBadProgram = er.GenericErrorType.CodeWithDetail("annminer.ErrBadProgram",
+	"invalid random hash program for this hard nonce")
+
+// Request describes the announcements to mine.
+type Request struct {
+	// Version is the version of the announcements, 0 or 1.  Version 1
+	// announcements require a parent block height of at least
+	// announce.Version1Height and version 0 announcements are not valid
+	// for blocks using PacketCrypt version 2 or higher.
+	Version uint8
+
+	// ParentBlockHash and ParentBlockHeight identify the block the
+	// announcements commit to.
+	ParentBlockHash   chainhash.Hash
+	ParentBlockHeight uint32
+
+	// WorkTarget is the target the work hash of the announcements must
+	// meet, in compact format.
+	WorkTarget uint32
+
+	// ContentType and Content are the content of the announcements.
+	// Content of up to 32 bytes is stored in the announcement, larger
+	// content is committed by its merkle root.
+	ContentType uint32
+	Content     []byte
+
+	// SigningKey is the optional ed25519 public key which the block miner
+	// must sign the announcements with.
+	SigningKey []byte
+}
+
+// Miner mines announcements for a request.
+type Miner struct {
+	header       [wire.PcAnnHeaderLen]byte
+	parentHash   chainhash.Hash
+	version      uint8
+	target       uint32
+	softNonceMax uint32
+	threads      int
+	hardNonce    uint32
+}
+
+// New returns a miner of the announcements described by the passed request,
+// which uses the passed number of threads, or one thread per CPU when it is 0.
+func New(req *Request, threads int) (*Miner, er.R) {
+	switch req.Version {
+	case 0:
+		if !difficulty.IsAnnMinDiffOk(req.WorkTarget, 1) {
+			return nil, er.Errorf("invalid work target %08x", req.WorkTarget)
+		}
+	case 1:
+		if req.ParentBlockHeight < announce.Version1Height {
+			return nil, er.Errorf("version 1 announcements require a "+
+				"parent block height of at least %d",
+				announce.Version1Height)
+		}
+		if !difficulty.IsAnnMinDiffOk(req.WorkTarget, 2) {
+			return nil, er.Errorf("invalid work target %08x", req.WorkTarget)
+		}
+	default:
+		return nil, er.Errorf("unsupported announcement version %d",
+			req.Version)
+	}
+	if len(req.SigningKey) != 0 && len(req.SigningKey) != 32 {
+		return nil, er.Errorf("signing key is %d bytes, want 32",
+			len(req.SigningKey))
+	}
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	m := &Miner{
+		parentHash:   req.ParentBlockHash,
+		version:      req.Version,
+		target:       req.WorkTarget,
+		softNonceMax: maxSoftNonce,
+		threads:      threads,
+	}
+	if req.Version > 0 {
+		m.softNonceMax = difficulty.Pc2AnnSoftNonceMax(req.WorkTarget)
+	}
+	h := m.header[:]
+	h[0] = req.Version
+	binary.LittleEndian.PutUint32(h[8:12], req.WorkTarget)
+	binary.LittleEndian.PutUint32(h[12:16], req.ParentBlockHeight)
+	binary.LittleEndian.PutUint32(h[16:20], req.ContentType)
+	binary.LittleEndian.PutUint32(h[20:24], uint32(len(req.Content)))
+	copy(h[24:56], ContentHash(req.Content))
+	copy(h[56:88], req.SigningKey)
+	return m, nil
+}
+
+// contentTree returns the layers of the merkle tree of the passed content,
+// starting with its 32 byte blocks, right-padded with zeros.  A node without
+// sibling is moved up the tree.
+func contentTree(content []byte) [][][]byte {
+	layer := make([][]byte, 0, (len(content)+31)/32)
+	for i := 0; i < len(content); i += 32 {
+		block := make([]byte, 32)
+		copy(block, content[i:])
+		layer = append(layer, block)
+	}
+	layers := [][][]byte{layer}
+	for len(layer) > 1 {
+		next := make([][]byte, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			b2 := blake2b.New256()
+			b2.Write(layer[i])
+			b2.Write(layer[i+1])
+			next = append(next, b2.Sum(nil))
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+	return layers
+}
+
+// ContentHash returns the content hash of an announcement with the passed
+// content.  Content of up to 32 bytes is its own hash, right-padded with
+// zeros, larger content is hashed as a merkle tree of 32 byte blocks.
+func ContentHash(content []byte) []byte {
+	if len(content) <= 32 {
+		out := make([]byte, 32)
+		copy(out, content)
+		return out
+	}
+	layers := contentTree(content)
+	return layers[len(layers)-1][0]
+}
+
+// ContentProof returns the proof of the content of an announcement which a
+// block with the passed content proof index must provide, it is the block
+// selected by the index followed by its siblings up the tree.  Content of up
+// to 32 bytes needs no proof.
+func ContentProof(content []byte, proofIdx uint32) []byte {
+	if len(content) <= 32 {
+		return nil
+	}
+	layers := contentTree(content)
+	blockToProve := proofIdx % uint32(len(layers[0]))
+	out := append([]byte{}, layers[0][blockToProve]...)
+	for _, layer := range layers[:len(layers)-1] {
+		if sibling := blockToProve ^ 1; sibling < uint32(len(layer)) {
+			out = append(out, layer[sibling]...)
+		}
+		blockToProve >>= 1
+	}
+	return out
+}
+
+// table is the state of the miner for a hard nonce.
+type table struct {
+	// header is the announcement header with the hard nonce and a zero
+	// soft nonce.
+	header [wire.PcAnnHeaderLen]byte
+
+	// annHash1 is the hash of the header and the merkle root, it seeds the
+	// cryptocycle state of each soft nonce.
+	annHash1 [64]byte
+
+	// tree is the merkle tree of the hashes of the items, from the leaves
+	// to the root.
+	tree [][][64]byte
+
+	// items are the items which are hashed with each soft nonce.  For
+	// version 0 announcements they are the items of the merkle tree, for
+	// version 1 they are made with a seed derived from the merkle root.
+	items []byte
+}
+
+// parallel calls the passed function for 0 to n-1 using the passed number of
+// threads.  It stops calling it once it returned false and returns whether it
+// returned true for every call.
+func parallel(threads, n int, f func(i int) bool) bool {
+	var failed int32
+	var wg sync.WaitGroup
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			for i := t; i < n && atomic.LoadInt32(&failed) == 0; i += threads {
+				if !f(i) {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}(t)
+	}
+	wg.Wait()
+	return failed == 0
+}
+
+// annHash returns the hash of the passed announcement header and 64 bytes
+// which are either the parent block hash or the merkle root.
+func annHash(header []byte, b []byte) [64]byte {
+	var buf [wire.PcAnnHeaderLen + 64]byte
+	copy(buf[:], header)
+	copy(buf[wire.PcAnnHeaderLen:], b)
+	var out [64]byte
+	pcutil.HashCompress64(out[:], buf[:])
+	return out
+}
+
+// mkItems makes all the items of a version 1 table with the passed 64 byte
+// seed.  The items are stored in the passed buffer and their hashes, if the
+// hashes slice is not nil.
+func (m *Miner) mkItems(seed []byte, items []byte, hashes [][64]byte) er.R {
+	var prog announce.MkItem2Program
+	if announce.MkItem2Prog(&prog, seed[:32]) != 0 {
+		return ErrBadProgram.Default()
+	}
+	ok := parallel(m.threads, tableSize, func(i int) bool {
+		item := items[i*itemSize:][:itemSize]
+		if announce.MkItem2(i, item, seed[32:], &prog) != 0 {
+			return false
+		}
+		if hashes != nil {
+			pcutil.HashCompress64(hashes[i][:], item)
+		}
+		return true
+	})
+	if !ok {
+		return ErrBadProgram.Default()
+	}
+	return nil
+}
+
+// newTable builds the table of a hard nonce.
+func (m *Miner) newTable(hardNonce uint32) (*table, er.R) {
+	t := &table{header: m.header}
+	binary.LittleEndian.PutUint32(t.header[4:8], hardNonce)
+	annHash0 := annHash(t.header[:], m.parentHash[:])
+
+	// Make the items of the merkle tree and hash them.
+	t.items = make([]byte, tableSize*itemSize)
+	leaves := make([][64]byte, tableSize)
+	if m.version > 0 {
+		if err := m.mkItems(annHash0[:], t.items, leaves); err != nil {
+			return nil, err
+		}
+	} else {
+		parallel(m.threads, tableSize, func(i int) bool {
+			var item [itemSize]byte
+			announce.MkItem(i, &item, annHash0[:32])
+			copy(t.items[i*itemSize:], item[:])
+			pcutil.HashCompress64(leaves[i][:], item[:])
+			return true
+		})
+	}
+
+	// Build the merkle tree.
+	t.tree = [][][64]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][64]byte, len(level)/2)
+		for i := range next {
+			var buf [128]byte
+			copy(buf[:64], level[2*i][:])
+			copy(buf[64:], level[2*i+1][:])
+			pcutil.HashCompress64(next[i][:], buf[:])
+		}
+		t.tree = append(t.tree, next)
+		level = next
+	}
+	root := t.tree[len(t.tree)-1][0]
+	t.annHash1 = annHash(t.header[:], root[:])
+
+	// Version 1 announcements hash items made with a seed derived from the
+	// merkle root, the items of the merkle tree are no longer needed.
+	if m.version > 0 {
+		var buf [128]byte
+		copy(buf[:64], root[:])
+		copy(buf[64:], annHash0[:])
+		var seed [64]byte
+		pcutil.HashCompress64(seed[:], buf[:])
+		if err := m.mkItems(seed[:], t.items, nil); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// tryNonce computes the work hash of a soft nonce and returns the
+// announcement if it meets the work target.
+func (m *Miner) tryNonce(t *table, softNonce uint32, state *cryptocycle.State,
+	progBuf *cryptocycle.Context) *wire.PacketCryptAnn {
+
+	randHashCycles := util.Conf_AnnHash_RANDHASH_CYCLES
+	if m.version > 0 {
+		randHashCycles = 0
+	}
+	cryptocycle.Init(state, t.annHash1[:32], uint64(softNonce))
+	itemNo := 0
+	for i := 0; i < 4; i++ {
+		itemNo = int(cryptocycle.GetItemNo(state) % announce.AnnounceTableSz)
+		item := t.items[itemNo*itemSize:][:itemSize]
+		if !cryptocycle.Update(state, item, nil, randHashCycles, progBuf) {
+			return nil
+		}
+	}
+	cryptocycle.Final(state)
+	if !difficulty.IsOk(state.Bytes[:32], m.target) {
+		return nil
+	}
+
+	ann := &wire.PacketCryptAnn{}
+	copy(ann.Header[:], t.header[:])
+	var nonce [4]byte
+	binary.LittleEndian.PutUint32(nonce[:], softNonce)
+	copy(ann.GetSoftNonce(), nonce[:3])
+
+	// The merkle proof of the last item followed by the root.
+	proof := ann.GetMerkleProof()
+	idx := itemNo
+	for i := 0; i < announce.AnnounceMerkleDepth; i++ {
+		copy(proof[i*64:][:64], t.tree[i][idx^1][:])
+		idx >>= 1
+	}
+	copy(proof[announce.AnnounceMerkleDepth*64:], t.tree[len(t.tree)-1][0][:])
+
+	if m.version > 0 {
+		// The item 4 prefix is zero and the announcement is encrypted
+		// with the final state.
+		return announce.AnnDecrypt(ann, state)
+	}
+	copy(ann.GetItem4Prefix(), t.items[itemNo*itemSize:])
+	return ann
+}
+
+// mineTable searches the soft nonces of a table for announcements, it stops
+// once limit announcements were found, if limit is not 0, or when quit is
+// closed.
+func (m *Miner) mineTable(t *table, limit int,
+	quit <-chan struct{}) []*wire.PacketCryptAnn {
+
+	var mtx sync.Mutex
+	var anns []*wire.PacketCryptAnn
+	var done int32
+	var wg sync.WaitGroup
+	for th := 0; th < m.threads; th++ {
+		wg.Add(1)
+		go func(th uint32) {
+			defer wg.Done()
+			state := new(cryptocycle.State)
+			progBuf := new(cryptocycle.Context)
+			for n := uint64(th); n <= uint64(m.softNonceMax); n += uint64(m.threads) {
+				if atomic.LoadInt32(&done) != 0 {
+					return
+				}
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				ann := m.tryNonce(t, uint32(n), state, progBuf)
+				if ann == nil {
+					continue
+				}
+				mtx.Lock()
+				if limit == 0 || len(anns) < limit {
+					anns = append(anns, ann)
+				}
+				if limit != 0 && len(anns) >= limit {
+					atomic.StoreInt32(&done, 1)
+				}
+				mtx.Unlock()
+			}
+		}(uint32(th))
+	}
+	wg.Wait()
+	return anns
+}
+
+// MineHardNonce returns all the announcements of a hard nonce which meet the
+// work target, or those found until quit is closed.
+func (m *Miner) MineHardNonce(hardNonce uint32,
+	quit <-chan struct{}) ([]*wire.PacketCryptAnn, er.R) {
+
+	t, err := m.newTable(hardNonce)
+	if err != nil {
+		return nil, err
+	}
+	return m.mineTable(t, 0, quit), nil
+}
+
+// Mine returns count announcements, or those found until quit is closed.  The
+// hard nonces are tried in sequence and calling Mine again continues with the
+// next hard nonce.
+func (m *Miner) Mine(count int, quit <-chan struct{}) ([]*wire.PacketCryptAnn, er.R) {
+	var anns []*wire.PacketCryptAnn
+	for len(anns) < count {
+		select {
+		case <-quit:
+			return anns, nil
+		default:
+		}
+		hardNonce := m.hardNonce
+		m.hardNonce++
+		t, err := m.newTable(hardNonce)
+		if ErrBadProgram.Is(err) {
+			continue
+		} else if err != nil {
+			return anns, err
+		}
+		anns = append(anns, m.mineTable(t, count-len(anns), quit)...)
+	}
+	return anns, nil
+}
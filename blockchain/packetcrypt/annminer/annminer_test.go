@@ -0,0 +1,202 @@
+package annminer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestMine ensures mined announcements of both versions are valid and carry
+// the requested fields.
+func TestMine(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	signingKey := bytes.Repeat([]byte{0x42}, 32)
+	content := bytes.Repeat([]byte("content "), 20)
+
+	tests := []struct {
+		name       string
+		req        annminer.Request
+		pcVersion  int
+		wantHeight uint32
+	}{
+		{
+			name: "version 0",
+			req: annminer.Request{
+				Version:           0,
+				ParentBlockHash:   parentHash,
+				ParentBlockHeight: 1000,
+				WorkTarget:        0x207fffff,
+				ContentType:       1,
+				Content:           []byte("short content"),
+			},
+			pcVersion: 1,
+		},
+		{
+			name: "version 1",
+			req: annminer.Request{
+				Version:           1,
+				ParentBlockHash:   parentHash,
+				ParentBlockHeight: announce.Version1Height,
+				WorkTarget:        0x207fffff,
+				ContentType:       2,
+				Content:           content,
+				SigningKey:        signingKey,
+			},
+			pcVersion: 2,
+		},
+	}
+
+	for _, test := range tests {
+		m, err := annminer.New(&test.req, 2)
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+		anns, err := m.Mine(3, nil)
+		if err != nil {
+			t.Fatalf("%s: Mine: %v", test.name, err)
+		}
+		if len(anns) != 3 {
+			t.Fatalf("%s: mined %d announcements, want 3", test.name,
+				len(anns))
+		}
+		for i, ann := range anns {
+			hash, err := packetcrypt.ValidatePcAnn(ann, &parentHash,
+				test.pcVersion)
+			if err != nil {
+				t.Fatalf("%s: announcement %d is invalid: %v", test.name,
+					i, err)
+			}
+			if !difficulty.IsOk(hash[:], test.req.WorkTarget) {
+				t.Fatalf("%s: announcement %d does not meet the target",
+					test.name, i)
+			}
+			if ann.GetVersion() != uint(test.req.Version) ||
+				ann.GetParentBlockHeight() != test.req.ParentBlockHeight ||
+				ann.GetWorkTarget() != test.req.WorkTarget ||
+				ann.GetContentLength() != uint32(len(test.req.Content)) ||
+				!bytes.Equal(ann.GetContentHash(),
+					annminer.ContentHash(test.req.Content)) {
+				t.Fatalf("%s: announcement %d has unexpected fields",
+					test.name, i)
+			}
+			if test.req.SigningKey != nil &&
+				!bytes.Equal(ann.GetSigningKey(), test.req.SigningKey) {
+				t.Fatalf("%s: announcement %d has the wrong signing key",
+					test.name, i)
+			}
+		}
+
+		// The announcements do not commit to another parent block.
+		otherHash := chainhash.DoubleHashH([]byte("other"))
+		if _, err := packetcrypt.ValidatePcAnn(anns[0], &otherHash,
+			test.pcVersion); err == nil {
+			t.Fatalf("%s: announcement is valid with another parent",
+				test.name)
+		}
+
+		// Tampering with the merkle proof invalidates the announcement.
+		tampered := *anns[0]
+		tampered.GetMerkleProof()[0] ^= 1
+		if _, err := packetcrypt.ValidatePcAnn(&tampered, &parentHash,
+			test.pcVersion); err == nil {
+			t.Fatalf("%s: tampered announcement is valid", test.name)
+		}
+	}
+}
+
+// TestMineHardNonce ensures all the announcements of a hard nonce meet the
+// target and use distinct soft nonces.
+func TestMineHardNonce(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	req := annminer.Request{
+		Version:           1,
+		ParentBlockHash:   parentHash,
+		ParentBlockHeight: announce.Version1Height + 10,
+		WorkTarget:        0x2000ffff,
+	}
+	m, err := annminer.New(&req, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var anns []*wire.PacketCryptAnn
+	for hardNonce := uint32(0); len(anns) == 0; hardNonce++ {
+		anns, err = m.MineHardNonce(hardNonce, nil)
+		if annminer.ErrBadProgram.Is(err) {
+			continue
+		} else if err != nil {
+			t.Fatalf("MineHardNonce: %v", err)
+		}
+	}
+	softNonces := make(map[string]struct{})
+	for i, ann := range anns {
+		if _, err := packetcrypt.ValidatePcAnn(ann, &parentHash, 2); err != nil {
+			t.Fatalf("announcement %d is invalid: %v", i, err)
+		}
+		softNonces[string(ann.GetSoftNonce())] = struct{}{}
+	}
+	if len(softNonces) != len(anns) {
+		t.Fatalf("announcements share soft nonces")
+	}
+}
+
+// TestNewErrors ensures invalid requests are rejected.
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  annminer.Request
+	}{
+		{
+			name: "unsupported version",
+			req:  annminer.Request{Version: 2, WorkTarget: 0x207fffff},
+		},
+		{
+			name: "version 1 below its activation height",
+			req: annminer.Request{
+				Version:           1,
+				ParentBlockHeight: announce.Version1Height - 1,
+				WorkTarget:        0x207fffff,
+			},
+		},
+		{
+			name: "invalid target",
+			req:  annminer.Request{WorkTarget: 0},
+		},
+		{
+			name: "short signing key",
+			req: annminer.Request{
+				WorkTarget: 0x207fffff,
+				SigningKey: make([]byte, 31),
+			},
+		},
+	}
+	for _, test := range tests {
+		if _, err := annminer.New(&test.req, 1); err == nil {
+			t.Fatalf("%s: New succeeded", test.name)
+		}
+	}
+}
+
+// TestContentHash ensures short content is stored as is and larger content is
+// hashed.
+func TestContentHash(t *testing.T) {
+	short := []byte("short")
+	want := make([]byte, 32)
+	copy(want, short)
+	if got := annminer.ContentHash(short); !bytes.Equal(got, want) {
+		t.Fatalf("unexpected short content hash %x", got)
+	}
+
+	// Content of 3 blocks, the last one is moved up the tree.
+	long := bytes.Repeat([]byte{1}, 70)
+	h1 := annminer.ContentHash(long[:64])
+	h2 := annminer.ContentHash(long)
+	if bytes.Equal(h1, h2) || len(h2) != 32 {
+		t.Fatalf("unexpected long content hashes %x and %x", h1, h2)
+	}
+}
@@ -0,0 +1,59 @@
+package announce
+
+import (
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// Err identifies a kind of announcement validation error, the number of each
+// error code is stable so it can be reported to remote miners.
+var Err er.ErrorType = er.NewErrorType("announce.Err")
+
+// These constants are used to identify a specific announcement Error.
+var (
+	// ErrVersionNotAllowed indicates the announcement version is not
+	// allowed at the parent block height of the announcement.
+	ErrVersionNotAllowed = Err.CodeWithNumber("Validate_checkAnn_ANN_VERSION_NOT_ALLOWED", 1)
+
+	// ErrVersionMismatch indicates the announcement version is not valid
+	// with the PacketCrypt version.
+	ErrVersionMismatch = Err.CodeWithNumber("Validate_checkAnn_ANN_VERSION_MISMATCH", 2)
+
+	// ErrSoftNonceHigh indicates the soft nonce of the announcement is
+	// above the maximum for its work target.
+	ErrSoftNonceHigh = Err.CodeWithNumber("Validate_checkAnn_SOFT_NONCE_HIGH", 3)
+
+	// ErrBadProgram indicates the item program could not be generated.
+	ErrBadProgram = Err.CodeWithNumber("Validate_checkAnn_BAD_PROGRAM", 4)
+
+	// ErrBadProgramExec indicates the item program failed to execute.
+	ErrBadProgramExec = Err.CodeWithNumber("Validate_checkAnn_BAD_PROGRAM_EXEC", 5)
+
+	// ErrInvalid indicates the cryptocycle of the announcement failed.
+	ErrInvalid = Err.CodeWithNumber("Validate_checkAnn_INVAL", 6)
+
+	// ErrInvalidItem4 indicates the item 4 prefix of the announcement does
+	// not match the item.
+	ErrInvalidItem4 = Err.CodeWithNumber("Validate_checkAnn_INVAL_ITEM4", 7)
+
+	// ErrBadProgram0 indicates the program of the item 4 could not be
+	// generated.
+	ErrBadProgram0 = Err.CodeWithNumber("Validate_checkAnn_BAD_PROGRAM0", 8)
+
+	// ErrBadProgram0Exec indicates the program of the item 4 failed to
+	// execute.
+	ErrBadProgram0Exec = Err.CodeWithNumber("Validate_checkAnn_BAD_PROGRAM0_EXEC", 9)
+
+	// ErrInvalidMerkle indicates the merkle proof of the item 4 is invalid.
+	ErrInvalidMerkle = Err.CodeWithNumber("Validate_checkAnn_INVAL_MERKLE", 10)
+
+	// ErrInsufficientPow indicates the work hash of the announcement does
+	// not meet its work target.
+	ErrInsufficientPow = Err.CodeWithNumber("Validate_checkAnn_INSUF_POW", 11)
+
+	// ErrMalformed indicates the announcement could not be decoded.
+	ErrMalformed = Err.CodeWithNumber("Validate_checkAnn_MALFORMED", 12)
+
+	// ErrUnknownParent indicates the parent block of the announcement is
+	// not known.
+	ErrUnknownParent = Err.CodeWithNumber("Validate_checkAnn_UNKNOWN_PARENT", 13)
+)
@@ -7,6 +7,7 @@ package announce
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	"github.com/pkt-cash/pktd/btcutil/er"
 
@@ -20,8 +21,16 @@ import (
 	"github.com/pkt-cash/pktd/wire"
 )
 
-const announceMerkleDepth int = 13
-const announceTableSz uint64 = 1 << uint(announceMerkleDepth)
+// AnnounceMerkleDepth is the depth of the merkle tree of the announcement
+// table.
+const AnnounceMerkleDepth int = 13
+
+// AnnounceTableSz is the number of items in the announcement table.
+const AnnounceTableSz uint64 = 1 << uint(AnnounceMerkleDepth)
+
+// Version1Height is the lowest parent block height of version 1
+// announcements.
+const Version1Height = 103869
 
 type context struct {
 	itemBytes [1024]byte
@@ -49,6 +58,7 @@ func memocycle(item *[1024]byte, bufcount, cycles int) {
 
 const announceItemHashcount int = 1024 / 64
 
+// MkItem makes an item of the table of a version 0 announcement.
 func MkItem(itemNo int, item *[1024]byte, seed []byte) {
 	pcutil.HashExpand(item[:64], seed, uint32(itemNo))
 	for i := 1; i < announceItemHashcount; i++ {
@@ -57,12 +67,16 @@ func MkItem(itemNo int, item *[1024]byte, seed []byte) {
 	memocycle(item, announceItemHashcount, util.Conf_AnnHash_MEMOHASH_CYCLES)
 }
 
-type mkItem2Program struct {
+// MkItem2Program is the program used to make the items of a version 1
+// announcement.
+type MkItem2Program struct {
 	memory [8192]byte
 	prog   []uint32
 }
 
-func mkItem2Prog(out *mkItem2Program, seed []byte) int {
+// MkItem2Prog generates the program for the passed 32 byte seed, it returns -1
+// when the program is invalid.
+func MkItem2Prog(out *MkItem2Program, seed []byte) int {
 	pcutil.HashExpand(out.memory[:], seed, 0)
 	prog, err := randgen.Generate(seed)
 	if err != nil {
@@ -75,7 +89,9 @@ func mkItem2Prog(out *mkItem2Program, seed []byte) int {
 	return 0
 }
 
-func mkItem2(itemNo int, item []byte, seed []byte, prog *mkItem2Program) int {
+// MkItem2 makes an item of the table of a version 1 announcement, it returns -1
+// when the program fails.
+func MkItem2(itemNo int, item []byte, seed []byte, prog *MkItem2Program) int {
 	state := cryptocycle.State{}
 	cryptocycle.Init(&state, seed, uint64(itemNo))
 	memoryBeginning := itemNo % ((len(prog.memory) / 4) - interpret.RandHash_MEMORY_SZ)
@@ -93,7 +109,10 @@ func mkItem2(itemNo int, item []byte, seed []byte, prog *mkItem2Program) int {
 	return 0
 }
 
-func annDecrypt(pcAnn *wire.PacketCryptAnn, state *cryptocycle.State) *wire.PacketCryptAnn {
+// AnnDecrypt decrypts the merkle proof and item 4 prefix of a version 1
+// announcement with the final cryptocycle state.  Since the cipher is a XOR,
+// it also encrypts them.
+func AnnDecrypt(pcAnn *wire.PacketCryptAnn, state *cryptocycle.State) *wire.PacketCryptAnn {
 	out := wire.PacketCryptAnn{}
 	copy(out.Header[:], pcAnn.Header[:])
 	j := 0
@@ -111,19 +130,19 @@ func annDecrypt(pcAnn *wire.PacketCryptAnn, state *cryptocycle.State) *wire.Pack
 func merkleIsValid(merkleProof []byte, item4Hash *[64]byte, itemNo int) bool {
 	var buf [128]byte
 	copy(buf[64*(itemNo&1):][:64], item4Hash[:])
-	for i := 0; i < announceMerkleDepth; i++ {
+	for i := 0; i < AnnounceMerkleDepth; i++ {
 		copy(buf[64*((^itemNo)&1):][:64], merkleProof[i*64:][:64])
 		itemNo >>= 1
 		pcutil.HashCompress64(buf[64*(itemNo&1):][:64], buf[:])
 	}
-	return bytes.Equal(buf[64*(itemNo&1):][:64], merkleProof[64*announceMerkleDepth:])
+	return bytes.Equal(buf[64*(itemNo&1):][:64], merkleProof[64*AnnounceMerkleDepth:])
 }
 
 func CheckAnn(pcAnn *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash, packetCryptVersion int) (*chainhash.Hash, er.R) {
-	if pcAnn.GetVersion() > 0 && pcAnn.GetParentBlockHeight() < 103869 {
-		return nil, er.New("Validate_checkAnn_ANN_VERSION_NOT_ALLOWED")
+	if pcAnn.GetVersion() > 0 && pcAnn.GetParentBlockHeight() < Version1Height {
+		return nil, ErrVersionNotAllowed.Default()
 	} else if packetCryptVersion > 1 && pcAnn.GetVersion() == 0 {
-		return nil, er.New("Validate_checkAnn_ANN_VERSION_MISMATCH")
+		return nil, ErrVersionMismatch.Default()
 	}
 	ctx := new(context)
 	copy(ctx.ann.GetAnnounceHeader(), pcAnn.GetAnnounceHeader())
@@ -139,28 +158,28 @@ func CheckAnn(pcAnn *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash, packe
 	mkItemSeed := ctx.annHash0[:32]
 	randHashCycles := util.Conf_AnnHash_RANDHASH_CYCLES
 	version := pcAnn.GetVersion()
-	prog := mkItem2Program{}
+	prog := MkItem2Program{}
 	if version > 0 {
 		randHashCycles = 0
 		if softNonce > difficulty.Pc2AnnSoftNonceMax(pcAnn.GetWorkTarget()) {
-			return nil, er.New("Validate_checkAnn_SOFT_NONCE_HIGH")
+			return nil, ErrSoftNonceHigh.Default()
 		}
 		buf := make([]byte, 64*2)
 		copy(buf[:64], pcAnn.GetMerkleProof()[13*64:])
 		copy(buf[64:], ctx.annHash0[:])
 		pcutil.HashCompress64(buf[:64], buf)
 		mkItemSeed = buf[:64]
-		if mkItem2Prog(&prog, mkItemSeed[:32]) != 0 {
-			return nil, er.New("Validate_checkAnn_BAD_PROGRAM")
+		if MkItem2Prog(&prog, mkItemSeed[:32]) != 0 {
+			return nil, ErrBadProgram.Default()
 		}
 	}
 	cryptocycle.Init(&ctx.ccState, ctx.annHash1[:32], uint64(softNonce))
 	itemNo := -1
 	for i := 0; i < 4; i++ {
-		itemNo = int(cryptocycle.GetItemNo(&ctx.ccState) % announceTableSz)
+		itemNo = int(cryptocycle.GetItemNo(&ctx.ccState) % AnnounceTableSz)
 		if version > 0 {
-			if mkItem2(itemNo, ctx.itemBytes[:], mkItemSeed[32:], &prog) != 0 {
-				return nil, er.New("Validate_checkAnn_BAD_PROGRAM_EXEC")
+			if MkItem2(itemNo, ctx.itemBytes[:], mkItemSeed[32:], &prog) != 0 {
+				return nil, ErrBadProgramExec.Default()
 			}
 		} else {
 			// only 32 bytes of the seed are used
@@ -168,33 +187,33 @@ func CheckAnn(pcAnn *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash, packe
 		}
 		if !cryptocycle.Update(
 			&ctx.ccState, ctx.itemBytes[:], nil, randHashCycles, &ctx.progBuf) {
-			return nil, er.New("Validate_checkAnn_INVAL")
+			return nil, ErrInvalid.Default()
 		}
 	}
 
 	cryptocycle.Final(&ctx.ccState)
 	if version > 0 {
-		pcAnn = annDecrypt(pcAnn, &ctx.ccState)
+		pcAnn = AnnDecrypt(pcAnn, &ctx.ccState)
 	}
 
 	//fmt.Printf("%s\n", hex.EncodeToString(pcAnn.Header[:]))
 
 	if version > 0 {
 		if !pcutil.IsZero(pcAnn.GetItem4Prefix()) {
-			return nil, er.New("Validate_checkAnn_INVAL_ITEM4")
+			return nil, ErrInvalidItem4.Default()
 		}
-		if mkItem2Prog(&prog, ctx.annHash0[:32]) != 0 {
-			return nil, er.New("Validate_checkAnn_BAD_PROGRAM0")
+		if MkItem2Prog(&prog, ctx.annHash0[:32]) != 0 {
+			return nil, ErrBadProgram0.Default()
 		}
-		if mkItem2(itemNo, ctx.itemBytes[:], ctx.annHash0[32:], &prog) != 0 {
-			return nil, er.New("Validate_checkAnn_BAD_PROGRAM0_EXEC")
+		if MkItem2(itemNo, ctx.itemBytes[:], ctx.annHash0[32:], &prog) != 0 {
+			return nil, ErrBadProgram0Exec.Default()
 		}
 	} else if !bytes.Equal(ctx.itemBytes[:wire.PcItem4PrefixLen], pcAnn.GetItem4Prefix()) {
-		return nil, er.New("Validate_checkAnn_INVAL_ITEM4")
+		return nil, ErrInvalidItem4.Default()
 	}
 	pcutil.HashCompress64(ctx.item4Hash[:], ctx.itemBytes[:])
 	if !merkleIsValid(pcAnn.GetMerkleProof(), &ctx.item4Hash, itemNo) {
-		return nil, er.New("Validate_checkAnn_INVAL_MERKLE")
+		return nil, ErrInvalidMerkle.Default()
 	}
 
 	target := pcAnn.GetWorkTarget()
@@ -202,8 +221,8 @@ func CheckAnn(pcAnn *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash, packe
 	h := chainhash.Hash{}
 	copy(h[:], ctx.ccState.Bytes[:32])
 	if !difficulty.IsOk(ctx.ccState.Bytes[:32], target) {
-		return &h, er.Errorf("Validate_checkAnn_INSUF_POW need target [%x] "+
-			"but ann work hash is [%s]", target, h.String())
+		return &h, ErrInsufficientPow.New(fmt.Sprintf("need target [%x] "+
+			"but ann work hash is [%s]", target, h.String()), nil)
 	}
 
 	return &h, nil
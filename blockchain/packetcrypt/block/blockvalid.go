@@ -34,15 +34,15 @@ func isWorkOk(ccState *cryptocycle.State, cb *wire.PcCoinbaseCommit, target uint
 	return difficulty.IsOk(ccState.Bytes[:32], effectiveTarget)
 }
 
-func isPcHashOk(
+// pcHash computes the PacketCrypt hash of the block header and proof, it
+// outputs the numbers of the announcements which the nonce selects in
+// indexesOut.
+func pcHash(
 	indexesOut *[4]uint64,
 	blockHeader *wire.BlockHeader,
 	proof *wire.PacketCryptProof,
-	cb *wire.PcCoinbaseCommit,
-	shareTarget uint32,
 	contentProofs [][]byte,
-	packetCryptVersion int,
-) (bool, bool) {
+) *cryptocycle.State {
 	ccState := new(cryptocycle.State)
 
 	buf := bytes.NewBuffer(make([]byte, 0, wire.MaxBlockHeaderPayload))
@@ -71,7 +71,19 @@ func isPcHashOk(
 	}
 	cryptocycle.Smul(ccState)
 	cryptocycle.Final(ccState)
+	return ccState
+}
 
+func isPcHashOk(
+	indexesOut *[4]uint64,
+	blockHeader *wire.BlockHeader,
+	proof *wire.PacketCryptProof,
+	cb *wire.PcCoinbaseCommit,
+	shareTarget uint32,
+	contentProofs [][]byte,
+	packetCryptVersion int,
+) (bool, bool) {
+	ccState := pcHash(indexesOut, blockHeader, proof, contentProofs)
 	if isWorkOk(ccState, cb, blockHeader.Bits, packetCryptVersion) {
 		return true, true
 	}
@@ -82,6 +94,62 @@ func isPcHashOk(
 	return false, false
 }
 
+// checkCoinbaseCommit checks the magic and the minimum announcement target of
+// the coinbase commitment.
+func checkCoinbaseCommit(cb *wire.PcCoinbaseCommit, packetCryptVersion int) er.R {
+	if cb.Magic() != wire.PcCoinbaseCommitMagic ||
+		!difficulty.IsAnnMinDiffOk(cb.AnnMinDifficulty(), packetCryptVersion) {
+		return er.New("Validate_checkBlock_BAD_COINBASE")
+	}
+	return nil
+}
+
+// annEffectiveTarget returns the target of the announcement once it is aged
+// for a block at blockHeight.
+func annEffectiveTarget(ann *wire.PacketCryptAnn, blockHeight int32, packetCryptVersion int) uint32 {
+	if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
+		return ann.GetWorkTarget()
+	}
+	age := uint32(blockHeight) - ann.GetParentBlockHeight()
+	return difficulty.GetAgedAnnTarget(ann.GetWorkTarget(), age, packetCryptVersion)
+}
+
+// checkAnn validates one of the announcements of the proof and checks that it
+// has at least the minimum work which the coinbase commitment claims.
+func checkAnn(
+	ann *wire.PacketCryptAnn,
+	blockHeight int32,
+	cb *wire.PcCoinbaseCommit,
+	parentBlockHash *chainhash.Hash,
+	packetCryptVersion int,
+) er.R {
+	if _, err := announce.CheckAnn(ann, parentBlockHash, packetCryptVersion); err != nil {
+		return err
+	}
+	if annEffectiveTarget(ann, blockHeight, packetCryptVersion) > cb.AnnMinDifficulty() {
+		return er.New("Validate_checkBlock_ANN_INSUF_POW")
+	}
+	return nil
+}
+
+// checkPcpHash checks that the announcement proof proves the announcements
+// are in the tree whose root is committed in the coinbase.
+func checkPcpHash(
+	annHashes *[4][32]byte,
+	cb *wire.PcCoinbaseCommit,
+	annIndexes *[4]uint64,
+	pcp *wire.PacketCryptProof,
+) er.R {
+	pcpHash, err := proof.PcpHash(annHashes, cb.AnnCount(), annIndexes, pcp)
+	if err != nil {
+		return er.Errorf("Validate_checkBlock_PCP_INVAL %v", err)
+	}
+	if !bytes.Equal(pcpHash[:], cb.MerkleRoot()) {
+		return er.New("Validate_checkBlock_PCP_MISMATCH")
+	}
+	return nil
+}
+
 // ValidatePcProof checks if the PacketCrypt proof is ok
 // returns an error if it is not and a bool which indicates whether it is good enough
 // to be a block in case that shareTarget is non-zero.
@@ -98,9 +166,8 @@ func ValidatePcProof(
 	packetCryptVersion int,
 ) (bool, er.R) {
 	// Check cb magic
-	if cb.Magic() != wire.PcCoinbaseCommitMagic ||
-		!difficulty.IsAnnMinDiffOk(cb.AnnMinDifficulty(), packetCryptVersion) {
-		return false, er.New("Validate_checkBlock_BAD_COINBASE")
+	if err := checkCoinbaseCommit(cb, packetCryptVersion); err != nil {
+		return false, err
 	}
 
 	// Check that the block has the declared amount of work
@@ -114,31 +181,15 @@ func ValidatePcProof(
 	var annHashes [4][32]byte
 	for i := 0; i < 4; i++ {
 		ann := &pcp.Announcements[i]
-		if _, err := announce.CheckAnn(ann, blockHashes[i], packetCryptVersion); err != nil {
+		if err := checkAnn(ann, blockHeight, cb, blockHashes[i], packetCryptVersion); err != nil {
 			return false, err
 		}
-		effectiveAnnTarget := uint32(0xffffffff)
-		if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
-			effectiveAnnTarget = ann.GetWorkTarget()
-		} else {
-			age := uint32(blockHeight) - ann.GetParentBlockHeight()
-			effectiveAnnTarget = difficulty.GetAgedAnnTarget(ann.GetWorkTarget(), age, packetCryptVersion)
-		}
-		if effectiveAnnTarget > cb.AnnMinDifficulty() {
-			return false, er.New("Validate_checkBlock_ANN_INSUF_POW")
-		}
 		pcutil.HashCompress(annHashes[i][:], ann.Header[:])
 	}
 
-	// Hash the merkle proof
-	pcpHash, err := proof.PcpHash(&annHashes, cb.AnnCount(), &annIndexes, pcp)
-	if err != nil {
-		return false, er.Errorf("Validate_checkBlock_PCP_INVAL %v", err)
-	}
-
-	// Compare to merkle root commitment
-	if !bytes.Equal(pcpHash[:], cb.MerkleRoot()) {
-		return false, er.New("Validate_checkBlock_PCP_MISMATCH")
+	// Hash the merkle proof and compare it to the merkle root commitment
+	if err := checkPcpHash(&annHashes, cb, &annIndexes, pcp); err != nil {
+		return false, err
 	}
 
 	return blockOk, nil
@@ -0,0 +1,98 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Check is the result of one of the checks which are made to validate a
+// PacketCrypt proof, Err is nil if the check passes.
+type Check struct {
+	Name string
+	Err  er.R
+}
+
+// ProofInfo describes a PacketCrypt proof as ValidatePcProof sees it.
+type ProofInfo struct {
+	// WorkHash is the PacketCrypt hash of the block header and proof
+	WorkHash [32]byte
+
+	// EffectiveTarget is the target which the work hash must meet, given the
+	// announcements which are committed in the coinbase
+	EffectiveTarget uint32
+
+	// AnnIndexes are the item numbers which the nonce selects, the
+	// announcements they select are at these numbers modulo the count of
+	// announcements committed in the coinbase
+	AnnIndexes [4]uint64
+
+	// AnnHashes are the hashes of the announcements of the proof
+	AnnHashes [4][32]byte
+
+	// AnnTargets are the targets of the announcements once they are aged
+	AnnTargets [4]uint32
+
+	// Checks are the results of each of the checks of ValidatePcProof, in
+	// the order in which it makes them
+	Checks []Check
+}
+
+// InspectPcProof makes each of the checks which ValidatePcProof makes and
+// describes the proof.  Unlike ValidatePcProof, it does not stop at the first
+// check which fails.  The entries of blockHashes may be nil if the parent block
+// of an announcement is not known, its check then fails.
+func InspectPcProof(
+	pcp *wire.PacketCryptProof,
+	blockHeight int32,
+	blockHeader *wire.BlockHeader,
+	cb *wire.PcCoinbaseCommit,
+	blockHashes []*chainhash.Hash,
+	contentProofs [][]byte,
+	packetCryptVersion int,
+) *ProofInfo {
+	out := &ProofInfo{}
+	out.Checks = append(out.Checks, Check{
+		Name: "coinbase",
+		Err:  checkCoinbaseCommit(cb, packetCryptVersion),
+	})
+
+	ccState := pcHash(&out.AnnIndexes, blockHeader, pcp, contentProofs)
+	copy(out.WorkHash[:], ccState.Bytes[:32])
+	out.EffectiveTarget = difficulty.GetEffectiveTarget(
+		blockHeader.Bits, cb.AnnMinDifficulty(), cb.AnnCount(), packetCryptVersion)
+	work := Check{Name: "work"}
+	if !difficulty.IsOk(out.WorkHash[:], out.EffectiveTarget) {
+		work.Err = er.New("Validate_checkBlock_INSUF_POW")
+	}
+	out.Checks = append(out.Checks, work)
+
+	for i := 0; i < 4; i++ {
+		ann := &pcp.Announcements[i]
+		pcutil.HashCompress(out.AnnHashes[i][:], ann.Header[:])
+		out.AnnTargets[i] = annEffectiveTarget(ann, blockHeight, packetCryptVersion)
+		check := Check{Name: fmt.Sprintf("ann%d", i)}
+		if i >= len(blockHashes) || blockHashes[i] == nil {
+			check.Err = er.Errorf("unknown parent block at height [%d]",
+				ann.GetParentBlockHeight())
+		} else {
+			check.Err = checkAnn(ann, blockHeight, cb, blockHashes[i], packetCryptVersion)
+		}
+		out.Checks = append(out.Checks, check)
+	}
+
+	// ValidatePcProof never gets to hash the announcement proof when there are
+	// no announcements because there cannot be enough work.
+	annProof := Check{Name: "annproof"}
+	if cb.AnnCount() == 0 {
+		annProof.Err = er.New("no announcements in the coinbase commitment")
+	} else {
+		annProof.Err = checkPcpHash(&out.AnnHashes, cb, &out.AnnIndexes, pcp)
+	}
+	out.Checks = append(out.Checks, annProof)
+	return out
+}
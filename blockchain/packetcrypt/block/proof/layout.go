@@ -0,0 +1,68 @@
+package proof
+
+import (
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// LayoutEntry describes an entry of the tree of an announcement proof and
+// what the proof provides for it.
+type LayoutEntry struct {
+	// Number is the id of the entry in the tree, see TreeNode.Number
+	Number int
+
+	// Depth is the distance from the entry to the root of the tree
+	Depth int
+
+	Flags Flag
+
+	// Offset is where the data of the entry starts in the proof, if the proof
+	// provides any.
+	Offset int
+
+	// Range is true if the proof provides the range of the entry
+	Range bool
+
+	// Hash is true if the proof provides the hash of the entry
+	Hash bool
+}
+
+// Layout returns the entries of the tree which is used to prove the
+// announcements at annIndexes in a set of annCount announcements, in the order
+// in which PcpHash reads their data from the proof.  It also returns the size
+// which such a proof must have.
+func Layout(annCount uint64, annIndexes *[4]uint64) ([]LayoutEntry, int, er.R) {
+	if annCount == 0 {
+		return nil, 0, er.New("no announcements to prove")
+	}
+	tree, _, err := newPcpTree(annCount, annIndexes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Which entries are provided only depends on the flags which NewTree
+	// sets: only the entries which cannot be computed from their children
+	// are provided, the announcement entries are always computable.
+	out := make([]LayoutEntry, 0, len(tree.entries))
+	size := 0
+	for i := 0; i < len(tree.entries); i++ {
+		e := &tree.entries[i]
+		le := LayoutEntry{
+			Number: e.Number(),
+			Flags:  e.Flags(),
+			Offset: size,
+			Range:  e.HasExplicitRange(),
+			Hash:   e.Flags()&FComputable == 0,
+		}
+		for p := e.GetParent(); p != nil; p = p.GetParent() {
+			le.Depth++
+		}
+		if le.Range {
+			size += 8
+		}
+		if le.Hash {
+			size += 32
+		}
+		out = append(out, le)
+	}
+	return out, size, nil
+}
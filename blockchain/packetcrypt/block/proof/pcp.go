@@ -18,13 +18,10 @@ import (
 
 const uint64Max uint64 = 0xffffffffffffffff
 
-func PcpHash(
-	annHashes *[4][32]byte,
-	annCount uint64,
-	annIndexes *[4]uint64,
-	pcp *wire.PacketCryptProof,
-) (*[32]byte, er.R) {
-
+// newPcpTree makes the tree of a proof of the announcements which are at
+// annIndexes in a set of annCount announcements, it returns the tree along with
+// the numbers of the announcement entries in the tree.
+func newPcpTree(annCount uint64, annIndexes *[4]uint64) (*Tree, *[4]uint64, er.R) {
 	// We need to bump the numbers to account for the zero entry
 	var annIdxs [4]uint64
 	for i := 0; i < 4; i++ {
@@ -33,6 +30,20 @@ func PcpHash(
 	annCount++
 
 	tree, err := NewTree(annCount, &annIdxs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, &annIdxs, nil
+}
+
+func PcpHash(
+	annHashes *[4][32]byte,
+	annCount uint64,
+	annIndexes *[4]uint64,
+	pcp *wire.PacketCryptProof,
+) (*[32]byte, er.R) {
+
+	tree, annIdxs, err := newPcpTree(annCount, annIndexes)
 	if err != nil {
 		return nil, err
 	}
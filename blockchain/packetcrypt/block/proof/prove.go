@@ -0,0 +1,188 @@
+package proof
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+)
+
+// annTreeNode is a node of the full announcement tree.
+type annTreeNode struct {
+	hash  [32]byte
+	start uint64
+	end   uint64
+}
+
+// padNode is the node which stands in for the missing entries on the right
+// side of each layer.
+var padNode = func() annTreeNode {
+	var n annTreeNode
+	pcutil.Memset(n.hash[:], 0xff)
+	n.start = uint64Max
+	n.end = uint64Max
+	return n
+}()
+
+// AnnTree is the full tree of the announcements which a block is mined with,
+// it is the prover side of PcpHash.  The root of the tree is committed in the
+// coinbase and the tree is used to make the AnnProof of the 4 announcements
+// which are selected by the PacketCrypt proof nonce.
+type AnnTree struct {
+	// layers[0] are the leaves, starting with the zero entry, and the last
+	// layer holds the root.  The pad entries are not stored.
+	layers [][]annTreeNode
+}
+
+func annHashStart(h *[32]byte) uint64 {
+	return binary.LittleEndian.Uint64(h[:8])
+}
+
+// NewAnnTree sorts the passed announcement hashes and builds their tree.
+// Hashes whose first 8 bytes are duplicate, all zero or all ff are dropped,
+// per the rules of the tree.
+func NewAnnTree(annHashes [][32]byte) (*AnnTree, er.R) {
+	hashes := make([][32]byte, len(annHashes))
+	copy(hashes, annHashes)
+	sort.Slice(hashes, func(i, j int) bool {
+		return annHashStart(&hashes[i]) < annHashStart(&hashes[j])
+	})
+
+	// The zero entry comes first.
+	leaves := make([]annTreeNode, 1, len(hashes)+1)
+	for i := range hashes {
+		start := annHashStart(&hashes[i])
+		if start == 0 || start == uint64Max ||
+			start == leaves[len(leaves)-1].start {
+			continue
+		}
+		leaves = append(leaves, annTreeNode{hash: hashes[i], start: start})
+	}
+	if len(leaves) == 1 {
+		return nil, er.New("no usable announcement hashes")
+	}
+	for i := range leaves {
+		if i+1 < len(leaves) {
+			leaves[i].end = leaves[i+1].start
+		} else {
+			leaves[i].end = uint64Max
+		}
+	}
+
+	t := &AnnTree{layers: [][]annTreeNode{leaves}}
+	for layer := leaves; len(layer) > 1; {
+		next := make([]annTreeNode, (len(layer)+1)/2)
+		for i := range next {
+			l := &layer[i*2]
+			r := &padNode
+			if i*2+1 < len(layer) {
+				r = &layer[i*2+1]
+			}
+			var buf [96]byte
+			copy(buf[:32], l.hash[:])
+			binary.LittleEndian.PutUint64(buf[32:40], l.start)
+			binary.LittleEndian.PutUint64(buf[40:48], l.end)
+			copy(buf[48:80], r.hash[:])
+			binary.LittleEndian.PutUint64(buf[80:88], r.start)
+			binary.LittleEndian.PutUint64(buf[88:96], r.end)
+			pcutil.HashCompress(next[i].hash[:], buf[:])
+			next[i].start = l.start
+			next[i].end = r.end
+		}
+		t.layers = append(t.layers, next)
+		layer = next
+	}
+	return t, nil
+}
+
+// node returns the node at the passed index of the passed layer, which is the
+// pad entry if it is past the end of the layer.
+func (t *AnnTree) node(layer int, index uint64) *annTreeNode {
+	if index >= uint64(len(t.layers[layer])) {
+		return &padNode
+	}
+	return &t.layers[layer][index]
+}
+
+// AnnCount returns the number of announcements in the tree, which is the
+// number committed in the coinbase.
+func (t *AnnTree) AnnCount() uint64 {
+	return uint64(len(t.layers[0]) - 1)
+}
+
+// AnnHash returns the hash of the announcement with the passed number, the
+// announcements are numbered in the order of the tree.
+func (t *AnnTree) AnnHash(annNum uint64) [32]byte {
+	return t.layers[0][annNum+1].hash
+}
+
+// Root returns the hash which is committed in the coinbase, as computed by
+// PcpHash.
+func (t *AnnTree) Root() [32]byte {
+	var buf [48]byte
+	copy(buf[:32], t.layers[len(t.layers)-1][0].hash[:])
+	pcutil.Memset(buf[40:], 0xff)
+	var out [32]byte
+	pcutil.HashCompress(out[:], buf[:])
+	return out
+}
+
+// IsProvable returns whether the announcement with the passed index can be
+// proven, the index is taken modulo the number of announcements.  When the
+// number of announcements is even, the last one is the left sibling of the pad
+// entry and PcpHash cannot infer the end of its range, so it cannot be proven.
+func (t *AnnTree) IsProvable(annIndex uint64) bool {
+	annCount := t.AnnCount()
+	return annCount%2 == 1 || annIndex%annCount != annCount-1
+}
+
+// Prove returns the AnnProof of the announcements with the passed indexes,
+// they are taken modulo the number of announcements like PcpHash does.
+func (t *AnnTree) Prove(annIndexes *[4]uint64) ([]byte, er.R) {
+	annCount := t.AnnCount()
+	var annIdxs [4]uint64
+	for i := 0; i < 4; i++ {
+		if !t.IsProvable(annIndexes[i]) {
+			return nil, er.Errorf("announcement [%d] cannot be proven",
+				annIndexes[i])
+		}
+		annIdxs[i] = (annIndexes[i] % annCount) + 1
+	}
+	tree, err := NewTree(annCount+1, &annIdxs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Locate the entries of the proof tree in the full tree.
+	layers := make([]int, len(tree.entries))
+	indexes := make([]uint64, len(tree.entries))
+	var locate func(e, layer int, index uint64)
+	locate = func(e, layer int, index uint64) {
+		layers[e] = layer
+		indexes[e] = index
+		te := &tree.entries[e]
+		if te.childLeft > -1 {
+			locate(te.childLeft, layer-1, index*2)
+			locate(te.childRight, layer-1, index*2+1)
+		}
+	}
+	locate(0, tree.branchHeight, 0)
+
+	// Provide the ranges and hashes in the order PcpHash reads them.
+	var out []byte
+	for i := range tree.entries {
+		e := &tree.entries[i]
+		n := t.node(layers[i], indexes[i])
+		if e.HasExplicitRange() {
+			var raNge [8]byte
+			binary.LittleEndian.PutUint64(raNge[:], n.end-n.start)
+			out = append(out, raNge[:]...)
+		}
+		if (e.Flags() & (FHasHash | FComputable)) == 0 {
+			out = append(out, n.hash[:]...)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,337 @@
+// Package blockminer implements a PacketCrypt block miner.
+//
+// The miner commits to a set of announcements in the coinbase of a block,
+// then searches the nonces of the PacketCrypt proof for one whose work hash
+// meets the effective target of the block.  The work hash is computed like
+// block.ValidatePcProof does, over the 4 announcements which are selected by
+// the nonce.  The blocks it produces are valid according to
+// packetcrypt.ValidatePcBlock.
+package blockminer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/dchest/blake2b"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/cryptocycle"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/randhash/util"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// maxNonce is the highest PacketCrypt proof nonce.
+const maxNonce = ^uint32(0)
+
+// Announcement is an announcement to mine blocks with.
+type Announcement struct {
+	Ann *wire.PacketCryptAnn
+
+	// Content is the content of the announcement.  It is only needed when
+	// the content is longer than 32 bytes and the PacketCrypt version is
+	// below 2, since the block must then prove the content.
+	Content []byte
+}
+
+// annEntry is an announcement which the miner uses.
+type annEntry struct {
+	ann     *wire.PacketCryptAnn
+	content []byte
+	target  uint32
+	hash    [32]byte
+}
+
+// Miner mines the blocks at a height with a set of announcements.
+type Miner struct {
+	version int
+	tree    *proof.AnnTree
+	anns    []*annEntry
+	commit  wire.PcCoinbaseCommit
+}
+
+// annTarget returns the target which the announcement is valued at in a
+// block at the passed height, or 0xffffffff if it cannot be used in it.
+func annTarget(ann *wire.PacketCryptAnn, blockHeight int32, version int) uint32 {
+	if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
+		return ann.GetWorkTarget()
+	}
+	parentHeight := ann.GetParentBlockHeight()
+	if parentHeight >= uint32(blockHeight) {
+		return 0xffffffff
+	}
+	return difficulty.GetAgedAnnTarget(ann.GetWorkTarget(),
+		uint32(blockHeight)-parentHeight, version)
+}
+
+// annWork returns the work which counts for the passed number of
+// announcements with the passed minimum work, the block requirement is
+// divided by it.
+func annWork(target uint32, annCount int, version int) *big.Int {
+	work := difficulty.WorkForTarget(difficulty.CompactToBig(target))
+	count := big.NewInt(int64(annCount))
+	if version >= 2 {
+		count.Mul(count, count)
+	}
+	return work.Mul(work, count)
+}
+
+// New returns a miner of the blocks at the passed height, with the passed
+// PacketCrypt proof version, using the passed announcements.  The
+// announcements are expected to be valid, those which cannot be used at this
+// height are skipped.  So are the announcements which must be signed, since
+// the miner does not have their keys, and those whose content must be proven
+// but is not known.  Of the remaining announcements, the miner uses those
+// with the least work which minimize the work required to mine the block.
+func New(anns []Announcement, blockHeight int32, version int) (*Miner, er.R) {
+	if version < 0 || version > 2 {
+		return nil, er.Errorf("unsupported PacketCrypt version %d", version)
+	}
+
+	var entries []*annEntry
+	seen := make(map[[32]byte]struct{})
+	for _, a := range anns {
+		ann := a.Ann
+		if ann.HasSigningKey() || (version >= 2 && ann.GetVersion() == 0) {
+			continue
+		}
+		if version < 2 && ann.GetContentLength() > 32 &&
+			(uint32(len(a.Content)) != ann.GetContentLength() ||
+				!bytes.Equal(annminer.ContentHash(a.Content),
+					ann.GetContentHash())) {
+			continue
+		}
+		e := &annEntry{
+			ann:     ann,
+			content: a.Content,
+			target:  annTarget(ann, blockHeight, version),
+		}
+		if !difficulty.IsAnnMinDiffOk(e.target, version) {
+			continue
+		}
+		pcutil.HashCompress(e.hash[:], ann.Header[:])
+		if _, ok := seen[e.hash]; ok {
+			continue
+		}
+		seen[e.hash] = struct{}{}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, er.New("no usable announcements")
+	}
+
+	// The work of the block is divided by the minimum announcement work
+	// times the announcement count (squared for version 2), so adding an
+	// announcement with less work can make the block harder to mine.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].target < entries[j].target
+	})
+	best := 0
+	var bestWork *big.Int
+	for i, e := range entries {
+		if i+1 < len(entries) && entries[i+1].target == e.target {
+			continue
+		}
+		if w := annWork(e.target, i+1, version); bestWork == nil ||
+			w.Cmp(bestWork) > 0 {
+			best = i
+			bestWork = w
+		}
+	}
+	entries = entries[:best+1]
+
+	hashes := make([][32]byte, len(entries))
+	byHash := make(map[[32]byte]*annEntry, len(entries))
+	for i, e := range entries {
+		hashes[i] = e.hash
+		byHash[e.hash] = e
+	}
+	tree, err := proof.NewAnnTree(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Miner{
+		version: version,
+		tree:    tree,
+		anns:    make([]*annEntry, tree.AnnCount()),
+	}
+	for i := range m.anns {
+		m.anns[i] = byHash[tree.AnnHash(uint64(i))]
+	}
+	b := m.commit.Bytes[:]
+	binary.LittleEndian.PutUint32(b[:4], wire.PcCoinbaseCommitMagic)
+	binary.LittleEndian.PutUint32(b[4:8], entries[best].target)
+	root := tree.Root()
+	copy(b[8:40], root[:])
+	binary.LittleEndian.PutUint64(b[40:48], tree.AnnCount())
+	return m, nil
+}
+
+// Commit returns the commitment of the miner which must be in the coinbase of
+// the blocks it mines.
+func (m *Miner) Commit() *wire.PcCoinbaseCommit {
+	c := m.commit
+	return &c
+}
+
+// InsertCommit places the commitment of the miner in the passed coinbase,
+// replacing any existing commitment.  The merkle root of the block must be
+// updated afterwards.
+func (m *Miner) InsertCommit(coinbaseTx *wire.MsgTx) {
+	for _, txOut := range coinbaseTx.TxOut {
+		script := txOut.PkScript
+		if len(script) == len(m.commit.Bytes)+2 &&
+			script[0] == 0x6a && script[1] == 0x30 &&
+			binary.LittleEndian.Uint32(script[2:6]) == wire.PcCoinbaseCommitMagic {
+			copy(script[2:], m.commit.Bytes[:])
+			return
+		}
+	}
+	packetcrypt.InsertCoinbaseCommit(coinbaseTx, &m.commit)
+}
+
+// Work is the state of the miner for a block header, a new one must be made
+// whenever the header changes.  It is not safe for concurrent access.
+type Work struct {
+	m        *Miner
+	hdrHash  [32]byte
+	proofIdx uint32
+	target   uint32
+	solvable bool
+	state    cryptocycle.State
+}
+
+// Work returns the state of the miner for the passed block header, whose
+// merkle root must cover the commitment of the miner.
+func (m *Miner) Work(header *wire.BlockHeader) *Work {
+	w := &Work{
+		m: m,
+		target: difficulty.GetEffectiveTarget(header.Bits,
+			m.commit.AnnMinDifficulty(), m.commit.AnnCount(), m.version),
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, wire.MaxBlockHeaderPayload))
+	if err := header.Serialize(buf); err != nil {
+		panic("failed to serialize block header")
+	}
+	pcutil.HashCompress(w.hdrHash[:], buf.Bytes())
+	b2 := blake2b.New256()
+	b2.Write(buf.Bytes())
+	w.proofIdx = binary.LittleEndian.Uint32(b2.Sum(nil))
+
+	// The first announcement is selected by the header alone, if it cannot
+	// be proven then no nonce can.
+	cryptocycle.Init(&w.state, w.hdrHash[:], 0)
+	w.solvable = m.tree.IsProvable(cryptocycle.GetItemNo(&w.state))
+	return w
+}
+
+// Solvable returns false if no nonce can solve the work, because the header
+// selects an announcement which cannot be proven, the header must then be
+// changed.
+func (w *Work) Solvable() bool {
+	return w.solvable
+}
+
+// Target returns the effective target which the work hash must meet.
+func (w *Work) Target() uint32 {
+	return w.target
+}
+
+// contentProof returns the content proof of the announcement, or nil if it
+// needs none.
+func (w *Work) contentProof(e *annEntry, nonce uint32) []byte {
+	if w.m.version >= 2 {
+		return nil
+	}
+	return annminer.ContentProof(e.content, w.proofIdx^nonce)
+}
+
+// hash runs the cryptocycle of the passed nonce, calling the passed function
+// with each selected announcement and its content proof.
+func (w *Work) hash(nonce uint32, f func(i int, idx uint64, e *annEntry, cp []byte)) {
+	cryptocycle.Init(&w.state, w.hdrHash[:], uint64(nonce))
+	for i := 0; i < 4; i++ {
+		idx := cryptocycle.GetItemNo(&w.state)
+		e := w.m.anns[idx%uint64(len(w.m.anns))]
+		cp := w.contentProof(e, nonce)
+		if f != nil {
+			f(i, idx, e, cp)
+		}
+		if cp != nil {
+			cp = cp[:32]
+		}
+		cryptocycle.Update(&w.state, e.ann.Header[:], cp, 0, nil)
+	}
+	cryptocycle.Smul(&w.state)
+	cryptocycle.Final(&w.state)
+}
+
+// Try returns whether the work hash of the passed nonce meets the target and
+// the announcements it selects can be proven.
+func (w *Work) Try(nonce uint32) bool {
+	if !w.solvable {
+		return false
+	}
+	provable := true
+	w.hash(nonce, func(_ int, idx uint64, _ *annEntry, _ []byte) {
+		provable = provable && w.m.tree.IsProvable(idx)
+	})
+	return provable && difficulty.IsOk(w.state.Bytes[:32], w.target)
+}
+
+// Proof returns the PacketCrypt proof of the passed nonce.
+func (w *Work) Proof(nonce uint32) (*wire.PacketCryptProof, er.R) {
+	pcp := &wire.PacketCryptProof{
+		Nonce:   nonce,
+		Version: w.m.version,
+	}
+	var indexes [4]uint64
+	w.hash(nonce, func(i int, idx uint64, e *annEntry, cp []byte) {
+		indexes[i] = idx
+		pcp.Announcements[i] = *e.ann
+		pcp.ContentProof = append(pcp.ContentProof, cp...)
+	})
+	annProof, err := w.m.tree.Prove(&indexes)
+	if err != nil {
+		return nil, err
+	}
+	pcp.AnnProof = annProof
+	return pcp, nil
+}
+
+// Mine searches the nonces for a PacketCrypt proof of the passed block and
+// sets it, the coinbase and the merkle root of the block must already cover
+// the commitment of the miner.  It returns false when no nonce meets the
+// target, immediately if the work is not solvable, or when quit is closed.
+func (m *Miner) Mine(mb *wire.MsgBlock, quit <-chan struct{}) (bool, er.R) {
+	w := m.Work(&mb.Header)
+	if !w.Solvable() {
+		return false, nil
+	}
+	for nonce := uint32(0); ; nonce++ {
+		if nonce&0xfff == 0 {
+			select {
+			case <-quit:
+				return false, nil
+			default:
+			}
+		}
+		if w.Try(nonce) {
+			pcp, err := w.Proof(nonce)
+			if err != nil {
+				return false, err
+			}
+			mb.Pcp = pcp
+			return true, nil
+		}
+		if nonce == maxNonce {
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package blockminer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// mineAnns mines count announcements with the passed request.
+func mineAnns(t *testing.T, req *annminer.Request, count int) []blockminer.Announcement {
+	m, err := annminer.New(req, 0)
+	if err != nil {
+		t.Fatalf("annminer.New: %v", err)
+	}
+	anns, err := m.Mine(count, nil)
+	if err != nil {
+		t.Fatalf("Mine: %v", err)
+	}
+	out := make([]blockminer.Announcement, len(anns))
+	for i, ann := range anns {
+		out[i] = blockminer.Announcement{Ann: ann, Content: req.Content}
+	}
+	return out
+}
+
+// newBlock returns a block whose coinbase contains the commitment of the
+// passed miner.
+func newBlock(m *blockminer.Miner) *wire.MsgBlock {
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			0xffffffff),
+		SignatureScript: []byte{0x01, 0x0d},
+	})
+	coinbase.AddTxOut(&wire.TxOut{Value: 1, PkScript: []byte{0x51}})
+	m.InsertCommit(coinbase)
+
+	mb := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:    1,
+		PrevBlock:  chainhash.DoubleHashH([]byte("prev")),
+		MerkleRoot: coinbase.TxHash(),
+		Bits:       0x207fffff,
+	})
+	mb.AddTransaction(coinbase)
+	return mb
+}
+
+// TestMine ensures the mined blocks are valid for both PacketCrypt versions.
+func TestMine(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	content := bytes.Repeat([]byte("content "), 20)
+
+	tests := []struct {
+		name        string
+		version     int
+		blockHeight int32
+		reqs        []annminer.Request
+	}{
+		{
+			name:        "version 1",
+			version:     1,
+			blockHeight: 13,
+			reqs: []annminer.Request{{
+				ParentBlockHash:   parentHash,
+				ParentBlockHeight: 10,
+				WorkTarget:        0x207fffff,
+				Content:           []byte("short"),
+			}, {
+				ParentBlockHash:   parentHash,
+				ParentBlockHeight: 10,
+				WorkTarget:        0x207fffff,
+				Content:           content,
+			}},
+		},
+		{
+			name:        "version 2",
+			version:     2,
+			blockHeight: announce.Version1Height + 3,
+			reqs: []annminer.Request{{
+				Version:           1,
+				ParentBlockHash:   parentHash,
+				ParentBlockHeight: announce.Version1Height,
+				WorkTarget:        0x207fffff,
+				Content:           content,
+			}},
+		},
+	}
+
+	hashes := []*chainhash.Hash{&parentHash, &parentHash, &parentHash,
+		&parentHash}
+	for _, test := range tests {
+		var anns []blockminer.Announcement
+		for i := range test.reqs {
+			anns = append(anns, mineAnns(t, &test.reqs[i], 16)...)
+		}
+		m, err := blockminer.New(anns, test.blockHeight, test.version)
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+		mb := newBlock(m)
+		if ok, err := m.Mine(mb, nil); err != nil || !ok {
+			t.Fatalf("%s: Mine: %v %v", test.name, ok, err)
+		}
+
+		// Round trip the proof through its wire encoding.
+		var buf bytes.Buffer
+		if err := mb.Pcp.BtcEncode(&buf, 0, 0); err != nil {
+			t.Fatalf("%s: BtcEncode: %v", test.name, err)
+		}
+		mb.Pcp = &wire.PacketCryptProof{}
+		if err := mb.Pcp.BtcDecode(&buf, 0, 0); err != nil {
+			t.Fatalf("%s: BtcDecode: %v", test.name, err)
+		}
+		if (mb.Pcp.ContentProof != nil) != (test.version < 2) {
+			t.Fatalf("%s: unexpected content proof", test.name)
+		}
+
+		ok, err := packetcrypt.ValidatePcBlock(mb, test.blockHeight, 0, hashes)
+		if err != nil || !ok {
+			t.Fatalf("%s: mined block is invalid: %v %v", test.name, ok, err)
+		}
+
+		// Tampering with the announcement proof invalidates the block.
+		mb.Pcp.AnnProof[len(mb.Pcp.AnnProof)-1] ^= 1
+		if _, err := packetcrypt.ValidatePcBlock(mb, test.blockHeight, 0,
+			hashes); err == nil {
+			t.Fatalf("%s: tampered block is valid", test.name)
+		}
+	}
+}
+
+// TestTryProvable ensures the nonces which select the last of an even number of
+// announcements, which cannot be proven, are not accepted, and that the work
+// of a header which always selects it is not solvable.
+func TestTryProvable(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	anns := mineAnns(t, &annminer.Request{
+		ParentBlockHash:   parentHash,
+		ParentBlockHeight: 10,
+		WorkTarget:        0x207fffff,
+	}, 16)
+	m, err := blockminer.New(anns, 13, 1)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	mb := newBlock(m)
+	w := m.Work(&mb.Header)
+
+	hashes := []*chainhash.Hash{&parentHash, &parentHash, &parentHash,
+		&parentHash}
+	accepted := 0
+	for nonce := uint32(0); accepted < 8 && nonce < 1<<16; nonce++ {
+		if !w.Try(nonce) {
+			continue
+		}
+		accepted++
+		pcp, err := w.Proof(nonce)
+		if err != nil {
+			t.Fatalf("Proof(%d): %v", nonce, err)
+		}
+		mb.Pcp = pcp
+		if ok, err := packetcrypt.ValidatePcBlock(mb, 13, 0, hashes); err != nil || !ok {
+			t.Fatalf("nonce %d: mined block is invalid: %v %v", nonce, ok, err)
+		}
+	}
+	if accepted == 0 {
+		t.Fatalf("no nonce accepted")
+	}
+
+	// A header which selects the last announcement first cannot be solved
+	// by any nonce.
+	for w.Solvable() {
+		mb.Header.Nonce++
+		w = m.Work(&mb.Header)
+	}
+	for nonce := uint32(0); nonce < 256; nonce++ {
+		if w.Try(nonce) {
+			t.Fatalf("nonce %d accepted for an unsolvable header", nonce)
+		}
+	}
+	if ok, err := m.Mine(mb, nil); err != nil || ok {
+		t.Fatalf("Mine: %v %v, want unsolvable", ok, err)
+	}
+}
+
+// TestInspectPcBlock ensures every check passes for a mined block, and that
+// the check which a tampered proof fails is pinpointed.
+func TestInspectPcBlock(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	content := bytes.Repeat([]byte("content "), 20)
+	anns := mineAnns(t, &annminer.Request{
+		ParentBlockHash:   parentHash,
+		ParentBlockHeight: 10,
+		WorkTarget:        0x207fffff,
+		Content:           content,
+	}, 16)
+	m, err := blockminer.New(anns, 13, 1)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	mb := newBlock(m)
+	if ok, err := m.Mine(mb, nil); err != nil || !ok {
+		t.Fatalf("Mine: %v %v", ok, err)
+	}
+
+	hashes := []*chainhash.Hash{&parentHash, &parentHash, nil, &parentHash}
+	bi, err := packetcrypt.InspectPcBlock(mb, 13, hashes)
+	if err != nil {
+		t.Fatalf("InspectPcBlock: %v", err)
+	}
+	for _, c := range bi.Checks {
+		if (c.Err != nil) != (c.Name == "ann2") {
+			t.Errorf("check %s: %v", c.Name, c.Err)
+		}
+	}
+	if bi.Commit == nil || bi.Proof == nil || len(bi.ContentProofs) != 4 ||
+		bi.Proof.EffectiveTarget != m.Work(&mb.Header).Target() {
+
+		t.Fatalf("unexpected description of the proof")
+	}
+	_, size, err := proof.Layout(bi.Commit.AnnCount(), &bi.Proof.AnnIndexes)
+	if err != nil || size != len(mb.Pcp.AnnProof) {
+		t.Fatalf("Layout: size %d, want %d: %v", size,
+			len(mb.Pcp.AnnProof), err)
+	}
+
+	hashes[2] = &parentHash
+	mb.Pcp.AnnProof[len(mb.Pcp.AnnProof)-1] ^= 1
+	if bi, err = packetcrypt.InspectPcBlock(mb, 13, hashes); err != nil {
+		t.Fatalf("InspectPcBlock: %v", err)
+	}
+	if bi.Ok() {
+		t.Fatalf("tampered block passes every check")
+	}
+	for _, c := range bi.Checks {
+		if (c.Err != nil) != (c.Name == "annproof") {
+			t.Errorf("tampered block: check %s: %v", c.Name, c.Err)
+		}
+	}
+}
+
+// TestNewErrors ensures a miner cannot be made without usable announcements.
+func TestNewErrors(t *testing.T) {
+	parentHash := chainhash.DoubleHashH([]byte("parent"))
+	anns := mineAnns(t, &annminer.Request{
+		ParentBlockHash:   parentHash,
+		ParentBlockHeight: 10,
+		WorkTarget:        0x207fffff,
+		Content:           bytes.Repeat([]byte{1}, 64),
+	}, 1)
+
+	// Too young.
+	if _, err := blockminer.New(anns, 12, 1); err == nil {
+		t.Fatalf("New succeeded with announcements which are too young")
+	}
+
+	// Version 0 announcements are not valid with version 2.
+	if _, err := blockminer.New(anns, 13, 2); err == nil {
+		t.Fatalf("New succeeded with version 0 announcements")
+	}
+
+	// The content is needed to prove it.
+	anns[0].Content = nil
+	if _, err := blockminer.New(anns, 13, 1); err == nil {
+		t.Fatalf("New succeeded without the announcement content")
+	}
+
+	if _, err := blockminer.New(anns, 13, 3); err == nil {
+		t.Fatalf("New succeeded with an unsupported version")
+	}
+}
@@ -0,0 +1,81 @@
+package packetcrypt
+
+import (
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// PcBlockInfo describes the PacketCrypt proof of a block as ValidatePcBlock
+// sees it.
+type PcBlockInfo struct {
+	// ContentProofIdx selects the block of the content of each announcement
+	// which the content proofs prove
+	ContentProofIdx uint32
+
+	// ContentProofs are the content proofs split by announcement, they are
+	// nil for PacketCrypt proofs of version 2 and above
+	ContentProofs [][]byte
+
+	// Commit is the commitment in the coinbase, nil if there is none
+	Commit *wire.PcCoinbaseCommit
+
+	// Proof describes the proof, it is nil if there is no commitment in the
+	// coinbase to validate it against
+	Proof *block.ProofInfo
+
+	// Checks are the results of each of the checks of ValidatePcBlock, in
+	// the order in which it makes them
+	Checks []block.Check
+}
+
+// Ok returns true if all of the checks pass.
+func (bi *PcBlockInfo) Ok() bool {
+	for _, c := range bi.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// InspectPcBlock makes each of the checks which ValidatePcBlock makes and
+// describes the PacketCrypt proof of the block.  Unlike ValidatePcBlock, it
+// does not stop at the first check which fails.  The entries of
+// annParentHashes may be nil if the parent block of an announcement is not
+// known.
+func InspectPcBlock(mb *wire.MsgBlock, height int32, annParentHashes []*chainhash.Hash) (*PcBlockInfo, er.R) {
+	if mb.Pcp == nil {
+		return nil, er.New("missing packetcrypt proof")
+	}
+	out := &PcBlockInfo{ContentProofIdx: contentProofIdx2(mb)}
+	out.Checks = append(out.Checks, block.Check{
+		Name: "signatures",
+		Err:  checkSignatures(mb.Pcp),
+	})
+
+	contentProofs, err := checkContentProofs(mb.Pcp, out.ContentProofIdx)
+	if err != nil && mb.Pcp.Version <= 1 {
+		// Hash with the content proofs as they are, if they can be split.
+		contentProofs, _ = mb.Pcp.SplitContentProof(out.ContentProofIdx)
+	}
+	out.ContentProofs = contentProofs
+	out.Checks = append(out.Checks, block.Check{Name: "contentproofs", Err: err})
+
+	commit := block.Check{Name: "commitment"}
+	if len(mb.Transactions) == 0 || mb.Transactions[0] == nil {
+		commit.Err = er.New("missing coinbase")
+	} else if out.Commit = ExtractCoinbaseCommit(mb.Transactions[0]); out.Commit == nil {
+		commit.Err = er.New("missing packetcrypt commitment")
+	}
+	out.Checks = append(out.Checks, commit)
+	if out.Commit == nil {
+		return out, nil
+	}
+
+	out.Proof = block.InspectPcProof(mb.Pcp, height, &mb.Header, out.Commit,
+		annParentHashes, contentProofs, mb.Pcp.Version)
+	out.Checks = append(out.Checks, out.Proof.Checks...)
+	return out, nil
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/metrics"
 
 	"github.com/dchest/blake2b"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
@@ -18,10 +19,22 @@ import (
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/wire"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/crypto/ed25519"
 )
 
+// validationTime is the time taken to validate announcements and block proofs.
+var validationTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "pktd_packetcrypt_validation_seconds",
+	Help: "Time taken to validate PacketCrypt announcements and block proofs.",
+}, []string{"kind"})
+
+func init() {
+	metrics.Register(validationTime)
+}
+
 func ValidatePcAnn(p *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash, packetCryptVersion int) (*chainhash.Hash, er.R) {
+	defer prometheus.NewTimer(validationTime.WithLabelValues("ann")).ObserveDuration()
 	return announce.CheckAnn(p, parentBlockHash, packetCryptVersion)
 }
 
@@ -71,7 +84,51 @@ func contentProofIdx2(mb *wire.MsgBlock) uint32 {
 	return binary.LittleEndian.Uint32(buf) ^ mb.Pcp.Nonce
 }
 
+// checkSignatures checks the signature of each announcement which has a
+// signing key.
+func checkSignatures(pcp *wire.PacketCryptProof) er.R {
+	for i, ann := range pcp.Announcements {
+		if !ann.HasSigningKey() {
+		} else if pcp.Signatures[i] == nil {
+			return er.Errorf("missing announcement signature for key [%s]",
+				hex.EncodeToString(ann.GetSigningKey()))
+		} else if !ed25519.Verify(ann.GetSigningKey(), ann.Header[:], pcp.Signatures[i]) {
+			return er.New("invalid announcement signature")
+		}
+	}
+	return nil
+}
+
+// checkContentProofs checks the proofs of the content of the announcements
+// and returns them split by announcement.
+func checkContentProofs(pcp *wire.PacketCryptProof, proofIdx uint32) ([][]byte, er.R) {
+	if pcp.Version > 1 {
+		if pcp.ContentProof != nil {
+			return nil, er.Errorf("For PcP type [%d] content proof must be nil", pcp.Version)
+		}
+		return nil, nil
+	}
+	contentProofs, err := pcp.SplitContentProof(proofIdx)
+	if err != nil {
+		return nil, err
+	}
+	for i, ann := range pcp.Announcements {
+		if ann.GetContentLength() <= 32 {
+			continue
+		}
+		if contentProofs[i] == nil {
+			return nil, er.New("missing announcement content proof")
+		}
+		contentBuf := bytes.NewBuffer(contentProofs[i])
+		if err := checkContentProof(&ann, proofIdx, contentBuf); err != nil {
+			return nil, err
+		}
+	}
+	return contentProofs, nil
+}
+
 func ValidatePcBlock(mb *wire.MsgBlock, height int32, shareTarget uint32, annParentHashes []*chainhash.Hash) (bool, er.R) {
+	defer prometheus.NewTimer(validationTime.WithLabelValues("block")).ObserveDuration()
 	if len(annParentHashes) != 4 {
 		return false, er.New("wrong number of annParentHashes")
 	}
@@ -80,39 +137,14 @@ func ValidatePcBlock(mb *wire.MsgBlock, height int32, shareTarget uint32, annPar
 	}
 
 	// Check ann sigs
-	for i, ann := range mb.Pcp.Announcements {
-		if !ann.HasSigningKey() {
-		} else if mb.Pcp.Signatures[i] == nil {
-			return false, er.Errorf("missing announcement signature for key [%s]",
-				hex.EncodeToString(ann.GetSigningKey()))
-		} else if !ed25519.Verify(ann.GetSigningKey(), ann.Header[:], mb.Pcp.Signatures[i]) {
-			return false, er.New("invalid announcement signature")
-		}
+	if err := checkSignatures(mb.Pcp); err != nil {
+		return false, err
 	}
 
 	// Check content proofs
-	proofIdx := contentProofIdx2(mb)
-	var contentProofs [][]byte
-	if mb.Pcp.Version <= 1 {
-		var err er.R
-		contentProofs, err = mb.Pcp.SplitContentProof(proofIdx)
-		if err != nil {
-			return false, err
-		}
-		for i, ann := range mb.Pcp.Announcements {
-			if ann.GetContentLength() <= 32 {
-				continue
-			}
-			if contentProofs[i] == nil {
-				return false, er.New("missing announcement content proof")
-			}
-			contentBuf := bytes.NewBuffer(contentProofs[i])
-			if err := checkContentProof(&ann, proofIdx, contentBuf); err != nil {
-				return false, err
-			}
-		}
-	} else if mb.Pcp.ContentProof != nil {
-		return false, er.Errorf("For PcP type [%d] content proof must be nil", mb.Pcp.Version)
+	contentProofs, err := checkContentProofs(mb.Pcp, contentProofIdx2(mb))
+	if err != nil {
+		return false, err
 	}
 
 	coinbase := mb.Transactions[0]
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"sort"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/database"
+)
+
+// MinPruneDepth is the minimum number of blocks below the best chain tip which
+// must have their data retained when pruning.  It matches the number of recent
+// blocks which a node advertising SFNodeNetworkLimited is expected to be able
+// to serve (BIP0159) and also bounds the depth of reorganizations which a
+// pruned node can handle, since disconnecting a block requires its data.
+const MinPruneDepth = 288
+
+// pruneBlocks deletes the stored data for main chain blocks which are more than
+// the configured prune depth below the passed node.  The utxo set, spend
+// journal, election state and optional indexes are kept in the metadata and
+// are not affected.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) pruneBlocks(dbTx database.Tx, node *blockNode) er.R {
+	if b.pruneDepth == 0 || node.height <= b.pruneDepth {
+		return nil
+	}
+
+	// Blocks are always stored after their parents, so every block in the
+	// main chain after the oldest one to keep is stored after it as well.
+	oldest := node.Ancestor(node.height - b.pruneDepth)
+	return dbTx.PruneBlocks(&oldest.hash)
+}
+
+// IsPruned returns whether or not the chain is configured to prune block data
+// or the database has been pruned in the past.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsPruned() bool {
+	return b.pruneDepth > 0 || b.beenPruned
+}
+
+// PruneHeight returns the height of the oldest main chain block which still has
+// its data available.  It is zero when no block data has been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneHeight() (int32, er.R) {
+	if !b.IsPruned() {
+		return 0, nil
+	}
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// Since blocks are stored in order, the pruned blocks form a prefix of
+	// the main chain which can be found with a binary search.
+	var err er.R
+	tipHeight := b.bestChain.Tip().height
+	height := sort.Search(int(tipHeight)+1, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		node := b.bestChain.NodeByHeight(int32(i))
+		err = b.db.View(func(dbTx database.Tx) er.R {
+			_, err := dbTx.FetchBlockHeader(&node.hash)
+			return err
+		})
+		if database.ErrBlockPruned.Is(err) {
+			err = nil
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int32(height), nil
+}
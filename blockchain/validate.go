@@ -401,7 +401,7 @@ func (b *BlockChain) pcCheckProofOfWork(block *btcutil.Block) (int32, er.R) {
 		return -1, err
 	}
 
-	if b.LatestCheckpoint().Height >= height {
+	if checkpoint := b.LatestCheckpoint(); checkpoint != nil && checkpoint.Height >= height {
 		return height, nil
 	}
 
@@ -1418,3 +1418,51 @@ func (b *BlockChain) CheckConnectBlockTemplate(block *btcutil.Block) er.R {
 	_, err = b.checkConnectBlock(newNode, block, view, nil)
 	return err
 }
+
+// CheckBlockHeaderContext validates the header of the passed block against its
+// parent without the block transactions, so it can be used to check a block
+// before downloading or reconstructing it.  Aside from the header checks, the
+// PacketCrypt proof is verified when it is in use, which requires the block to
+// contain the coinbase transaction.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckBlockHeaderContext(block *btcutil.Block) er.R {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	header := &block.MsgBlock().Header
+	err := checkBlockHeaderSanity(header, b.chainParams.PowLimit, b.timeSource,
+		BFNone)
+	if err != nil {
+		return err
+	}
+
+	prevNode := b.index.LookupNode(&header.PrevBlock)
+	if prevNode == nil {
+		str := fmt.Sprintf("previous block %s is unknown", header.PrevBlock)
+		return ruleerror.ErrPreviousBlockUnknown.New(str, nil)
+	}
+	if err := b.checkBlockHeaderContext(header, prevNode, BFNone); err != nil {
+		return err
+	}
+
+	if globalcfg.GetProofOfWorkAlgorithm() != globalcfg.PowPacketCrypt {
+		return nil
+	}
+	txns := block.MsgBlock().Transactions
+	if len(txns) == 0 || !IsCoinBaseTx(txns[0]) {
+		return ruleerror.ErrFirstTxNotCoinbase.New("first transaction in "+
+			"block is not a coinbase", nil)
+	}
+	height, err := b.pcCheckProofOfWork(block)
+	if err != nil {
+		return err
+	}
+	if height != prevNode.height+1 {
+		str := fmt.Sprintf("the coinbase signature script serialized "+
+			"block height is %d when %d was expected", height,
+			prevNode.height+1)
+		return ruleerror.ErrBadCoinbaseHeight.New(str, nil)
+	}
+	return nil
+}
@@ -151,6 +151,76 @@ func TestCheckConnectBlockTemplate(t *testing.T) {
 	}
 }
 
+// TestCheckBlockHeaderContext ensures the CheckBlockHeaderContext function
+// accepts a header which extends a known block and rejects one with an invalid
+// proof of work, difficulty or parent.
+func TestCheckBlockHeaderContext(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("checkblockheadercontext",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Errorf("Failed to setup chain instance: %v", err)
+		return
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	blocks, err := loadBlocks("blk_0_to_4.dat.bz2")
+	if err != nil {
+		t.Fatalf("Error loading file: %v\n", err)
+	}
+	for i := 1; i <= 2; i++ {
+		if _, _, err := chain.ProcessBlock(blocks[i], BFNone); err != nil {
+			t.Fatalf("CheckBlockHeaderContext: Received unexpected error "+
+				"processing block %d: %v", i, err)
+		}
+	}
+
+	// Only the coinbase of the block is needed.
+	headerBlock := func(modify func(*wire.BlockHeader)) *btcutil.Block {
+		msgBlock := *blocks[4].MsgBlock()
+		msgBlock.Transactions = msgBlock.Transactions[:1]
+		modify(&msgBlock.Header)
+		return btcutil.NewBlock(&msgBlock)
+	}
+
+	// Block 4 extends a known block even though block 3 is missing.
+	err = chain.CheckBlockHeaderContext(headerBlock(func(*wire.BlockHeader) {}))
+	if err == nil {
+		t.Fatal("CheckBlockHeaderContext: Did not received expected error " +
+			"on block 4 with unknown parent")
+	}
+	err = chain.CheckBlockHeaderContext(blocks[3])
+	if err != nil {
+		t.Fatalf("CheckBlockHeaderContext: Received unexpected error on "+
+			"block 3: %v", err)
+	}
+	if _, _, err := chain.ProcessBlock(blocks[3], BFNone); err != nil {
+		t.Fatalf("CheckBlockHeaderContext: Received unexpected error "+
+			"processing block 3: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*wire.BlockHeader)
+		valid  bool
+	}{
+		{"valid", func(*wire.BlockHeader) {}, true},
+		{"bad nonce", func(h *wire.BlockHeader) { h.Nonce++ }, false},
+		{"bad bits", func(h *wire.BlockHeader) { h.Bits-- }, false},
+	}
+	for _, test := range tests {
+		err := chain.CheckBlockHeaderContext(headerBlock(test.modify))
+		if test.valid && err != nil {
+			t.Errorf("CheckBlockHeaderContext (%s): Received unexpected "+
+				"error on block 4: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("CheckBlockHeaderContext (%s): Did not received "+
+				"expected error on block 4", test.name)
+		}
+	}
+}
+
 // TestCheckBlockSanity tests the CheckBlockSanity function to ensure it works
 // as expected.
 func TestCheckBlockSanity(t *testing.T) {
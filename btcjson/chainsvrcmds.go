@@ -102,6 +102,20 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// DecodePcProofCmd defines the decodepcproof JSON-RPC command.
+type DecodePcProofCmd struct {
+	Block string
+}
+
+// NewDecodePcProofCmd returns a new instance which can be used to issue a
+// decodepcproof JSON-RPC command.  The block is either the hash of a block or
+// a hex-encoded serialized block.
+func NewDecodePcProofCmd(block string) *DecodePcProofCmd {
+	return &DecodePcProofCmd{
+		Block: block,
+	}
+}
+
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -120,6 +134,24 @@ func NewGetAddedNodeInfoCmd(dns bool, node *string) *GetAddedNodeInfoCmd {
 	}
 }
 
+// GetAnnPoolCmd defines the getannpool JSON-RPC command.
+type GetAnnPoolCmd struct {
+	Verbose      *bool `jsonrpcdefault:"false"`
+	ParentHeight *int64
+}
+
+// NewGetAnnPoolCmd returns a new instance which can be used to issue a
+// getannpool JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAnnPoolCmd(verbose *bool, parentHeight *int64) *GetAnnPoolCmd {
+	return &GetAnnPoolCmd{
+		Verbose:      verbose,
+		ParentHeight: parentHeight,
+	}
+}
+
 // GetBestBlockHashCmd defines the getbestblockhash JSON-RPC command.
 type GetBestBlockHashCmd struct{}
 
@@ -369,6 +401,42 @@ func NewGetInfoCmd() *GetInfoCmd {
 	return &GetInfoCmd{}
 }
 
+// GetMempoolAncestorsCmd defines the getmempoolancestors JSON-RPC command.
+type GetMempoolAncestorsCmd struct {
+	TxID    string
+	Verbose *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetMempoolAncestorsCmd returns a new instance which can be used to issue a
+// getmempoolancestors JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMempoolAncestorsCmd(txHash string, verbose *bool) *GetMempoolAncestorsCmd {
+	return &GetMempoolAncestorsCmd{
+		TxID:    txHash,
+		Verbose: verbose,
+	}
+}
+
+// GetMempoolDescendantsCmd defines the getmempooldescendants JSON-RPC command.
+type GetMempoolDescendantsCmd struct {
+	TxID    string
+	Verbose *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetMempoolDescendantsCmd returns a new instance which can be used to issue
+// a getmempooldescendants JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMempoolDescendantsCmd(txHash string, verbose *bool) *GetMempoolDescendantsCmd {
+	return &GetMempoolDescendantsCmd{
+		TxID:    txHash,
+		Verbose: verbose,
+	}
+}
+
 // GetMempoolEntryCmd defines the getmempoolentry JSON-RPC command.
 type GetMempoolEntryCmd struct {
 	TxID string
@@ -439,8 +507,41 @@ func NewGetNetworkHashPSCmd(numBlocks, height *int) *GetNetworkHashPSCmd {
 	}
 }
 
+// GetPcNetworkInfoCmd defines the getpcnetworkinfo JSON-RPC command.
+type GetPcNetworkInfoCmd struct {
+	Blocks *int `jsonrpcdefault:"120"`
+	Height *int `jsonrpcdefault:"-1"`
+}
+
+// NewGetPcNetworkInfoCmd returns a new instance which can be used to issue a
+// getpcnetworkinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetPcNetworkInfoCmd(numBlocks, height *int) *GetPcNetworkInfoCmd {
+	return &GetPcNetworkInfoCmd{
+		Blocks: numBlocks,
+		Height: height,
+	}
+}
+
+// ConfigureMiningPayoutsCmd defines the configureminingpayouts JSON-RPC
+// command.
 type ConfigureMiningPayoutsCmd struct {
 	PayoutPercents map[string]float64 `json:"payoutpercents"`
+	Height         *int64             `json:"height"`
+}
+
+// NewConfigureMiningPayoutsCmd returns a new instance which can be used to
+// issue a configureminingpayouts JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewConfigureMiningPayoutsCmd(payoutPercents map[string]float64, height *int64) *ConfigureMiningPayoutsCmd {
+	return &ConfigureMiningPayoutsCmd{
+		PayoutPercents: payoutPercents,
+		Height:         height,
+	}
 }
 
 type GetMiningPayoutsResult map[string]float64
@@ -457,7 +558,21 @@ func NewGetPeerInfoCmd() *GetPeerInfoCmd {
 	return &GetPeerInfoCmd{}
 }
 
-type GetRawBlockTemplateCmd struct{}
+// GetRawBlockTemplateCmd defines the getrawblocktemplate JSON-RPC command.
+type GetRawBlockTemplateCmd struct {
+	LongPollID *string `json:"longpollid"`
+}
+
+// NewGetRawBlockTemplateCmd returns a new instance which can be used to issue
+// a getrawblocktemplate JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetRawBlockTemplateCmd(longPollID *string) *GetRawBlockTemplateCmd {
+	return &GetRawBlockTemplateCmd{
+		LongPollID: longPollID,
+	}
+}
 
 type CheckPcShareCmdStructure struct {
 	ShareTarget  uint32   `json:"sharetarget"`
@@ -481,6 +596,33 @@ type CheckPcAnnResult struct {
 	WorkHash string `json:"workhash"`
 }
 
+// CheckPcAnnsCmd defines the checkpcanns JSON-RPC command.
+type CheckPcAnnsCmd struct {
+	AnnsHex   []string `json:"annshex"`
+	PcVersion *int     `json:"pcversion"`
+}
+
+// NewCheckPcAnnsCmd returns a new instance which can be used to issue a
+// checkpcanns JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCheckPcAnnsCmd(annsHex []string, pcVersion *int) *CheckPcAnnsCmd {
+	return &CheckPcAnnsCmd{
+		AnnsHex:   annsHex,
+		PcVersion: pcVersion,
+	}
+}
+
+// CheckPcAnnsResult is the result of one announcement of the checkpcanns
+// command.  The code is 0 when the announcement is valid, or the number of an
+// announce.Err error code.
+type CheckPcAnnsResult struct {
+	WorkHash string `json:"workhash,omitempty"`
+	Code     int    `json:"code"`
+	Error    string `json:"error,omitempty"`
+}
+
 // GetRawMempoolCmd defines the getmempool JSON-RPC command.
 type GetRawMempoolCmd struct {
 	Verbose *bool `jsonrpcdefault:"false"`
@@ -645,6 +787,15 @@ func NewReconsiderBlockCmd(blockHash string) *ReconsiderBlockCmd {
 	}
 }
 
+// SaveMempoolCmd defines the savemempool JSON-RPC command.
+type SaveMempoolCmd struct{}
+
+// NewSaveMempoolCmd returns a new instance which can be used to issue a
+// savemempool JSON-RPC command.
+func NewSaveMempoolCmd() *SaveMempoolCmd {
+	return &SaveMempoolCmd{}
+}
+
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -861,10 +1012,12 @@ func init() {
 	MustRegisterCmd("configureminingpayouts", (*ConfigureMiningPayoutsCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("decodepcproof", (*DecodePcProofCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
 	MustRegisterCmd("estimatefee", (*EstimateFeeCmd)(nil), flags)
 	MustRegisterCmd("estimatesmartfee", (*EstimateSmartFeeCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
+	MustRegisterCmd("getannpool", (*GetAnnPoolCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
 	MustRegisterCmd("getblockchaininfo", (*GetBlockChainInfoCmd)(nil), flags)
@@ -880,6 +1033,8 @@ func init() {
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
+	MustRegisterCmd("getmempoolancestors", (*GetMempoolAncestorsCmd)(nil), flags)
+	MustRegisterCmd("getmempooldescendants", (*GetMempoolDescendantsCmd)(nil), flags)
 	MustRegisterCmd("getmempoolentry", (*GetMempoolEntryCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
@@ -888,10 +1043,12 @@ func init() {
 	MustRegisterCmd("getnettotals", (*GetNetTotalsCmd)(nil), flags)
 	MustRegisterCmd("getnetworksteward", (*GetNetworkStewardCmd)(nil), flags)
 	MustRegisterCmd("getnetworkhashps", (*GetNetworkHashPSCmd)(nil), flags)
+	MustRegisterCmd("getpcnetworkinfo", (*GetPcNetworkInfoCmd)(nil), flags)
 	MustRegisterCmd("getpeerinfo", (*GetPeerInfoCmd)(nil), flags)
 	MustRegisterCmd("getrawblocktemplate", (*GetRawBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("checkpcshare", (*CheckPcShareCmd)(nil), flags)
 	MustRegisterCmd("checkpcann", (*CheckPcAnnCmd)(nil), flags)
+	MustRegisterCmd("checkpcanns", (*CheckPcAnnsCmd)(nil), flags)
 	MustRegisterCmd("getrawmempool", (*GetRawMempoolCmd)(nil), flags)
 	MustRegisterCmd("getrawtransaction", (*GetRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("gettxout", (*GetTxOutCmd)(nil), flags)
@@ -904,6 +1061,7 @@ func init() {
 	MustRegisterCmd("echo", (*EchoCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
+	MustRegisterCmd("savemempool", (*SaveMempoolCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
@@ -41,6 +41,34 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "configureminingpayouts",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("configureminingpayouts", `{"addr":100}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConfigureMiningPayoutsCmd(map[string]float64{"addr": 100}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"configureminingpayouts","params":[{"addr":100}],"id":1}`,
+			unmarshalled: &btcjson.ConfigureMiningPayoutsCmd{
+				PayoutPercents: map[string]float64{"addr": 100},
+			},
+		},
+		{
+			name: "configureminingpayouts optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("configureminingpayouts", `{"addr":100}`, 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConfigureMiningPayoutsCmd(map[string]float64{"addr": 100},
+					btcjson.Int64(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"configureminingpayouts","params":[{"addr":100},1000],"id":1}`,
+			unmarshalled: &btcjson.ConfigureMiningPayoutsCmd{
+				PayoutPercents: map[string]float64{"addr": 100},
+				Height:         btcjson.Int64(1000),
+			},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, er.R) {
@@ -92,6 +120,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decoderawtransaction","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.DecodeRawTransactionCmd{HexTx: "123"},
 		},
+		{
+			name: "decodepcproof",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("decodepcproof", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDecodePcProofCmd("123")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodepcproof","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.DecodePcProofCmd{Block: "123"},
+		},
 		{
 			name: "decodescript",
 			newCmd: func() (interface{}, er.R) {
@@ -128,6 +167,33 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: btcjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getannpool",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getannpool")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAnnPoolCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getannpool","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetAnnPoolCmd{
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getannpool optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getannpool", true, 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAnnPoolCmd(btcjson.Bool(true), btcjson.Int64(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getannpool","params":[true,10],"id":1}`,
+			unmarshalled: &btcjson.GetAnnPoolCmd{
+				Verbose:      btcjson.Bool(true),
+				ParentHeight: btcjson.Int64(10),
+			},
+		},
 		{
 			name: "getbestblockhash",
 			newCmd: func() (interface{}, er.R) {
@@ -419,6 +485,113 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetInfoCmd{},
 		},
+		{
+			name: "getmempoolancestors",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getmempoolancestors", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolAncestorsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempoolancestors optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getmempoolancestors", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolAncestorsCmd("txhash", btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "getmempooldescendants",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getmempooldescendants", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDescendantsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempooldescendants optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getmempooldescendants", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDescendantsCmd("txhash", btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "checkpcanns",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("checkpcanns", []string{"00", "01"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCheckPcAnnsCmd([]string{"00", "01"}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"checkpcanns","params":[["00","01"]],"id":1}`,
+			unmarshalled: &btcjson.CheckPcAnnsCmd{
+				AnnsHex: []string{"00", "01"},
+			},
+		},
+		{
+			name: "checkpcanns optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("checkpcanns", []string{"00"}, 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCheckPcAnnsCmd([]string{"00"}, btcjson.Int(2))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"checkpcanns","params":[["00"],2],"id":1}`,
+			unmarshalled: &btcjson.CheckPcAnnsCmd{
+				AnnsHex:   []string{"00"},
+				PcVersion: btcjson.Int(2),
+			},
+		},
+		{
+			name: "getrawblocktemplate",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getrawblocktemplate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRawBlockTemplateCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrawblocktemplate","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetRawBlockTemplateCmd{},
+		},
+		{
+			name: "getrawblocktemplate optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getrawblocktemplate", "id")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRawBlockTemplateCmd(btcjson.String("id"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getrawblocktemplate","params":["id"],"id":1}`,
+			unmarshalled: &btcjson.GetRawBlockTemplateCmd{
+				LongPollID: btcjson.String("id"),
+			},
+		},
 		{
 			name: "getmempoolentry",
 			newCmd: func() (interface{}, er.R) {
@@ -518,6 +691,34 @@ func TestChainSvrCmds(t *testing.T) {
 				Height: btcjson.Int(123),
 			},
 		},
+		{
+			name: "getpcnetworkinfo",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getpcnetworkinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPcNetworkInfoCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpcnetworkinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetPcNetworkInfoCmd{
+				Blocks: btcjson.Int(120),
+				Height: btcjson.Int(-1),
+			},
+		},
+		{
+			name: "getpcnetworkinfo optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("getpcnetworkinfo", 200, 123)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPcNetworkInfoCmd(btcjson.Int(200), btcjson.Int(123))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpcnetworkinfo","params":[200,123],"id":1}`,
+			unmarshalled: &btcjson.GetPcNetworkInfoCmd{
+				Blocks: btcjson.Int(200),
+				Height: btcjson.Int(123),
+			},
+		},
 		{
 			name: "getpeerinfo",
 			newCmd: func() (interface{}, er.R) {
@@ -756,6 +957,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "savemempool",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("savemempool")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSaveMempoolCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"savemempool","params":[],"id":1}`,
+			unmarshalled: &btcjson.SaveMempoolCmd{},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, er.R) {
@@ -92,6 +92,103 @@ type GetAddedNodeInfoResultAddr struct {
 	Connected string `json:"connected"`
 }
 
+// PcProofAnnResult models an announcement of a PacketCrypt proof in the result
+// of the decodepcproof command.
+type PcProofAnnResult struct {
+	Hash            string `json:"hash"`
+	Index           uint64 `json:"index"`
+	Version         uint   `json:"version"`
+	ParentHeight    uint32 `json:"parentheight"`
+	ParentHash      string `json:"parenthash,omitempty"`
+	WorkTarget      string `json:"worktarget"`
+	EffectiveTarget string `json:"effectivetarget,omitempty"`
+	SigningKey      string `json:"signingkey,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+	ContentLength   uint32 `json:"contentlength"`
+	ContentHash     string `json:"contenthash"`
+	ContentProof    string `json:"contentproof,omitempty"`
+}
+
+// PcProofTreeEntryResult models an entry of the tree of the announcement proof
+// in the result of the decodepcproof command.
+type PcProofTreeEntryResult struct {
+	Number int    `json:"number"`
+	Depth  int    `json:"depth"`
+	Flags  string `json:"flags"`
+	Offset int    `json:"offset"`
+	Range  bool   `json:"range"`
+	Hash   bool   `json:"hash"`
+}
+
+// PcProofCheckResult models the result of one of the checks of a PacketCrypt
+// proof in the result of the decodepcproof command.
+type PcProofCheckResult struct {
+	Name  string `json:"name"`
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// DecodePcProofResult models the data returned from the decodepcproof command.
+type DecodePcProofResult struct {
+	Hash             string                   `json:"hash"`
+	Height           int32                    `json:"height"`
+	Bits             string                   `json:"bits"`
+	Version          int                      `json:"version"`
+	Nonce            uint32                   `json:"nonce"`
+	AnnCount         uint64                   `json:"anncount"`
+	AnnMinTarget     string                   `json:"annmintarget,omitempty"`
+	MerkleRoot       string                   `json:"merkleroot,omitempty"`
+	EffectiveTarget  string                   `json:"effectivetarget,omitempty"`
+	WorkHash         string                   `json:"workhash,omitempty"`
+	ContentProofIdx  uint32                   `json:"contentproofidx"`
+	Announcements    []PcProofAnnResult       `json:"announcements"`
+	AnnProofSize     int                      `json:"annproofsize"`
+	AnnProofExpected int                      `json:"annproofexpected"`
+	AnnProofTree     []PcProofTreeEntryResult `json:"annprooftree"`
+	Checks           []PcProofCheckResult     `json:"checks"`
+	Valid            bool                     `json:"valid"`
+}
+
+// PcAnnAgeResult models the number of announcements of a given age in the
+// result of the getpcnetworkinfo command.
+type PcAnnAgeResult struct {
+	Age   uint32 `json:"age"`
+	Count int    `json:"count"`
+}
+
+// GetPcNetworkInfoResult models the data returned from the getpcnetworkinfo
+// command.  The difficulties are the estimated number of encryptions needed,
+// the averages are over the blocks which have a PacketCrypt proof.
+type GetPcNetworkInfoResult struct {
+	StartHeight            int32            `json:"startheight"`
+	EndHeight              int32            `json:"endheight"`
+	Blocks                 int              `json:"blocks"`
+	AvgAnnCount            float64          `json:"avganncount"`
+	AvgAnnMinTarget        string           `json:"avgannmintarget,omitempty"`
+	AvgAnnDifficulty       float64          `json:"avganndifficulty"`
+	AnnEncryptionsPerSec   float64          `json:"annencryptionspersec"`
+	AvgHeaderTarget        string           `json:"avgheadertarget,omitempty"`
+	AvgHeaderDifficulty    float64          `json:"avgheaderdifficulty"`
+	AvgEffectiveTarget     string           `json:"avgeffectivetarget,omitempty"`
+	AvgEffectiveDifficulty float64          `json:"avgeffectivedifficulty"`
+	BlkEncryptionsPerSec   float64          `json:"blkencryptionspersec"`
+	MinAnnAge              uint32           `json:"minannage"`
+	MaxAnnAge              uint32           `json:"maxannage"`
+	AvgAnnAge              float64          `json:"avgannage"`
+	AnnAges                []PcAnnAgeResult `json:"annages"`
+}
+
+// GetAnnPoolVerboseResult models the data returned from the getannpool command
+// when the verbose flag is set.  When the verbose flag is not set, getannpool
+// returns an array of announcement hashes.
+type GetAnnPoolVerboseResult struct {
+	Hash         string `json:"hash"`
+	ParentHeight uint32 `json:"parentheight"`
+	WorkTarget   string `json:"worktarget"`
+	Version      uint   `json:"version"`
+	Hex          string `json:"hex"`
+}
+
 // GetAddedNodeInfoResult models the data from the getaddednodeinfo command.
 type GetAddedNodeInfoResult struct {
 	AddedNode string                        `json:"addednode"`
@@ -137,6 +234,14 @@ type GetBlockChainInfoResult struct {
 	Bip9SoftForks        map[string]*Bip9SoftForkDescription `json:"bip9_softforks"`
 }
 
+// GetChainTipsResult models the data returned from the getchaintips command.
+type GetChainTipsResult struct {
+	Height    int32  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int32  `json:"branchlen"`
+	Status    string `json:"status"`
+}
+
 // GetBlockTemplateResultTx models the transactions field of the
 // getblocktemplate command.
 type GetBlockTemplateResultTx struct {
@@ -263,6 +368,7 @@ type GetRawBlockTemplateResult struct {
 	CoinbaseNoWitness string   `json:"coinbase_no_witness"`
 	MerkleBranch      []string `json:"merklebranch"`
 	Transactions      []string `json:"transactions"`
+	LongPollID        string   `json:"longpollid"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
@@ -279,6 +385,28 @@ type GetRawMempoolVerboseResult struct {
 	Depends          []string `json:"depends"`
 }
 
+// GetMempoolEntryResult models the data returned from the getmempoolentry
+// command and from the getmempoolancestors and getmempooldescendants commands
+// when the verbose flag is set.  The ancestor and descendant totals include the
+// transaction itself.
+type GetMempoolEntryResult struct {
+	Size             int32    `json:"size"`
+	Vsize            int32    `json:"vsize"`
+	Fee              float64  `json:"fee"`
+	Time             int64    `json:"time"`
+	Height           int64    `json:"height"`
+	StartingPriority float64  `json:"startingpriority"`
+	CurrentPriority  float64  `json:"currentpriority"`
+	DescendantCount  int64    `json:"descendantcount"`
+	DescendantSize   int64    `json:"descendantsize"`
+	DescendantFees   float64  `json:"descendantfees"`
+	AncestorCount    int64    `json:"ancestorcount"`
+	AncestorSize     int64    `json:"ancestorsize"`
+	AncestorFees     float64  `json:"ancestorfees"`
+	Depends          []string `json:"depends"`
+	SpentBy          []string `json:"spentby"`
+}
+
 // GetTxOutResult models the data from the gettxout command.
 type GetTxOutResult struct {
 	BestBlock     string  `json:"bestblock"`
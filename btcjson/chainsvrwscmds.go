@@ -41,6 +41,26 @@ func NewStopNotifyBlocksCmd() *StopNotifyBlocksCmd {
 	return &StopNotifyBlocksCmd{}
 }
 
+// NotifyRawBlockTemplateCmd defines the notifyrawblocktemplate JSON-RPC
+// command.
+type NotifyRawBlockTemplateCmd struct{}
+
+// NewNotifyRawBlockTemplateCmd returns a new instance which can be used to
+// issue a notifyrawblocktemplate JSON-RPC command.
+func NewNotifyRawBlockTemplateCmd() *NotifyRawBlockTemplateCmd {
+	return &NotifyRawBlockTemplateCmd{}
+}
+
+// StopNotifyRawBlockTemplateCmd defines the stopnotifyrawblocktemplate
+// JSON-RPC command.
+type StopNotifyRawBlockTemplateCmd struct{}
+
+// NewStopNotifyRawBlockTemplateCmd returns a new instance which can be used to
+// issue a stopnotifyrawblocktemplate JSON-RPC command.
+func NewStopNotifyRawBlockTemplateCmd() *StopNotifyRawBlockTemplateCmd {
+	return &StopNotifyRawBlockTemplateCmd{}
+}
+
 // NotifyNewTransactionsCmd defines the notifynewtransactions JSON-RPC command.
 type NotifyNewTransactionsCmd struct {
 	Verbose *bool `jsonrpcdefault:"false"`
@@ -225,11 +245,13 @@ func init() {
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
+	MustRegisterCmd("notifyrawblocktemplate", (*NotifyRawBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
+	MustRegisterCmd("stopnotifyrawblocktemplate", (*StopNotifyRawBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("rescan", (*RescanCmd)(nil), flags)
 	MustRegisterCmd("rescanblocks", (*RescanBlocksCmd)(nil), flags)
 }
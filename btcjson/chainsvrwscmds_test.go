@@ -64,6 +64,28 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifyrawblocktemplate",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("notifyrawblocktemplate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyRawBlockTemplateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifyrawblocktemplate","params":[],"id":1}`,
+			unmarshalled: &btcjson.NotifyRawBlockTemplateCmd{},
+		},
+		{
+			name: "stopnotifyrawblocktemplate",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("stopnotifyrawblocktemplate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyRawBlockTemplateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyrawblocktemplate","params":[],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyRawBlockTemplateCmd{},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, er.R) {
@@ -75,6 +75,10 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// RawBlockTemplateNtfnMethod is the method used for notifications from
+	// the chain server that the raw block template has changed.
+	RawBlockTemplateNtfnMethod = "rawblocktemplate"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +289,19 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// RawBlockTemplateNtfn defines the rawblocktemplate JSON-RPC notification.
+type RawBlockTemplateNtfn struct {
+	Template GetRawBlockTemplateResult
+}
+
+// NewRawBlockTemplateNtfn returns a new instance which can be used to issue a
+// rawblocktemplate JSON-RPC notification.
+func NewRawBlockTemplateNtfn(template GetRawBlockTemplateResult) *RawBlockTemplateNtfn {
+	return &RawBlockTemplateNtfn{
+		Template: template,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -301,4 +318,5 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(RawBlockTemplateNtfnMethod, (*RawBlockTemplateNtfn)(nil), flags)
 }
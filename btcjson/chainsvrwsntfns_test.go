@@ -226,6 +226,33 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Transaction: "001122",
 			},
 		},
+		{
+			name: "rawblocktemplate",
+			newNtfn: func() (interface{}, er.R) {
+				return btcjson.NewCmd("rawblocktemplate", `{"height":2,"header":"00","coinbase_no_witness":"01","merklebranch":["02"],"transactions":[],"longpollid":"id"}`)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewRawBlockTemplateNtfn(btcjson.GetRawBlockTemplateResult{
+					Height:            2,
+					Header:            "00",
+					CoinbaseNoWitness: "01",
+					MerkleBranch:      []string{"02"},
+					Transactions:      []string{},
+					LongPollID:        "id",
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"rawblocktemplate","params":[{"height":2,"header":"00","coinbase_no_witness":"01","merklebranch":["02"],"transactions":[],"longpollid":"id"}],"id":null}`,
+			unmarshalled: &btcjson.RawBlockTemplateNtfn{
+				Template: btcjson.GetRawBlockTemplateResult{
+					Height:            2,
+					Header:            "00",
+					CoinbaseNoWitness: "01",
+					MerkleBranch:      []string{"02"},
+					Transactions:      []string{},
+					LongPollID:        "id",
+				},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
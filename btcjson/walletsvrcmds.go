@@ -32,6 +32,29 @@ func NewAddWitnessAddressCmd(address string) *AddWitnessAddressCmd {
 	}
 }
 
+// BumpFeeOpts are the options of the bumpfee JSON-RPC command.
+type BumpFeeOpts struct {
+	FeeRate *float64 `json:"feerate,omitempty"` // In BTC/kB
+}
+
+// BumpFeeCmd defines the bumpfee JSON-RPC command.
+type BumpFeeCmd struct {
+	Txid    string
+	Options *BumpFeeOpts
+}
+
+// NewBumpFeeCmd returns a new instance which can be used to issue a bumpfee
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewBumpFeeCmd(txid string, options *BumpFeeOpts) *BumpFeeCmd {
+	return &BumpFeeCmd{
+		Txid:    txid,
+		Options: options,
+	}
+}
+
 // CreateMultisigCmd defines the createmultisig JSON-RPC command.
 type CreateMultisigCmd struct {
 	NRequired int
@@ -150,6 +173,64 @@ func NewImportPrivKeyCmd(privKey string, label *string, rescan *bool) *ImportPri
 	}
 }
 
+// ImportDescriptorsRequest is an output descriptor to import with the
+// importdescriptors JSON-RPC command.  Range is either [end] or [begin, end],
+// the indexes of the addresses which are imported from a ranged descriptor.
+type ImportDescriptorsRequest struct {
+	Desc       string `json:"desc"`
+	Range      []int  `json:"range,omitempty"`
+	RescanFrom *int   `json:"rescanfrom,omitempty"`
+}
+
+// ImportDescriptorsCmd defines the importdescriptors JSON-RPC command.
+type ImportDescriptorsCmd struct {
+	Requests []ImportDescriptorsRequest
+	Rescan   *bool `jsonrpcdefault:"true"`
+}
+
+// NewImportDescriptorsCmd returns a new instance which can be used to issue a
+// importdescriptors JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportDescriptorsCmd(requests []ImportDescriptorsRequest, rescan *bool) *ImportDescriptorsCmd {
+	return &ImportDescriptorsCmd{
+		Requests: requests,
+		Rescan:   rescan,
+	}
+}
+
+// ImportXpubCmd defines the importxpub JSON-RPC command.
+type ImportXpubCmd struct {
+	Xpub        string
+	AccountName string
+	Rescan      *bool `jsonrpcdefault:"true"`
+	RescanFrom  *int  `jsonrpcdefault:"0"`
+}
+
+// NewImportXpubCmd returns a new instance which can be used to issue a
+// importxpub JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportXpubCmd(xpub, accountName string, rescan *bool, rescanFrom *int) *ImportXpubCmd {
+	return &ImportXpubCmd{
+		Xpub:        xpub,
+		AccountName: accountName,
+		Rescan:      rescan,
+		RescanFrom:  rescanFrom,
+	}
+}
+
+// ListDescriptorsCmd defines the listdescriptors JSON-RPC command.
+type ListDescriptorsCmd struct{}
+
+// NewListDescriptorsCmd returns a new instance which can be used to issue a
+// listdescriptors JSON-RPC command.
+func NewListDescriptorsCmd() *ListDescriptorsCmd {
+	return &ListDescriptorsCmd{}
+}
+
 // ListLockUnspentCmd defines the listlockunspent JSON-RPC command.
 type ListLockUnspentCmd struct{}
 
@@ -283,6 +364,37 @@ type CreateTransactionCmd struct {
 	AutoLock       *string
 }
 
+// FoldAddressCmd defines the foldaddress JSON-RPC command.
+type FoldAddressCmd struct {
+	ToAddress       string
+	FromAddresses   *[]string
+	MaxAmount       *float64 // In BTC
+	MinConf         *int     `jsonrpcdefault:"1"`
+	MaxInputs       *int
+	MaxTransactions *int
+	MaxFee          *float64 // In BTC
+	DryRun          *bool    `jsonrpcdefault:"false"`
+}
+
+// NewFoldAddressCmd returns a new instance which can be used to issue a
+// foldaddress JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewFoldAddressCmd(toAddress string, fromAddresses *[]string, maxAmount *float64,
+	minConf *int, maxInputs, maxTransactions *int, maxFee *float64, dryRun *bool) *FoldAddressCmd {
+	return &FoldAddressCmd{
+		ToAddress:       toAddress,
+		FromAddresses:   fromAddresses,
+		MaxAmount:       maxAmount,
+		MinConf:         minConf,
+		MaxInputs:       maxInputs,
+		MaxTransactions: maxTransactions,
+		MaxFee:          maxFee,
+		DryRun:          dryRun,
+	}
+}
+
 // SendManyCmd defines the sendmany JSON-RPC command.
 type SendManyCmd struct {
 	Amounts       map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In BTC
@@ -387,6 +499,120 @@ func NewSignRawTransactionCmd(hexEncodedTx string, inputs *[]RawTxInput, privKey
 	}
 }
 
+// AnalyzePsbtCmd defines the analyzepsbt JSON-RPC command.
+type AnalyzePsbtCmd struct {
+	Psbt string
+}
+
+// NewAnalyzePsbtCmd returns a new instance which can be used to issue an
+// analyzepsbt JSON-RPC command.
+func NewAnalyzePsbtCmd(psbt string) *AnalyzePsbtCmd {
+	return &AnalyzePsbtCmd{
+		Psbt: psbt,
+	}
+}
+
+// CombinePsbtCmd defines the combinepsbt JSON-RPC command.
+type CombinePsbtCmd struct {
+	Txs []string
+}
+
+// NewCombinePsbtCmd returns a new instance which can be used to issue a
+// combinepsbt JSON-RPC command.
+func NewCombinePsbtCmd(txs []string) *CombinePsbtCmd {
+	return &CombinePsbtCmd{
+		Txs: txs,
+	}
+}
+
+// DecodePsbtCmd defines the decodepsbt JSON-RPC command.
+type DecodePsbtCmd struct {
+	Psbt string
+}
+
+// NewDecodePsbtCmd returns a new instance which can be used to issue a
+// decodepsbt JSON-RPC command.
+func NewDecodePsbtCmd(psbt string) *DecodePsbtCmd {
+	return &DecodePsbtCmd{
+		Psbt: psbt,
+	}
+}
+
+// FinalizePsbtCmd defines the finalizepsbt JSON-RPC command.
+type FinalizePsbtCmd struct {
+	Psbt    string
+	Extract *bool `jsonrpcdefault:"true"`
+}
+
+// NewFinalizePsbtCmd returns a new instance which can be used to issue a
+// finalizepsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewFinalizePsbtCmd(psbt string, extract *bool) *FinalizePsbtCmd {
+	return &FinalizePsbtCmd{
+		Psbt:    psbt,
+		Extract: extract,
+	}
+}
+
+// PsbtInput is an input of the transaction of the PSBT created by the
+// walletcreatefundedpsbt JSON-RPC command.
+type PsbtInput struct {
+	Txid     string  `json:"txid"`
+	Vout     uint32  `json:"vout"`
+	Sequence *uint32 `json:"sequence,omitempty"`
+}
+
+// WalletCreateFundedPsbtOpts are the options of the walletcreatefundedpsbt
+// JSON-RPC command.
+type WalletCreateFundedPsbtOpts struct {
+	FeeRate *float64 `json:"feerate,omitempty"` // In BTC/kB
+	Account *uint32  `json:"account,omitempty"`
+}
+
+// WalletCreateFundedPsbtCmd defines the walletcreatefundedpsbt JSON-RPC
+// command.
+type WalletCreateFundedPsbtCmd struct {
+	Inputs   []PsbtInput
+	Outputs  map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In BTC
+	Locktime *uint32            `jsonrpcdefault:"0"`
+	Options  *WalletCreateFundedPsbtOpts
+}
+
+// NewWalletCreateFundedPsbtCmd returns a new instance which can be used to
+// issue a walletcreatefundedpsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewWalletCreateFundedPsbtCmd(inputs []PsbtInput, outputs map[string]float64,
+	locktime *uint32, options *WalletCreateFundedPsbtOpts) *WalletCreateFundedPsbtCmd {
+	return &WalletCreateFundedPsbtCmd{
+		Inputs:   inputs,
+		Outputs:  outputs,
+		Locktime: locktime,
+		Options:  options,
+	}
+}
+
+// WalletProcessPsbtCmd defines the walletprocesspsbt JSON-RPC command.
+type WalletProcessPsbtCmd struct {
+	Psbt string
+	Sign *bool `jsonrpcdefault:"true"`
+}
+
+// NewWalletProcessPsbtCmd returns a new instance which can be used to issue a
+// walletprocesspsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewWalletProcessPsbtCmd(psbt string, sign *bool) *WalletProcessPsbtCmd {
+	return &WalletProcessPsbtCmd{
+		Psbt: psbt,
+		Sign: sign,
+	}
+}
+
 // WalletLockCmd defines the walletlock JSON-RPC command.
 type WalletLockCmd struct{}
 
@@ -441,12 +667,18 @@ func init() {
 	MustRegisterCmd("addmultisigaddress", (*AddMultisigAddressCmd)(nil), flags)
 	MustRegisterCmd("addp2shscript", (*AddP2shScriptCmd)(nil), flags)
 	MustRegisterCmd("addwitnessaddress", (*AddWitnessAddressCmd)(nil), flags)
+	MustRegisterCmd("analyzepsbt", (*AnalyzePsbtCmd)(nil), flags)
+	MustRegisterCmd("bumpfee", (*BumpFeeCmd)(nil), flags)
+	MustRegisterCmd("combinepsbt", (*CombinePsbtCmd)(nil), flags)
 	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
 	MustRegisterCmd("createtransaction", (*CreateTransactionCmd)(nil), flags)
+	MustRegisterCmd("decodepsbt", (*DecodePsbtCmd)(nil), flags)
 	MustRegisterCmd("getaddressbalances", (*GetAddressBalancesCmd)(nil), flags)
 	MustRegisterCmd("resync", (*ResyncCmd)(nil), flags)
 	MustRegisterCmd("stopresync", (*StopResyncCmd)(nil), flags)
 	MustRegisterCmd("dumpprivkey", (*DumpPrivKeyCmd)(nil), flags)
+	MustRegisterCmd("finalizepsbt", (*FinalizePsbtCmd)(nil), flags)
+	MustRegisterCmd("foldaddress", (*FoldAddressCmd)(nil), flags)
 	MustRegisterCmd("getbalance", (*GetBalanceCmd)(nil), flags)
 	MustRegisterCmd("getnetworkstewardvote", (*GetNetworkStewardVoteCmd)(nil), flags)
 	MustRegisterCmd("getnewaddress", (*GetNewAddressCmd)(nil), flags)
@@ -455,6 +687,9 @@ func init() {
 	MustRegisterCmd("getwalletseed", (*GetWalletSeedCmd)(nil), flags)
 	MustRegisterCmd("getsecret", (*GetSecretCmd)(nil), flags)
 	MustRegisterCmd("importprivkey", (*ImportPrivKeyCmd)(nil), flags)
+	MustRegisterCmd("importdescriptors", (*ImportDescriptorsCmd)(nil), flags)
+	MustRegisterCmd("importxpub", (*ImportXpubCmd)(nil), flags)
+	MustRegisterCmd("listdescriptors", (*ListDescriptorsCmd)(nil), flags)
 	MustRegisterCmd("listlockunspent", (*ListLockUnspentCmd)(nil), flags)
 	MustRegisterCmd("listreceivedbyaddress", (*ListReceivedByAddressCmd)(nil), flags)
 	MustRegisterCmd("listsinceblock", (*ListSinceBlockCmd)(nil), flags)
@@ -468,8 +703,10 @@ func init() {
 	MustRegisterCmd("settxfee", (*SetTxFeeCmd)(nil), flags)
 	MustRegisterCmd("signmessage", (*SignMessageCmd)(nil), flags)
 	MustRegisterCmd("signrawtransaction", (*SignRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("walletcreatefundedpsbt", (*WalletCreateFundedPsbtCmd)(nil), flags)
 	MustRegisterCmd("walletlock", (*WalletLockCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrase", (*WalletPassphraseCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrasechange", (*WalletPassphraseChangeCmd)(nil), flags)
+	MustRegisterCmd("walletprocesspsbt", (*WalletProcessPsbtCmd)(nil), flags)
 	MustRegisterCmd("walletmempool", (*WalletMempoolCmd)(nil), flags)
 }
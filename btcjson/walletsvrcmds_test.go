@@ -53,6 +53,47 @@ func TestWalletSvrCmds(t *testing.T) {
 				Address: "1address",
 			},
 		},
+		{
+			name: "bumpfee",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("bumpfee", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeeCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.BumpFeeCmd{
+				Txid: "123",
+			},
+		},
+		{
+			name: "bumpfee optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("bumpfee", "123", `{"feerate":0.0001}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeeCmd("123",
+					&btcjson.BumpFeeOpts{FeeRate: btcjson.Float64(0.0001)})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","params":["123",{"feerate":0.0001}],"id":1}`,
+			unmarshalled: &btcjson.BumpFeeCmd{
+				Txid:    "123",
+				Options: &btcjson.BumpFeeOpts{FeeRate: btcjson.Float64(0.0001)},
+			},
+		},
+		{
+			name: "combinepsbt",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("combinepsbt", `["cHNidP8=","cHNidP8="]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCombinePsbtCmd([]string{"cHNidP8=", "cHNidP8="})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"combinepsbt","params":[["cHNidP8=","cHNidP8="]],"id":1}`,
+			unmarshalled: &btcjson.CombinePsbtCmd{
+				Txs: []string{"cHNidP8=", "cHNidP8="},
+			},
+		},
 		{
 			name: "createmultisig",
 			newCmd: func() (interface{}, er.R) {
@@ -94,6 +135,124 @@ func TestWalletSvrCmds(t *testing.T) {
 				NumBlocks: 6,
 			},
 		},
+		{
+			name: "finalizepsbt",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("finalizepsbt", "cHNidP8=", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFinalizePsbtCmd("cHNidP8=", btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","params":["cHNidP8=",false],"id":1}`,
+			unmarshalled: &btcjson.FinalizePsbtCmd{
+				Psbt:    "cHNidP8=",
+				Extract: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "foldaddress",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("foldaddress", "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFoldAddressCmd("1Address", nil, nil, nil, nil, nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"foldaddress","params":["1Address"],"id":1}`,
+			unmarshalled: &btcjson.FoldAddressCmd{
+				ToAddress: "1Address",
+				MinConf:   btcjson.Int(1),
+				DryRun:    btcjson.Bool(false),
+			},
+		},
+		{
+			name: "foldaddress optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("foldaddress", "1Address", &[]string{"from"}, 0.5, 6, 100, 2, 0.01, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFoldAddressCmd("1Address", &[]string{"from"}, btcjson.Float64(0.5),
+					btcjson.Int(6), btcjson.Int(100), btcjson.Int(2), btcjson.Float64(0.01), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"foldaddress","params":["1Address",["from"],0.5,6,100,2,0.01,true],"id":1}`,
+			unmarshalled: &btcjson.FoldAddressCmd{
+				ToAddress:       "1Address",
+				FromAddresses:   &[]string{"from"},
+				MaxAmount:       btcjson.Float64(0.5),
+				MinConf:         btcjson.Int(6),
+				MaxInputs:       btcjson.Int(100),
+				MaxTransactions: btcjson.Int(2),
+				MaxFee:          btcjson.Float64(0.01),
+				DryRun:          btcjson.Bool(true),
+			},
+		},
+		{
+			name: "importdescriptors",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("importdescriptors", `[{"desc":"wpkh(xpub/0/*)","range":[0,99]}]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportDescriptorsCmd([]btcjson.ImportDescriptorsRequest{
+					{Desc: "wpkh(xpub/0/*)", Range: []int{0, 99}},
+				}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importdescriptors","params":[[{"desc":"wpkh(xpub/0/*)","range":[0,99]}]],"id":1}`,
+			unmarshalled: &btcjson.ImportDescriptorsCmd{
+				Requests: []btcjson.ImportDescriptorsRequest{
+					{Desc: "wpkh(xpub/0/*)", Range: []int{0, 99}},
+				},
+				Rescan: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "importdescriptors optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("importdescriptors", `[{"desc":"wpkh(xpub/0/*)","rescanfrom":1000}]`, false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportDescriptorsCmd([]btcjson.ImportDescriptorsRequest{
+					{Desc: "wpkh(xpub/0/*)", RescanFrom: btcjson.Int(1000)},
+				}, btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importdescriptors","params":[[{"desc":"wpkh(xpub/0/*)","rescanfrom":1000}],false],"id":1}`,
+			unmarshalled: &btcjson.ImportDescriptorsCmd{
+				Requests: []btcjson.ImportDescriptorsRequest{
+					{Desc: "wpkh(xpub/0/*)", RescanFrom: btcjson.Int(1000)},
+				},
+				Rescan: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "importxpub",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("importxpub", "zpub", "cold")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportXpubCmd("zpub", "cold", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importxpub","params":["zpub","cold"],"id":1}`,
+			unmarshalled: &btcjson.ImportXpubCmd{
+				Xpub:        "zpub",
+				AccountName: "cold",
+				Rescan:      btcjson.Bool(true),
+				RescanFrom:  btcjson.Int(0),
+			},
+		},
+		{
+			name: "importxpub optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("importxpub", "zpub", "cold", false, 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportXpubCmd("zpub", "cold", btcjson.Bool(false), btcjson.Int(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importxpub","params":["zpub","cold",false,1000],"id":1}`,
+			unmarshalled: &btcjson.ImportXpubCmd{
+				Xpub:        "zpub",
+				AccountName: "cold",
+				Rescan:      btcjson.Bool(false),
+				RescanFrom:  btcjson.Int(1000),
+			},
+		},
 		{
 			name: "getbalance",
 			newCmd: func() (interface{}, er.R) {
@@ -178,6 +337,17 @@ func TestWalletSvrCmds(t *testing.T) {
 				IncludeWatchOnly: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "listdescriptors",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("listdescriptors")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListDescriptorsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listdescriptors","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListDescriptorsCmd{},
+		},
 		{
 			name: "listlockunspent",
 			newCmd: func() (interface{}, er.R) {
@@ -681,6 +851,42 @@ func TestWalletSvrCmds(t *testing.T) {
 				Flags:    btcjson.String("ALL"),
 			},
 		},
+		{
+			name: "walletcreatefundedpsbt",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("walletcreatefundedpsbt", `[]`, `{"1Address":0.5}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletCreateFundedPsbtCmd([]btcjson.PsbtInput{},
+					map[string]float64{"1Address": 0.5}, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","params":[[],{"1Address":0.5}],"id":1}`,
+			unmarshalled: &btcjson.WalletCreateFundedPsbtCmd{
+				Inputs:   []btcjson.PsbtInput{},
+				Outputs:  map[string]float64{"1Address": 0.5},
+				Locktime: btcjson.Uint32(0),
+			},
+		},
+		{
+			name: "walletcreatefundedpsbt optional",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("walletcreatefundedpsbt", `[{"txid":"123","vout":1}]`,
+					`{"1Address":0.5}`, 100, `{"feerate":0.0001}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletCreateFundedPsbtCmd(
+					[]btcjson.PsbtInput{{Txid: "123", Vout: 1}},
+					map[string]float64{"1Address": 0.5}, btcjson.Uint32(100),
+					&btcjson.WalletCreateFundedPsbtOpts{FeeRate: btcjson.Float64(0.0001)})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","params":[[{"txid":"123","vout":1}],{"1Address":0.5},100,{"feerate":0.0001}],"id":1}`,
+			unmarshalled: &btcjson.WalletCreateFundedPsbtCmd{
+				Inputs:   []btcjson.PsbtInput{{Txid: "123", Vout: 1}},
+				Outputs:  map[string]float64{"1Address": 0.5},
+				Locktime: btcjson.Uint32(100),
+				Options:  &btcjson.WalletCreateFundedPsbtOpts{FeeRate: btcjson.Float64(0.0001)},
+			},
+		},
 		{
 			name: "walletlock",
 			newCmd: func() (interface{}, er.R) {
@@ -720,6 +926,20 @@ func TestWalletSvrCmds(t *testing.T) {
 				NewPassphrase: "new",
 			},
 		},
+		{
+			name: "walletprocesspsbt",
+			newCmd: func() (interface{}, er.R) {
+				return btcjson.NewCmd("walletprocesspsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletProcessPsbtCmd("cHNidP8=", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletprocesspsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.WalletProcessPsbtCmd{
+				Psbt: "cHNidP8=",
+				Sign: btcjson.Bool(true),
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -243,6 +243,153 @@ type GetAddressBalancesResult struct {
 	OutputCount int32 `json:"outputcount"`
 }
 
+// FoldAddressTx models a transaction of the result of the foldaddress command.
+type FoldAddressTx struct {
+	Txid   string  `json:"txid"`
+	Inputs int     `json:"inputs"`
+	Amount float64 `json:"amount"`
+	Fee    float64 `json:"fee"`
+	Hex    string  `json:"hex,omitempty"`
+}
+
+// FoldAddressResult models the data from the foldaddress command.
+type FoldAddressResult struct {
+	Transactions []FoldAddressTx `json:"transactions"`
+	Inputs       int             `json:"inputs"`
+	Amount       float64         `json:"amount"`
+	Fee          float64         `json:"fee"`
+	StopReason   string          `json:"stopreason,omitempty"`
+}
+
+// ImportDescriptorsResult models the data returned for each descriptor by the
+// importdescriptors command.
+type ImportDescriptorsResult struct {
+	Success bool   `json:"success"`
+	Desc    string `json:"desc,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ListDescriptorsResult models the data of each descriptor returned by the
+// listdescriptors command.
+type ListDescriptorsResult struct {
+	Desc     string   `json:"desc"`
+	Active   bool     `json:"active"`
+	Account  string   `json:"account,omitempty"`
+	Internal *bool    `json:"internal,omitempty"`
+	Range    []uint32 `json:"range,omitempty"`
+	Next     *uint32  `json:"next,omitempty"`
+}
+
+// AnalyzePsbtMissing models what an input of a PSBT needs before it can be
+// finalized, as returned by the analyzepsbt command.
+type AnalyzePsbtMissing struct {
+	Signatures    []string `json:"signatures,omitempty"`
+	RedeemScript  string   `json:"redeemscript,omitempty"`
+	WitnessScript string   `json:"witnessscript,omitempty"`
+}
+
+// AnalyzePsbtInput models the data of each input returned by the analyzepsbt
+// command.
+type AnalyzePsbtInput struct {
+	HasUtxo bool                `json:"has_utxo"`
+	IsFinal bool                `json:"is_final"`
+	Missing *AnalyzePsbtMissing `json:"missing,omitempty"`
+	Next    string              `json:"next"`
+}
+
+// AnalyzePsbtResult models the data from the analyzepsbt command.
+type AnalyzePsbtResult struct {
+	Inputs           []AnalyzePsbtInput `json:"inputs"`
+	EstimatedVSize   *int64             `json:"estimated_vsize,omitempty"`
+	EstimatedFeeRate *float64           `json:"estimated_feerate,omitempty"`
+	Fee              *float64           `json:"fee,omitempty"`
+	Next             string             `json:"next"`
+}
+
+// PsbtUtxo models the output spent by an input of a PSBT, as returned by the
+// decodepsbt command.
+type PsbtUtxo struct {
+	Amount       float64 `json:"amount"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	Address      string  `json:"address,omitempty"`
+}
+
+// PsbtBip32Deriv models the BIP0032 derivation of a key of a PSBT, as returned
+// by the decodepsbt command.
+type PsbtBip32Deriv struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// DecodePsbtInput models the data of each input returned by the decodepsbt
+// command.
+type DecodePsbtInput struct {
+	NonWitnessUtxo     *PsbtUtxo         `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *PsbtUtxo         `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string `json:"partial_signatures,omitempty"`
+	SigHash            string            `json:"sighash,omitempty"`
+	RedeemScript       string            `json:"redeem_script,omitempty"`
+	WitnessScript      string            `json:"witness_script,omitempty"`
+	Bip32Derivs        []PsbtBip32Deriv  `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     string            `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness []string          `json:"final_scriptwitness,omitempty"`
+	Unknown            map[string]string `json:"unknown,omitempty"`
+}
+
+// DecodePsbtOutput models the data of each output returned by the decodepsbt
+// command.
+type DecodePsbtOutput struct {
+	RedeemScript  string           `json:"redeem_script,omitempty"`
+	WitnessScript string           `json:"witness_script,omitempty"`
+	Bip32Derivs   []PsbtBip32Deriv `json:"bip32_derivs,omitempty"`
+}
+
+// DecodePsbtResult models the data from the decodepsbt command.
+type DecodePsbtResult struct {
+	Tx      TxRawDecodeResult  `json:"tx"`
+	Unknown map[string]string  `json:"unknown"`
+	Inputs  []DecodePsbtInput  `json:"inputs"`
+	Outputs []DecodePsbtOutput `json:"outputs"`
+	Fee     *float64           `json:"fee,omitempty"`
+}
+
+// FinalizePsbtResult models the data from the finalizepsbt command.
+type FinalizePsbtResult struct {
+	Psbt     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
+// ImportXpubResult models the data from the importxpub command.
+type ImportXpubResult struct {
+	Account     uint32 `json:"account"`
+	AccountName string `json:"accountname"`
+	KeyScope    string `json:"keyscope"`
+}
+
+// WalletCreateFundedPsbtResult models the data from the
+// walletcreatefundedpsbt command.
+type WalletCreateFundedPsbtResult struct {
+	Psbt      string  `json:"psbt"`
+	Fee       float64 `json:"fee"`
+	ChangePos int32   `json:"changepos"`
+}
+
+// WalletProcessPsbtResult models the data from the walletprocesspsbt command.
+type WalletProcessPsbtResult struct {
+	Psbt     string `json:"psbt"`
+	Complete bool   `json:"complete"`
+}
+
+// BumpFeeResult models the data from the bumpfee command.
+type BumpFeeResult struct {
+	Txid    string  `json:"txid"`
+	OrigFee float64 `json:"origfee"`
+	Fee     float64 `json:"fee"`
+	Method  string  `json:"method"`
+}
+
 type MaintenanceStats struct {
 	// Burned           int
 	// Orphaned         int
@@ -209,6 +209,35 @@ func (k *ExtendedKey) ParentFingerprint() uint32 {
 	return binary.BigEndian.Uint32(k.parentFP)
 }
 
+// ChildIndex returns the index at which the child extended key was derived.
+//
+// Extended keys with depth 0 are the master key, so their child index is
+// always 0.
+func (k *ExtendedKey) ChildIndex() uint32 {
+	return k.childNum
+}
+
+// Version returns the four version bytes of the serialized extended key,
+// which identify the network and, for SLIP-0132 keys, the address type.
+func (k *ExtendedKey) Version() []byte {
+	return k.version
+}
+
+// CloneWithVersion returns a new extended key cloned from this extended key,
+// but using the provided HD version bytes.  The version must be a private HD
+// key ID for an extended private key, and a public HD key ID for an extended
+// public key.
+//
+// This method creates a new copy and therefore does not mutate the original
+// extended key instance.
+func (k *ExtendedKey) CloneWithVersion(version []byte) (*ExtendedKey, er.R) {
+	if len(version) != 4 {
+		return nil, chaincfg.ErrUnknownHDKeyID.Default()
+	}
+	return NewExtendedKey(version, k.key, k.chainCode, k.parentFP, k.depth,
+		k.childNum, k.isPrivate), nil
+}
+
 // Derive returns a derived child extended key at the given index.
 //
 // IMPORTANT: if you were previously using the Child method, this method is incompatible.
@@ -0,0 +1,179 @@
+package psbt
+
+// The Combiner is the role described in BIP174 which merges several PSBTs of
+// the same transaction, such as the PSBTs which were signed by each of the
+// signers of a multisig input, into one PSBT which carries all of their data.
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Combine merges the key-value pairs of PSBTs which have the same unsigned
+// transaction into a new PSBT.  Partial signatures, BIP32 derivations and
+// unknown pairs are merged by their key, while for the other fields the value
+// of the first packet which has one is used.  The passed packets are not
+// modified, and the combined packet has its own copies of their transactions,
+// signatures and derivations.  If the unsigned transactions of the packets differ then
+// ErrDifferentTransactions is returned.
+func Combine(packets []*Packet) (*Packet, er.R) {
+	if len(packets) == 0 {
+		return nil, er.Errorf("no PSBT to combine")
+	}
+	for _, p := range packets {
+		if err := VerifyInputOutputLen(p, false, false); err != nil {
+			return nil, err
+		}
+	}
+
+	txHash := packets[0].UnsignedTx.TxHash()
+	for _, p := range packets[1:] {
+		if p.UnsignedTx.TxHash() != txHash {
+			return nil, ErrDifferentTransactions.Default()
+		}
+	}
+
+	combined := &Packet{
+		UnsignedTx: packets[0].UnsignedTx.Copy(),
+		Inputs:     make([]PInput, len(packets[0].Inputs)),
+		Outputs:    make([]POutput, len(packets[0].Outputs)),
+	}
+	for _, p := range packets {
+		combined.Unknowns = combineUnknowns(combined.Unknowns, p.Unknowns)
+		for i := range p.Inputs {
+			combineInput(&combined.Inputs[i], &p.Inputs[i])
+		}
+		for i := range p.Outputs {
+			combineOutput(&combined.Outputs[i], &p.Outputs[i])
+		}
+	}
+
+	if err := combined.SanityCheck(); err != nil {
+		return nil, err
+	}
+	return combined, nil
+}
+
+// combineInput adds the key-value pairs of the input src which dst does not
+// have yet to dst.
+func combineInput(dst, src *PInput) {
+	if dst.NonWitnessUtxo == nil && src.NonWitnessUtxo != nil {
+		dst.NonWitnessUtxo = src.NonWitnessUtxo.Copy()
+	}
+	if dst.WitnessUtxo == nil && src.WitnessUtxo != nil {
+		dst.WitnessUtxo = wire.NewTxOut(
+			src.WitnessUtxo.Value, copyBytes(src.WitnessUtxo.PkScript),
+		)
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	if dst.FinalScriptSig == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+	}
+	if dst.FinalScriptWitness == nil {
+		dst.FinalScriptWitness = src.FinalScriptWitness
+	}
+
+	for _, sig := range src.PartialSigs {
+		found := false
+		for _, x := range dst.PartialSigs {
+			if bytes.Equal(x.PubKey, sig.PubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.PartialSigs = append(dst.PartialSigs, &PartialSig{
+				PubKey:    copyBytes(sig.PubKey),
+				Signature: copyBytes(sig.Signature),
+			})
+		}
+	}
+	dst.Bip32Derivation = combineBip32Derivations(
+		dst.Bip32Derivation, src.Bip32Derivation,
+	)
+
+	for _, u := range src.Unknowns {
+		found := false
+		for _, x := range dst.Unknowns {
+			if bytes.Equal(x.Key, u.Key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.Unknowns = append(dst.Unknowns, u)
+		}
+	}
+}
+
+// combineOutput adds the key-value pairs of the output src which dst does not
+// have yet to dst.
+func combineOutput(dst, src *POutput) {
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	dst.Bip32Derivation = combineBip32Derivations(
+		dst.Bip32Derivation, src.Bip32Derivation,
+	)
+}
+
+// combineBip32Derivations returns the derivations of dst followed by the
+// derivations of src for the public keys which dst has no derivation of.
+func combineBip32Derivations(dst, src []*Bip32Derivation) []*Bip32Derivation {
+	for _, d := range src {
+		found := false
+		for _, x := range dst {
+			if bytes.Equal(x.PubKey, d.PubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, &Bip32Derivation{
+				PubKey:               copyBytes(d.PubKey),
+				MasterKeyFingerprint: d.MasterKeyFingerprint,
+				Bip32Path:            append([]uint32(nil), d.Bip32Path...),
+			})
+		}
+	}
+	return dst
+}
+
+// combineUnknowns returns the unknown pairs of dst followed by the unknown
+// pairs of src whose keys dst does not have.
+func combineUnknowns(dst, src []Unknown) []Unknown {
+	for _, u := range src {
+		found := false
+		for _, x := range dst {
+			if bytes.Equal(x.Key, u.Key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, u)
+		}
+	}
+	return dst
+}
+
+// copyBytes returns a copy of b, or nil if b is nil.
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,73 @@
+package psbt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil/util"
+)
+
+func TestCombine(t *testing.T) {
+	parse := func() *Packet {
+		p, err := NewFromRawBytes(
+			bytes.NewReader([]byte(finalizerPsbtData["finalizeb64"])),
+			true,
+		)
+		if err != nil {
+			t.Fatalf("Failed to parse PSBT: %v", err)
+		}
+		return p
+	}
+
+	// Split the signatures of both 2-of-2 inputs between two PSBTs, as
+	// they would be returned by two signers.
+	psbt1, psbt2 := parse(), parse()
+	for i := range psbt1.Inputs {
+		if len(psbt1.Inputs[i].PartialSigs) != 2 {
+			t.Fatalf("Expected 2 signatures on input %d", i)
+		}
+		psbt1.Inputs[i].PartialSigs = psbt1.Inputs[i].PartialSigs[:1]
+		psbt2.Inputs[i].PartialSigs = psbt2.Inputs[i].PartialSigs[1:]
+	}
+	if err := MaybeFinalizeAll(psbt1); err == nil {
+		t.Fatalf("Finalized a PSBT with missing signatures")
+	}
+
+	combined, err := Combine([]*Packet{psbt1, psbt2})
+	if err != nil {
+		t.Fatalf("Unable to combine PSBTs: %v", err)
+	}
+	if len(psbt1.Inputs[0].PartialSigs) != 1 {
+		t.Fatalf("Combine modified the passed PSBT")
+	}
+	for i, in := range combined.Inputs {
+		orig := &psbt1.Inputs[i]
+		if (in.NonWitnessUtxo != nil && in.NonWitnessUtxo == orig.NonWitnessUtxo) ||
+			(in.WitnessUtxo != nil && in.WitnessUtxo == orig.WitnessUtxo) ||
+			in.PartialSigs[0] == orig.PartialSigs[0] {
+
+			t.Fatalf("Combined input %d shares data with the passed PSBT", i)
+		}
+	}
+	if err := MaybeFinalizeAll(combined); err != nil {
+		t.Fatalf("Unable to finalize combined PSBT: %v", err)
+	}
+	var b bytes.Buffer
+	if err := combined.Serialize(&b); err != nil {
+		t.Fatalf("Unable to serialize combined PSBT: %v", err)
+	}
+	expected, err := util.DecodeHex(finalizerPsbtData["result"])
+	if err != nil {
+		t.Fatalf("Unable to decode hex: %v", err)
+	}
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Fatalf("Unexpected combined and finalized PSBT %x", b.Bytes())
+	}
+
+	// PSBTs of different transactions cannot be combined.
+	psbt2.UnsignedTx.LockTime++
+	_, err = Combine([]*Packet{psbt1, psbt2})
+	if !ErrDifferentTransactions.Is(err) {
+		t.Fatalf("Expected ErrDifferentTransactions, got %v", err)
+	}
+}
@@ -105,6 +105,11 @@ var (
 	// scriptwitness given is not supported by this codebase, or is otherwise
 	// not valid.
 	ErrUnsupportedScriptType = PsbtError.CodeWithDetail("ErrUnsupportedScriptType", "Unsupported script type")
+
+	// ErrDifferentTransactions indicates that the PSBTs passed to the
+	// Combiner do not all have the same unsigned transaction.
+	ErrDifferentTransactions = PsbtError.CodeWithDetail("ErrDifferentTransactions", "Cannot combine PSBTs "+
+		"of different transactions")
 )
 
 // Unknown is a struct encapsulating a key-value pair for which the key type is
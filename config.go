@@ -19,14 +19,17 @@ import (
 	"time"
 
 	flags "github.com/jessevdk/go-flags"
+	"github.com/pkt-cash/pktd/annpool"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/connmgr"
 	"github.com/pkt-cash/pktd/database"
 	_ "github.com/pkt-cash/pktd/database/ffldb"
+	"github.com/pkt-cash/pktd/lnd/tor"
 	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/peer"
@@ -46,6 +49,7 @@ const (
 	defaultMaxRPCClients         = 10
 	defaultMaxRPCWebsockets      = 25
 	defaultMaxRPCConcurrentReqs  = 20
+	defaultMaxStratumClients     = 100
 	defaultDbType                = "ffldb"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultTrickleInterval       = peer.DefaultTrickleInterval
@@ -63,6 +67,7 @@ const (
 	defaultSigCacheMaxSize       = 100000
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
+	miningPayoutsFilename        = "miningpayouts.json"
 )
 
 var (
@@ -119,11 +124,31 @@ type config struct {
 	RPCMaxWebsockets     int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
+	REST                 bool          `long:"rest" description:"Enable the unauthenticated, read-only REST interface on the RPC listeners"`
+	ZMQPubRawBlock       string        `long:"zmqpubrawblock" description:"Publish raw blocks on this ZMQ address (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubHashBlock      string        `long:"zmqpubhashblock" description:"Publish block hashes on this ZMQ address"`
+	ZMQPubRawTx          string        `long:"zmqpubrawtx" description:"Publish raw transactions on this ZMQ address (eg. tcp://127.0.0.1:28333)"`
+	ZMQPubHashTx         string        `long:"zmqpubhashtx" description:"Publish transaction hashes on this ZMQ address"`
+	ZMQPubSequence       string        `long:"zmqpubsequence" description:"Publish block connections and disconnections and memory pool additions on this ZMQ address"`
+	MetricsListen        string        `long:"metricslisten" description:"Serve Prometheus metrics on /metrics of this address (eg. 127.0.0.1:8989)"`
+	PoolListen           string        `long:"poollisten" description:"Serve PacketCrypt pool work, announcement uploads and block shares on this address (eg. 127.0.0.1:8990)"`
+	PoolAnnTarget        uint32        `long:"poolanntarget" base:"16" description:"Highest work target of the announcements accepted by the pool server, in hex"`
+	PoolShareTarget      uint32        `long:"poolsharetarget" base:"16" description:"Work target of the block shares accepted by the pool server, in hex"`
+	StratumListen        string        `long:"stratumlisten" description:"Serve block template work to miners with the stratum protocol on this address, requires the RPC server (eg. 127.0.0.1:8991)"`
+	StratumShareTarget   uint32        `long:"stratumsharetarget" base:"16" description:"Easiest work target of the shares accepted by the stratum server, in hex, miners may ask for a harder one"`
+	StratumMaxClients    int           `long:"stratummaxclients" description:"Max number of stratum connections"`
+	StratumPass          string        `long:"stratumpass" default-mask:"-" description:"Password which miners must authorize with on the stratum server, any password is accepted when it is not set"`
 	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
 	DisableTLS           bool          `long:"notls" description:"Nolonger used, see --tls" hidden:"true"`
 	EnableTLS            bool          `long:"tls" description:"Enable TLS for the RPC server -- default is disabled unless bound to non-localhost"`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
+	Proxy                string        `long:"proxy" description:"Connect to peers via SOCKS5 proxy (eg. 127.0.0.1:9050) -- DNS lookups are also made through the proxy, which must be Tor, unless --noonion or --onion is specified"`
+	OnionProxy           string        `long:"onion" description:"Connect to Tor hidden services via this SOCKS5 proxy (eg. 127.0.0.1:9050) -- defaults to --proxy"`
+	NoOnion              bool          `long:"noonion" description:"Disable connecting to Tor hidden services"`
+	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by using a separate circuit for each proxied connection"`
+	TorControl           string        `long:"torcontrol" description:"Create a v3 Tor hidden service for incoming connections using this Tor control port (eg. 127.0.0.1:9051)"`
+	TorPassword          string        `long:"torpassword" default-mask:"-" description:"Password for the Tor control port, if it does not use cookie authentication"`
 	TestNet3             bool          `long:"testnet" description:"Use the test network"`
 	PktTest              bool          `long:"pkttest" description:"Use the pkt.cash test network"`
 	BtcMainNet           bool          `long:"btc" description:"Use the bitcoin main network"`
@@ -143,9 +168,10 @@ type config struct {
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	TrickleInterval      time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	NoPersistMempool     bool          `long:"nopersistmempool" description:"Do not save the mempool on shutdown and load it on startup"`
 	Generate             bool          `long:"generate" hidden:"true" description:"Generate (mine) bitcoins using the CPU - doesn't work for PacketCrypt"`
 	Coinbase             string        `long:"coinbase" description:"Include this message in generated coinbase"`
-	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set -- Ignored once the payouts are set with configureminingpayouts"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
@@ -157,18 +183,22 @@ type config struct {
 	DropCfIndex          bool          `long:"dropcfindex" description:"Deletes the index used for committed filtering (CF) support from the database on start up and then exits."`
 	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
+	RelayAnns            bool          `long:"relayanns" description:"Relay PacketCrypt announcements with the peers which support it and keep them in an announcement pool"`
+	MaxAnnPool           int           `long:"maxannpool" description:"Max number of PacketCrypt announcements to keep in the announcement pool"`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	Prune                uint32        `long:"prune" description:"Reduce storage requirements by deleting the data of blocks which are more than this many blocks below the best block (0 = disabled, otherwise at least 288).  Incompatible with --txindex and --addrindex."`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
 	MiningSkipChecks     string        `long:"miningskipchecks" description:"Either 'txns', 'template' or 'both', skips certain time-consuming checks during mining process, be careful as you might create invalid block templates!"`
 	lookup               func(string) ([]net.IP, er.R)
-	dial                 func(string, string, time.Duration) (net.Conn, er.R)
+	dial                 func(net.Addr, time.Duration) (net.Conn, er.R)
+	oniondial            func(net.Addr, time.Duration) (net.Conn, er.R)
 	addCheckpoints       []chaincfg.Checkpoint
-	miningAddrs          map[btcutil.Address]float64
+	miningPayouts        *mining.PayoutSchedule
 	minRelayTxFee        btcutil.Amount
 	whitelists           []*net.IPNet
 }
@@ -364,10 +394,15 @@ func loadConfig() (*config, []string, er.R) {
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxAnnPool:           annpool.DefaultMaxAnns,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
+		PoolAnnTarget:        defaultPoolTarget,
+		PoolShareTarget:      defaultPoolTarget,
+		StratumShareTarget:   defaultPoolTarget,
+		StratumMaxClients:    defaultMaxStratumClients,
 	}
 
 	// Service options which are only added on Windows.
@@ -699,6 +734,11 @@ func loadConfig() (*config, []string, er.R) {
 
 	if cfg.DisableRPC {
 		log.Infof("RPC service is disabled")
+		if cfg.REST {
+			log.Warnf("The REST interface is served on the RPC " +
+				"listeners and is disabled along with the RPC " +
+				"service")
+		}
 	}
 
 	// Default RPC to listen on localhost only.
@@ -765,6 +805,16 @@ func loadConfig() (*config, []string, er.R) {
 		return nil, nil, err
 	}
 
+	// The announcement pool cannot have a negative size.
+	if cfg.MaxAnnPool < 0 {
+		str := "%s: The maxannpool option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := er.Errorf(str, funcName, cfg.MaxAnnPool)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max orphan count to a sane vlue.
 	if cfg.MaxOrphanTxs < 0 {
 		str := "%s: The maxorphantx option may not be less than 0 " +
@@ -841,8 +891,39 @@ func loadConfig() (*config, []string, er.R) {
 		return nil, nil, err
 	}
 
+	// --prune must keep enough blocks to handle reorganizations.
+	if cfg.Prune != 0 && cfg.Prune < blockchain.MinPruneDepth {
+		err := er.Errorf("%s: the --prune option must be at least %d "+
+			"blocks", funcName, blockchain.MinPruneDepth)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --prune and --txindex do not mix.
+	if cfg.Prune != 0 && cfg.TxIndex {
+		err := er.Errorf("%s: the --prune and --txindex options may "+
+			"not be activated at the same time because the "+
+			"transaction index requires the full blocks",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --prune and --addrindex do not mix.
+	if cfg.Prune != 0 && cfg.AddrIndex {
+		err := er.Errorf("%s: the --prune and --addrindex options may "+
+			"not be activated at the same time because the "+
+			"address index requires the full blocks",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
-	cfg.miningAddrs = make(map[btcutil.Address]float64)
+	miningAddrs := make(map[btcutil.Address]float64)
 	for _, strAddr := range cfg.MiningAddrs {
 		addr, err := btcutil.DecodeAddress(strAddr, activeNetParams.Params)
 		if err != nil {
@@ -859,12 +940,34 @@ func loadConfig() (*config, []string, er.R) {
 			fmt.Fprintln(os.Stderr, usageMessage)
 			return nil, nil, err
 		}
-		cfg.miningAddrs[addr] = float64(1)
+		miningAddrs[addr] = float64(1)
+	}
+
+	// The payouts which were set with configureminingpayouts are stored in
+	// the data directory and take precedence over the mining addresses.
+	cfg.miningPayouts = mining.NewPayoutSchedule(
+		filepath.Join(cfg.DataDir, miningPayoutsFilename),
+		activeNetParams.Params, cfg.minRelayTxFee)
+	if ok, err := cfg.miningPayouts.Load(); err != nil {
+		err := er.Errorf("%s: %v", funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	} else if ok {
+		log.Infof("Using the mining payouts stored in [%s]",
+			filepath.Join(cfg.DataDir, miningPayoutsFilename))
+	} else if len(miningAddrs) > 0 {
+		if err := cfg.miningPayouts.Seed(miningAddrs); err != nil {
+			str := "%s: the mining addresses cannot be paid: %v"
+			err := er.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
 	}
 
 	// Ensure there is at least one mining address when the generate flag is
 	// set.
-	if cfg.Generate && len(cfg.MiningAddrs) == 0 {
+	if cfg.Generate && !cfg.miningPayouts.HasPayouts() {
 		str := "%s: the generate flag is set, but there are no mining " +
 			"addresses specified "
 		err := er.Errorf(str, funcName)
@@ -873,6 +976,35 @@ func loadConfig() (*config, []string, er.R) {
 		return nil, nil, err
 	}
 
+	// Ensure there is at least one mining address for the blocks of the
+	// pool server.
+	if cfg.PoolListen != "" && !cfg.miningPayouts.HasPayouts() {
+		str := "%s: the poollisten option is set, but there are no " +
+			"mining addresses specified"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The stratum server serves the block templates of the RPC server and
+	// its blocks need mining addresses too.
+	if cfg.StratumListen != "" && cfg.DisableRPC {
+		str := "%s: the stratumlisten option requires the RPC server"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.StratumListen != "" && !cfg.miningPayouts.HasPayouts() {
+		str := "%s: the stratumlisten option is set, but there are no " +
+			"mining addresses specified"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Add default port to all listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
@@ -890,6 +1022,61 @@ func loadConfig() (*config, []string, er.R) {
 	cfg.ConnectPeers = normalizeAddresses(cfg.ConnectPeers,
 		activeNetParams.DefaultPort)
 
+	// The proxy addresses and Tor control port must be in the form of
+	// host:port.
+	for _, proxyAddr := range []struct {
+		option string
+		addr   string
+	}{
+		{"proxy", cfg.Proxy},
+		{"onion", cfg.OnionProxy},
+		{"torcontrol", cfg.TorControl},
+	} {
+		if proxyAddr.addr == "" {
+			continue
+		}
+		if _, _, errr := net.SplitHostPort(proxyAddr.addr); errr != nil {
+			str := "%s: --%s address is not in the form of " +
+				"host:port [%s]: %v"
+			err := er.Errorf(str, funcName, proxyAddr.option,
+				proxyAddr.addr, errr)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// --onion and --noonion do not mix.
+	if cfg.OnionProxy != "" && cfg.NoOnion {
+		str := "%s: the --onion and --noonion options may not be " +
+			"activated at the same time"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Tor stream isolation requires either proxy or onion proxy to be set.
+	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
+		str := "%s: Tor stream isolation requires either --proxy or " +
+			"--onion to be set"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// A hidden service can only be created when listening for incoming
+	// connections.
+	if cfg.TorControl != "" && cfg.DisableListen {
+		str := "%s: the --torcontrol option requires listening for " +
+			"incoming connections"
+		err := er.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Check the checkpoints for syntax errors.
 	var err er.R
 	cfg.addCheckpoints, err = parseCheckpoints(cfg.AddCheckpoints)
@@ -903,15 +1090,46 @@ func loadConfig() (*config, []string, er.R) {
 
 	// Setup dial and DNS resolution (lookup) functions depending on the
 	// specified options.  The default is to use the standard
-	// net.DialTimeout function as well as the system DNS resolver.
-	cfg.dial = func(n string, addr string, to time.Duration) (net.Conn, er.R) {
-		ret, errr := net.DialTimeout(n, addr, to)
+	// net.DialTimeout function as well as the system DNS resolver.  When a
+	// proxy is specified, connections are made through it and DNS lookups
+	// are made through Tor, unless --noonion is set or there is a separate
+	// proxy for hidden services in which case the proxy may not be Tor.
+	cfg.dial = func(addr net.Addr, to time.Duration) (net.Conn, er.R) {
+		ret, errr := net.DialTimeout(addr.Network(), addr.String(), to)
 		return ret, er.E(errr)
 	}
 	cfg.lookup = func(host string) ([]net.IP, er.R) {
 		out, errr := net.LookupIP(host)
 		return out, er.E(errr)
 	}
+	if cfg.Proxy != "" {
+		proxy := cfg.Proxy
+		cfg.dial = func(addr net.Addr, to time.Duration) (net.Conn, er.R) {
+			return tor.DialNetAddr(addr, proxy, cfg.TorIsolation, to)
+		}
+		if !cfg.NoOnion && cfg.OnionProxy == "" {
+			cfg.lookup = func(host string) ([]net.IP, er.R) {
+				return connmgr.TorLookupIP(host, proxy)
+			}
+		}
+	}
+
+	// Setup the dial function for Tor hidden services.  It uses the onion
+	// proxy if one is specified and otherwise falls back to the regular
+	// proxy.
+	cfg.oniondial = cfg.dial
+	if cfg.OnionProxy != "" {
+		onionProxy := cfg.OnionProxy
+		cfg.oniondial = func(addr net.Addr, to time.Duration) (net.Conn, er.R) {
+			return tor.DialNetAddr(addr, onionProxy, cfg.TorIsolation, to)
+		}
+	}
+	if cfg.NoOnion || (cfg.Proxy == "" && cfg.OnionProxy == "") {
+		cfg.oniondial = func(addr net.Addr, to time.Duration) (net.Conn, er.R) {
+			return nil, er.Errorf("unable to connect to %s: Tor has "+
+				"been disabled", addr)
+		}
+	}
 
 	// Warn about missing config file only after all other configuration is
 	// done.  This prevents the warning on help messages and invalid
@@ -926,7 +1144,10 @@ func loadConfig() (*config, []string, er.R) {
 // pktdDial connects to the address on the named network using the appropriate
 // dial function depending on the address and configuration options.
 func pktdDial(addr net.Addr) (net.Conn, er.R) {
-	return cfg.dial(addr.Network(), addr.String(), defaultConnectTimeout)
+	if _, ok := addr.(*tor.OnionAddr); ok {
+		return cfg.oniondial(addr, defaultConnectTimeout)
+	}
+	return cfg.dial(addr, defaultConnectTimeout)
 }
 
 // pktdLookup resolves the IP of the given host using the correct DNS lookup
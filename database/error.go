@@ -102,6 +102,10 @@ var (
 	// ErrBlockNotFound instead.
 	ErrBlockRegionInvalid = Err.Code("ErrBlockRegionInvalid")
 
+	// ErrBlockPruned indicates the data for a block with the provided hash
+	// is no longer available because it has been pruned.
+	ErrBlockPruned = Err.Code("ErrBlockPruned")
+
 	// ***********************************
 	// Support for driver-specific errors.
 	// ***********************************
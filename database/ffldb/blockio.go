@@ -13,8 +13,11 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/pkt-cash/pktd/btcutil/er"
@@ -166,6 +169,11 @@ type blockStore struct {
 	// new blocks are written to.
 	writeCursor *writeCursor
 
+	// firstFileNum is the number of the oldest block file which has not
+	// been pruned.  All block files before it have been deleted.  It is
+	// protected by obfMutex.
+	firstFileNum uint32
+
 	// These functions are set to openFile, openWriteFile, and deleteFile by
 	// default, but are exposed here to allow the whitebox tests to replace
 	// them when working with mock files.
@@ -306,6 +314,62 @@ func (s *blockStore) deleteFile(fileNum uint32) er.R {
 	return nil
 }
 
+// prunedFileErr returns the error used when data is requested from the passed
+// flat file number after it has been deleted by pruneFiles.
+func prunedFileErr(fileNum uint32) er.R {
+	str := fmt.Sprintf("block file %d has been pruned", fileNum)
+	return makeDbErr(database.ErrBlockPruned, str, nil)
+}
+
+// pruneFiles deletes all block files which come before the passed flat file
+// number, closing them first if needed.  The current write file is never
+// deleted.
+func (s *blockStore) pruneFiles(fileNum uint32) er.R {
+	// Never delete the file which is currently being written to.
+	wc := s.writeCursor
+	wc.RLock()
+	if fileNum > wc.curFileNum {
+		fileNum = wc.curFileNum
+	}
+	wc.RUnlock()
+
+	s.obfMutex.Lock()
+	defer s.obfMutex.Unlock()
+
+	// Nothing to do if the files have already been pruned.
+	oldFirstFileNum := s.firstFileNum
+	if fileNum <= oldFirstFileNum {
+		return nil
+	}
+
+	// Move the first file forward before deleting anything so that any
+	// further reads of the old files fail as pruned instead of with an
+	// unexpected I/O error.
+	s.firstFileNum = fileNum
+	for num := oldFirstFileNum; num < fileNum; num++ {
+		// Close the file under its write lock in case any readers are
+		// still reading from it.
+		if obf, ok := s.openBlockFiles[num]; ok {
+			s.lruMutex.Lock()
+			s.openBlocksLRU.Remove(s.fileNumToLRUElem[num])
+			delete(s.fileNumToLRUElem, num)
+			s.lruMutex.Unlock()
+
+			obf.Lock()
+			_ = obf.file.Close()
+			obf.Unlock()
+			delete(s.openBlockFiles, num)
+		}
+
+		if err := s.deleteFileFunc(num); err != nil {
+			return err
+		}
+		log.Debugf("Pruned block file %d", num)
+	}
+
+	return nil
+}
+
 // blockFile attempts to return an existing file handle for the passed flat file
 // number if it is already open as well as marking it as most recently used.  It
 // will also open the file when it's not already open subject to the rules
@@ -330,6 +394,10 @@ func (s *blockStore) blockFile(fileNum uint32) (*lockableFile, er.R) {
 
 	// Try to return an open file under the overall files read lock.
 	s.obfMutex.RLock()
+	if fileNum < s.firstFileNum {
+		s.obfMutex.RUnlock()
+		return nil, prunedFileErr(fileNum)
+	}
 	if obf, ok := s.openBlockFiles[fileNum]; ok {
 		s.lruMutex.Lock()
 		s.openBlocksLRU.MoveToFront(s.fileNumToLRUElem[fileNum])
@@ -337,14 +405,20 @@ func (s *blockStore) blockFile(fileNum uint32) (*lockableFile, er.R) {
 
 		obf.RLock()
 		s.obfMutex.RUnlock()
+		cacheResult(true, blockFileCacheHits, blockFileCacheMisses)
 		return obf, nil
 	}
 	s.obfMutex.RUnlock()
+	cacheResult(false, blockFileCacheHits, blockFileCacheMisses)
 
 	// Since the file isn't open already, need to check the open block files
 	// map again under write lock in case multiple readers got here and a
 	// separate one is already opening the file.
 	s.obfMutex.Lock()
+	if fileNum < s.firstFileNum {
+		s.obfMutex.Unlock()
+		return nil, prunedFileErr(fileNum)
+	}
 	if obf, ok := s.openBlockFiles[fileNum]; ok {
 		obf.RLock()
 		s.obfMutex.Unlock()
@@ -707,15 +781,47 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 	}
 }
 
+// scanFirstBlockFile searches the database directory for the oldest flat block
+// file.  Block files before it have been removed by pruning.  Zero is returned
+// when there are no block files.
+func scanFirstBlockFile(dbPath string) uint32 {
+	entries, err := ioutil.ReadDir(dbPath)
+	if err != nil {
+		return 0
+	}
+
+	first := -1
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || len(name) != 13 ||
+			!strings.HasSuffix(name, ".fdb") {
+
+			continue
+		}
+		fileNum, err := strconv.ParseUint(name[:9], 10, 32)
+		if err != nil {
+			continue
+		}
+		if first == -1 || int(fileNum) < first {
+			first = int(fileNum)
+		}
+	}
+	if first == -1 {
+		return 0
+	}
+	return uint32(first)
+}
+
 // scanBlockFiles searches the database directory for all flat block files to
 // find the end of the most recent file.  This position is considered the
 // current write cursor which is also stored in the metadata.  Thus, it is used
 // to detect unexpected shutdowns in the middle of writes so the block files
-// can be reconciled.
-func scanBlockFiles(dbPath string) (int, uint32) {
+// can be reconciled.  The search starts at the passed first file number since
+// older files may have been pruned.
+func scanBlockFiles(dbPath string, firstFile uint32) (int, uint32) {
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
+	for i := int(firstFile); ; i++ {
 		filePath := blockFilePath(dbPath, uint32(i))
 		st, err := os.Stat(filePath)
 		if err != nil {
@@ -737,10 +843,12 @@ func newBlockStore(basePath string, network protocol.BitcoinNet) *blockStore {
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoing of the block files on
 	// disk.
-	fileNum, fileOff := scanBlockFiles(basePath)
+	firstFileNum := scanFirstBlockFile(basePath)
+	fileNum, fileOff := scanBlockFiles(basePath, firstFileNum)
 	if fileNum == -1 {
 		fileNum = 0
 		fileOff = 0
+		firstFileNum = 0
 	}
 
 	store := &blockStore{
@@ -750,6 +858,7 @@ func newBlockStore(basePath string, network protocol.BitcoinNet) *blockStore {
 		openBlockFiles:   make(map[uint32]*lockableFile),
 		openBlocksLRU:    list.New(),
 		fileNumToLRUElem: make(map[uint32]*list.Element),
+		firstFileNum:     firstFileNum,
 
 		writeCursor: &writeCursor{
 			curFile:    &lockableFile{},
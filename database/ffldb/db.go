@@ -1621,6 +1621,66 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	return blockRegions, nil
 }
 
+// PruneBlocks deletes the stored data for blocks which were stored before the
+// block identified by the given hash.  Block data is stored in flat files, so
+// only whole files which precede the file containing the given block are
+// removed.  Entries in the block index are left in place so that attempts to
+// fetch the pruned blocks return ErrBlockPruned rather than ErrBlockNotFound.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrBlockNotFound if the requested block hash does not exist
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) PruneBlocks(hash *chainhash.Hash) er.R {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune blocks requires a writable database transaction"
+		return makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	// A block which is pending to be written on commit will end up in the
+	// current write file which is never pruned, so there is nothing to do.
+	if _, exists := tx.pendingBlocks[*hash]; exists {
+		return nil
+	}
+
+	// Lookup the location of the block in the files from the block index.
+	blockRow, err := tx.fetchBlockRow(hash)
+	if err != nil {
+		return err
+	}
+	location := deserializeBlockLoc(blockRow)
+
+	return tx.db.store.pruneFiles(location.blockFileNum)
+}
+
+// BeenPruned returns whether or not any block data has ever been deleted from
+// the database by PruneBlocks.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) BeenPruned() (bool, er.R) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return false, err
+	}
+
+	store := tx.db.store
+	store.obfMutex.RLock()
+	pruned := store.firstFileNum > 0
+	store.obfMutex.RUnlock()
+	return pruned, nil
+}
+
 // close marks the transaction closed then releases any pending data, the
 // underlying snapshot, the transaction read lock, and the write lock when the
 // transaction is writable.
@@ -290,13 +290,16 @@ type dbCacheSnapshot struct {
 func (snap *dbCacheSnapshot) Has(key []byte) bool {
 	// Check the cached entries first.
 	if snap.pendingRemove.Has(key) {
+		cacheResult(true, metadataCacheHits, metadataCacheMisses)
 		return false
 	}
 	if snap.pendingKeys.Has(key) {
+		cacheResult(true, metadataCacheHits, metadataCacheMisses)
 		return true
 	}
 
 	// Consult the database.
+	cacheResult(false, metadataCacheHits, metadataCacheMisses)
 	hasKey, _ := snap.dbSnapshot.Has(key, nil)
 	return hasKey
 }
@@ -306,13 +309,16 @@ func (snap *dbCacheSnapshot) Has(key []byte) bool {
 func (snap *dbCacheSnapshot) Get(key []byte) []byte {
 	// Check the cached entries first.
 	if snap.pendingRemove.Has(key) {
+		cacheResult(true, metadataCacheHits, metadataCacheMisses)
 		return nil
 	}
 	if value := snap.pendingKeys.Get(key); value != nil {
+		cacheResult(true, metadataCacheHits, metadataCacheMisses)
 		return value
 	}
 
 	// Consult the database.
+	cacheResult(false, metadataCacheHits, metadataCacheMisses)
 	value, err := snap.dbSnapshot.Get(key, nil)
 	if err != nil {
 		return nil
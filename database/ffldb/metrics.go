@@ -0,0 +1,35 @@
+package ffldb
+
+import (
+	"github.com/pkt-cash/pktd/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheLookups counts the lookups in the database caches.
+var cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pktd_ffldb_cache_lookups_total",
+	Help: "Number of lookups in the ffldb metadata cache and open block " +
+		"files cache, the hit rate is hits divided by all lookups.",
+}, []string{"cache", "result"})
+
+// The counters of each cache are resolved once since they are on the hot path
+// of every read.
+var (
+	metadataCacheHits    = cacheLookups.WithLabelValues("metadata", "hit")
+	metadataCacheMisses  = cacheLookups.WithLabelValues("metadata", "miss")
+	blockFileCacheHits   = cacheLookups.WithLabelValues("blockfiles", "hit")
+	blockFileCacheMisses = cacheLookups.WithLabelValues("blockfiles", "miss")
+)
+
+// cacheResult increments the hit or miss counter of a cache.
+func cacheResult(hit bool, hits, misses prometheus.Counter) {
+	if hit {
+		hits.Inc()
+	} else {
+		misses.Inc()
+	}
+}
+
+func init() {
+	metrics.Register(cacheLookups)
+}
@@ -689,3 +689,126 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestPruneBlocks ensures pruning deletes the expected block files, that the
+// pruned blocks report as pruned, and that the pruned state survives reopening
+// the database.
+func TestPruneBlocks(t *testing.T) {
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-pruneblocks")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+
+	// Change the maximum file size to a small value to force multiple flat
+	// files with the test data set.
+	store := idb.(*db).store
+	store.maxBlockFileSize = 1024 // 1KiB
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		idb.Close()
+		t.Fatalf("loadBlocks: unexpected error: %v", err)
+	}
+	err = idb.Update(func(tx database.Tx) er.R {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		idb.Close()
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// Ensure the database does not claim to be pruned yet.
+	err = idb.View(func(tx database.Tx) er.R {
+		pruned, err := tx.BeenPruned()
+		if err != nil {
+			return err
+		}
+		if pruned {
+			return er.New("database claims to be pruned before " +
+				"pruning")
+		}
+		return nil
+	})
+	if err != nil {
+		idb.Close()
+		t.Fatalf("BeenPruned: %v", err)
+	}
+
+	// Ensure pruning requires a writable transaction.
+	keepIdx := len(blocks) / 2
+	keepHash := blocks[keepIdx].Hash()
+	err = idb.View(func(tx database.Tx) er.R {
+		return tx.PruneBlocks(keepHash)
+	})
+	if !util.CheckError(t, "PruneBlocks: read-only", err,
+		database.ErrTxNotWritable) {
+
+		idb.Close()
+		return
+	}
+
+	// Prune everything stored before the block to keep.
+	err = idb.Update(func(tx database.Tx) er.R {
+		return tx.PruneBlocks(keepHash)
+	})
+	if err != nil {
+		idb.Close()
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	idb.Close()
+
+	// checkPruned ensures the earliest blocks are pruned while the kept
+	// block and all blocks after it are still available.
+	checkPruned := func(idb database.DB) er.R {
+		return idb.View(func(tx database.Tx) er.R {
+			pruned, err := tx.BeenPruned()
+			if err != nil {
+				return err
+			}
+			if !pruned {
+				return er.New("database does not claim to be " +
+					"pruned")
+			}
+
+			_, err = tx.FetchBlock(blocks[0].Hash())
+			if !database.ErrBlockPruned.Is(err) {
+				return er.Errorf("unexpected error fetching "+
+					"pruned block - got %v, want %v", err,
+					database.ErrBlockPruned)
+			}
+			if exists, _ := tx.HasBlock(blocks[0].Hash()); !exists {
+				return er.New("pruned block is no longer known")
+			}
+
+			for _, block := range blocks[keepIdx:] {
+				if _, err := tx.FetchBlock(block.Hash()); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// Ensure the pruned state is correctly detected from the block files on
+	// disk after reopening the database.
+	idb, err = openDB(dbPath, blockDataNet, false)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer idb.Close()
+	if _, errr := os.Stat(blockFilePath(dbPath, 0)); !os.IsNotExist(errr) {
+		t.Fatalf("block file 0 was not deleted: %v", errr)
+	}
+	if err := checkPruned(idb); err != nil {
+		t.Fatalf("checkPruned: %v", err)
+	}
+}
@@ -307,6 +307,7 @@ type Tx interface {
 	// The interface contract guarantees at least the following errors will
 	// be returned (other implementation-specific errors are possible):
 	//   - ErrBlockNotFound if the requested block hash does not exist
+	//   - ErrBlockPruned if the block data has been pruned
 	//   - ErrTxClosed if the transaction has already been closed
 	//   - ErrCorruption if the database has somehow become corrupted
 	//
@@ -390,6 +391,31 @@ type Tx interface {
 	// implementations.
 	FetchBlockRegions(regions []BlockRegion) ([][]byte, er.R)
 
+	// PruneBlocks deletes the stored data for blocks which were stored
+	// before the block identified by the given hash.  Only the raw block
+	// data is removed, the blocks remain known to the database and any
+	// attempt to fetch them will return ErrBlockPruned.  Depending on the
+	// backend implementation, blocks stored shortly before the given block
+	// may be retained.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrBlockNotFound if the requested block hash does not exist
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// NOTE: Unlike other modifications, the block data is deleted
+	// immediately and is not restored if the transaction is rolled back.
+	PruneBlocks(hash *chainhash.Hash) er.R
+
+	// BeenPruned returns whether or not any block data has ever been
+	// deleted from the database by PruneBlocks.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	BeenPruned() (bool, er.R)
+
 	// ******************************************************************
 	// Methods related to both atomic metadata storage and block storage.
 	// ******************************************************************
@@ -78,6 +78,24 @@ func Dial(address, socksAddr string, streamIsolation bool,
 	}, nil
 }
 
+// DialNetAddr is like Dial, but the passed address is used as the remote
+// address of the connection as is rather than being parsed, and possibly
+// resolved through the proxy, again.  This allows it to be used with SOCKS5
+// proxies which do not support Tor's resolve extension.
+func DialNetAddr(addr net.Addr, socksAddr string, streamIsolation bool,
+	timeout time.Duration) (net.Conn, er.R) {
+
+	conn, err := dial(addr.String(), socksAddr, streamIsolation, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyConn{
+		Conn:       conn,
+		remoteAddr: addr,
+	}, nil
+}
+
 // dial establishes a connection to the address via Tor's SOCKS proxy. Only TCP
 // is supported over Tor. The argument streamIsolation determines if we should
 // force stream isolation for this new connection. If we do, then this means
@@ -107,6 +107,12 @@ type Config struct {
 	// FeeEstimatator provides a feeEstimator. If it is not nil, the mempool
 	// records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
+
+	// TxRemoved, if it is not nil, is called for each transaction which is
+	// removed from the mempool for any reason, such as being mined,
+	// conflicting with a mined transaction or being replaced.  It is called
+	// with the mempool lock held so it must not call back into the mempool.
+	TxRemoved func(tx *btcutil.Tx)
 }
 
 // Policy houses the policy (configuration parameters) which is used to
@@ -494,6 +500,10 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 		}
 		delete(mp.pool, *txHash)
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+
+		if mp.cfg.TxRemoved != nil {
+			mp.cfg.TxRemoved(txDesc.Tx)
+		}
 	}
 }
 
@@ -1477,6 +1487,142 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 	return result
 }
 
+// mempoolEntry returns the btcjson result describing the passed transaction
+// descriptor along with the totals for its in-pool ancestors and descendants.
+// The totals include the transaction itself.  The caches are passed through to
+// txAncestors and txDescendants.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntry(desc *TxDesc, bestHeight int32,
+	ancestorCache, descendantCache map[chainhash.Hash]map[chainhash.Hash]*btcutil.Tx) *btcjson.GetMempoolEntryResult {
+
+	// Calculate the current priority based on the inputs to the
+	// transaction.  Use zero if one or more of the input transactions
+	// can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			bestHeight+1)
+	}
+
+	vsize := GetTxVirtualSize(tx)
+	entry := &btcjson.GetMempoolEntryResult{
+		Size:             int32(tx.MsgTx().SerializeSize()),
+		Vsize:            int32(vsize),
+		Fee:              btcutil.Amount(desc.Fee).ToBTC(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		DescendantCount:  1,
+		DescendantSize:   vsize,
+		AncestorCount:    1,
+		AncestorSize:     vsize,
+		Depends:          make([]string, 0),
+		SpentBy:          make([]string, 0),
+	}
+
+	descendantFees := desc.Fee
+	for hash, descendant := range mp.txDescendants(tx, descendantCache) {
+		entry.DescendantCount++
+		entry.DescendantSize += GetTxVirtualSize(descendant)
+		descendantFees += mp.pool[hash].Fee
+	}
+	ancestorFees := desc.Fee
+	for hash, ancestor := range mp.txAncestors(tx, ancestorCache) {
+		entry.AncestorCount++
+		entry.AncestorSize += GetTxVirtualSize(ancestor)
+		ancestorFees += mp.pool[hash].Fee
+	}
+	entry.DescendantFees = btcutil.Amount(descendantFees).ToBTC()
+	entry.AncestorFees = btcutil.Amount(ancestorFees).ToBTC()
+
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.haveTransaction(hash) {
+			entry.Depends = append(entry.Depends, hash.String())
+		}
+	}
+	op := wire.OutPoint{Hash: *tx.Hash()}
+	for i := range tx.MsgTx().TxOut {
+		op.Index = uint32(i)
+		if spender, ok := mp.outpoints[op]; ok {
+			entry.SpentBy = append(entry.SpentBy,
+				spender.Hash().String())
+		}
+	}
+
+	return entry
+}
+
+// MempoolEntry returns the btcjson result describing the transaction with the
+// passed hash, including the count, size and fees of its in-pool ancestors and
+// descendants.  An error is returned if the transaction is not in the main
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolEntry(txHash *chainhash.Hash) (*btcjson.GetMempoolEntryResult, er.R) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, er.Errorf("transaction is not in the pool")
+	}
+	return mp.mempoolEntry(desc, mp.cfg.BestHeight(), nil, nil), nil
+}
+
+// MempoolAncestors returns the btcjson results describing every in-pool
+// ancestor of the transaction with the passed hash, keyed by transaction hash.
+// An error is returned if the transaction is not in the main pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolAncestors(txHash *chainhash.Hash) (map[string]*btcjson.GetMempoolEntryResult, er.R) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, er.Errorf("transaction is not in the pool")
+	}
+	return mp.mempoolEntries(mp.txAncestors(desc.Tx, nil)), nil
+}
+
+// MempoolDescendants returns the btcjson results describing every in-pool
+// descendant of the transaction with the passed hash, keyed by transaction
+// hash.  An error is returned if the transaction is not in the main pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolDescendants(txHash *chainhash.Hash) (map[string]*btcjson.GetMempoolEntryResult, er.R) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, er.Errorf("transaction is not in the pool")
+	}
+	return mp.mempoolEntries(mp.txDescendants(desc.Tx, nil)), nil
+}
+
+// mempoolEntries returns the btcjson results describing each of the passed
+// pool transactions, keyed by transaction hash.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntries(txns map[chainhash.Hash]*btcutil.Tx) map[string]*btcjson.GetMempoolEntryResult {
+	bestHeight := mp.cfg.BestHeight()
+	ancestorCache := make(map[chainhash.Hash]map[chainhash.Hash]*btcutil.Tx)
+	descendantCache := make(map[chainhash.Hash]map[chainhash.Hash]*btcutil.Tx)
+
+	result := make(map[string]*btcjson.GetMempoolEntryResult, len(txns))
+	for hash := range txns {
+		result[hash.String()] = mp.mempoolEntry(mp.pool[hash],
+			bestHeight, ancestorCache, descendantCache)
+	}
+	return result
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
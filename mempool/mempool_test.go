@@ -1801,3 +1801,150 @@ func TestRBF(t *testing.T) {
 		}
 	}
 }
+
+// TestMempoolEntry ensures that the ancestor and descendant totals reported for
+// a transaction, as well as the ancestor and descendant sets themselves, are
+// correct for a simple chain of transactions.
+func TestMempoolEntry(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	pool := harness.txPool
+
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	for _, tx := range chainedTxns {
+		_, err := pool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"transaction %v", err)
+		}
+	}
+
+	var totalSize int64
+	for _, tx := range chainedTxns {
+		totalSize += GetTxVirtualSize(tx)
+	}
+
+	// The middle transaction has one ancestor and one descendant.
+	middle := chainedTxns[1]
+	entry, err := pool.MempoolEntry(middle.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if entry.AncestorCount != 2 || entry.DescendantCount != 2 {
+		t.Fatalf("MempoolEntry: unexpected counts -- got %d ancestors "+
+			"and %d descendants, want 2 and 2", entry.AncestorCount,
+			entry.DescendantCount)
+	}
+	wantSize := totalSize - GetTxVirtualSize(chainedTxns[2])
+	if entry.AncestorSize != wantSize {
+		t.Fatalf("MempoolEntry: unexpected ancestor size -- got %d, "+
+			"want %d", entry.AncestorSize, wantSize)
+	}
+	wantSize = totalSize - GetTxVirtualSize(chainedTxns[0])
+	if entry.DescendantSize != wantSize {
+		t.Fatalf("MempoolEntry: unexpected descendant size -- got %d, "+
+			"want %d", entry.DescendantSize, wantSize)
+	}
+	if len(entry.Depends) != 1 ||
+		entry.Depends[0] != chainedTxns[0].Hash().String() {
+
+		t.Fatalf("MempoolEntry: unexpected depends %v", entry.Depends)
+	}
+	if len(entry.SpentBy) != 1 ||
+		entry.SpentBy[0] != chainedTxns[2].Hash().String() {
+
+		t.Fatalf("MempoolEntry: unexpected spentby %v", entry.SpentBy)
+	}
+
+	// The root transaction has every other transaction as a descendant and
+	// the leaf has every other transaction as an ancestor.
+	descendants, err := pool.MempoolDescendants(chainedTxns[0].Hash())
+	if err != nil {
+		t.Fatalf("MempoolDescendants: %v", err)
+	}
+	ancestors, err := pool.MempoolAncestors(chainedTxns[2].Hash())
+	if err != nil {
+		t.Fatalf("MempoolAncestors: %v", err)
+	}
+	for _, tx := range chainedTxns[1:] {
+		if _, ok := descendants[tx.Hash().String()]; !ok {
+			t.Fatalf("MempoolDescendants: missing %v", tx.Hash())
+		}
+	}
+	for _, tx := range chainedTxns[:2] {
+		if _, ok := ancestors[tx.Hash().String()]; !ok {
+			t.Fatalf("MempoolAncestors: missing %v", tx.Hash())
+		}
+	}
+	if len(descendants) != 2 || len(ancestors) != 2 {
+		t.Fatalf("unexpected number of relatives -- got %d "+
+			"descendants and %d ancestors, want 2 and 2",
+			len(descendants), len(ancestors))
+	}
+
+	// Transactions which are not in the pool are reported as such.
+	pool.RemoveTransaction(chainedTxns[0], true)
+	if _, err := pool.MempoolEntry(middle.Hash()); err == nil {
+		t.Fatalf("MempoolEntry: did not fail for removed transaction")
+	}
+}
+
+// TestTxRemoved ensures the removal callback is called for each transaction
+// which is removed from the pool, including the redeemers which are removed
+// along with it.
+func TestTxRemoved(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	pool := harness.txPool
+	var removed []*btcutil.Tx
+	pool.cfg.TxRemoved = func(tx *btcutil.Tx) {
+		removed = append(removed, tx)
+	}
+
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	for _, tx := range chainedTxns {
+		_, err := pool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"transaction %v", err)
+		}
+	}
+	if len(removed) != 0 {
+		t.Fatalf("%d transactions reported removed on acceptance",
+			len(removed))
+	}
+
+	// The redeemers are removed before the transactions they spend.
+	pool.RemoveTransaction(chainedTxns[0], true)
+	if len(removed) != len(chainedTxns) {
+		t.Fatalf("%d transactions reported removed, want %d",
+			len(removed), len(chainedTxns))
+	}
+	for i, tx := range removed {
+		want := chainedTxns[len(chainedTxns)-1-i]
+		if !tx.Hash().IsEqual(want.Hash()) {
+			t.Fatalf("removal %d is %v, want %v", i, tx.Hash(),
+				want.Hash())
+		}
+	}
+
+	// Removing a transaction which is not in the pool does nothing.
+	pool.RemoveTransaction(chainedTxns[0], true)
+	if len(removed) != len(chainedTxns) {
+		t.Fatalf("removal of a missing transaction was reported")
+	}
+}
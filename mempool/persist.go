@@ -0,0 +1,205 @@
+package mempool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// PersistFileName is the name of the file, within the data directory,
+	// which holds the contents of the memory pool between restarts.
+	PersistFileName = "mempool.dat"
+
+	// persistVersion is the version of the serialized memory pool format.
+	persistVersion = 1
+
+	// DefaultPersistExpiry is the default maximum age of a persisted
+	// transaction.  Transactions which arrived earlier than this are
+	// dropped rather than reloaded.
+	DefaultPersistExpiry = time.Hour * 24 * 14
+)
+
+// LoadStats describes the outcome of reloading a persisted memory pool.
+type LoadStats struct {
+	// Accepted is the number of transactions which were added to the pool.
+	Accepted int
+
+	// Expired is the number of transactions which were dropped because
+	// they were older than the expiry.
+	Expired int
+
+	// Failed is the number of transactions which were dropped because
+	// they are no longer valid, conflict with other transactions or are
+	// already in the pool.
+	Failed int
+}
+
+// Dump serializes every transaction in the pool, along with the time it was
+// added and its fee delta, to the passed writer.  Transactions are written
+// after any of their parents which are also in the pool so that they can be
+// loaded back in a single pass.
+//
+// The fee delta is reserved for manual transaction prioritization, which is
+// not currently supported, so it is always written as zero.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Dump(w io.Writer) er.R {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	ordered := make([]*TxDesc, 0, len(mp.pool))
+	visited := make(map[chainhash.Hash]struct{}, len(mp.pool))
+	var visit func(txD *TxDesc)
+	visit = func(txD *TxDesc) {
+		hash := *txD.Tx.Hash()
+		if _, ok := visited[hash]; ok {
+			return
+		}
+		visited[hash] = struct{}{}
+		for _, txIn := range txD.Tx.MsgTx().TxIn {
+			if parent, ok := mp.pool[txIn.PreviousOutPoint.Hash]; ok {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, txD)
+	}
+	for _, txD := range mp.pool {
+		visit(txD)
+	}
+
+	bw := bufio.NewWriter(w)
+	if errr := binary.Write(bw, binary.BigEndian, uint32(persistVersion)); errr != nil {
+		return er.E(errr)
+	}
+	if errr := binary.Write(bw, binary.BigEndian, uint32(len(ordered))); errr != nil {
+		return er.E(errr)
+	}
+	for _, txD := range ordered {
+		if err := txD.Tx.MsgTx().Serialize(bw); err != nil {
+			return err
+		}
+		if errr := binary.Write(bw, binary.BigEndian, txD.Added.Unix()); errr != nil {
+			return er.E(errr)
+		}
+		if errr := binary.Write(bw, binary.BigEndian, int64(0)); errr != nil {
+			return er.E(errr)
+		}
+	}
+	return er.E(bw.Flush())
+}
+
+// Load reads transactions which were serialized with Dump from the passed
+// reader and attempts to add each of them back to the pool.  Transactions which
+// arrived before the passed expiry, which are no longer valid against the
+// current chain or which conflict with transactions already in the pool are
+// dropped.  Accepted transactions keep the time they were originally added.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Load(r io.Reader, expiry time.Duration) (*LoadStats, er.R) {
+	br := bufio.NewReader(r)
+
+	var version, count uint32
+	if errr := binary.Read(br, binary.BigEndian, &version); errr != nil {
+		return nil, er.E(errr)
+	}
+	if version != persistVersion {
+		return nil, er.Errorf("unsupported mempool file version %d",
+			version)
+	}
+	if errr := binary.Read(br, binary.BigEndian, &count); errr != nil {
+		return nil, er.E(errr)
+	}
+
+	stats := &LoadStats{}
+	now := time.Now()
+	for i := uint32(0); i < count; i++ {
+		var msgTx wire.MsgTx
+		if err := msgTx.Deserialize(br); err != nil {
+			return stats, err
+		}
+		var added, feeDelta int64
+		if errr := binary.Read(br, binary.BigEndian, &added); errr != nil {
+			return stats, er.E(errr)
+		}
+		if errr := binary.Read(br, binary.BigEndian, &feeDelta); errr != nil {
+			return stats, er.E(errr)
+		}
+
+		addedTime := time.Unix(added, 0)
+		if now.Sub(addedTime) > expiry {
+			stats.Expired++
+			continue
+		}
+
+		// The transaction was already accepted once so it is treated the
+		// same way as one which is added back after a reorganize, and
+		// missing parents mean it can no longer be accepted.
+		tx := btcutil.NewTx(&msgTx)
+		missingParents, txD, err := mp.MaybeAcceptTransaction(tx, false, false)
+		if err != nil || len(missingParents) > 0 {
+			log.Debugf("Dropping persisted transaction %v: %v",
+				tx.Hash(), err)
+			stats.Failed++
+			continue
+		}
+
+		mp.mtx.Lock()
+		txD.Added = addedTime
+		mp.mtx.Unlock()
+		stats.Accepted++
+	}
+	return stats, nil
+}
+
+// DumpFile writes the contents of the pool to the named file.  The data is
+// written to a temporary file first and then moved into place so an existing
+// file is never left partially written.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) DumpFile(path string) er.R {
+	tmp, errr := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".new")
+	if errr != nil {
+		return er.E(errr)
+	}
+	if err := mp.Dump(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if errr := tmp.Close(); errr != nil {
+		os.Remove(tmp.Name())
+		return er.E(errr)
+	}
+	if errr := os.Rename(tmp.Name(), path); errr != nil {
+		os.Remove(tmp.Name())
+		return er.E(errr)
+	}
+	return nil
+}
+
+// LoadFile reloads the pool from the named file as described by Load.  A file
+// which does not exist is not an error and results in empty stats.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) LoadFile(path string, expiry time.Duration) (*LoadStats, er.R) {
+	f, errr := os.Open(path)
+	if os.IsNotExist(errr) {
+		return &LoadStats{}, nil
+	}
+	if errr != nil {
+		return nil, er.E(errr)
+	}
+	defer f.Close()
+	return mp.Load(f, expiry)
+}
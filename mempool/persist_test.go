@@ -0,0 +1,97 @@
+package mempool
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestPersistRoundTrip ensures that transactions dumped from the pool are
+// loaded back with their original arrival times, that expired transactions
+// are dropped and that transactions which conflict with the pool are dropped
+// along with their descendants.
+func TestPersistRoundTrip(t *testing.T) {
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+	pool := harness.txPool
+
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	for _, tx := range chainedTxns {
+		_, err := pool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"transaction %v", err)
+		}
+	}
+
+	// Give the transactions a distinct arrival time so it can be checked
+	// after they are reloaded.
+	added := time.Now().Add(-time.Hour).Truncate(time.Second)
+	pool.mtx.Lock()
+	for _, txD := range pool.pool {
+		txD.Added = added
+	}
+	pool.mtx.Unlock()
+
+	var buf bytes.Buffer
+	if err := pool.Dump(&buf); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	data := buf.Bytes()
+
+	reload := func(expiry time.Duration, want LoadStats) {
+		t.Helper()
+		pool.RemoveTransaction(chainedTxns[0], true)
+		stats, err := pool.Load(bytes.NewReader(data), expiry)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if *stats != want {
+			t.Fatalf("Load: unexpected stats -- got %+v, want %+v",
+				*stats, want)
+		}
+	}
+
+	// All transactions are reloaded with their arrival times intact.
+	reload(DefaultPersistExpiry, LoadStats{Accepted: 3})
+	for _, tx := range chainedTxns {
+		testPoolMembership(tc, tx, false, true)
+		pool.mtx.RLock()
+		got := pool.pool[*tx.Hash()].Added
+		pool.mtx.RUnlock()
+		if !got.Equal(added) {
+			t.Fatalf("Load: unexpected arrival time for %v -- got "+
+				"%v, want %v", tx.Hash(), got, added)
+		}
+	}
+
+	// Transactions older than the expiry are dropped.
+	reload(time.Minute, LoadStats{Expired: 3})
+	for _, tx := range chainedTxns {
+		testPoolMembership(tc, tx, false, false)
+	}
+
+	// A transaction which double spends the root of the chain causes the
+	// whole chain to be dropped.
+	conflict, err := harness.CreateSignedTx(spendableOuts[:1], 2, 1000, false)
+	if err != nil {
+		t.Fatalf("unable to create conflicting transaction: %v", err)
+	}
+	if _, err := pool.ProcessTransaction(conflict, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid "+
+			"transaction %v", err)
+	}
+	reload(DefaultPersistExpiry, LoadStats{Failed: 3})
+	for _, tx := range chainedTxns {
+		testPoolMembership(tc, tx, false, false)
+	}
+	testPoolMembership(tc, conflict, false, true)
+}
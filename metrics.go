@@ -0,0 +1,238 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/metrics"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// mempoolFeeRateBuckets are the upper bounds, in atoms per virtual byte, of
+// the buckets the memory pool transactions are counted in.
+var mempoolFeeRateBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// rpcDuration is the time taken to handle RPC requests by method.
+var rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "pktd_rpc_duration_seconds",
+	Help: "Time taken to handle RPC requests by method.",
+}, []string{"method"})
+
+func init() {
+	metrics.Register(rpcDuration)
+}
+
+// Descriptions of the metrics which are collected by the serverCollector.
+var (
+	mempoolFeeRateDesc = prometheus.NewDesc("pktd_mempool_feerate",
+		"Fee rate of the transactions in the memory pool in atoms per "+
+			"virtual byte.", nil, nil)
+	peersDesc = prometheus.NewDesc("pktd_peers",
+		"Number of connected peers by direction.",
+		[]string{"direction"}, nil)
+	peerBytesReceivedDesc = prometheus.NewDesc("pktd_peer_bytes_received_total",
+		"Number of bytes received from each connected peer.",
+		[]string{"id", "addr"}, nil)
+	peerBytesSentDesc = prometheus.NewDesc("pktd_peer_bytes_sent_total",
+		"Number of bytes sent to each connected peer.",
+		[]string{"id", "addr"}, nil)
+	peerBanScoreDesc = prometheus.NewDesc("pktd_peer_ban_score",
+		"Current ban score of each connected peer.",
+		[]string{"id", "addr"}, nil)
+)
+
+// serverCollector collects the metrics of the memory pool and the connected
+// peers which have labels that are only known when the metrics are scraped.
+type serverCollector struct {
+	s *server
+}
+
+// Describe sends the descriptions of the metrics to the passed channel.
+//
+// This is part of the prometheus.Collector interface.
+func (c serverCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mempoolFeeRateDesc
+	ch <- peersDesc
+	ch <- peerBytesReceivedDesc
+	ch <- peerBytesSentDesc
+	ch <- peerBanScoreDesc
+}
+
+// Collect sends the current values of the metrics to the passed channel.
+//
+// This is part of the prometheus.Collector interface.
+func (c serverCollector) Collect(ch chan<- prometheus.Metric) {
+	buckets := make(map[float64]uint64, len(mempoolFeeRateBuckets))
+	var count uint64
+	var sum float64
+	for _, desc := range c.s.txMemPool.TxDescs() {
+		feeRate := float64(desc.FeePerKB) / 1000
+		for _, bound := range mempoolFeeRateBuckets {
+			if feeRate <= bound {
+				buckets[bound]++
+			}
+		}
+		count++
+		sum += feeRate
+	}
+	ch <- prometheus.MustNewConstHistogram(mempoolFeeRateDesc, count, sum,
+		buckets)
+
+	var inbound, outbound float64
+	for _, sp := range c.s.connectedPeers() {
+		if sp.Inbound() {
+			inbound++
+		} else {
+			outbound++
+		}
+		id, addr := strconv.Itoa(int(sp.ID())), sp.Addr()
+		ch <- prometheus.MustNewConstMetric(peerBytesReceivedDesc,
+			prometheus.CounterValue, float64(sp.BytesReceived()), id, addr)
+		ch <- prometheus.MustNewConstMetric(peerBytesSentDesc,
+			prometheus.CounterValue, float64(sp.BytesSent()), id, addr)
+		ch <- prometheus.MustNewConstMetric(peerBanScoreDesc,
+			prometheus.GaugeValue, float64(sp.banScore.Int()), id, addr)
+	}
+	ch <- prometheus.MustNewConstMetric(peersDesc, prometheus.GaugeValue,
+		inbound, "inbound")
+	ch <- prometheus.MustNewConstMetric(peersDesc, prometheus.GaugeValue,
+		outbound, "outbound")
+}
+
+// connectedPeers returns the peers which are currently connected, or nil when
+// the server is shutting down.
+func (s *server) connectedPeers() []*serverPeer {
+	replyChan := make(chan []*serverPeer)
+	select {
+	case s.query <- getPeersMsg{reply: replyChan}:
+		return <-replyChan
+	case <-s.quit:
+		return nil
+	}
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// verificationProgress returns the height of the best chain relative to the
+// highest block announced by the connected peers, as reported by
+// getblockchaininfo.
+func (s *server) verificationProgress() float64 {
+	height := s.chain.BestSnapshot().Height
+	highestBlock := height
+	for _, sp := range s.connectedPeers() {
+		if lb := sp.LastBlock(); lb > highestBlock {
+			highestBlock = lb
+		}
+	}
+	if highestBlock == 0 {
+		return 1
+	}
+	return float64(height) / float64(highestBlock)
+}
+
+// newMetricsRegistry returns a registry of the metrics which are collected
+// from the server state when the metrics are scraped.
+func (s *server) newMetricsRegistry() *prometheus.Registry {
+	gauge := func(name, help string, value func() float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: name,
+			Help: help,
+		}, value)
+	}
+	counter := func(name, help string, value func() float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: name,
+			Help: help,
+		}, value)
+	}
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		// Chain.
+		gauge("pktd_chain_height", "Height of the best chain.",
+			func() float64 {
+				return float64(s.chain.BestSnapshot().Height)
+			}),
+		gauge("pktd_chain_median_time_seconds",
+			"Median time of the best chain.",
+			func() float64 {
+				return float64(s.chain.BestSnapshot().MedianTime.Unix())
+			}),
+		gauge("pktd_chain_verification_progress",
+			"Height of the best chain relative to the highest block "+
+				"announced by the peers.",
+			s.verificationProgress),
+		gauge("pktd_chain_initial_block_download",
+			"1 while the initial block download is in progress, 0 "+
+				"otherwise.",
+			func() float64 {
+				return boolValue(!s.chain.IsCurrent())
+			}),
+
+		// Memory pool.
+		gauge("pktd_mempool_txs",
+			"Number of transactions in the memory pool.",
+			func() float64 {
+				return float64(s.txMemPool.Count())
+			}),
+		gauge("pktd_mempool_bytes",
+			"Total virtual size of the transactions in the memory pool.",
+			func() float64 {
+				var size int64
+				for _, desc := range s.txMemPool.TxDescs() {
+					size += mempool.GetTxVirtualSize(desc.Tx)
+				}
+				return float64(size)
+			}),
+
+		// Network.
+		counter("pktd_net_bytes_received_total",
+			"Number of bytes received from all peers.",
+			func() float64 {
+				return float64(atomic.LoadUint64(&s.bytesReceived))
+			}),
+		counter("pktd_net_bytes_sent_total",
+			"Number of bytes sent to all peers.",
+			func() float64 {
+				return float64(atomic.LoadUint64(&s.bytesSent))
+			}),
+
+		serverCollector{s: s},
+	)
+	return r
+}
+
+// startMetricsServer starts serving the metrics on /metrics of the address
+// configured with --metricslisten.
+func (s *server) startMetricsServer() er.R {
+	listener, errr := net.Listen("tcp", cfg.MetricsListen)
+	if errr != nil {
+		return er.E(errr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler(metrics.Registry,
+		s.newMetricsRegistry()))
+	s.metricsServer = &http.Server{
+		Handler:     mux,
+		ReadTimeout: time.Second * rpcAuthTimeoutSeconds,
+	}
+	log.Infof("Metrics server listening on %s", listener.Addr())
+	go func() {
+		if errr := s.metricsServer.Serve(listener); errr != http.ErrServerClosed {
+			log.Errorf("Metrics server: %v", errr)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package metrics holds the Prometheus registry which the metrics declared by
+// packages are registered with.
+//
+// Packages declare their collectors as package level variables and register
+// them with the Registry, the metrics which are collected from the server state
+// are kept in a registry of their own and served along with it.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is the registry of the metrics declared by packages.  It is used
+// rather than the prometheus default registry so that only pktd metrics are
+// exported.
+var Registry = prometheus.NewRegistry()
+
+// Register adds the passed collectors to the Registry.  It panics when a
+// collector is invalid or already registered since this is a programming
+// error.
+func Register(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// Handler returns an HTTP handler which serves the metrics of the passed
+// gatherers in the Prometheus exposition format.
+func Handler(gatherers ...prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(prometheus.Gatherers(gatherers),
+		promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
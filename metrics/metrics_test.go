@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestHandler ensures the handler serves the metrics of all the gatherers.
+func TestHandler(t *testing.T) {
+	r1 := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "test_lookups_total",
+		Help: "Lookups.",
+	}, []string{"cache", "result"})
+	counter.WithLabelValues("blocks", "hit").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "test_duration_seconds",
+		Help:    "Durations.",
+		Buckets: []float64{0.1, 1},
+	})
+	histogram.Observe(0.05)
+	histogram.Observe(5)
+	r1.MustRegister(counter, histogram)
+
+	r2 := prometheus.NewRegistry()
+	r2.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "test_height",
+		Help: "Height.",
+	}, func() float64 { return 7 }))
+
+	rec := httptest.NewRecorder()
+	Handler(r1, r2).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE test_lookups_total counter\n",
+		`test_lookups_total{cache="blocks",result="hit"} 3`,
+		`test_duration_seconds_bucket{le="0.1"} 1`,
+		`test_duration_seconds_bucket{le="+Inf"} 2`,
+		"test_duration_seconds_count 2",
+		"# TYPE test_height gauge\n",
+		"test_height 7",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body does not contain %q:\n%s", want, body)
+		}
+	}
+}
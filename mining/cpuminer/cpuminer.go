@@ -7,16 +7,21 @@ package cpuminer
 
 import (
 	"fmt"
+	"math/big"
 	"math/rand"
 	"runtime"
 	"sync"
 	"time"
 
 	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/wire"
@@ -41,6 +46,13 @@ const (
 	// reduce the amount of syncs between the workers that must be done to
 	// keep track of the hashes per second.
 	hashUpdateSecs = 15
+
+	// ownAnnCount is the number of announcements the miner mines itself
+	// when no announcements are available to mine a PacketCrypt block.
+	ownAnnCount = 4096
+
+	// maxAnnTarget is the highest work target of an announcement.
+	maxAnnTarget = 0x207fffff
 )
 
 var (
@@ -60,9 +72,10 @@ type Config struct {
 	// generate block templates that the miner will attempt to solve.
 	BlockTemplateGenerator *mining.BlkTmplGenerator
 
-	// MiningAddrs is a map of payment addresses to percentages to use for
-	// the generated blocks. Each generated block will pay all of them.
-	MiningAddrs map[btcutil.Address]float64
+	// Payouts is the schedule of the payment addresses and percentages to
+	// use for the generated blocks. Each generated block will pay all of the
+	// addresses which the schedule has for its height.
+	Payouts *mining.PayoutSchedule
 
 	// ProcessBlock defines the function to call with any solved blocks.
 	// It typically must run the provided block through the same set of
@@ -84,6 +97,17 @@ type Config struct {
 	// not current since any solved blocks would be on a side chain and and
 	// up orphaned anyways.
 	IsCurrent func() bool
+
+	// Announcements defines the function to use to obtain the PacketCrypt
+	// announcements to mine the block at the passed height with, on chains
+	// which use PacketCrypt proof of work.  When it is nil or none of the
+	// announcements are usable, the miner mines its own announcements.
+	Announcements func(height int32) []blockminer.Announcement
+
+	// BlockHashByHeight defines the function to use to obtain the hash of
+	// the main chain block at the passed height.  The announcements which
+	// the miner mines itself commit to it.
+	BlockHashByHeight func(height int32) (*chainhash.Hash, er.R)
 }
 
 // CPUMiner provides facilities for solving blocks (mining) using the CPU in
@@ -107,6 +131,12 @@ type CPUMiner struct {
 	updateHashes      chan uint64
 	speedMonitorQuit  chan struct{}
 	quit              chan struct{}
+
+	// ownAnns are the announcements which the miner mined itself, they
+	// commit to the block ownAnnsParent.
+	ownAnnsLock   sync.Mutex
+	ownAnns       []blockminer.Announcement
+	ownAnnsParent chainhash.Hash
 }
 
 // speedMonitor handles tracking the number of hashes per second the mining
@@ -204,18 +234,136 @@ func (m *CPUMiner) submitBlock(block *btcutil.Block) bool {
 	return true
 }
 
+// ownAnnTarget returns the work target of the announcements which the miner
+// mines itself for a block with the passed target.  Below PacketCrypt version
+// 2, the work of the block is the cube of the header work divided by the work
+// of an announcement and by the announcement count, so the square root of the
+// cube is spent mining the announcements and as much mining the block.
+func ownAnnTarget(bits uint32, version int) uint32 {
+	if version >= 2 {
+		return maxAnnTarget
+	}
+	work := difficulty.WorkForTarget(difficulty.CompactToBig(bits))
+	work.Exp(work, big.NewInt(3), nil)
+	work.Sqrt(work)
+	work.Div(work, big.NewInt(ownAnnCount))
+	if work.Sign() == 0 {
+		return maxAnnTarget
+	}
+	target := difficulty.BigToCompact(difficulty.TargetForWork(work))
+	if target > maxAnnTarget {
+		return maxAnnTarget
+	}
+	return target
+}
+
+// ownAnnouncements returns announcements, mined by the miner itself, which
+// can be used to mine a block at the passed height with the passed PacketCrypt
+// version.  They are reused for the blocks at the same height.
+func (m *CPUMiner) ownAnnouncements(blockHeight int32, bits uint32,
+	version int, quit chan struct{}) ([]blockminer.Announcement, er.R) {
+
+	parentHeight := blockHeight - 3
+	if parentHeight < 0 {
+		parentHeight = 0
+	}
+	parentHash, err := m.cfg.BlockHashByHeight(parentHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ownAnnsLock.Lock()
+	defer m.ownAnnsLock.Unlock()
+	if m.ownAnns != nil && m.ownAnnsParent.IsEqual(parentHash) {
+		return m.ownAnns, nil
+	}
+
+	// Version 0 announcements are not valid from PacketCrypt version 2.
+	annVersion := uint8(0)
+	if version >= 2 {
+		annVersion = 1
+	}
+	am, err := annminer.New(&annminer.Request{
+		Version:           annVersion,
+		ParentBlockHash:   *parentHash,
+		ParentBlockHeight: uint32(parentHeight),
+		WorkTarget:        ownAnnTarget(bits, version),
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Mining %d announcements for block %d", ownAnnCount,
+		blockHeight)
+	anns, err := am.Mine(ownAnnCount, quit)
+	if err != nil {
+		return nil, err
+	}
+	if len(anns) < ownAnnCount {
+		// Interrupted, do not keep a partial set.
+		return nil, er.New("announcement mining interrupted")
+	}
+	m.ownAnns = make([]blockminer.Announcement, len(anns))
+	for i, ann := range anns {
+		m.ownAnns[i] = blockminer.Announcement{Ann: ann}
+	}
+	m.ownAnnsParent = *parentHash
+	return m.ownAnns, nil
+}
+
+// packetCryptMiner returns a PacketCrypt block miner for the block at the
+// passed height with the passed target.  It uses the configured
+// announcements, or announcements it mines itself when none of them are
+// usable.
+func (m *CPUMiner) packetCryptMiner(blockHeight int32, bits uint32,
+	quit chan struct{}) (*blockminer.Miner, er.R) {
+
+	version := 1
+	if globalcfg.IsPacketCryptAllowedVersion(2, blockHeight) {
+		version = 2
+	}
+	var anns []blockminer.Announcement
+	if m.cfg.Announcements != nil {
+		anns = m.cfg.Announcements(blockHeight)
+	}
+	if pcm, err := blockminer.New(anns, blockHeight, version); err == nil {
+		return pcm, nil
+	}
+	own, err := m.ownAnnouncements(blockHeight, bits, version, quit)
+	if err != nil {
+		return nil, err
+	}
+	return blockminer.New(own, blockHeight, version)
+}
+
 // solveBlock attempts to find some combination of a nonce, extra nonce, and
 // current timestamp which makes the passed block hash to a value less than the
 // target difficulty.  The timestamp is updated periodically and the passed
 // block is modified with all tweaks during this process.  This means that
 // when the function returns true, the block is ready for submission.
 //
+// On chains which use PacketCrypt proof of work, the nonce of the PacketCrypt
+// proof is searched instead of the header nonce, with the announcements
+// committed in the coinbase, and the proof is set when a solution is found.
+//
 // This function will return early with false when conditions that trigger a
 // stale block such as a new block showing up or periodically when there are
 // new transactions and enough time has elapsed without finding a solution.
 func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 	ticker *time.Ticker, quit chan struct{}) bool {
 
+	var pcm *blockminer.Miner
+	var work *blockminer.Work
+	if globalcfg.GetProofOfWorkAlgorithm() == globalcfg.PowPacketCrypt {
+		var err er.R
+		pcm, err = m.packetCryptMiner(blockHeight, msgBlock.Header.Bits,
+			quit)
+		if err != nil {
+			log.Errorf("Unable to mine a PacketCrypt block: %v", err)
+			return false
+		}
+		pcm.InsertCommit(msgBlock.Transactions[0])
+	}
+
 	// Choose a random extra nonce offset for this block template and
 	// worker.
 	enOffset, err := wire.RandomUint64()
@@ -237,11 +385,18 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 	// Note that the entire extra nonce range is iterated and the offset is
 	// added relying on the fact that overflow will wrap around 0 as
 	// provided by the Go spec.
+extraNonces:
 	for extraNonce := uint64(0); extraNonce < maxExtraNonce; extraNonce++ {
 		// Update the extra nonce in the block template with the
 		// new value by regenerating the coinbase script and
 		// setting the merkle root to the new value.
 		m.g.UpdateExtraNonce(msgBlock, blockHeight, extraNonce+enOffset)
+		if pcm != nil {
+			work = pcm.Work(header)
+			if !work.Solvable() {
+				continue
+			}
+		}
 
 		// Search through the entire nonce range for a solution while
 		// periodically checking for early quit and stale block
@@ -273,10 +428,36 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				}
 
 				m.g.UpdateBlockTime(msgBlock)
+				if pcm != nil {
+					// The new timestamp may select an
+					// announcement which cannot be proven,
+					// the extra nonce must then change too.
+					work = pcm.Work(header)
+					if !work.Solvable() {
+						continue extraNonces
+					}
+				}
 			default:
 				// Non-blocking select to fall through
 			}
 
+			// Try the PacketCrypt proof nonce, the block is solved
+			// when its work hash meets the effective target.
+			if pcm != nil {
+				hashesCompleted++
+				if !work.Try(i) {
+					continue
+				}
+				pcp, err := work.Proof(i)
+				if err != nil {
+					log.Errorf("Unable to make PacketCrypt proof: %v", err)
+					return false
+				}
+				msgBlock.Pcp = pcp
+				m.updateHashes <- hashesCompleted
+				return true
+			}
+
 			// Update the nonce and hash the block header.  Each
 			// hash is actually a double sha256 (two hashes), so
 			// increment the number of hashes completed for each
@@ -348,7 +529,7 @@ out:
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := m.g.NewBlockTemplate(m.cfg.MiningAddrs, nil)
+		template, err := m.g.NewBlockTemplate(m.cfg.Payouts, nil)
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
@@ -601,7 +782,7 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, er.R) {
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := m.g.NewBlockTemplate(m.cfg.MiningAddrs, nil)
+		template, err := m.g.NewBlockTemplate(m.cfg.Payouts, nil)
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
@@ -255,8 +255,9 @@ func standardCoinbaseScript(coinbase []byte, nextBlockHeight int32, extraNonce u
 }
 
 // createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy
-// based on the passed block height to the provided address.  When the address
-// is nil, the coinbase transaction will instead be redeemable by anyone.
+// based on the passed block height to the provided addresses, split as
+// payoutTxOuts splits it.  When the addresses are nil, the coinbase
+// transaction will instead be redeemable by anyone.
 //
 // See the comment for NewBlockTemplate for more information about why the nil
 // address handling is useful.
@@ -275,42 +276,12 @@ func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockH
 	tax := blockchain.PktCalcNetworkStewardPayout(subsidy)
 
 	if addrs != nil {
-		sum := float64(0)
-		for _, pct := range addrs {
-			sum += pct
+		txOuts, err := payoutTxOuts(subsidy-tax, addrs)
+		if err != nil {
+			return nil, err
 		}
-		coins := subsidy - tax
-		coinsToDate := int64(0)
-		count := len(addrs)
-		i := 1
-		for addr, pct := range addrs {
-			var err er.R
-			pkScript, err := txscript.PayToAddrScript(addr)
-			if err != nil {
-				return nil, err
-			}
-			realPct := pct / sum
-			amt := int64(float64(coins) * realPct)
-			if coinsToDate+amt > coins {
-				amt2 := coins - coinsToDate
-				diff := float64(amt-amt2) / float64(globalcfg.SatoshiPerBitcoin())
-				log.Infof("Shaved [%v] coins off from address [%s] to make exact total",
-					diff, addr.EncodeAddress())
-				amt = amt2
-			}
-			if count == i && coinsToDate+amt < coins {
-				amt2 := coins - coinsToDate
-				diff := float64(amt2-amt) / float64(globalcfg.SatoshiPerBitcoin())
-				log.Infof("Gave away [%v] coins off from address [%s] to make exact total",
-					diff, addr.EncodeAddress())
-				amt = amt2
-			}
-			coinsToDate += amt
-			i++
-			tx.AddTxOut(&wire.TxOut{
-				Value:    amt,
-				PkScript: pkScript,
-			})
+		for _, txOut := range txOuts {
+			tx.AddTxOut(txOut)
 		}
 	} else {
 		var err er.R
@@ -425,8 +396,9 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 
 // NewBlockTemplate returns a new block template that is ready to be solved
 // using the transactions from the passed transaction source pool and a coinbase
-// that either pays to the passed address if it is not nil, or a coinbase that
-// is redeemable by anyone if the passed address is nil.  The nil address
+// that either pays to the addresses which the passed payout schedule has for
+// the height of the block if it is not nil, or a coinbase that is redeemable
+// by anyone if the passed schedule is nil.  The nil schedule
 // functionality is useful since there are cases such as the getblocktemplate
 // RPC where external mining software is responsible for creating their own
 // coinbase which will replace the one generated for the block template.  Thus
@@ -485,11 +457,27 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 //  |  transactions (while block size   |   |
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
-func (g *BlkTmplGenerator) NewBlockTemplate(payToAddresses map[btcutil.Address]float64, cbc *wire.PcCoinbaseCommit) (*BlockTemplate, er.R) {
+func (g *BlkTmplGenerator) NewBlockTemplate(payouts *PayoutSchedule, cbc *wire.PcCoinbaseCommit) (*BlockTemplate, er.R) {
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
 
+	// The split is checked again since the subsidy shrinks and the dust
+	// limit may have changed since it was configured.
+	var payToAddresses map[btcutil.Address]float64
+	if payouts != nil {
+		payToAddresses = payouts.SplitAt(nextBlockHeight)
+		if payToAddresses == nil {
+			return nil, er.Errorf("no payouts configured for height [%d]",
+				nextBlockHeight)
+		}
+		err := CheckPayouts(g.chainParams, nextBlockHeight, payToAddresses,
+			g.policy.TxMinFreeFee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create a standard coinbase transaction paying to the provided
 	// address.  NOTE: The coinbase value will be updated to include the
 	// fees from the selected transactions later after they have actually
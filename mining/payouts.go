@@ -0,0 +1,318 @@
+package mining
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// MaxPayouts is the maximum number of addresses which the coinbase of a block
+// template may pay, the coinbase also pays the network steward and may carry
+// the witness and PacketCrypt commitments.
+const MaxPayouts = 64
+
+// ScheduledPayouts is a split of the block reward between addresses which
+// applies to the blocks starting at Height.
+type ScheduledPayouts struct {
+	// Height is the height of the first block which the split applies to
+	Height int32
+
+	// Split maps each address to its share of the block reward, the shares
+	// are relative to their sum
+	Split map[btcutil.Address]float64
+}
+
+// payoutsJSON is how ScheduledPayouts are stored.
+type payoutsJSON struct {
+	Height  int32              `json:"height"`
+	Payouts map[string]float64 `json:"payouts"`
+}
+
+// splitAddrs returns the addresses of a split in a stable order, so that
+// the same split always gives the same coinbase.
+func splitAddrs(split map[btcutil.Address]float64) []btcutil.Address {
+	addrs := make([]btcutil.Address, 0, len(split))
+	for addr := range split {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].EncodeAddress() < addrs[j].EncodeAddress()
+	})
+	return addrs
+}
+
+// payoutTxOuts divides coins between the addresses of the split, any coins
+// which are left over by rounding go to the last address.
+func payoutTxOuts(coins int64, split map[btcutil.Address]float64) ([]*wire.TxOut, er.R) {
+	sum := float64(0)
+	for _, pct := range split {
+		sum += pct
+	}
+	addrs := splitAddrs(split)
+	out := make([]*wire.TxOut, 0, len(addrs))
+	coinsToDate := int64(0)
+	for i, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		amt := int64(float64(coins) * (split[addr] / sum))
+		if coinsToDate+amt > coins {
+			amt2 := coins - coinsToDate
+			diff := float64(amt-amt2) / float64(globalcfg.SatoshiPerBitcoin())
+			log.Infof("Shaved [%v] coins off from address [%s] to make exact total",
+				diff, addr.EncodeAddress())
+			amt = amt2
+		}
+		if i == len(addrs)-1 && coinsToDate+amt < coins {
+			amt2 := coins - coinsToDate
+			diff := float64(amt2-amt) / float64(globalcfg.SatoshiPerBitcoin())
+			log.Infof("Gave away [%v] coins off from address [%s] to make exact total",
+				diff, addr.EncodeAddress())
+			amt = amt2
+		}
+		coinsToDate += amt
+		out = append(out, &wire.TxOut{
+			Value:    amt,
+			PkScript: pkScript,
+		})
+	}
+	return out, nil
+}
+
+// CheckPayouts returns an error if the split cannot be paid by the coinbase of
+// a block at the passed height, because it pays no address, too many
+// addresses, or because an address would be paid less than the dust limit.
+func CheckPayouts(params *chaincfg.Params, height int32,
+	split map[btcutil.Address]float64, minRelayTxFee btcutil.Amount) er.R {
+
+	if len(split) == 0 {
+		return er.New("no address to pay")
+	}
+	if len(split) > MaxPayouts {
+		return er.Errorf("cannot pay [%d] addresses, the maximum is [%d]",
+			len(split), MaxPayouts)
+	}
+	for addr, pct := range split {
+		if !addr.IsForNet(params) {
+			return er.Errorf("address [%s] is on the wrong network",
+				addr.EncodeAddress())
+		}
+		if !(pct > 0) || math.IsInf(pct, 0) {
+			return er.Errorf("share [%v] of address [%s] is not a positive number",
+				pct, addr.EncodeAddress())
+		}
+	}
+
+	subsidy := blockchain.CalcBlockSubsidy(height, params)
+	coins := subsidy - blockchain.PktCalcNetworkStewardPayout(subsidy)
+	txOuts, err := payoutTxOuts(coins, split)
+	if err != nil {
+		return err
+	}
+	for i, addr := range splitAddrs(split) {
+		if IsDust(txOuts[i], minRelayTxFee) {
+			return er.Errorf("payment of [%v] to address [%s] at height [%d] "+
+				"would be dust", btcutil.Amount(txOuts[i].Value),
+				addr.EncodeAddress(), height)
+		}
+	}
+	return nil
+}
+
+// PayoutSchedule is the split of the block reward between the mining
+// addresses and the changes to it which are scheduled at later heights.  The
+// schedule is stored in a file so it persists across restarts.
+//
+// It is safe for concurrent access.
+type PayoutSchedule struct {
+	mtx           sync.Mutex
+	path          string
+	params        *chaincfg.Params
+	minRelayTxFee btcutil.Amount
+
+	// payouts are sorted by height
+	payouts []ScheduledPayouts
+}
+
+// NewPayoutSchedule returns an empty schedule which is stored in the file at
+// path.  The splits are checked against the dust limit of minRelayTxFee.
+func NewPayoutSchedule(path string, params *chaincfg.Params,
+	minRelayTxFee btcutil.Amount) *PayoutSchedule {
+
+	return &PayoutSchedule{
+		path:          path,
+		params:        params,
+		minRelayTxFee: minRelayTxFee,
+	}
+}
+
+// Load reads the schedule from its file.  It returns false if there is no
+// file, in which case the schedule is left as it is.
+func (ps *PayoutSchedule) Load() (bool, er.R) {
+	b, errr := ioutil.ReadFile(ps.path)
+	if os.IsNotExist(errr) {
+		return false, nil
+	} else if errr != nil {
+		return false, er.E(errr)
+	}
+	var pj []payoutsJSON
+	if errr := json.Unmarshal(b, &pj); errr != nil {
+		return false, er.Errorf("unable to parse [%s]: %v", ps.path, errr)
+	}
+	payouts := make([]ScheduledPayouts, 0, len(pj))
+	for _, p := range pj {
+		split := make(map[btcutil.Address]float64, len(p.Payouts))
+		for k, v := range p.Payouts {
+			addr, err := btcutil.DecodeAddress(k, ps.params)
+			if err != nil {
+				return false, er.Errorf("address [%s] in [%s] could not be "+
+					"decoded: %v", k, ps.path, err)
+			}
+			split[addr] = v
+		}
+		if err := CheckPayouts(ps.params, p.Height, split, ps.minRelayTxFee); err != nil {
+			return false, er.Errorf("payouts at height [%d] in [%s]: %v",
+				p.Height, ps.path, err)
+		}
+		payouts = append(payouts, ScheduledPayouts{Height: p.Height, Split: split})
+	}
+	sort.Slice(payouts, func(i, j int) bool {
+		return payouts[i].Height < payouts[j].Height
+	})
+
+	ps.mtx.Lock()
+	ps.payouts = payouts
+	ps.mtx.Unlock()
+	return true, nil
+}
+
+// save writes the schedule to its file, replacing the file only once it is
+// completely written.
+//
+// This function MUST be called with the lock held.
+func (ps *PayoutSchedule) save() er.R {
+	pj := make([]payoutsJSON, 0, len(ps.payouts))
+	for _, p := range ps.payouts {
+		m := make(map[string]float64, len(p.Split))
+		for addr, pct := range p.Split {
+			m[addr.EncodeAddress()] = pct
+		}
+		pj = append(pj, payoutsJSON{Height: p.Height, Payouts: m})
+	}
+	b, errr := json.MarshalIndent(pj, "", "  ")
+	if errr != nil {
+		return er.E(errr)
+	}
+	tmpPath := ps.path + ".new"
+	if errr := ioutil.WriteFile(tmpPath, b, 0600); errr != nil {
+		return er.E(errr)
+	}
+	return er.E(os.Rename(tmpPath, ps.path))
+}
+
+// Seed makes split the payouts of all blocks without storing it, it is meant
+// for the payouts which are configured when there is no stored schedule.
+func (ps *PayoutSchedule) Seed(split map[btcutil.Address]float64) er.R {
+	if err := CheckPayouts(ps.params, 0, split, ps.minRelayTxFee); err != nil {
+		return err
+	}
+	ps.mtx.Lock()
+	ps.payouts = []ScheduledPayouts{{Height: 0, Split: split}}
+	ps.mtx.Unlock()
+	return nil
+}
+
+// Set makes split the payouts of the blocks from height on, until the next
+// scheduled change if there is one, and stores the schedule.  A split which
+// was set at the same height is replaced.
+func (ps *PayoutSchedule) Set(height int32, split map[btcutil.Address]float64) er.R {
+	if err := CheckPayouts(ps.params, height, split, ps.minRelayTxFee); err != nil {
+		return err
+	}
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	payouts := make([]ScheduledPayouts, 0, len(ps.payouts)+1)
+	for _, p := range ps.payouts {
+		if p.Height != height {
+			payouts = append(payouts, p)
+		}
+	}
+	payouts = append(payouts, ScheduledPayouts{Height: height, Split: split})
+	sort.Slice(payouts, func(i, j int) bool {
+		return payouts[i].Height < payouts[j].Height
+	})
+
+	old := ps.payouts
+	ps.payouts = payouts
+	if err := ps.save(); err != nil {
+		ps.payouts = old
+		return err
+	}
+	return nil
+}
+
+// Prune drops the payouts which only apply to the blocks below the passed
+// height and stores the schedule if any were dropped.
+func (ps *PayoutSchedule) Prune(height int32) er.R {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	payouts := ps.payouts
+	for len(payouts) > 1 && payouts[1].Height <= height {
+		payouts = payouts[1:]
+	}
+	if len(payouts) == len(ps.payouts) {
+		return nil
+	}
+	old := ps.payouts
+	ps.payouts = payouts
+	if err := ps.save(); err != nil {
+		ps.payouts = old
+		return err
+	}
+	return nil
+}
+
+// SplitAt returns the split which applies to the block at the passed height,
+// or nil if there is none.  The returned map must not be modified.
+func (ps *PayoutSchedule) SplitAt(height int32) map[btcutil.Address]float64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	var split map[btcutil.Address]float64
+	for _, p := range ps.payouts {
+		if p.Height > height {
+			break
+		}
+		split = p.Split
+	}
+	return split
+}
+
+// Payouts returns all of the scheduled payouts sorted by height.
+func (ps *PayoutSchedule) Payouts() []ScheduledPayouts {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	out := make([]ScheduledPayouts, len(ps.payouts))
+	copy(out, ps.payouts)
+	return out
+}
+
+// HasPayouts returns true if any block is paid to an address.
+func (ps *PayoutSchedule) HasPayouts() bool {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	return len(ps.payouts) > 0
+}
@@ -0,0 +1,146 @@
+package mining
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// splitShare returns the share of addr in the split, the addresses of a loaded
+// split are not the same instances as the ones it was set with.
+func splitShare(split map[btcutil.Address]float64, addr btcutil.Address) float64 {
+	for a, pct := range split {
+		if a.EncodeAddress() == addr.EncodeAddress() {
+			return pct
+		}
+	}
+	return 0
+}
+
+// TestPayoutSchedule ensures the payouts are split exactly, checked against
+// the dust and output limits, and stored with their scheduled changes.
+func TestPayoutSchedule(t *testing.T) {
+	dir, errr := ioutil.TempDir("", "payouts")
+	if errr != nil {
+		t.Fatalf("TempDir: %v", errr)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &chaincfg.SimNetParams
+	addrs := make([]btcutil.Address, MaxPayouts+1)
+	for i := range addrs {
+		hash := make([]byte, 20)
+		hash[0] = byte(i)
+		addr, err := btcutil.NewAddressPubKeyHash(hash, params)
+		if err != nil {
+			t.Fatalf("NewAddressPubKeyHash: %v", err)
+		}
+		addrs[i] = addr
+	}
+
+	// The split is stable and pays all of the coins.
+	split := map[btcutil.Address]float64{addrs[2]: 1, addrs[1]: 1, addrs[0]: 1}
+	txOuts, err := payoutTxOuts(1000, split)
+	if err != nil {
+		t.Fatalf("payoutTxOuts: %v", err)
+	}
+	if len(txOuts) != 3 || txOuts[0].Value != 333 || txOuts[1].Value != 333 ||
+		txOuts[2].Value != 334 {
+
+		t.Fatalf("payoutTxOuts: unexpected split %v %v %v", txOuts[0].Value,
+			txOuts[1].Value, txOuts[2].Value)
+	}
+
+	const minRelayTxFee = btcutil.Amount(1000)
+	invalid := []map[btcutil.Address]float64{
+		{},
+		{addrs[0]: 1, addrs[1]: 0},
+		{addrs[0]: 1, addrs[1]: -1},
+		{addrs[0]: 1, addrs[1]: 1e-15},
+	}
+	tooMany := make(map[btcutil.Address]float64)
+	for _, addr := range addrs {
+		tooMany[addr] = 1
+	}
+	invalid = append(invalid, tooMany)
+	for i, split := range invalid {
+		if err := CheckPayouts(params, 1, split, minRelayTxFee); err == nil {
+			t.Fatalf("CheckPayouts #%d: invalid split accepted", i)
+		}
+	}
+
+	path := filepath.Join(dir, "miningpayouts.json")
+	ps := NewPayoutSchedule(path, params, minRelayTxFee)
+	if ok, err := ps.Load(); err != nil || ok {
+		t.Fatalf("Load: %v %v, want no file", ok, err)
+	}
+	if err := ps.Seed(map[btcutil.Address]float64{addrs[0]: 1}); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	if _, errr := os.Stat(path); !os.IsNotExist(errr) {
+		t.Fatalf("Seed stored the schedule")
+	}
+	if err := ps.Set(10, map[btcutil.Address]float64{addrs[1]: 1}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := ps.Set(20, map[btcutil.Address]float64{addrs[2]: 3, addrs[3]: 1}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := ps.Set(30, invalid[3]); err == nil {
+		t.Fatalf("Set: invalid split accepted")
+	}
+
+	// The schedule is the same once it is loaded again.
+	loaded := NewPayoutSchedule(path, params, minRelayTxFee)
+	if ok, err := loaded.Load(); err != nil || !ok {
+		t.Fatalf("Load: %v %v", ok, err)
+	}
+	for _, ps := range []*PayoutSchedule{ps, loaded} {
+		tests := []struct {
+			height int32
+			addr   btcutil.Address
+			pct    float64
+		}{
+			{0, addrs[0], 1},
+			{9, addrs[0], 1},
+			{10, addrs[1], 1},
+			{19, addrs[1], 1},
+			{20, addrs[2], 3},
+			{1000, addrs[3], 1},
+		}
+		for _, test := range tests {
+			split := ps.SplitAt(test.height)
+			if splitShare(split, test.addr) != test.pct {
+				t.Fatalf("SplitAt(%d): %v, want %s at %v", test.height, split,
+					test.addr, test.pct)
+			}
+		}
+	}
+	if len(loaded.Payouts()) != 3 {
+		t.Fatalf("Payouts: %v", loaded.Payouts())
+	}
+
+	// Pruning drops only the payouts of the past blocks.
+	if err := loaded.Prune(15); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if p := loaded.Payouts(); len(p) != 2 || p[0].Height != 10 {
+		t.Fatalf("Prune: %v", p)
+	}
+	if splitShare(loaded.SplitAt(15), addrs[1]) != 1 {
+		t.Fatalf("Prune: dropped the current payouts")
+	}
+
+	// A small payment which is above the dust limit is accepted.
+	subsidy := blockchain.CalcBlockSubsidy(1, params)
+	share := float64(1000) / float64(subsidy)
+	split = map[btcutil.Address]float64{addrs[0]: 1 - share, addrs[1]: share}
+	if err := CheckPayouts(params, 1, split, minRelayTxFee); err != nil {
+		t.Fatalf("CheckPayouts: %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+package netsync
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/protocol"
+	"github.com/pkt-cash/pktd/wire/ruleerror"
+
+	peerpkg "github.com/pkt-cash/pktd/peer"
+)
+
+// maxHighBandwidthPeers is the maximum number of peers which are asked to
+// announce new blocks with cmpctblock messages (BIP0152 high bandwidth mode).
+const maxHighBandwidthPeers = 3
+
+// maxPartialBlocksPerPeer is the maximum number of compact blocks from a
+// single peer which may be waiting for missing transactions at the same time.
+const maxPartialBlocksPerPeer = 3
+
+// partialBlockTimeout is how long a compact block waits for its missing
+// transactions before it is forgotten.
+const partialBlockTimeout = time.Minute
+
+// cmpctBlockMsg packages a bitcoin cmpctblock message and the peer it came from
+// together so the block handler has access to that information.
+type cmpctBlockMsg struct {
+	cmpctBlock *wire.MsgCmpctBlock
+	peer       *peerpkg.Peer
+	reply      chan struct{}
+}
+
+// blockTxnMsg packages a bitcoin blocktxn message and the peer it came from
+// together so the block handler has access to that information.
+type blockTxnMsg struct {
+	blockTxn *wire.MsgBlockTxn
+	peer     *peerpkg.Peer
+	reply    chan struct{}
+}
+
+// partialBlock is a block which was received as a compact block and is waiting
+// for the transactions which could not be found in the memory pool.
+type partialBlock struct {
+	peer     *peerpkg.Peer
+	block    *wire.MsgBlock
+	missing  []uint32
+	received time.Time
+}
+
+// reconstructBlock rebuilds the block described by the passed compact block
+// using the prefilled transactions and the passed transactions from the memory
+// pool.  The indexes of the transactions which could not be found are returned
+// along with the block, in which they are left nil.  An error is returned when
+// the compact block is malformed or the short IDs are ambiguous, in which case
+// the full block must be requested.
+func reconstructBlock(msg *wire.MsgCmpctBlock, txDescs []*mempool.TxDesc) (*wire.MsgBlock, []uint32, er.R) {
+	count := msg.TxCount()
+	if count == 0 {
+		return nil, nil, er.New("compact block has no transactions")
+	}
+
+	txns := make([]*wire.MsgTx, count)
+	for _, ptx := range msg.PrefilledTxs {
+		if int(ptx.Index) >= count || txns[ptx.Index] != nil {
+			return nil, nil, er.Errorf("invalid prefilled "+
+				"transaction index %d", ptx.Index)
+		}
+		txns[ptx.Index] = ptx.Tx
+	}
+
+	// Map each short ID to the position of its transaction in the block.
+	// Duplicate short IDs can't be resolved from the memory pool.
+	positions := make(map[uint64]int, len(msg.ShortIDs))
+	next := 0
+	for i := range txns {
+		if txns[i] != nil {
+			continue
+		}
+		id := msg.ShortIDs[next]
+		next++
+		if _, ok := positions[id]; ok {
+			return nil, nil, er.Errorf("duplicate short ID %x", id)
+		}
+		positions[id] = i
+	}
+
+	// Fill in the transactions from the memory pool.  When more than one
+	// transaction matches a short ID it is left to be requested from the
+	// peer.
+	key := msg.ShortIDKey()
+	ambiguous := make(map[int]struct{})
+	for _, txD := range txDescs {
+		wtxid := txD.Tx.Hash()
+		if txD.Tx.HasWitness() {
+			hash := txD.Tx.MsgTx().WitnessHash()
+			wtxid = &hash
+		}
+		i, ok := positions[wire.ShortTxID(&key, wtxid)]
+		if !ok {
+			continue
+		}
+		if _, ok := ambiguous[i]; ok {
+			continue
+		}
+		if txns[i] != nil {
+			txns[i] = nil
+			ambiguous[i] = struct{}{}
+			continue
+		}
+		txns[i] = txD.Tx.MsgTx()
+	}
+
+	var missing []uint32
+	for i, tx := range txns {
+		if tx == nil {
+			missing = append(missing, uint32(i))
+		}
+	}
+
+	block := &wire.MsgBlock{
+		Header:       msg.Header,
+		Pcp:          msg.Pcp,
+		Transactions: txns,
+	}
+	return block, missing, nil
+}
+
+// requestFullBlock requests the block with the passed hash from the peer with a
+// getdata message.  It is used when a compact block could not be
+// reconstructed.
+func (sm *SyncManager) requestFullBlock(peer *peerpkg.Peer, state *peerSyncState,
+	blockHash *chainhash.Hash) {
+
+	sm.requestedBlocks[*blockHash] = struct{}{}
+	sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+	state.requestedBlocks[*blockHash] = struct{}{}
+
+	iv := wire.NewInvVect(wire.InvTypeBlock, blockHash)
+	if peer.IsWitnessEnabled() {
+		iv.Type = wire.InvTypeWitnessBlock
+	}
+	gdmsg := wire.NewMsgGetData()
+	gdmsg.AddInvVect(iv)
+	peer.QueueMessage(gdmsg, nil)
+}
+
+// processReconstructedBlock processes a block which was reconstructed from a
+// compact block the same way as a block received from the peer in full.
+func (sm *SyncManager) processReconstructedBlock(peer *peerpkg.Peer, state *peerSyncState,
+	msgBlock *wire.MsgBlock) {
+
+	// A short ID collision with a transaction in the memory pool results in
+	// a block which does not match its merkle root.  That is not the fault
+	// of the peer so the full block is requested instead.
+	block := btcutil.NewBlock(msgBlock)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	if !msgBlock.Header.MerkleRoot.IsEqual(merkles[len(merkles)-1]) {
+		log.Debugf("Reconstructed compact block %v from %s does not "+
+			"match its merkle root -- requesting full block",
+			block.Hash(), peer)
+		sm.requestFullBlock(peer, state, block.Hash())
+		return
+	}
+
+	sm.requestedBlocks[*block.Hash()] = struct{}{}
+	state.requestedBlocks[*block.Hash()] = struct{}{}
+	sm.handleBlockMsg(&blockMsg{block: block, peer: peer})
+}
+
+// checkCmpctBlockHeader checks the header of the passed compact block against
+// its parent, along with the PacketCrypt proof which is verified using the
+// prefilled coinbase.  Nothing is allocated for a compact block which fails
+// these checks.
+func (sm *SyncManager) checkCmpctBlockHeader(msg *wire.MsgCmpctBlock) er.R {
+	msgBlock := &wire.MsgBlock{Header: msg.Header, Pcp: msg.Pcp}
+	for _, ptx := range msg.PrefilledTxs {
+		if ptx.Index == 0 {
+			msgBlock.Transactions = []*wire.MsgTx{ptx.Tx}
+			break
+		}
+	}
+	err := sm.chain.CheckBlockHeaderContext(btcutil.NewBlock(msgBlock))
+	if ruleerror.ErrPowCannotVerify.Is(err) {
+		err = nil
+	}
+	return err
+}
+
+// numPartialBlocks returns the number of compact blocks from the passed peer
+// which are waiting for missing transactions.
+func (sm *SyncManager) numPartialBlocks(peer *peerpkg.Peer) int {
+	n := 0
+	for _, pb := range sm.partialBlocks {
+		if pb.peer == peer {
+			n++
+		}
+	}
+	return n
+}
+
+// expirePartialBlocks forgets the compact blocks which have been waiting for
+// their missing transactions for longer than partialBlockTimeout so the blocks
+// can be requested again.
+func (sm *SyncManager) expirePartialBlocks() {
+	for hash, pb := range sm.partialBlocks {
+		if time.Since(pb.received) <= partialBlockTimeout {
+			continue
+		}
+		log.Debugf("Compact block %v from %s timed out waiting for "+
+			"%d transactions", hash, pb.peer, len(pb.missing))
+		delete(sm.partialBlocks, hash)
+		delete(sm.requestedBlocks, hash)
+		sm.syncPeerMutex.RLock()
+		state, exists := sm.peerStates[pb.peer]
+		sm.syncPeerMutex.RUnlock()
+		if exists {
+			delete(state.requestedBlocks, hash)
+		}
+	}
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers.  The block
+// is reconstructed from the memory pool and any transactions which are not
+// found are requested from the peer.  Compact blocks which do not extend a
+// known block are handled like an inventory announcement.
+func (sm *SyncManager) handleCmpctBlockMsg(cmsg *cmpctBlockMsg) {
+	peer := cmsg.peer
+	sm.syncPeerMutex.RLock()
+	state, exists := sm.peerStates[peer]
+	sm.syncPeerMutex.RUnlock()
+	if !exists {
+		log.Warnf("Received cmpctblock message from unknown peer %s", peer)
+		return
+	}
+
+	msg := cmsg.cmpctBlock
+	blockHash := msg.BlockHash()
+	iv := wire.NewInvVect(wire.InvTypeBlock, &blockHash)
+	haveInv, err := sm.haveInventory(iv)
+	if err != nil {
+		log.Warnf("Unexpected failure when checking for existing "+
+			"block %v: %v", blockHash, err)
+		return
+	}
+	if haveInv {
+		return
+	}
+	if _, ok := sm.partialBlocks[blockHash]; ok {
+		return
+	}
+	if _, ok := sm.requestedBlocks[blockHash]; ok {
+		return
+	}
+
+	// Only blocks which extend a known block are reconstructed, anything
+	// else is fetched through the normal sync process.
+	prevHash := &msg.Header.PrevBlock
+	haveParent, err := sm.chain.HaveBlock(prevHash)
+	if err != nil || !haveParent || sm.chain.IsKnownOrphan(prevHash) ||
+		sm.headersFirstMode {
+
+		inv := wire.NewMsgInvSizeHint(1)
+		inv.AddInvVect(iv)
+		sm.handleInvMsg(&invMsg{inv: inv, peer: peer})
+		return
+	}
+
+	if err := sm.checkCmpctBlockHeader(msg); err != nil {
+		log.Infof("Rejected compact block %v from %s: %v - "+
+			"disconnecting peer", blockHash, peer, err)
+		code, reason := ruleerror.ErrToRejectErr(err)
+		peer.PushRejectMsg(wire.CmdCmpctBlock, code, reason, &blockHash,
+			false)
+		peer.Disconnect()
+		return
+	}
+
+	block, missing, err := reconstructBlock(msg, sm.txMemPool.TxDescs())
+	if err != nil {
+		log.Debugf("Unable to reconstruct compact block %v from %s: "+
+			"%v -- requesting full block", blockHash, peer, err)
+		sm.requestFullBlock(peer, state, &blockHash)
+		return
+	}
+	if len(missing) == 0 {
+		sm.processReconstructedBlock(peer, state, block)
+		return
+	}
+
+	if sm.numPartialBlocks(peer) >= maxPartialBlocksPerPeer {
+		log.Debugf("Too many compact blocks from %s waiting for "+
+			"transactions -- requesting full block %v", peer,
+			blockHash)
+		sm.requestFullBlock(peer, state, &blockHash)
+		return
+	}
+
+	log.Debugf("Requesting %d of %d transactions of compact block %v "+
+		"from %s", len(missing), msg.TxCount(), blockHash, peer)
+	sm.partialBlocks[blockHash] = &partialBlock{
+		peer:     peer,
+		block:    block,
+		missing:  missing,
+		received: time.Now(),
+	}
+	sm.requestedBlocks[blockHash] = struct{}{}
+	sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+	state.requestedBlocks[blockHash] = struct{}{}
+	peer.QueueMessage(wire.NewMsgGetBlockTxn(&blockHash, missing), nil)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers.  The
+// transactions complete a block which was received as a compact block.
+func (sm *SyncManager) handleBlockTxnMsg(bmsg *blockTxnMsg) {
+	peer := bmsg.peer
+	sm.syncPeerMutex.RLock()
+	state, exists := sm.peerStates[peer]
+	sm.syncPeerMutex.RUnlock()
+	if !exists {
+		log.Warnf("Received blocktxn message from unknown peer %s", peer)
+		return
+	}
+
+	msg := bmsg.blockTxn
+	pb, ok := sm.partialBlocks[msg.BlockHash]
+	if !ok || pb.peer != peer {
+		log.Debugf("Ignoring unrequested blocktxn for block %v from %s",
+			msg.BlockHash, peer)
+		return
+	}
+	delete(sm.partialBlocks, msg.BlockHash)
+
+	if len(msg.Transactions) != len(pb.missing) {
+		log.Debugf("Received %d transactions for compact block %v "+
+			"from %s, expected %d -- requesting full block",
+			len(msg.Transactions), msg.BlockHash, peer,
+			len(pb.missing))
+		sm.requestFullBlock(peer, state, &msg.BlockHash)
+		return
+	}
+	for i, index := range pb.missing {
+		pb.block.Transactions[index] = msg.Transactions[i]
+	}
+	sm.processReconstructedBlock(peer, state, pb.block)
+}
+
+// updateHighBandwidthPeers asks the passed peer, which was the first to provide
+// a new block, to announce new blocks with cmpctblock messages.  Only the
+// maxHighBandwidthPeers most recent such peers are kept in high bandwidth mode,
+// the oldest peer is asked to stop when there are too many.
+func (sm *SyncManager) updateHighBandwidthPeers(peer *peerpkg.Peer) {
+	if !peer.SupportsCmpctBlocks() {
+		return
+	}
+
+	for i, p := range sm.highBandwidthPeers {
+		if p == peer {
+			sm.highBandwidthPeers = append(sm.highBandwidthPeers[:i],
+				sm.highBandwidthPeers[i+1:]...)
+			sm.highBandwidthPeers = append(sm.highBandwidthPeers, peer)
+			return
+		}
+	}
+
+	peer.QueueMessage(wire.NewMsgSendCmpct(true, wire.CmpctBlockVersion), nil)
+	sm.highBandwidthPeers = append(sm.highBandwidthPeers, peer)
+	if len(sm.highBandwidthPeers) > maxHighBandwidthPeers {
+		oldest := sm.highBandwidthPeers[0]
+		sm.highBandwidthPeers = sm.highBandwidthPeers[1:]
+		oldest.QueueMessage(wire.NewMsgSendCmpct(false,
+			wire.CmpctBlockVersion), nil)
+	}
+}
+
+// removeCmpctBlockPeer forgets the compact block state of a peer which has
+// disconnected.
+func (sm *SyncManager) removeCmpctBlockPeer(peer *peerpkg.Peer) {
+	for i, p := range sm.highBandwidthPeers {
+		if p == peer {
+			sm.highBandwidthPeers = append(sm.highBandwidthPeers[:i],
+				sm.highBandwidthPeers[i+1:]...)
+			break
+		}
+	}
+	for hash, pb := range sm.partialBlocks {
+		if pb.peer == peer {
+			delete(sm.partialBlocks, hash)
+		}
+	}
+}
+
+// supportsCmpctBlocks returns whether compact blocks can be exchanged with the
+// passed peer.  Only the witness version of compact blocks is implemented so
+// the peer must support witnesses.
+func supportsCmpctBlocks(peer *peerpkg.Peer) bool {
+	return peer.ProtocolVersion() >= protocol.ShortIDsBlocksVersion &&
+		peer.IsWitnessEnabled()
+}
+
+// QueueCmpctBlock adds the passed cmpctblock message and peer to the block
+// handling queue.  Responds to the done channel argument after the block has
+// been processed or the missing transactions have been requested.
+func (sm *SyncManager) QueueCmpctBlock(cmpctBlock *wire.MsgCmpctBlock, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &cmpctBlockMsg{cmpctBlock: cmpctBlock, peer: peer, reply: done}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the block handling
+// queue.  Responds to the done channel argument after the completed block has
+// been processed.
+func (sm *SyncManager) QueueBlockTxn(blockTxn *wire.MsgBlockTxn, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &blockTxnMsg{blockTxn: blockTxn, peer: peer, reply: done}
+}
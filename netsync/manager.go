@@ -193,6 +193,10 @@ type SyncManager struct {
 	startHeader      *list.Element
 	nextCheckpoint   *chaincfg.Checkpoint
 
+	// The following fields are used for compact block relay.
+	partialBlocks      map[chainhash.Hash]*partialBlock
+	highBandwidthPeers []*peerpkg.Peer
+
 	// An optional fee estimator.
 	feeEstimator  *mempool.FeeEstimator
 	syncPeerMutex sync.RWMutex
@@ -443,6 +447,14 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peerpkg.Peer) {
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
 	}
 
+	// Let the peer know compact blocks are supported, it is asked to
+	// announce blocks with them once it has proven to be a good source of
+	// new blocks.
+	if supportsCmpctBlocks(peer) {
+		peer.QueueMessage(wire.NewMsgSendCmpct(false,
+			wire.CmpctBlockVersion), nil)
+	}
+
 	// Start syncing by choosing the best candidate if needed.
 	if isSyncCandidate && sm.syncPeer == nil {
 		sm.startSync()
@@ -519,6 +531,7 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 	log.Infof("Lost peer %s", peer)
 
 	sm.clearRequestedState(state)
+	sm.removeCmpctBlockPeer(peer)
 
 	if peer == sm.syncPeer {
 		// Update the sync peer. The server has already disconnected the
@@ -812,6 +825,12 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 
 		// Clear the rejected transactions.
 		sm.rejectedTxns = make(map[chainhash.Hash]struct{})
+
+		// The peer was the first to provide a new block so it is
+		// asked to announce the next ones with compact blocks.
+		if sm.current() && supportsCmpctBlocks(peer) {
+			sm.updateHighBandwidthPeers(peer)
+		}
 	}
 
 	// Update the block height for this peer. But only send a message to
@@ -1355,6 +1374,14 @@ out:
 				sm.handleBlockMsg(msg)
 				msg.reply <- struct{}{}
 
+			case *cmpctBlockMsg:
+				sm.handleCmpctBlockMsg(msg)
+				msg.reply <- struct{}{}
+
+			case *blockTxnMsg:
+				sm.handleBlockTxnMsg(msg)
+				msg.reply <- struct{}{}
+
 			case *invMsg:
 				sm.handleInvMsg(msg)
 
@@ -1395,6 +1422,7 @@ out:
 
 		case <-stallTicker.C:
 			sm.handleStallSample()
+			sm.expirePartialBlocks()
 
 		case <-sm.quit:
 			break out
@@ -1428,7 +1456,7 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 
 		// Generate the inventory vector and relay it.
 		iv := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
-		sm.peerNotifier.RelayInventory(iv, block.MsgBlock().Header)
+		sm.peerNotifier.RelayInventory(iv, block)
 
 	// A block has been connected to the main block chain.
 	case blockchain.NTBlockConnected:
@@ -1646,6 +1674,7 @@ func New(config *Config) (*SyncManager, er.R) {
 		progressLogger:  newBlockProgressLogger("Processed"),
 		msgChan:         make(chan interface{}, config.MaxPeers*3),
 		headerList:      list.New(),
+		partialBlocks:   make(map[chainhash.Hash]*partialBlock),
 		quit:            make(chan struct{}),
 		feeEstimator:    config.FeeEstimator,
 	}
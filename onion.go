@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkt-cash/pktd/addrmgr"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/lnd/tor"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// onionKeyFilename is the name of the file, within the data directory, which
+// holds the private key of the Tor hidden service so that the same onion
+// address is used across restarts.
+const onionKeyFilename = "onion_v3_private_key"
+
+// onionEnabled returns whether connections to Tor hidden services can be made,
+// which requires either the proxy or the onion proxy to be set.
+func onionEnabled() bool {
+	return !cfg.NoOnion && (cfg.Proxy != "" || cfg.OnionProxy != "")
+}
+
+// startOnionService connects to the Tor control port and creates a v3 hidden
+// service which forwards incoming connections to the first listener.  The
+// service is removed by Tor when the controller is stopped.
+func (s *server) startOnionService() er.R {
+	if len(cfg.Listeners) == 0 {
+		return er.New("not listening for incoming connections")
+	}
+	host, portStr, errr := net.SplitHostPort(cfg.Listeners[0])
+	if errr != nil {
+		return er.E(errr)
+	}
+	port, errr := strconv.Atoi(portStr)
+	if errr != nil {
+		return er.E(errr)
+	}
+
+	// Tor connects to the local host by default, which only works when
+	// listening on all interfaces or the loopback interface.
+	var targetIP string
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		targetIP = ip.String()
+	}
+
+	controller := tor.NewController(cfg.TorControl, targetIP,
+		cfg.TorPassword)
+	if err := controller.Start(); err != nil {
+		return err
+	}
+
+	keyPath := filepath.Join(cfg.DataDir, onionKeyFilename)
+	onionAddr, err := controller.AddOnion(tor.AddOnionConfig{
+		Type:        tor.V3,
+		VirtualPort: port,
+		Store:       tor.NewOnionFile(keyPath, 0600),
+	})
+	if err != nil {
+		controller.Stop()
+		return err
+	}
+
+	s.torController = controller
+	s.onionAddr = onionAddr
+	log.Infof("Tor hidden service available at %s", onionAddr)
+
+	// Advertise the hidden service to peers which support addrv2 messages,
+	// v3 onion addresses cannot be relayed with addr messages.
+	na, err := wire.NewNetAddressHost(onionAddr.OnionService,
+		uint16(onionAddr.Port), s.services)
+	if err != nil {
+		return err
+	}
+	return s.addrManager.AddLocalAddress(na, addrmgr.ManualPrio)
+}
@@ -31,7 +31,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = protocol.FeeFilterVersion
+	MaxProtocolVersion = protocol.AddrV2Version
 
 	// DefaultTrickleInterval is the min time between attempts to send an
 	// inv message to a peer.
@@ -143,6 +143,9 @@ type MessageListeners struct {
 	// OnAddr is invoked when a peer receives an addr bitcoin message.
 	OnAddr func(p *Peer, msg *wire.MsgAddr)
 
+	// OnAddrV2 is invoked when a peer receives an addrv2 bitcoin message.
+	OnAddrV2 func(p *Peer, msg *wire.MsgAddrV2)
+
 	// OnPing is invoked when a peer receives a ping bitcoin message.
 	OnPing func(p *Peer, msg *wire.MsgPing)
 
@@ -235,6 +238,35 @@ type MessageListeners struct {
 	// message.
 	OnSendHeaders func(p *Peer, msg *wire.MsgSendHeaders)
 
+	// OnSendAddrV2 is invoked when a peer receives a sendaddrv2 bitcoin
+	// message.
+	OnSendAddrV2 func(p *Peer, msg *wire.MsgSendAddrV2)
+
+	// OnSendCmpct is invoked when a peer receives a sendcmpct bitcoin
+	// message.
+	OnSendCmpct func(p *Peer, msg *wire.MsgSendCmpct)
+
+	// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin
+	// message.
+	OnCmpctBlock func(p *Peer, msg *wire.MsgCmpctBlock)
+
+	// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin
+	// message.
+	OnGetBlockTxn func(p *Peer, msg *wire.MsgGetBlockTxn)
+
+	// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin
+	// message.
+	OnBlockTxn func(p *Peer, msg *wire.MsgBlockTxn)
+
+	// OnAnnInv is invoked when a peer receives an anninv pktd message.
+	OnAnnInv func(p *Peer, msg *wire.MsgAnnInv)
+
+	// OnGetAnns is invoked when a peer receives a getanns pktd message.
+	OnGetAnns func(p *Peer, msg *wire.MsgGetAnns)
+
+	// OnAnns is invoked when a peer receives an anns pktd message.
+	OnAnns func(p *Peer, msg *wire.MsgAnns)
+
 	// OnRead is invoked when a peer receives a bitcoin message.  It
 	// consists of the number of bytes read, the message, and whether or not
 	// an error in the read occurred.  Typically, callers will opt to use
@@ -465,6 +497,9 @@ type Peer struct {
 	advertisedProtoVer   uint32 // protocol version advertised by remote
 	protocolVersion      uint32 // negotiated protocol version
 	sendHeadersPreferred bool   // peer sent a sendheaders message
+	sendAddrV2           bool   // peer sent a sendaddrv2 message
+	cmpctBlocks          bool   // peer supports compact blocks
+	cmpctBlocksAnnounce  bool   // peer wants cmpctblock announcements
 	verAckReceived       bool
 	witnessEnabled       bool
 
@@ -521,6 +556,9 @@ type PeerDesc struct {
 	AdvertisedProtoVer   uint32 // protocol version advertised by remote
 	ProtocolVersion      uint32 // negotiated protocol version
 	SendHeadersPreferred bool   // peer sent a sendheaders message
+	SendAddrV2           bool   // peer sent a sendaddrv2 message
+	CmpctBlocks          bool   // peer supports compact blocks
+	CmpctBlocksAnnounce  bool   // peer wants cmpctblock announcements
 	VerAckReceived       bool
 	WitnessEnabled       bool
 
@@ -557,6 +595,9 @@ func (p *Peer) Describe() PeerDesc {
 	pd.AdvertisedProtoVer = p.advertisedProtoVer
 	pd.ProtocolVersion = p.protocolVersion
 	pd.SendHeadersPreferred = p.sendHeadersPreferred
+	pd.SendAddrV2 = p.sendAddrV2
+	pd.CmpctBlocks = p.cmpctBlocks
+	pd.CmpctBlocksAnnounce = p.cmpctBlocksAnnounce
 	pd.VerAckReceived = p.verAckReceived
 	pd.WitnessEnabled = p.witnessEnabled
 	pd.WireEncoding = p.wireEncoding
@@ -888,6 +929,43 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// WantsAddrV2 returns if the peer wants addresses relayed with addrv2 messages
+// (BIP0155) instead of addr messages.
+//
+// This function is safe for concurrent access.
+func (p *Peer) WantsAddrV2() bool {
+	p.flagsMtx.Lock()
+	sendAddrV2 := p.sendAddrV2
+	p.flagsMtx.Unlock()
+
+	return sendAddrV2
+}
+
+// SupportsCmpctBlocks returns if the peer has signaled support for the
+// version of compact block relay (BIP0152) implemented by the wire package.
+//
+// This function is safe for concurrent access.
+func (p *Peer) SupportsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	cmpctBlocks := p.cmpctBlocks
+	p.flagsMtx.Unlock()
+
+	return cmpctBlocks
+}
+
+// WantsCmpctBlocks returns if the peer wants new blocks to be announced with
+// cmpctblock messages instead of inventory vectors or headers, which is known
+// as high bandwidth mode.
+//
+// This function is safe for concurrent access.
+func (p *Peer) WantsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	announce := p.cmpctBlocks && p.cmpctBlocksAnnounce
+	p.flagsMtx.Unlock()
+
+	return announce
+}
+
 // IsWitnessEnabled returns true if the peer has signaled that it supports
 // segregated witness.
 //
@@ -907,33 +985,49 @@ func (p *Peer) IsWitnessEnabled() bool {
 // are too many.  It returns the addresses that were actually sent and no
 // message will be sent if there are no entries in the provided addresses slice.
 //
+// An addrv2 message is sent instead when the peer has signaled support for it,
+// otherwise addresses which cannot be represented in an addr message, such as
+// Tor v3 addresses, are left out.
+//
 // This function is safe for concurrent access.
 func (p *Peer) PushAddrMsg(addresses []*wire.NetAddress) ([]*wire.NetAddress, er.R) {
-	addressCount := len(addresses)
+	addrV2 := p.WantsAddrV2()
+	addrList := make([]*wire.NetAddress, 0, len(addresses))
+	for _, na := range addresses {
+		if na.IP == nil && !addrV2 {
+			continue
+		}
+		addrList = append(addrList, na)
+	}
+	addressCount := len(addrList)
 
 	// Nothing to send.
 	if addressCount == 0 {
 		return nil, nil
 	}
 
-	msg := wire.NewMsgAddr()
-	msg.AddrList = make([]*wire.NetAddress, addressCount)
-	copy(msg.AddrList, addresses)
-
 	// Randomize the addresses sent if there are more than the maximum allowed.
 	if addressCount > wire.MaxAddrPerMsg {
 		// Shuffle the address list.
 		for i := 0; i < wire.MaxAddrPerMsg; i++ {
 			j := i + rand.Intn(addressCount-i)
-			msg.AddrList[i], msg.AddrList[j] = msg.AddrList[j], msg.AddrList[i]
+			addrList[i], addrList[j] = addrList[j], addrList[i]
 		}
 
 		// Truncate it to the maximum size.
-		msg.AddrList = msg.AddrList[:wire.MaxAddrPerMsg]
+		addrList = addrList[:wire.MaxAddrPerMsg]
 	}
 
-	p.QueueMessage(msg, nil)
-	return msg.AddrList, nil
+	if addrV2 {
+		msg := wire.NewMsgAddrV2()
+		msg.AddrList = addrList
+		p.QueueMessage(msg, nil)
+	} else {
+		msg := wire.NewMsgAddr()
+		msg.AddrList = addrList
+		p.QueueMessage(msg, nil)
+	}
+	return addrList, nil
 }
 
 // PushGetBlocksMsg sends a getblocks message for the provided block locator
@@ -1251,12 +1345,22 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		}
 
 	case wire.CmdGetData:
-		// Expects a block, merkleblock, tx, or notfound message.
+		// Expects a block, cmpctblock, merkleblock, tx, or notfound
+		// message.
 		pendingResponses[wire.CmdBlock] = deadline
+		pendingResponses[wire.CmdCmpctBlock] = deadline
 		pendingResponses[wire.CmdMerkleBlock] = deadline
 		pendingResponses[wire.CmdTx] = deadline
 		pendingResponses[wire.CmdNotFound] = deadline
 
+	case wire.CmdGetBlockTxn:
+		// Expects a blocktxn message.
+		pendingResponses[wire.CmdBlockTxn] = deadline
+
+	case wire.CmdGetAnns:
+		// Expects an anns message.
+		pendingResponses[wire.CmdAnns] = deadline
+
 	case wire.CmdGetHeaders:
 		// Expects a headers message.  Use a longer deadline since it
 		// can take a while for the remote peer to load all of the
@@ -1310,12 +1414,15 @@ out:
 				switch msgCmd := msg.message.Command(); msgCmd {
 				case wire.CmdBlock:
 					fallthrough
+				case wire.CmdCmpctBlock:
+					fallthrough
 				case wire.CmdMerkleBlock:
 					fallthrough
 				case wire.CmdTx:
 					fallthrough
 				case wire.CmdNotFound:
 					delete(pendingResponses, wire.CmdBlock)
+					delete(pendingResponses, wire.CmdCmpctBlock)
 					delete(pendingResponses, wire.CmdMerkleBlock)
 					delete(pendingResponses, wire.CmdTx)
 					delete(pendingResponses, wire.CmdNotFound)
@@ -1497,6 +1604,11 @@ out:
 				p.cfg.Listeners.OnAddr(p, msg)
 			}
 
+		case *wire.MsgAddrV2:
+			if p.cfg.Listeners.OnAddrV2 != nil {
+				p.cfg.Listeners.OnAddrV2(p, msg)
+			}
+
 		case *wire.MsgPing:
 			p.handlePingMsg(msg)
 			if p.cfg.Listeners.OnPing != nil {
@@ -1618,6 +1730,68 @@ out:
 				p.cfg.Listeners.OnSendHeaders(p, msg)
 			}
 
+		case *wire.MsgSendAddrV2:
+			// BIP0155 requires the message to be sent before the verack so
+			// it is ignored once the verack has been received.
+			p.flagsMtx.Lock()
+			if p.verAckReceived {
+				p.flagsMtx.Unlock()
+				log.Debugf("Ignoring 'sendaddrv2' message received "+
+					"after verack from peer %v", p)
+				break
+			}
+			p.sendAddrV2 = true
+			p.flagsMtx.Unlock()
+
+			if p.cfg.Listeners.OnSendAddrV2 != nil {
+				p.cfg.Listeners.OnSendAddrV2(p, msg)
+			}
+
+		case *wire.MsgSendCmpct:
+			// Only the version implemented by the wire package is
+			// supported, other versions are ignored as required by
+			// BIP0152.
+			if msg.Version == wire.CmpctBlockVersion {
+				p.flagsMtx.Lock()
+				p.cmpctBlocks = true
+				p.cmpctBlocksAnnounce = msg.Announce
+				p.flagsMtx.Unlock()
+			}
+
+			if p.cfg.Listeners.OnSendCmpct != nil {
+				p.cfg.Listeners.OnSendCmpct(p, msg)
+			}
+
+		case *wire.MsgCmpctBlock:
+			if p.cfg.Listeners.OnCmpctBlock != nil {
+				p.cfg.Listeners.OnCmpctBlock(p, msg)
+			}
+
+		case *wire.MsgGetBlockTxn:
+			if p.cfg.Listeners.OnGetBlockTxn != nil {
+				p.cfg.Listeners.OnGetBlockTxn(p, msg)
+			}
+
+		case *wire.MsgBlockTxn:
+			if p.cfg.Listeners.OnBlockTxn != nil {
+				p.cfg.Listeners.OnBlockTxn(p, msg)
+			}
+
+		case *wire.MsgAnnInv:
+			if p.cfg.Listeners.OnAnnInv != nil {
+				p.cfg.Listeners.OnAnnInv(p, msg)
+			}
+
+		case *wire.MsgGetAnns:
+			if p.cfg.Listeners.OnGetAnns != nil {
+				p.cfg.Listeners.OnGetAnns(p, msg)
+			}
+
+		case *wire.MsgAnns:
+			if p.cfg.Listeners.OnAnns != nil {
+				p.cfg.Listeners.OnAnns(p, msg)
+			}
+
 		default:
 			log.Debugf("Received unhandled message of type %v "+
 				"from %v", rmsg.Command(), p)
@@ -2185,6 +2359,12 @@ func (p *Peer) start() er.R {
 	go p.outHandler()
 	go p.pingHandler()
 
+	// Signal support for addrv2 messages, which must happen before the verack
+	// is sent, when the negotiated protocol version allows it.
+	if p.ProtocolVersion() >= protocol.AddrV2Version {
+		p.QueueMessage(wire.NewMsgSendAddrV2(), nil)
+	}
+
 	// Send our verack message now that the IO processing machinery has started.
 	p.QueueMessage(wire.NewMsgVerAck(), nil)
 	return nil
@@ -337,6 +337,9 @@ func TestPeerListeners(t *testing.T) {
 			OnAddr: func(p *peer.Peer, msg *wire.MsgAddr) {
 				ok <- msg
 			},
+			OnAddrV2: func(p *peer.Peer, msg *wire.MsgAddrV2) {
+				ok <- msg
+			},
 			OnPing: func(p *peer.Peer, msg *wire.MsgPing) {
 				ok <- msg
 			},
@@ -413,6 +416,27 @@ func TestPeerListeners(t *testing.T) {
 			OnSendHeaders: func(p *peer.Peer, msg *wire.MsgSendHeaders) {
 				ok <- msg
 			},
+			OnSendCmpct: func(p *peer.Peer, msg *wire.MsgSendCmpct) {
+				ok <- msg
+			},
+			OnCmpctBlock: func(p *peer.Peer, msg *wire.MsgCmpctBlock) {
+				ok <- msg
+			},
+			OnGetBlockTxn: func(p *peer.Peer, msg *wire.MsgGetBlockTxn) {
+				ok <- msg
+			},
+			OnBlockTxn: func(p *peer.Peer, msg *wire.MsgBlockTxn) {
+				ok <- msg
+			},
+			OnAnnInv: func(p *peer.Peer, msg *wire.MsgAnnInv) {
+				ok <- msg
+			},
+			OnGetAnns: func(p *peer.Peer, msg *wire.MsgGetAnns) {
+				ok <- msg
+			},
+			OnAnns: func(p *peer.Peer, msg *wire.MsgAnns) {
+				ok <- msg
+			},
 		},
 		UserAgentName:     "peer",
 		UserAgentVersion:  "1.0",
@@ -449,6 +473,13 @@ func TestPeerListeners(t *testing.T) {
 		}
 	}
 
+	// Both peers support addrv2 so the sendaddrv2 message must have been
+	// received before the verack.
+	if !inPeer.WantsAddrV2() {
+		t.Errorf("TestPeerListeners: sendaddrv2 not received")
+		return
+	}
+
 	tests := []struct {
 		listener string
 		msg      wire.Message
@@ -461,6 +492,10 @@ func TestPeerListeners(t *testing.T) {
 			"OnAddr",
 			wire.NewMsgAddr(),
 		},
+		{
+			"OnAddrV2",
+			wire.NewMsgAddrV2(),
+		},
 		{
 			"OnPing",
 			wire.NewMsgPing(42),
@@ -558,6 +593,34 @@ func TestPeerListeners(t *testing.T) {
 			"OnSendHeaders",
 			wire.NewMsgSendHeaders(),
 		},
+		{
+			"OnSendCmpct",
+			wire.NewMsgSendCmpct(true, wire.CmpctBlockVersion),
+		},
+		{
+			"OnCmpctBlock",
+			wire.NewMsgCmpctBlock(wire.NewMsgBlock(&wire.BlockHeader{}), 1),
+		},
+		{
+			"OnGetBlockTxn",
+			wire.NewMsgGetBlockTxn(&chainhash.Hash{}, []uint32{1}),
+		},
+		{
+			"OnBlockTxn",
+			wire.NewMsgBlockTxn(&chainhash.Hash{}, nil),
+		},
+		{
+			"OnAnnInv",
+			wire.NewMsgAnnInv([]chainhash.Hash{{}}),
+		},
+		{
+			"OnGetAnns",
+			wire.NewMsgGetAnns([]chainhash.Hash{{}}),
+		},
+		{
+			"OnAnns",
+			wire.NewMsgAnns(),
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for _, test := range tests {
@@ -570,10 +633,94 @@ func TestPeerListeners(t *testing.T) {
 			return
 		}
 	}
+
+	// The sendcmpct message requested high bandwidth mode.
+	if !inPeer.WantsCmpctBlocks() {
+		t.Errorf("TestPeerListeners: sendcmpct not applied")
+	}
 	inPeer.Disconnect()
 	outPeer.Disconnect()
 }
 
+// TestAddrV2Negotiation ensures sendaddrv2 is only exchanged, and addresses
+// only relayed with addrv2 messages, when both peers support AddrV2Version.
+func TestAddrV2Negotiation(t *testing.T) {
+	if protocol.ProtocolVersion < protocol.AddrV2Version ||
+		peer.MaxProtocolVersion < protocol.AddrV2Version {
+
+		t.Fatalf("protocol version %d does not support addrv2",
+			protocol.ProtocolVersion)
+	}
+
+	tests := []struct {
+		name         string
+		inVersion    uint32
+		outVersion   uint32
+		wantAddrV2   bool
+		wantProtocol uint32
+	}{
+		{"default", 0, 0, true, peer.MaxProtocolVersion},
+		{"addrv2", protocol.AddrV2Version, protocol.AddrV2Version, true,
+			protocol.AddrV2Version},
+		{"old inbound", protocol.ShortIDsBlocksVersion, 0, false,
+			protocol.ShortIDsBlocksVersion},
+		{"old outbound", 0, protocol.FeeFilterVersion, false,
+			protocol.FeeFilterVersion},
+	}
+	for _, test := range tests {
+		verack := make(chan struct{}, 2)
+		listeners := peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+		}
+		inConn, outConn := pipe(
+			&conn{raddr: "10.0.0.1:8333"},
+			&conn{raddr: "10.0.0.2:8333"},
+		)
+		inPeer := peer.NewInboundPeer(&peer.Config{
+			Listeners:       listeners,
+			ChainParams:     &chaincfg.MainNetParams,
+			ProtocolVersion: test.inVersion,
+			TrickleInterval: time.Second * 1,
+		})
+		inPeer.AssociateConnection(inConn)
+		outPeer, err := peer.NewOutboundPeer(&peer.Config{
+			Listeners:       listeners,
+			ChainParams:     &chaincfg.MainNetParams,
+			ProtocolVersion: test.outVersion,
+			TrickleInterval: time.Second * 1,
+		}, "10.0.0.2:8333")
+		if err != nil {
+			t.Fatalf("%s: NewOutboundPeer: unexpected err %v", test.name, err)
+		}
+		outPeer.AssociateConnection(outConn)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-verack:
+			case <-time.After(time.Second * 1):
+				t.Fatalf("%s: verack timeout", test.name)
+			}
+		}
+		for _, p := range []*peer.Peer{inPeer, outPeer} {
+			if p.ProtocolVersion() != test.wantProtocol {
+				t.Errorf("%s: negotiated protocol version %d, want %d",
+					test.name, p.ProtocolVersion(), test.wantProtocol)
+			}
+			if p.WantsAddrV2() != test.wantAddrV2 {
+				t.Errorf("%s: WantsAddrV2 is %v, want %v", test.name,
+					p.WantsAddrV2(), test.wantAddrV2)
+			}
+		}
+
+		inPeer.Disconnect()
+		outPeer.Disconnect()
+		inPeer.WaitForDisconnect()
+		outPeer.WaitForDisconnect()
+	}
+}
+
 // TestOutboundPeer tests that the outbound peer works as expected.
 func TestOutboundPeer(t *testing.T) {
 
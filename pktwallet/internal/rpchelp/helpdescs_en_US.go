@@ -22,7 +22,7 @@ var helpDescsEnUS = map[string]string{
 	"addp2shscript--result0":  "The address corrisponding to this script",
 
 	// CreateTransactionCmd help.
-	"createtransaction--synopsis":      "Create a transaction but do not send it to the chain",
+	"createtransaction--synopsis":      "Create a transaction but do not send it to the chain, if it spends coins of a watch-only account, because all of the fromaddresses are watch-only or the other coins are not enough, then the result is an unsigned PSBT",
 	"createtransaction-vote":           "True if you wish for this transaction to contain a network steward vote",
 	"createtransaction-minconf":        "Do not spend any outputs which don't have at least this number of confirmations (default 1)",
 	"createtransaction-changeaddress":  "Return extra coins to this address, if unspecified then one will be created",
@@ -33,7 +33,28 @@ var helpDescsEnUS = map[string]string{
 	"createtransaction-inputminheight": "The minimum block height to take inputs from (default: 0)",
 	"createtransaction-maxinputs":      "Maximum number of transaction inputs that are allowed",
 	"createtransaction-autolock":       "If specified, all txouts spent for this transaction will be locked under this name",
-	"createtransaction--result0":       "The hex encoded transaction result",
+	"createtransaction--result0":       "The hex encoded transaction result, or the base64 encoded PSBT when spending from a watch-only account",
+
+	// FoldAddressCmd help.
+	"foldaddress--synopsis":          "Sweep many small outputs into one address, making as many transactions as needed with as many inputs as a transaction can hold",
+	"foldaddress-toaddress":          "The address to send the folded coins to",
+	"foldaddress-fromaddresses":      "Addresses whose outputs are folded, if unspecified then outputs of the whole wallet are folded",
+	"foldaddress-maxamount":          "Only fold outputs which are worth less than this amount, if unspecified then outputs of any value are folded",
+	"foldaddress-minconf":            "Do not fold any outputs which don't have at least this number of confirmations (default 1)",
+	"foldaddress-maxinputs":          "Maximum number of inputs in each transaction",
+	"foldaddress-maxtransactions":    "Maximum number of transactions to make, if unspecified then folding continues until there is nothing left to fold",
+	"foldaddress-maxfee":             "Maximum total fee to pay for all transactions",
+	"foldaddress-dryrun":             "If true then the transactions are made but they are neither signed nor sent",
+	"foldaddressresult-transactions": "The transactions which were made",
+	"foldaddressresult-inputs":       "The total number of outputs which were folded",
+	"foldaddressresult-amount":       "The total amount of coins received by the address",
+	"foldaddressresult-fee":          "The total fee of the transactions",
+	"foldaddressresult-stopreason":   "Why folding stopped, empty if the maximum number of transactions was reached",
+	"foldaddresstx-txid":             "The hash of the transaction",
+	"foldaddresstx-inputs":           "The number of outputs folded by the transaction",
+	"foldaddresstx-amount":           "The amount of coins received by the address",
+	"foldaddresstx-fee":              "The fee of the transaction",
+	"foldaddresstx-hex":              "The hex encoded unsigned transaction, only in a dry run",
 
 	// GetAddressBalancesCmd help.
 	"getaddressbalances--synopsis":             "Get balances for each address",
@@ -184,6 +205,44 @@ var helpDescsEnUS = map[string]string{
 	"importprivkey-rescan":    "Rescan the blockchain (since the genesis block) for outputs controlled by the imported key",
 	"importprivkey-legacy":    "If true then import as a legacy address, otherwise segwit",
 
+	// ImportDescriptorsCmd help.
+	"importdescriptors--synopsis": "Imports the addresses of output descriptors as watch-only addresses. The supported descriptors are pkh(KEY), wpkh(KEY), sh(wpkh(KEY)), sh(MULTI), wsh(MULTI) and sh(wsh(MULTI)), where MULTI is multi(k,KEY,...) or sortedmulti(k,KEY,...) and KEY is a public key or an extended public key, which may end with /* to describe a range of addresses. Descriptors of scripts can only be imported while the wallet is unlocked.",
+	"importdescriptors-requests":  "The descriptors to import",
+	"importdescriptors-rescan":    "Resync the blockchain for payments to the imported addresses, starting at the lowest rescanfrom of the requests",
+	"importdescriptors--result0":  "The outcome of the import of each descriptor",
+
+	// ImportDescriptorsRequest help.
+	"importdescriptorsrequest-desc":       "The output descriptor, optionally followed by its checksum",
+	"importdescriptorsrequest-range":      "The indexes of the addresses imported from a ranged descriptor, [end] or [begin, end] (default=[0, 999])",
+	"importdescriptorsrequest-rescanfrom": "The block height to start the resync from (default=0)",
+
+	// ImportDescriptorsResult help.
+	"importdescriptorsresult-success": "Whether the addresses of the descriptor were imported",
+	"importdescriptorsresult-desc":    "The descriptor followed by its checksum",
+	"importdescriptorsresult-error":   "The reason why the descriptor could not be imported",
+
+	// ListDescriptorsCmd help.
+	"listdescriptors--synopsis": "Returns the output descriptors of the accounts of the wallet and the imported descriptors. No private keys are returned.",
+	"listdescriptors--result0":  "The descriptors of the wallet",
+
+	// ListDescriptorsResult help.
+	"listdescriptorsresult-desc":     "The descriptor followed by its checksum",
+	"listdescriptorsresult-active":   "Whether the wallet derives new addresses from the descriptor, false for imported descriptors",
+	"listdescriptorsresult-account":  "The account of an active descriptor",
+	"listdescriptorsresult-internal": "Whether an active descriptor describes change addresses",
+	"listdescriptorsresult-range":    "The indexes of the imported addresses of a ranged imported descriptor, [begin, end]",
+	"listdescriptorsresult-next":     "The index of the next address of an active descriptor",
+
+	// ImportXpubCmd help.
+	"importxpub--synopsis":         "Imports an account extended public key as a watch-only account. An xpub derives legacy addresses, a ypub derives p2sh-segwit addresses and a zpub derives segwit addresses.",
+	"importxpub-xpub":              "The account extended public key (xpub, ypub or zpub)",
+	"importxpub-accountname":       "The name of the new account",
+	"importxpub-rescan":            "Resync the blockchain for payments to the account, discovering its addresses as they are used",
+	"importxpub-rescanfrom":        "The block height to start the resync from",
+	"importxpubresult-account":     "The number of the new account",
+	"importxpubresult-accountname": "The name of the new account",
+	"importxpubresult-keyscope":    "The key scope of the new account",
+
 	// ListLockUnspentCmd help.
 	"listlockunspent--synopsis": "Returns a JSON array of outpoints marked as locked (with lockunspent) for this wallet session.",
 
@@ -374,6 +433,119 @@ var helpDescsEnUS = map[string]string{
 	"validateaddresswalletresult-sigsrequired": "The number of required signatures to redeem outputs to the multisig address",
 
 	// VerifyMessageCmd help.
+	// AnalyzePsbtCmd help.
+	"analyzepsbt--synopsis":               "Tells which role of BIP0174 can make progress on each input of a PSBT and on the PSBT as a whole, along with the fee and the estimated size of the transaction when they are known",
+	"analyzepsbt-psbt":                    "The base64 encoded PSBT",
+	"analyzepsbtresult-inputs":            "The state of each input",
+	"analyzepsbtresult-estimated_vsize":   "The estimated virtual size of the signed transaction, if it can be estimated",
+	"analyzepsbtresult-estimated_feerate": "The estimated fee rate of the signed transaction in coins per kilobyte, if the size can be estimated",
+	"analyzepsbtresult-fee":               "The fee paid by the transaction, if the outputs spent by all of its inputs are known",
+	"analyzepsbtresult-next":              "The role which can make progress on the PSBT (updater, signer, finalizer or extractor)",
+	"analyzepsbtinput-has_utxo":           "Whether the output spent by the input is known",
+	"analyzepsbtinput-is_final":           "Whether the input is finalized",
+	"analyzepsbtinput-missing":            "What the input needs before it can be finalized",
+	"analyzepsbtinput-next":               "The role which can make progress on the input",
+	"analyzepsbtmissing-signatures":       "The hash160 of the public keys whose signatures are missing",
+	"analyzepsbtmissing-redeemscript":     "The hash160 of the missing redeem script",
+	"analyzepsbtmissing-witnessscript":    "The sha256 of the missing witness script",
+
+	// BumpFeeCmd help.
+	"bumpfee--synopsis":     "Speeds up the confirmation of an unmined transaction sent by the wallet. If the transaction signals replaceability (BIP0125) it is replaced by a transaction which pays the same outputs with a higher fee, adding inputs of the wallet if needed, otherwise a child transaction spending its change back to the wallet pays for it.",
+	"bumpfee-txid":          "The hash of the transaction",
+	"bumpfee-options":       "Options of the fee bump",
+	"bumpfeeopts-feerate":   "The fee rate in coins per kilobyte (default: the fee rate of the transaction plus the minimum relay fee)",
+	"bumpfeeresult-txid":    "The hash of the replacement or of the child transaction",
+	"bumpfeeresult-origfee": "The fee paid by the original transaction in coins",
+	"bumpfeeresult-fee":     "The fee paid by the replacement or by the child transaction in coins",
+	"bumpfeeresult-method":  "How the fee was bumped, \"replace\" or \"cpfp\" (child pays for parent)",
+
+	// CombinePsbtCmd help.
+	"combinepsbt--synopsis": "Merges several PSBTs of the same transaction, such as the PSBTs signed by each of the signers of a multisig input, into one PSBT",
+	"combinepsbt-txs":       "The base64 encoded PSBTs to combine",
+	"combinepsbt--result0":  "The base64 encoded combined PSBT",
+
+	// DecodePsbtCmd help.
+	"decodepsbt--synopsis":                      "Returns a JSON object representing a base64 encoded PSBT",
+	"decodepsbt-psbt":                           "The base64 encoded PSBT",
+	"decodepsbtresult-tx":                       "The unsigned transaction of the PSBT",
+	"decodepsbtresult-unknown":                  "The unknown global key-value pairs",
+	"decodepsbtresult-unknown--key":             "key",
+	"decodepsbtresult-unknown--value":           "value",
+	"decodepsbtresult-unknown--desc":            "The hex encoded key and value of each unknown pair",
+	"decodepsbtresult-inputs":                   "The inputs of the PSBT",
+	"decodepsbtresult-outputs":                  "The outputs of the PSBT",
+	"decodepsbtresult-fee":                      "The fee paid by the transaction, if the outputs spent by all of its inputs are known",
+	"decodepsbtinput-non_witness_utxo":          "The output spent by the input, taken from the transaction which made it",
+	"decodepsbtinput-witness_utxo":              "The output spent by the input",
+	"decodepsbtinput-partial_signatures":        "The signatures of the input",
+	"decodepsbtinput-partial_signatures--key":   "pubkey",
+	"decodepsbtinput-partial_signatures--value": "signature",
+	"decodepsbtinput-partial_signatures--desc":  "The hex encoded public key and signature of each signer",
+	"decodepsbtinput-sighash":                   "The signature hash type which signers must use",
+	"decodepsbtinput-redeem_script":             "The hex encoded redeem script",
+	"decodepsbtinput-witness_script":            "The hex encoded witness script",
+	"decodepsbtinput-bip32_derivs":              "The BIP0032 derivations of the keys of the input",
+	"decodepsbtinput-final_scriptSig":           "The hex encoded final signature script",
+	"decodepsbtinput-final_scriptwitness":       "The hex encoded items of the final witness",
+	"decodepsbtinput-unknown":                   "The unknown key-value pairs of the input",
+	"decodepsbtinput-unknown--key":              "key",
+	"decodepsbtinput-unknown--value":            "value",
+	"decodepsbtinput-unknown--desc":             "The hex encoded key and value of each unknown pair",
+	"decodepsbtoutput-redeem_script":            "The hex encoded redeem script",
+	"decodepsbtoutput-witness_script":           "The hex encoded witness script",
+	"decodepsbtoutput-bip32_derivs":             "The BIP0032 derivations of the keys of the output",
+	"psbtutxo-amount":                           "The amount in coins",
+	"psbtutxo-scriptPubKey":                     "The hex encoded public key script",
+	"psbtutxo-address":                          "The address paid to",
+	"psbtbip32deriv-pubkey":                     "The hex encoded public key",
+	"psbtbip32deriv-master_fingerprint":         "The fingerprint of the master key",
+	"psbtbip32deriv-path":                       "The derivation path of the key",
+
+	// ScriptSig help.
+	"scriptsig-asm": "Disassembly of the script",
+	"scriptsig-hex": "Hex-encoded bytes of the script",
+
+	// PrevOut help.
+	"prevout-value":   "previous output value",
+	"prevout-svalue":  "previous output value in atomic units, string containing base 10 number",
+	"prevout-address": "The address which this transaction is spending from",
+
+	// VinPrevOut help.
+	"vinprevout-coinbase":    "The hex-encoded bytes of the signature script (coinbase txns only)",
+	"vinprevout-txid":        "The hash of the origin transaction (non-coinbase txns only)",
+	"vinprevout-vout":        "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
+	"vinprevout-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
+	"vinprevout-txinwitness": "The witness stack of the passed input, encoded as a JSON string array",
+	"vinprevout-prevOut":     "Data from the origin transaction output with index vout.",
+	"vinprevout-sequence":    "The script sequence number",
+
+	"vote-for":     "The network steward which this payment is voting for",
+	"vote-against": "The network steward address which this payment is voting against",
+
+	// Vout help.
+	"vout-value":   "The amount in coins",
+	"vout-svalue":  "previous output value in atomic units, string containing base 10 number",
+	"vout-n":       "The index of this transaction output",
+	"vout-address": "The address paid to",
+	"vout-vote":    "A vote on network steward, if any exists",
+
+	// TxRawDecodeResult help.
+	"txrawdecoderesult-txid":     "The hash of the transaction",
+	"txrawdecoderesult-version":  "The transaction version",
+	"txrawdecoderesult-locktime": "The transaction lock time",
+	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
+	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
+	"txrawdecoderesult-vsize":    "The virtual size of the transaction, offering a discount for segwit data",
+	"txrawdecoderesult-size":     "The full size of the transaction, including segwit data",
+	"txrawdecoderesult-sfee":     "Number of atomic units of fees, base 10 string",
+
+	// FinalizePsbtCmd help.
+	"finalizepsbt--synopsis":      "Makes the final signature scripts and witnesses of the inputs of a PSBT out of their signatures, and extracts the signed transaction if every input is finalized. No signatures are added, use walletprocesspsbt to sign.",
+	"finalizepsbt-psbt":           "The base64 encoded PSBT",
+	"finalizepsbt-extract":        "If true and the PSBT is complete then the hex encoded transaction is returned instead of the PSBT",
+	"finalizepsbtresult-psbt":     "The base64 encoded PSBT, if the transaction was not extracted",
+	"finalizepsbtresult-hex":      "The hex encoded signed transaction, if it was extracted",
+	"finalizepsbtresult-complete": "Whether every input is finalized",
 	"verifymessage--synopsis": "Verify a message was signed with the associated private key of some address.",
 	"verifymessage-address":   "Address used to sign message",
 	"verifymessage-signature": "The signature to verify",
@@ -393,6 +565,30 @@ var helpDescsEnUS = map[string]string{
 	"walletpassphrasechange-oldpassphrase": "The old wallet passphrase",
 	"walletpassphrasechange-newpassphrase": "The new wallet passphrase",
 
+	// WalletCreateFundedPsbtCmd help.
+	"walletcreatefundedpsbt--synopsis":       "Creates a PSBT which pays to the outputs, adding inputs of the wallet when no inputs are given and a change output as needed to pay them and the fee. The PSBT is not signed, use walletprocesspsbt to sign it.",
+	"walletcreatefundedpsbt-inputs":          "The inputs which the transaction spends, if any are given then no other inputs are added and they must be enough to pay the outputs and the fee",
+	"walletcreatefundedpsbt-outputs":         "Pairs of payment addresses and the output amount to pay each",
+	"walletcreatefundedpsbt-outputs--desc":   "JSON object using payment addresses as keys and output amounts valued in coins to send to each address",
+	"walletcreatefundedpsbt-outputs--key":    "Address to pay",
+	"walletcreatefundedpsbt-outputs--value":  "Amount to send to the payment address valued in coins",
+	"walletcreatefundedpsbt-locktime":        "The lock time of the transaction",
+	"walletcreatefundedpsbt-options":         "Options of the funding",
+	"walletcreatefundedpsbtopts-feerate":     "The fee rate in coins per kilobyte (default: the minimum relay fee)",
+	"walletcreatefundedpsbtopts-account":     "The account which receives the change, it can only be set when inputs are given (default=0)",
+	"psbtinput-txid":                         "The hash of the transaction which made the output",
+	"psbtinput-vout":                         "The index of the output",
+	"psbtinput-sequence":                     "The sequence number of the input",
+	"walletcreatefundedpsbtresult-psbt":      "The base64 encoded PSBT",
+	"walletcreatefundedpsbtresult-fee":       "The fee paid by the transaction in coins",
+	"walletcreatefundedpsbtresult-changepos": "The index of the change output, or -1 if there is none",
+
+	// WalletProcessPsbtCmd help.
+	"walletprocesspsbt--synopsis":      "Attaches the outputs spent by the inputs of a PSBT which belong to the wallet along with their scripts and key derivations, signs those inputs and finalizes every input which can be finalized",
+	"walletprocesspsbt-psbt":           "The base64 encoded PSBT",
+	"walletprocesspsbt-sign":           "Whether to sign the inputs of the wallet, which requires the wallet to be unlocked",
+	"walletprocesspsbtresult-psbt":     "The base64 encoded PSBT",
+	"walletprocesspsbtresult-complete": "Whether every input is finalized",
 	// WalletMempoolCmd help.
 	"walletmempool--synopsis":    "Show the unconfirmed transactions which are being broadcasted by the wallet",
 	"walletmempoolitem-received": "The time when the transaction was first seen/made",
@@ -23,8 +23,14 @@ var Methods = []struct {
 	ResultTypes []interface{}
 }{
 	{"addmultisigaddress", returnsString},
+	{"analyzepsbt", []interface{}{(*btcjson.AnalyzePsbtResult)(nil)}},
+	{"bumpfee", []interface{}{(*btcjson.BumpFeeResult)(nil)}},
+	{"combinepsbt", returnsString},
 	{"createmultisig", []interface{}{(*btcjson.CreateMultiSigResult)(nil)}},
 	{"createtransaction", returnsString},
+	{"decodepsbt", []interface{}{(*btcjson.DecodePsbtResult)(nil)}},
+	{"finalizepsbt", []interface{}{(*btcjson.FinalizePsbtResult)(nil)}},
+	{"foldaddress", []interface{}{(*btcjson.FoldAddressResult)(nil)}},
 	{"getaddressbalances", []interface{}{(*[]btcjson.GetAddressBalancesResult)(nil)}},
 	{"setnetworkstewardvote", []interface{}{(*btcjson.SetNetworkStewardVoteResult)(nil)}},
 	{"getnetworkstewardvote", []interface{}{(*btcjson.GetNetworkStewardVoteResult)(nil)}},
@@ -43,6 +49,9 @@ var Methods = []struct {
 	{"getsecret", returnsString},
 	{"help", append(returnsString, returnsString[0])},
 	{"importprivkey", nil},
+	{"importdescriptors", []interface{}{(*[]btcjson.ImportDescriptorsResult)(nil)}},
+	{"importxpub", []interface{}{(*btcjson.ImportXpubResult)(nil)}},
+	{"listdescriptors", []interface{}{(*[]btcjson.ListDescriptorsResult)(nil)}},
 	{"listlockunspent", []interface{}{(*[]btcjson.TransactionInput)(nil)}},
 	{"listreceivedbyaddress", []interface{}{(*[]btcjson.ListReceivedByAddressResult)(nil)}},
 	{"listsinceblock", []interface{}{(*btcjson.ListSinceBlockResult)(nil)}},
@@ -57,9 +66,11 @@ var Methods = []struct {
 	{"signrawtransaction", []interface{}{(*btcjson.SignRawTransactionResult)(nil)}},
 	{"validateaddress", []interface{}{(*btcjson.ValidateAddressWalletResult)(nil)}},
 	{"verifymessage", returnsBool},
+	{"walletcreatefundedpsbt", []interface{}{(*btcjson.WalletCreateFundedPsbtResult)(nil)}},
 	{"walletlock", nil},
 	{"walletpassphrase", nil},
 	{"walletpassphrasechange", nil},
+	{"walletprocesspsbt", []interface{}{(*btcjson.WalletProcessPsbtResult)(nil)}},
 	{"walletmempool", []interface{}{(*btcjson.WalletMempoolRes)(nil)}},
 	{"exportwatchingwallet", returnsString},
 	{"getbestblock", []interface{}{(*btcjson.GetBestBlockResult)(nil)}},
@@ -8,10 +8,12 @@ package legacyrpc
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +29,8 @@ import (
 	"github.com/pkt-cash/pktd/btcec"
 	"github.com/pkt-cash/pktd/btcjson"
 	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/btcutil/psbt"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/pktwallet/chain"
@@ -38,6 +42,7 @@ import (
 	"github.com/pkt-cash/pktd/rpcclient"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
 )
 
 // confirms returns the number of confirmations for a transaction in a block at
@@ -84,8 +89,13 @@ var rpcHandlers = map[string]struct {
 }{
 	// Reference implementation wallet methods (implemented)
 	"addmultisigaddress":     {handler: addMultiSigAddress},
+	"analyzepsbt":            {handler: analyzePsbt},
+	"bumpfee":                {handler: bumpFee},
+	"combinepsbt":            {handler: combinePsbt},
 	"createmultisig":         {handler: createMultiSig},
+	"decodepsbt":             {handler: decodePsbt},
 	"dumpprivkey":            {handler: dumpPrivKey},
+	"finalizepsbt":           {handler: finalizePsbt},
 	"getbalance":             {handler: getBalance},
 	"getbestblockhash":       {handler: getBestBlockHash},
 	"getblockcount":          {handler: getBlockCount},
@@ -95,6 +105,9 @@ var rpcHandlers = map[string]struct {
 	"gettransaction":         {handler: getTransaction},
 	"help":                   {handler: helpNoChainRPC, handlerRPC: helpWithChainRPC},
 	"importprivkey":          {handler: importPrivKey},
+	"importdescriptors":      {handler: importDescriptors},
+	"importxpub":             {handler: importXpub},
+	"listdescriptors":        {handler: listDescriptors},
 	"listlockunspent":        {handler: listLockUnspent},
 	"listreceivedbyaddress":  {handler: listReceivedByAddress},
 	"listsinceblock":         {handlerChain: listSinceBlock},
@@ -109,9 +122,11 @@ var rpcHandlers = map[string]struct {
 	"signrawtransaction":     {handlerChain: signRawTransaction},
 	"validateaddress":        {handler: validateAddress},
 	"verifymessage":          {handler: verifyMessage},
+	"walletcreatefundedpsbt": {handler: walletCreateFundedPsbt},
 	"walletlock":             {handler: walletLock},
 	"walletpassphrase":       {handler: walletPassphrase},
 	"walletpassphrasechange": {handler: walletPassphraseChange},
+	"walletprocesspsbt":      {handler: walletProcessPsbt},
 
 	// Extensions to the reference client JSON-RPC API
 	"getbestblock":          {handler: getBestBlock},
@@ -119,6 +134,7 @@ var rpcHandlers = map[string]struct {
 	"getnetworkstewardvote": {handler: getNetworkStewardVote},
 	"addp2shscript":         {handler: addP2shScript},
 	"createtransaction":     {handler: createTransaction},
+	"foldaddress":           {handler: foldAddress},
 	"resync":                {handler: resync},
 	"stopresync":            {handler: stopResync},
 	"getaddressbalances":    {handler: getAddressBalances},
@@ -627,6 +643,111 @@ func importPrivKey(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
 	return addr, err
 }
 
+// importDescriptors handles an importdescriptors request by importing the
+// addresses of output descriptors as watch-only addresses.
+func importDescriptors(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.ImportDescriptorsCmd)
+
+	reqs := make([]wallet.ImportDescriptorReq, 0, len(cmd.Requests))
+	for _, r := range cmd.Requests {
+		start, end := 0, wallet.DefaultDescriptorRangeEnd
+		switch len(r.Range) {
+		case 0:
+		case 1:
+			end = r.Range[0]
+		case 2:
+			start, end = r.Range[0], r.Range[1]
+		default:
+			return nil, btcjson.ErrRPCInvalidParameter.New(
+				"range must be [end] or [begin, end]", nil)
+		}
+		if start < 0 || end < 0 {
+			return nil, btcjson.ErrRPCInvalidParameter.New(
+				"range must not be negative", nil)
+		}
+		req := wallet.ImportDescriptorReq{
+			Descriptor: r.Desc,
+			RangeStart: uint32(start),
+			RangeEnd:   uint32(end),
+		}
+		if r.RescanFrom != nil {
+			req.RescanFrom = int32(*r.RescanFrom)
+		}
+		reqs = append(reqs, req)
+	}
+
+	results, err := w.ImportDescriptors(reqs, *cmd.Rescan)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]btcjson.ImportDescriptorsResult, 0, len(results))
+	for _, r := range results {
+		res := btcjson.ImportDescriptorsResult{
+			Success: r.Err == nil,
+			Desc:    r.Descriptor,
+		}
+		if waddrmgr.ErrLocked.Is(r.Err) {
+			res.Error = "the wallet must be unlocked to import a script"
+		} else if r.Err != nil {
+			res.Error = r.Err.Message()
+		}
+		ret = append(ret, res)
+	}
+	return ret, nil
+}
+
+// listDescriptors handles a listdescriptors request by returning the output
+// descriptors of the accounts of the wallet and the imported descriptors.
+func listDescriptors(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	descs, err := w.ListDescriptors()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]btcjson.ListDescriptorsResult, 0, len(descs))
+	for _, d := range descs {
+		res := btcjson.ListDescriptorsResult{
+			Desc:    d.Descriptor,
+			Active:  d.Active,
+			Account: d.Account,
+		}
+		if d.Active {
+			internal, next := d.Internal, d.Next
+			res.Internal = &internal
+			res.Next = &next
+		} else if d.IsRange {
+			res.Range = []uint32{d.RangeStart, d.RangeEnd}
+		}
+		ret = append(ret, res)
+	}
+	return ret, nil
+}
+
+// importXpub handles an importxpub request by importing an account extended
+// public key as a watch-only account.
+func importXpub(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.ImportXpubCmd)
+
+	scope, props, err := w.ImportAccountPubKey(cmd.AccountName, cmd.Xpub,
+		*cmd.Rescan, int32(*cmd.RescanFrom))
+	switch {
+	case waddrmgr.ErrDuplicateAccount.Is(err), waddrmgr.ErrInvalidAccount.Is(err):
+		return nil, btcjson.ErrRPCWalletInvalidAccountName.New(
+			"invalid account name", err)
+	case waddrmgr.ErrKeyChain.Is(err), waddrmgr.ErrInvalidKeyType.Is(err),
+		waddrmgr.ErrWrongNet.Is(err):
+		return nil, btcjson.ErrRPCInvalidAddressOrKey.New(
+			"invalid extended public key", err)
+	case err != nil:
+		return nil, err
+	}
+
+	return &btcjson.ImportXpubResult{
+		Account:     props.AccountNumber,
+		AccountName: props.AccountName,
+		KeyScope:    scope.String(),
+	}, nil
+}
+
 // getNewAddress handles a getnewaddress request by returning a new
 // address for an account.  If the account does not exist an appropiate
 // error is returned.
@@ -1191,6 +1312,7 @@ func sendOutputs(
 	minconf int32,
 	feeSatPerKb btcutil.Amount,
 	dryRun bool,
+	watchOnly bool,
 	changeAddress *string,
 	inputMinHeight int,
 	maxInputs int,
@@ -1201,6 +1323,7 @@ func sendOutputs(
 		DryRun:         dryRun,
 		InputMinHeight: inputMinHeight,
 		MaxInputs:      maxInputs,
+		WatchOnly:      watchOnly,
 		Label:          "",
 	}
 	if inputMinHeight > 0 {
@@ -1242,7 +1365,7 @@ func sendOutputs(
 		if waddrmgr.ErrLocked.Is(err) {
 			return nil, btcjson.ErrRPCWalletUnlockNeeded.Default()
 		}
-		if btcjson.Err.Is(err) {
+		if btcjson.Err.Is(err) || wallet.InsufficientFundsError.Is(err) {
 			return nil, err
 		}
 		return nil, btcjson.ErrRPCInternal.New("SendOutputs failed", err)
@@ -1261,7 +1384,7 @@ func sendPairs(w *wallet.Wallet, amounts map[string]btcutil.Amount,
 		return "", err
 	}
 
-	tx, err := sendOutputs(w, amounts, vote, fromAddressses, minconf, feeSatPerKb, false, nil, inputMinHeight, maxInputs)
+	tx, err := sendOutputs(w, amounts, vote, fromAddressses, minconf, feeSatPerKb, false, false, nil, inputMinHeight, maxInputs)
 	if err != nil {
 		return "", err
 	}
@@ -1360,11 +1483,41 @@ func createTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
 		maxInputs = *cmd.MaxInputs
 	}
 
+	// The wallet cannot sign for the coins of a watch-only account, so they
+	// are only spent when all of the addresses to spend from are watch-only
+	// or when the other coins cannot pay, and then the result is a PSBT for
+	// the holder of the keys to sign.  A locked wallet is not a reason to
+	// spend them, it needs to be unlocked like for any other transaction.
+	watchOnly := cmd.FromAddresses != nil && len(*cmd.FromAddresses) > 0
+	if cmd.FromAddresses != nil {
+		for _, addrStr := range *cmd.FromAddresses {
+			addr, err := decodeAddress(addrStr, w.ChainParams())
+			if err != nil {
+				return nil, err
+			}
+			wo, err := w.IsWatchOnlyAddress(addr)
+			if err != nil {
+				return nil, err
+			}
+			watchOnly = watchOnly && wo
+		}
+	}
 	tx, err := sendOutputs(w, amounts, vote, cmd.FromAddresses, minconf,
-		feeSatPerKb, true, cmd.ChangeAddress, inputMinHeight, maxInputs)
+		feeSatPerKb, true, watchOnly, cmd.ChangeAddress, inputMinHeight, maxInputs)
+	if !watchOnly && wallet.InsufficientFundsError.Is(err) {
+		woTx, woErr := sendOutputs(w, amounts, vote, cmd.FromAddresses, minconf,
+			feeSatPerKb, true, true, cmd.ChangeAddress, inputMinHeight, maxInputs)
+		if woErr == nil {
+			tx, err = woTx, nil
+		}
+	}
 	if err != nil {
 		return "", err
 	}
+	watchOnly, err = w.SpendsWatchOnly(tx.Tx)
+	if err != nil {
+		return nil, err
+	}
 
 	if cmd.AutoLock != nil {
 		for _, in := range tx.Tx.TxIn {
@@ -1381,6 +1534,14 @@ func createTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
 		return hex.EncodeToString(b.Bytes()), nil
 	}
 
+	if watchOnly {
+		packet, err := w.UnsignedPsbt(tx.Tx)
+		if err != nil {
+			return nil, err
+		}
+		return packet.B64Encode()
+	}
+
 	b := bytes.NewBuffer(make([]byte, 0, tx.Tx.SerializeSize()))
 	if err := tx.Tx.Serialize(b); err != nil {
 		return nil, err
@@ -1388,6 +1549,95 @@ func createTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
 	return hex.EncodeToString(b.Bytes()), nil
 }
 
+// foldAddress handles a foldaddress RPC request by sweeping the small unspent
+// outputs of some addresses, or of the whole wallet, to one address in as few
+// transactions as possible.
+func foldAddress(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.FoldAddressCmd)
+
+	req := wallet.FoldCoinsReq{
+		Minconf:     int32(*cmd.MinConf),
+		FeeSatPerKB: txrules.DefaultRelayFeePerKb,
+		DryRun:      *cmd.DryRun,
+	}
+	if req.Minconf < 0 {
+		return nil, errNeedPositiveMinconf()
+	}
+	var err er.R
+	req.ToAddress, err = decodeAddress(cmd.ToAddress, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+	if cmd.FromAddresses != nil {
+		addrs := make([]btcutil.Address, 0, len(*cmd.FromAddresses))
+		for _, addrStr := range *cmd.FromAddresses {
+			addr, err := decodeAddress(addrStr, w.ChainParams())
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, addr)
+		}
+		req.FromAddresses = &addrs
+	}
+	if cmd.MaxAmount != nil {
+		if *cmd.MaxAmount < 0 {
+			return nil, errNeedPositiveAmount()
+		}
+		if req.MaxAmount, err = btcutil.NewAmount(*cmd.MaxAmount); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.MaxFee != nil {
+		if *cmd.MaxFee < 0 {
+			return nil, errNeedPositiveAmount()
+		}
+		if req.MaxFee, err = btcutil.NewAmount(*cmd.MaxFee); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.MaxInputs != nil {
+		req.MaxInputs = *cmd.MaxInputs
+	}
+	if cmd.MaxTransactions != nil {
+		req.MaxTransactions = *cmd.MaxTransactions
+	}
+
+	res, err := w.FoldCoins(req)
+	if err != nil {
+		if waddrmgr.ErrLocked.Is(err) {
+			return nil, btcjson.ErrRPCWalletUnlockNeeded.Default()
+		}
+		return nil, btcjson.ErrRPCInternal.New("FoldCoins failed", err)
+	}
+
+	out := btcjson.FoldAddressResult{
+		Transactions: make([]btcjson.FoldAddressTx, 0, len(res.Transactions)),
+		Inputs:       res.Inputs,
+		Amount:       res.Amount.ToBTC(),
+		Fee:          res.Fee.ToBTC(),
+	}
+	for _, ftx := range res.Transactions {
+		tx := btcjson.FoldAddressTx{
+			Txid:   ftx.Tx.TxHash().String(),
+			Inputs: ftx.Inputs,
+			Amount: ftx.Amount.ToBTC(),
+			Fee:    ftx.Fee.ToBTC(),
+		}
+		if req.DryRun {
+			b := bytes.NewBuffer(make([]byte, 0, ftx.Tx.SerializeSize()))
+			if err := ftx.Tx.Serialize(b); err != nil {
+				return nil, err
+			}
+			tx.Hex = hex.EncodeToString(b.Bytes())
+		}
+		out.Transactions = append(out.Transactions, tx)
+	}
+	if res.StopReason != nil {
+		out.StopReason = res.StopReason.Message()
+	}
+	return out, nil
+}
+
 func stopResync(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
 	return w.StopResync()
 }
@@ -1878,6 +2128,490 @@ func walletPassphraseChange(icmd interface{}, w *wallet.Wallet) (interface{}, er
 	return nil, err
 }
 
+// decodePsbtStr decodes a base64 encoded PSBT.
+func decodePsbtStr(s string) (*psbt.Packet, er.R) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(s), true)
+	if err != nil {
+		return nil, errDeserialization("PSBT decode failed", err)
+	}
+	return packet, nil
+}
+
+// walletCreateFundedPsbt handles a walletcreatefundedpsbt RPC request by
+// creating a PSBT which pays to the requested outputs and which is funded by
+// the wallet.  The inputs of the PSBT carry the outputs which they spend and
+// their key derivations, but they are not signed.
+func walletCreateFundedPsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.WalletCreateFundedPsbtCmd)
+
+	pairs := make(map[string]btcutil.Amount, len(cmd.Outputs))
+	for k, v := range cmd.Outputs {
+		amt, err := btcutil.NewAmount(v)
+		if err != nil {
+			return nil, err
+		}
+		if amt < 0 {
+			return nil, errNeedPositiveAmount()
+		}
+		pairs[k] = amt
+	}
+	txOut, err := makeOutputs(pairs, nil, w.ChainParams())
+	if err != nil {
+		return nil, btcjson.ErrRPCInvalidAddressOrKey.New("", err)
+	}
+
+	tx := wire.NewMsgTx(constants.TxVersion)
+	tx.TxOut = txOut
+	tx.LockTime = *cmd.Locktime
+	for _, input := range cmd.Inputs {
+		txHash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			return nil, errDeserialization("unable to parse txid", err)
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(txHash, input.Vout), nil, nil)
+
+		// A lock time is only enforced if an input is not final.
+		if input.Sequence != nil {
+			txIn.Sequence = *input.Sequence
+		} else if tx.LockTime != 0 {
+			txIn.Sequence = constants.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, txIn)
+	}
+
+	feeSatPerKb := txrules.DefaultRelayFeePerKb
+	account := uint32(waddrmgr.DefaultAccountNum)
+	if cmd.Options != nil {
+		if cmd.Options.FeeRate != nil {
+			feeSatPerKb, err = btcutil.NewAmount(*cmd.Options.FeeRate)
+			if err != nil {
+				return nil, err
+			}
+			if feeSatPerKb < 0 {
+				return nil, errNeedPositiveAmount()
+			}
+		}
+		if cmd.Options.Account != nil {
+			// Inputs selected by the wallet are not limited to an
+			// account, the account only receives the change of the
+			// given inputs.
+			if len(cmd.Inputs) == 0 {
+				return nil, btcjson.ErrRPCInvalidParameter.New(
+					"account can only be set when inputs are given", nil)
+			}
+			account = *cmd.Options.Account
+		}
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, btcjson.ErrRPCInvalidParameter.New("", err)
+	}
+	changePos, err := w.FundPsbt(packet, account, feeSatPerKb)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.ProcessPsbt(packet, false); err != nil {
+		return nil, err
+	}
+
+	fee, err := psbt.SumUtxoInputValues(packet)
+	if err != nil {
+		return nil, err
+	}
+	for _, out := range packet.UnsignedTx.TxOut {
+		fee -= out.Value
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &btcjson.WalletCreateFundedPsbtResult{
+		Psbt:      b64,
+		Fee:       btcutil.Amount(fee).ToBTC(),
+		ChangePos: changePos,
+	}, nil
+}
+
+// walletProcessPsbt handles a walletprocesspsbt RPC request by attaching the
+// outputs spent by the inputs of a PSBT which belong to the wallet, signing
+// those inputs and finalizing every input which can be finalized.
+func walletProcessPsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.WalletProcessPsbtCmd)
+
+	packet, err := decodePsbtStr(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	complete, err := w.ProcessPsbt(packet, *cmd.Sign)
+	if err != nil {
+		if waddrmgr.ErrLocked.Is(err) {
+			return nil, btcjson.ErrRPCWalletUnlockNeeded.Default()
+		}
+		return nil, err
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &btcjson.WalletProcessPsbtResult{
+		Psbt:     b64,
+		Complete: complete,
+	}, nil
+}
+
+// bumpFee handles a bumpfee RPC request by replacing an unmined transaction of
+// the wallet with one paying a higher fee, or by paying for it with a child
+// transaction if it cannot be replaced.
+func bumpFee(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.BumpFeeCmd)
+
+	txHash, err := chainhash.NewHashFromStr(cmd.Txid)
+	if err != nil {
+		return nil, errDeserialization("unable to parse txid", err)
+	}
+
+	var feeSatPerKb btcutil.Amount
+	if cmd.Options != nil && cmd.Options.FeeRate != nil {
+		feeSatPerKb, err = btcutil.NewAmount(*cmd.Options.FeeRate)
+		if err != nil {
+			return nil, err
+		}
+		if feeSatPerKb <= 0 {
+			return nil, errNeedPositiveAmount()
+		}
+	}
+
+	res, err := w.BumpFee(txHash, feeSatPerKb)
+	if waddrmgr.ErrLocked.Is(err) {
+		return nil, btcjson.ErrRPCWalletUnlockNeeded.Default()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	method := "cpfp"
+	if res.Replaced {
+		method = "replace"
+	}
+	return &btcjson.BumpFeeResult{
+		Txid:    res.Tx.TxHash().String(),
+		OrigFee: res.OrigFee.ToBTC(),
+		Fee:     res.Fee.ToBTC(),
+		Method:  method,
+	}, nil
+}
+
+// combinePsbt handles a combinepsbt RPC request by merging several PSBTs of
+// the same transaction into one.
+func combinePsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.CombinePsbtCmd)
+
+	if len(cmd.Txs) == 0 {
+		return nil, btcjson.ErrRPCInvalidParameter.New("No PSBTs to combine", nil)
+	}
+	packets := make([]*psbt.Packet, 0, len(cmd.Txs))
+	for _, s := range cmd.Txs {
+		packet, err := decodePsbtStr(s)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+	combined, err := psbt.Combine(packets)
+	if err != nil {
+		return nil, btcjson.ErrRPCInvalidParameter.New("", err)
+	}
+	return combined.B64Encode()
+}
+
+// finalizePsbt handles a finalizepsbt RPC request by finalizing every input
+// of a PSBT which has all of its signatures, and extracting the signed
+// transaction if all of the inputs are finalized.
+func finalizePsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.FinalizePsbtCmd)
+
+	packet, err := decodePsbtStr(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inputs which are missing signatures are left as they are.
+	for idx := range packet.Inputs {
+		_, _ = psbt.MaybeFinalize(packet, idx)
+	}
+
+	if !packet.IsComplete() || !*cmd.Extract {
+		b64, err := packet.B64Encode()
+		if err != nil {
+			return nil, err
+		}
+		return &btcjson.FinalizePsbtResult{
+			Psbt:     b64,
+			Complete: packet.IsComplete(),
+		}, nil
+	}
+
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, err
+	}
+	b := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(b); err != nil {
+		return nil, err
+	}
+	return &btcjson.FinalizePsbtResult{
+		Hex:      hex.EncodeToString(b.Bytes()),
+		Complete: true,
+	}, nil
+}
+
+// analyzePsbt handles an analyzepsbt RPC request by telling which role can
+// make progress on each input of a PSBT, along with the fee and the estimated
+// fee rate of the transaction.
+func analyzePsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.AnalyzePsbtCmd)
+
+	packet, err := decodePsbtStr(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := wallet.AnalyzePsbt(packet)
+	if err != nil {
+		return nil, btcjson.ErrRPCInvalidParameter.New("", err)
+	}
+
+	result := &btcjson.AnalyzePsbtResult{
+		Inputs: make([]btcjson.AnalyzePsbtInput, 0, len(analysis.Inputs)),
+		Next:   analysis.Next.String(),
+	}
+	for _, in := range analysis.Inputs {
+		input := btcjson.AnalyzePsbtInput{
+			HasUtxo: in.HasUtxo,
+			IsFinal: in.IsFinal,
+			Next:    in.Next.String(),
+		}
+		if len(in.MissingSigs) > 0 || in.MissingRedeemScript != nil ||
+			in.MissingWitnessScript != nil {
+
+			missing := &btcjson.AnalyzePsbtMissing{
+				RedeemScript:  hex.EncodeToString(in.MissingRedeemScript),
+				WitnessScript: hex.EncodeToString(in.MissingWitnessScript),
+			}
+			for _, keyHash := range in.MissingSigs {
+				missing.Signatures = append(missing.Signatures,
+					hex.EncodeToString(keyHash))
+			}
+			input.Missing = missing
+		}
+		result.Inputs = append(result.Inputs, input)
+	}
+	if analysis.HasFee {
+		fee := analysis.Fee.ToBTC()
+		result.Fee = &fee
+		if analysis.EstimatedVSize > 0 {
+			vsize := int64(analysis.EstimatedVSize)
+			feeRate := (analysis.Fee * 1000 / btcutil.Amount(vsize)).ToBTC()
+			result.EstimatedVSize = &vsize
+			result.EstimatedFeeRate = &feeRate
+		}
+	}
+	return result, nil
+}
+
+// sigHashNames are the names of the signature hash types, as they are given
+// to signrawtransaction.
+var sigHashNames = map[params.SigHashType]string{
+	params.SigHashAll:    "ALL",
+	params.SigHashNone:   "NONE",
+	params.SigHashSingle: "SINGLE",
+	params.SigHashAll | params.SigHashAnyOneCanPay:    "ALL|ANYONECANPAY",
+	params.SigHashNone | params.SigHashAnyOneCanPay:   "NONE|ANYONECANPAY",
+	params.SigHashSingle | params.SigHashAnyOneCanPay: "SINGLE|ANYONECANPAY",
+}
+
+// decodePsbt handles a decodepsbt RPC request by returning the content of a
+// PSBT as a JSON object.
+func decodePsbt(icmd interface{}, w *wallet.Wallet) (interface{}, er.R) {
+	cmd := icmd.(*btcjson.DecodePsbtCmd)
+
+	packet, err := decodePsbtStr(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	chainParams := w.ChainParams()
+	tx := packet.UnsignedTx
+
+	psbtUtxo := func(out *wire.TxOut) *btcjson.PsbtUtxo {
+		return &btcjson.PsbtUtxo{
+			Amount:       btcutil.Amount(out.Value).ToBTC(),
+			ScriptPubKey: hex.EncodeToString(out.PkScript),
+			Address:      txscript.PkScriptToAddress(out.PkScript, chainParams).EncodeAddress(),
+		}
+	}
+	bip32Derivs := func(derivs []*psbt.Bip32Derivation) []btcjson.PsbtBip32Deriv {
+		var res []btcjson.PsbtBip32Deriv
+		for _, d := range derivs {
+			var fingerprint [4]byte
+			binary.LittleEndian.PutUint32(fingerprint[:], d.MasterKeyFingerprint)
+			path := "m"
+			for _, index := range d.Bip32Path {
+				if index >= hdkeychain.HardenedKeyStart {
+					path += fmt.Sprintf("/%d'", index-hdkeychain.HardenedKeyStart)
+				} else {
+					path += fmt.Sprintf("/%d", index)
+				}
+			}
+			res = append(res, btcjson.PsbtBip32Deriv{
+				PubKey:            hex.EncodeToString(d.PubKey),
+				MasterFingerprint: hex.EncodeToString(fingerprint[:]),
+				Path:              path,
+			})
+		}
+		return res
+	}
+	unknowns := func(u []*psbt.Unknown) map[string]string {
+		res := make(map[string]string, len(u))
+		for _, kv := range u {
+			res[hex.EncodeToString(kv.Key)] = hex.EncodeToString(kv.Value)
+		}
+		return res
+	}
+	globalUnknowns := make([]*psbt.Unknown, 0, len(packet.Unknowns))
+	for i := range packet.Unknowns {
+		globalUnknowns = append(globalUnknowns, &packet.Unknowns[i])
+	}
+
+	result := &btcjson.DecodePsbtResult{
+		Tx: btcjson.TxRawDecodeResult{
+			Txid:     tx.TxHash().String(),
+			Version:  tx.Version,
+			Locktime: tx.LockTime,
+			Sfee:     "unknown",
+			Size:     int32(tx.SerializeSize()),
+			Vsize: int32((blockchain.GetTransactionWeight(btcutil.NewTx(tx)) +
+				blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor),
+			Vin:  make([]btcjson.VinPrevOut, 0, len(tx.TxIn)),
+			Vout: make([]btcjson.Vout, 0, len(tx.TxOut)),
+		},
+		Unknown: unknowns(globalUnknowns),
+		Inputs:  make([]btcjson.DecodePsbtInput, 0, len(packet.Inputs)),
+		Outputs: make([]btcjson.DecodePsbtOutput, 0, len(packet.Outputs)),
+	}
+
+	var fee int64
+	haveFee := true
+	for idx, txIn := range tx.TxIn {
+		in := &packet.Inputs[idx]
+		vin := btcjson.VinPrevOut{
+			Txid:      txIn.PreviousOutPoint.Hash.String(),
+			Vout:      txIn.PreviousOutPoint.Index,
+			Sequence:  txIn.Sequence,
+			ScriptSig: &btcjson.ScriptSig{},
+		}
+		input := btcjson.DecodePsbtInput{
+			RedeemScript:   hex.EncodeToString(in.RedeemScript),
+			WitnessScript:  hex.EncodeToString(in.WitnessScript),
+			Bip32Derivs:    bip32Derivs(in.Bip32Derivation),
+			FinalScriptSig: hex.EncodeToString(in.FinalScriptSig),
+		}
+		if len(in.Unknowns) > 0 {
+			input.Unknown = unknowns(in.Unknowns)
+		}
+
+		// The output spent by the input is taken from the witness utxo
+		// if there is one, as a non-witness utxo is the whole previous
+		// transaction.
+		var utxo *wire.TxOut
+		if in.NonWitnessUtxo != nil {
+			prevOut := txIn.PreviousOutPoint
+			if in.NonWitnessUtxo.TxHash() != prevOut.Hash ||
+				int(prevOut.Index) >= len(in.NonWitnessUtxo.TxOut) {
+
+				return nil, errDeserialization(fmt.Sprintf("Previous "+
+					"transaction of input %d doesn't match its outpoint", idx), nil)
+			}
+			utxo = in.NonWitnessUtxo.TxOut[prevOut.Index]
+			input.NonWitnessUtxo = psbtUtxo(utxo)
+		}
+		if in.WitnessUtxo != nil {
+			utxo = in.WitnessUtxo
+			input.WitnessUtxo = psbtUtxo(utxo)
+		}
+		if utxo != nil {
+			vin.PrevOut = &btcjson.PrevOut{
+				Address:    txscript.PkScriptToAddress(utxo.PkScript, chainParams).EncodeAddress(),
+				ValueCoins: btcutil.Amount(utxo.Value).ToBTC(),
+				Svalue:     strconv.FormatInt(utxo.Value, 10),
+			}
+			fee += utxo.Value
+		} else {
+			haveFee = false
+		}
+
+		if len(in.PartialSigs) > 0 {
+			input.PartialSignatures = make(map[string]string, len(in.PartialSigs))
+			for _, sig := range in.PartialSigs {
+				input.PartialSignatures[hex.EncodeToString(sig.PubKey)] =
+					hex.EncodeToString(sig.Signature)
+			}
+		}
+		if in.SighashType != 0 {
+			name, ok := sigHashNames[in.SighashType]
+			if !ok {
+				name = fmt.Sprintf("%#x", uint32(in.SighashType))
+			}
+			input.SigHash = name
+		}
+
+		// The final witness is serialized as a count of its items
+		// followed by each item.
+		if in.FinalScriptWitness != nil {
+			r := bytes.NewReader(in.FinalScriptWitness)
+			count, err := wire.ReadVarInt(r, 0)
+			if err != nil {
+				return nil, errDeserialization("Final witness decode failed", err)
+			}
+			input.FinalScriptWitness = make([]string, 0, count)
+			for i := uint64(0); i < count; i++ {
+				item, err := wire.ReadVarBytes(r, 0, wire.MaxMessagePayload,
+					"witness item")
+				if err != nil {
+					return nil, errDeserialization("Final witness decode failed", err)
+				}
+				input.FinalScriptWitness = append(input.FinalScriptWitness,
+					hex.EncodeToString(item))
+			}
+		}
+
+		result.Tx.Vin = append(result.Tx.Vin, vin)
+		result.Inputs = append(result.Inputs, input)
+	}
+
+	for idx, txOut := range tx.TxOut {
+		out := &packet.Outputs[idx]
+		result.Tx.Vout = append(result.Tx.Vout, btcjson.Vout{
+			ValueCoins: btcutil.Amount(txOut.Value).ToBTC(),
+			Svalue:     strconv.FormatInt(txOut.Value, 10),
+			N:          uint32(idx),
+			Address:    txscript.PkScriptToAddress(txOut.PkScript, chainParams).EncodeAddress(),
+		})
+		result.Outputs = append(result.Outputs, btcjson.DecodePsbtOutput{
+			RedeemScript:  hex.EncodeToString(out.RedeemScript),
+			WitnessScript: hex.EncodeToString(out.WitnessScript),
+			Bip32Derivs:   bip32Derivs(out.Bip32Derivation),
+		})
+		fee -= txOut.Value
+	}
+
+	if haveFee {
+		result.Tx.Sfee = strconv.FormatInt(fee, 10)
+		feeCoins := btcutil.Amount(fee).ToBTC()
+		result.Fee = &feeCoins
+	}
+	return result, nil
+}
+
 // decodeHexStr decodes the hex encoding of a string, possibly prepending a
 // leading '0' character if there is an odd number of bytes in the hex string.
 // This is to prevent an error for an invalid hex string when using an odd
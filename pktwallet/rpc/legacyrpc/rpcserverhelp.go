@@ -5,8 +5,14 @@ package legacyrpc
 func helpDescsEnUS() map[string]string {
 	return map[string]string{
 		"addmultisigaddress":      "addmultisigaddress nrequired [\"key\",...]\n\nGenerates and imports a multisig address and redeeming script to the 'imported' account.\n\nArguments:\n1. nrequired (numeric, required)         The number of signatures required to redeem outputs paid to this address\n2. keys      (array of string, required) Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address\n\nResult:\n\"value\" (string) The imported pay-to-script-hash address\n",
+		"analyzepsbt":             "analyzepsbt \"psbt\"\n\nTells which role of BIP0174 can make progress on each input of a PSBT and on the PSBT as a whole, along with the fee and the estimated size of the transaction when they are known\n\nArguments:\n1. psbt (string, required) The base64 encoded PSBT\n\nResult:\n{\n \"inputs\": [{                   (array of object) The state of each input\n  \"has_utxo\": true|false,       (boolean)         Whether the output spent by the input is known\n  \"is_final\": true|false,       (boolean)         Whether the input is finalized\n  \"missing\": {                  (object)          What the input needs before it can be finalized\n   \"signatures\": [\"value\",...], (array of string) The hash160 of the public keys whose signatures are missing\n   \"redeemscript\": \"value\",     (string)          The hash160 of the missing redeem script\n   \"witnessscript\": \"value\",    (string)          The sha256 of the missing witness script\n  },                                              \n  \"next\": \"value\",              (string)          The role which can make progress on the input\n },...],                                          \n \"estimated_vsize\": n,          (numeric)         The estimated virtual size of the signed transaction, if it can be estimated\n \"estimated_feerate\": n.nnn,    (numeric)         The estimated fee rate of the signed transaction in coins per kilobyte, if the size can be estimated\n \"fee\": n.nnn,                  (numeric)         The fee paid by the transaction, if the outputs spent by all of its inputs are known\n \"next\": \"value\",               (string)          The role which can make progress on the PSBT (updater, signer, finalizer or extractor)\n}                               \n",
+		"bumpfee":                 "bumpfee \"txid\" ({\"feerate\":feerate})\n\nSpeeds up the confirmation of an unmined transaction sent by the wallet. If the transaction signals replaceability (BIP0125) it is replaced by a transaction which pays the same outputs with a higher fee, adding inputs of the wallet if needed, otherwise a child transaction spending its change back to the wallet pays for it.\n\nArguments:\n1. txid    (string, required) The hash of the transaction\n2. options (object, optional) Options of the fee bump\n{\n \"feerate\": n.nnn, (numeric) The fee rate in coins per kilobyte (default: the fee rate of the transaction plus the minimum relay fee)\n}                  \n\nResult:\n{\n \"txid\": \"value\",   (string)  The hash of the replacement or of the child transaction\n \"origfee\": n.nnn,  (numeric) The fee paid by the original transaction in coins\n \"fee\": n.nnn,      (numeric) The fee paid by the replacement or by the child transaction in coins\n \"method\": \"value\", (string)  How the fee was bumped, \"replace\" or \"cpfp\" (child pays for parent)\n}                   \n",
+		"combinepsbt":             "combinepsbt [\"tx\",...]\n\nMerges several PSBTs of the same transaction, such as the PSBTs signed by each of the signers of a multisig input, into one PSBT\n\nArguments:\n1. txs (array of string, required) The base64 encoded PSBTs to combine\n\nResult:\n\"value\" (string) The base64 encoded combined PSBT\n",
 		"createmultisig":          "createmultisig nrequired [\"key\",...]\n\nGenerate a multisig address and redeem script.\n\nArguments:\n1. nrequired (numeric, required)         The number of signatures required to redeem outputs paid to this address\n2. keys      (array of string, required) Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address\n\nResult:\n{\n \"address\": \"value\",      (string) The generated pay-to-script-hash address\n \"redeemScript\": \"value\", (string) The script required to redeem outputs paid to the multisig address\n}                         \n",
-		"createtransaction":       "createtransaction \"toaddress\" amount ([\"fromaddress\",...] electrumformat \"changeaddress\" inputminheight minconf=1 vote maxinputs \"autolock\")\n\nCreate a transaction but do not send it to the chain\n\nArguments:\n1.  toaddress      (string, required)             The recipient to send the coins to\n2.  amount         (numeric, required)            The amount of coins to send\n3.  fromaddresses  (array of string, optional)    Addresses to use for selecting coins to spend\n4.  electrumformat (boolean, optional)            If true, then the transaction result will be output in electrum incomplete transaction format, useful for signing later\n5.  changeaddress  (string, optional)             Return extra coins to this address, if unspecified then one will be created\n6.  inputminheight (numeric, optional)            The minimum block height to take inputs from (default: 0)\n7.  minconf        (numeric, optional, default=1) Do not spend any outputs which don't have at least this number of confirmations (default 1)\n8.  vote           (boolean, optional)            True if you wish for this transaction to contain a network steward vote\n9.  maxinputs      (numeric, optional)            Maximum number of transaction inputs that are allowed\n10. autolock       (string, optional)             If specified, all txouts spent for this transaction will be locked under this name\n\nResult:\n\"value\" (string) The hex encoded transaction result\n",
+		"createtransaction":       "createtransaction \"toaddress\" amount ([\"fromaddress\",...] electrumformat \"changeaddress\" inputminheight minconf=1 vote maxinputs \"autolock\")\n\nCreate a transaction but do not send it to the chain, if it spends coins of a watch-only account, because all of the fromaddresses are watch-only or the other coins are not enough, then the result is an unsigned PSBT\n\nArguments:\n1.  toaddress      (string, required)             The recipient to send the coins to\n2.  amount         (numeric, required)            The amount of coins to send\n3.  fromaddresses  (array of string, optional)    Addresses to use for selecting coins to spend\n4.  electrumformat (boolean, optional)            If true, then the transaction result will be output in electrum incomplete transaction format, useful for signing later\n5.  changeaddress  (string, optional)             Return extra coins to this address, if unspecified then one will be created\n6.  inputminheight (numeric, optional)            The minimum block height to take inputs from (default: 0)\n7.  minconf        (numeric, optional, default=1) Do not spend any outputs which don't have at least this number of confirmations (default 1)\n8.  vote           (boolean, optional)            True if you wish for this transaction to contain a network steward vote\n9.  maxinputs      (numeric, optional)            Maximum number of transaction inputs that are allowed\n10. autolock       (string, optional)             If specified, all txouts spent for this transaction will be locked under this name\n\nResult:\n\"value\" (string) The hex encoded transaction result, or the base64 encoded PSBT when spending from a watch-only account\n",
+		"decodepsbt":              "decodepsbt \"psbt\"\n\nReturns a JSON object representing a base64 encoded PSBT\n\nArguments:\n1. psbt (string, required) The base64 encoded PSBT\n\nResult:\n{\n \"tx\": {                         (object)          The unsigned transaction of the PSBT\n  \"txid\": \"value\",               (string)          The hash of the transaction\n  \"version\": n,                  (numeric)         The transaction version\n  \"locktime\": n,                 (numeric)         The transaction lock time\n  \"sfee\": \"value\",               (string)          Number of atomic units of fees, base 10 string\n  \"size\": n,                     (numeric)         The full size of the transaction, including segwit data\n  \"vsize\": n,                    (numeric)         The virtual size of the transaction, offering a discount for segwit data\n  \"vin\": [{                      (array of object) The transaction inputs as JSON objects\n   \"coinbase\": \"value\",          (string)          The hex-encoded bytes of the signature script (coinbase txns only)\n   \"txid\": \"value\",              (string)          The hash of the origin transaction (non-coinbase txns only)\n   \"vout\": n,                    (numeric)         The index of the output being redeemed from the origin transaction (non-coinbase txns only)\n   \"scriptSig\": {                (object)          The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)\n    \"asm\": \"value\",              (string)          Disassembly of the script\n    \"hex\": \"value\",              (string)          Hex-encoded bytes of the script\n   },                                              \n   \"txinwitness\": [\"value\",...], (array of string) The witness stack of the passed input, encoded as a JSON string array\n   \"prevOut\": {                  (object)          Data from the origin transaction output with index vout.\n    \"address\": \"value\",          (string)          The address which this transaction is spending from\n    \"value\": n.nnn,              (numeric)         previous output value\n    \"svalue\": \"value\",           (string)          previous output value in atomic units, string containing base 10 number\n   },                                              \n   \"sequence\": n,                (numeric)         The script sequence number\n  },...],                                          \n  \"vout\": [{                     (array of object) The transaction outputs as JSON objects\n   \"value\": n.nnn,               (numeric)         The amount in coins\n   \"svalue\": \"value\",            (string)          previous output value in atomic units, string containing base 10 number\n   \"n\": n,                       (numeric)         The index of this transaction output\n   \"address\": \"value\",           (string)          The address paid to\n   \"vote\": {                     (object)          A vote on network steward, if any exists\n    \"for\": \"value\",              (string)          The network steward which this payment is voting for\n    \"against\": \"value\",          (string)          The network steward address which this payment is voting against\n   },                                              \n  },...],                                          \n },                                                \n \"unknown\": {                    (object)          The unknown global key-value pairs\n  \"key\": value, (object) The hex encoded key and value of each unknown pair\n  ...\n }\n \"inputs\": [{               (array of object) The inputs of the PSBT\n  \"non_witness_utxo\": {     (object)          The output spent by the input, taken from the transaction which made it\n   \"amount\": n.nnn,         (numeric)         The amount in coins\n   \"scriptPubKey\": \"value\", (string)          The hex encoded public key script\n   \"address\": \"value\",      (string)          The address paid to\n  },                                          \n  \"witness_utxo\": {         (object)          The output spent by the input\n   \"amount\": n.nnn,         (numeric)         The amount in coins\n   \"scriptPubKey\": \"value\", (string)          The hex encoded public key script\n   \"address\": \"value\",      (string)          The address paid to\n  },                                          \n  \"partial_signatures\": {   (object)          The signatures of the input\n   \"pubkey\": signature, (object) The hex encoded public key and signature of each signer\n   ...\n  }\n  \"sighash\": \"value\",                   (string)          The signature hash type which signers must use\n  \"redeem_script\": \"value\",             (string)          The hex encoded redeem script\n  \"witness_script\": \"value\",            (string)          The hex encoded witness script\n  \"bip32_derivs\": [{                    (array of object) The BIP0032 derivations of the keys of the input\n   \"pubkey\": \"value\",                   (string)          The hex encoded public key\n   \"master_fingerprint\": \"value\",       (string)          The fingerprint of the master key\n   \"path\": \"value\",                     (string)          The derivation path of the key\n  },...],                                                 \n  \"final_scriptSig\": \"value\",           (string)          The hex encoded final signature script\n  \"final_scriptwitness\": [\"value\",...], (array of string) The hex encoded items of the final witness\n  \"unknown\": {                          (object)          The unknown key-value pairs of the input\n   \"key\": value, (object) The hex encoded key and value of each unknown pair\n   ...\n  }\n },...],                                            \n \"outputs\": [{                    (array of object) The outputs of the PSBT\n  \"redeem_script\": \"value\",       (string)          The hex encoded redeem script\n  \"witness_script\": \"value\",      (string)          The hex encoded witness script\n  \"bip32_derivs\": [{              (array of object) The BIP0032 derivations of the keys of the output\n   \"pubkey\": \"value\",             (string)          The hex encoded public key\n   \"master_fingerprint\": \"value\", (string)          The fingerprint of the master key\n   \"path\": \"value\",               (string)          The derivation path of the key\n  },...],                                           \n },...],                                            \n \"fee\": n.nnn,                    (numeric)         The fee paid by the transaction, if the outputs spent by all of its inputs are known\n}                                 \n",
+		"finalizepsbt":            "finalizepsbt \"psbt\" (extract=true)\n\nMakes the final signature scripts and witnesses of the inputs of a PSBT out of their signatures, and extracts the signed transaction if every input is finalized. No signatures are added, use walletprocesspsbt to sign.\n\nArguments:\n1. psbt    (string, required)                The base64 encoded PSBT\n2. extract (boolean, optional, default=true) If true and the PSBT is complete then the hex encoded transaction is returned instead of the PSBT\n\nResult:\n{\n \"psbt\": \"value\",        (string)  The base64 encoded PSBT, if the transaction was not extracted\n \"hex\": \"value\",         (string)  The hex encoded signed transaction, if it was extracted\n \"complete\": true|false, (boolean) Whether every input is finalized\n}                        \n",
+		"foldaddress":             "foldaddress \"toaddress\" ([\"fromaddress\",...] maxamount minconf=1 maxinputs maxtransactions maxfee dryrun=false)\n\nSweep many small outputs into one address, making as many transactions as needed with as many inputs as a transaction can hold\n\nArguments:\n1. toaddress       (string, required)                 The address to send the folded coins to\n2. fromaddresses   (array of string, optional)        Addresses whose outputs are folded, if unspecified then outputs of the whole wallet are folded\n3. maxamount       (numeric, optional)                Only fold outputs which are worth less than this amount, if unspecified then outputs of any value are folded\n4. minconf         (numeric, optional, default=1)     Do not fold any outputs which don't have at least this number of confirmations (default 1)\n5. maxinputs       (numeric, optional)                Maximum number of inputs in each transaction\n6. maxtransactions (numeric, optional)                Maximum number of transactions to make, if unspecified then folding continues until there is nothing left to fold\n7. maxfee          (numeric, optional)                Maximum total fee to pay for all transactions\n8. dryrun          (boolean, optional, default=false) If true then the transactions are made but they are neither signed nor sent\n\nResult:\n{\n \"transactions\": [{     (array of object) The transactions which were made\n  \"txid\": \"value\",      (string)          The hash of the transaction\n  \"inputs\": n,          (numeric)         The number of outputs folded by the transaction\n  \"amount\": n.nnn,      (numeric)         The amount of coins received by the address\n  \"fee\": n.nnn,         (numeric)         The fee of the transaction\n  \"hex\": \"value\",       (string)          The hex encoded unsigned transaction, only in a dry run\n },...],                                  \n \"inputs\": n,           (numeric)         The total number of outputs which were folded\n \"amount\": n.nnn,       (numeric)         The total amount of coins received by the address\n \"fee\": n.nnn,          (numeric)         The total fee of the transactions\n \"stopreason\": \"value\", (string)          Why folding stopped, empty if the maximum number of transactions was reached\n}                       \n",
 		"getaddressbalances":      "getaddressbalances (minconf=1 showzerobalance)\n\nGet balances for each address\n\nArguments:\n1. minconf         (numeric, optional, default=1) Minimum number of confirmations for coins to be considered received\n2. showzerobalance (boolean, optional)            If true then addresses which have been created but carry zero balance will be included\n\nResult:\n[{\n \"address\": \"value\",         (string)  The address which has this balance\n \"total\": n.nnn,             (numeric) Total balance\n \"stotal\": \"value\",          (string)  Total balance (atomic units as base 10 string)\n \"spendable\": n.nnn,         (numeric) Balance which is currently spendable\n \"sspendable\": \"value\",      (string)  Balance which is currently spendable (atomic units as base 10 string)\n \"immaturereward\": n.nnn,    (numeric) Mined coins which have not yet matured\n \"simmaturereward\": \"value\", (string)  Mined coins which have not yet matured (atomic units as base 10 string)\n \"unconfirmed\": n.nnn,       (numeric) Unconfirmed balance\n \"sunconfirmed\": \"value\",    (string)  Unconfirmed balance (atomic units as base 10 string)\n \"outputcount\": n,           (numeric) The number of transaction outputs which make up the balance\n},...]\n",
 		"setnetworkstewardvote":   "setnetworkstewardvote (\"votefor\" \"voteagainst\")\n\nConfigure the wallet to vote for a network steward when making payments (note: payments to segwit addresses cannot vote)\n\nArguments:\n1. votefor     (string, optional) The address to vote for (in the event of an election, this is the address who should win)\n2. voteagainst (string, optional) The address to vote against (if this is the current NS then this will cause a vote for an election)\n\nResult:\n{\n} \n",
 		"getnetworkstewardvote":   "getnetworkstewardvote\n\nFind out how the wallet is currently configured to vote in a network steward election\n\nArguments:\nNone\n\nResult:\n{\n \"votefor\": \"value\",     (string) The address which your wallet is currently voting for\n \"voteagainst\": \"value\", (string) The address which your wallet is currently voting against\n}                        \n",
@@ -25,6 +31,9 @@ func helpDescsEnUS() map[string]string {
 		"getsecret":               "getsecret \"name\"\n\nGet a secret seed which is generated using the wallet's private key, this can be used as a password for another application\n\nArguments:\n1. name (string, required) A name which will be used to generate the secret seed, the same seed will always be provided given the same name\n\nResult:\n\"value\" (string) A 32 byte secret seed in hex form\n",
 		"help":                    "help (\"command\")\n\nReturns a list of all commands or help for a specified command.\n\nArguments:\n1. command (string, optional) The command to retrieve help for\n\nResult (no command provided):\n\"value\" (string) List of commands\n\nResult (command specified):\n\"value\" (string) Help for specified command\n",
 		"importprivkey":           "importprivkey \"privkey\" (\"label\" rescan=true legacy=false)\n\nImports a WIF-encoded private key to the 'imported' account.\n\nArguments:\n1. privkey (string, required)                 The WIF-encoded private key\n2. label   (string, optional)                 Unused (must be unset or 'imported')\n3. rescan  (boolean, optional, default=true)  Rescan the blockchain (since the genesis block) for outputs controlled by the imported key\n4. legacy  (boolean, optional, default=false) If true then import as a legacy address, otherwise segwit\n\nResult:\nNothing\n",
+		"importdescriptors":       "importdescriptors [{\"desc\":\"value\",\"range\":[range,...],\"rescanfrom\":rescanfrom},...] (rescan=true)\n\nImports the addresses of output descriptors as watch-only addresses. The supported descriptors are pkh(KEY), wpkh(KEY), sh(wpkh(KEY)), sh(MULTI), wsh(MULTI) and sh(wsh(MULTI)), where MULTI is multi(k,KEY,...) or sortedmulti(k,KEY,...) and KEY is a public key or an extended public key, which may end with /* to describe a range of addresses. Descriptors of scripts can only be imported while the wallet is unlocked.\n\nArguments:\n1. requests (array of object, required) The descriptors to import\n[{\n \"desc\": \"value\",  (string)           The output descriptor, optionally followed by its checksum\n \"range\": [n,...], (array of numeric) The indexes of the addresses imported from a ranged descriptor, [end] or [begin, end] (default=[0, 999])\n \"rescanfrom\": n,  (numeric)          The block height to start the resync from (default=0)\n},...]\n2. rescan (boolean, optional, default=true) Resync the blockchain for payments to the imported addresses, starting at the lowest rescanfrom of the requests\n\nResult:\n[{\n \"success\": true|false, (boolean) Whether the addresses of the descriptor were imported\n \"desc\": \"value\",       (string)  The descriptor followed by its checksum\n \"error\": \"value\",      (string)  The reason why the descriptor could not be imported\n},...]\n",
+		"importxpub":              "importxpub \"xpub\" \"accountname\" (rescan=true rescanfrom=0)\n\nImports an account extended public key as a watch-only account. An xpub derives legacy addresses, a ypub derives p2sh-segwit addresses and a zpub derives segwit addresses.\n\nArguments:\n1. xpub        (string, required)                The account extended public key (xpub, ypub or zpub)\n2. accountname (string, required)                The name of the new account\n3. rescan      (boolean, optional, default=true) Resync the blockchain for payments to the account, discovering its addresses as they are used\n4. rescanfrom  (numeric, optional, default=0)    The block height to start the resync from\n\nResult:\n{\n \"account\": n,           (numeric) The number of the new account\n \"accountname\": \"value\", (string)  The name of the new account\n \"keyscope\": \"value\",    (string)  The key scope of the new account\n}                        \n",
+		"listdescriptors":         "listdescriptors\n\nReturns the output descriptors of the accounts of the wallet and the imported descriptors. No private keys are returned.\n\nArguments:\nNone\n\nResult:\n[{\n \"desc\": \"value\",        (string)           The descriptor followed by its checksum\n \"active\": true|false,   (boolean)          Whether the wallet derives new addresses from the descriptor, false for imported descriptors\n \"account\": \"value\",     (string)           The account of an active descriptor\n \"internal\": true|false, (boolean)          Whether an active descriptor describes change addresses\n \"range\": [n,...],       (array of numeric) The indexes of the imported addresses of a ranged imported descriptor, [begin, end]\n \"next\": n,              (numeric)          The index of the next address of an active descriptor\n},...]\n",
 		"listlockunspent":         "listlockunspent\n\nReturns a JSON array of outpoints marked as locked (with lockunspent) for this wallet session.\n\nArguments:\nNone\n\nResult:\n[{\n \"txid\": \"value\", (string)  The transaction hash of the referenced output\n \"vout\": n,       (numeric) The output index of the referenced output\n},...]\n",
 		"listreceivedbyaddress":   "listreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\n\nReturns a JSON array of objects listing wallet payment addresses and their total received amounts.\n\nArguments:\n1. minconf          (numeric, optional, default=1)     Minimum number of block confirmations required before a transaction is considered\n2. includeempty     (boolean, optional, default=false) Unused\n3. includewatchonly (boolean, optional, default=false) Unused\n\nResult:\n[{\n \"account\": \"value\",              (string)          DEPRECATED -- Unset\n \"address\": \"value\",              (string)          The payment address\n \"amount\": n.nnn,                 (numeric)         Total amount received by the payment address valued in bitcoin\n \"confirmations\": n,              (numeric)         Number of block confirmations of the most recent transaction relevant to the address\n \"txids\": [\"value\",...],          (array of string) Transaction hashes of all transactions involving this address\n \"involvesWatchonly\": true|false, (boolean)         Unset\n},...]\n",
 		"listsinceblock":          "listsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\n\nReturns a JSON array of objects listing details of all wallet transactions after some block.\n\nArguments:\n1. blockhash           (string, optional)                 Hash of the parent block of the first block to consider transactions from, or unset to list all transactions\n2. targetconfirmations (numeric, optional, default=1)     Minimum number of block confirmations of the last block in the result object.  Must be 1 or greater.  Note: The transactions array in the result object is not affected by this parameter\n3. includewatchonly    (boolean, optional, default=false) Unused\n\nResult:\n{\n \"transactions\": [{                 (array of object) JSON array of objects containing verbose details of the each transaction\n  \"abandoned\": true|false,          (boolean)         Unset\n  \"account\": \"value\",               (string)          DEPRECATED -- Unset\n  \"address\": \"value\",               (string)          Payment address for a transaction output\n  \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in bitcoin\n  \"bip125-replaceable\": \"value\",    (string)          Unset\n  \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n  \"blockindex\": n,                  (numeric)         Unset\n  \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n  \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n  \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n  \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n  \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n  \"involveswatchonly\": true|false,  (boolean)         Unset\n  \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n  \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n  \"trusted\": true|false,            (boolean)         Unset\n  \"txid\": \"value\",                  (string)          The hash of the transaction\n  \"vout\": n,                        (numeric)         The transaction output index\n  \"walletconflicts\": [\"value\",...], (array of string) Unset\n  \"comment\": \"value\",               (string)          Unset\n  \"otheraccount\": \"value\",          (string)          Unset\n },...],                                              \n \"lastblock\": \"value\",              (string)          Hash of the latest-synced block to be used in later calls to listsinceblock\n}                                   \n",
@@ -39,9 +48,11 @@ func helpDescsEnUS() map[string]string {
 		"signrawtransaction":      "signrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\n\nSigns transaction inputs using private keys from this wallet and request.\nThe valid flags options are ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, and SINGLE|ANYONECANPAY.\n\nArguments:\n1. rawtx    (string, required)                Unsigned or partially unsigned transaction to sign encoded as a hexadecimal string\n2. inputs   (array of object, optional)       Additional data regarding inputs that this wallet may not be tracking\n3. privkeys (array of string, optional)       Additional WIF-encoded private keys to use when creating signatures\n4. flags    (string, optional, default=\"ALL\") Sighash flags\n\nResult:\n{\n \"hex\": \"value\",         (string)          The resulting transaction encoded as a hexadecimal string\n \"complete\": true|false, (boolean)         Whether all input signatures have been created\n \"errors\": [{            (array of object) Script verification errors (if exists)\n  \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n  \"vout\": n,             (numeric)         The output index of the referenced previous output\n  \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n  \"sequence\": n,         (numeric)         Script sequence number\n  \"error\": \"value\",      (string)          Verification or signing error related to the input\n },...],                                   \n}                        \n",
 		"validateaddress":         "validateaddress \"address\"\n\nVerify that an address is valid.\nExtra details are returned if the address is controlled by this wallet.\nThe following fields are valid only when the address is controlled by this wallet (ismine=true): isscript, pubkey, iscompressed, account, addresses, hex, script, and sigsrequired.\nThe following fields are only valid when address has an associated public key: pubkey, iscompressed.\nThe following fields are only valid when address is a pay-to-script-hash address: addresses, hex, and script.\nIf the address is a multisig address controlled by this wallet, the multisig fields will be left unset if the wallet is locked since the redeem script cannot be decrypted.\n\nArguments:\n1. address (string, required) Address to validate\n\nResult:\n{\n \"isvalid\": true|false,      (boolean)         Whether or not the address is valid\n \"address\": \"value\",         (string)          The payment address (only when isvalid is true)\n \"ismine\": true|false,       (boolean)         Whether this address is controlled by the wallet (only when isvalid is true)\n \"iswatchonly\": true|false,  (boolean)         Unset\n \"isscript\": true|false,     (boolean)         Whether the payment address is a pay-to-script-hash address (only when isvalid is true)\n \"pubkey\": \"value\",          (string)          The associated public key of the payment address, if any (only when isvalid is true)\n \"iscompressed\": true|false, (boolean)         Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)\n \"account\": \"value\",         (string)          The account this payment address belongs to (only when isvalid is true)\n \"addresses\": [\"value\",...], (array of string) All associated payment addresses of the script if address is a multisig address (only when isvalid is true)\n \"hex\": \"value\",             (string)          The redeem script \n \"script\": \"value\",          (string)          The class of redeem script for a multisig address\n \"sigsrequired\": n,          (numeric)         The number of required signatures to redeem outputs to the multisig address\n}                            \n",
 		"verifymessage":           "verifymessage \"address\" \"signature\" \"message\"\n\nVerify a message was signed with the associated private key of some address.\n\nArguments:\n1. address   (string, required) Address used to sign message\n2. signature (string, required) The signature to verify\n3. message   (string, required) The message to verify\n\nResult:\ntrue|false (boolean) Whether the message was signed with the private key of 'address'\n",
+		"walletcreatefundedpsbt":  "walletcreatefundedpsbt [{\"txid\":\"value\",\"vout\":n,\"sequence\":sequence},...] {\"address\":amount,...} (locktime=0 {\"feerate\":feerate,\"account\":account})\n\nCreates a PSBT which pays to the outputs, adding inputs of the wallet when no inputs are given and a change output as needed to pay them and the fee. The PSBT is not signed, use walletprocesspsbt to sign it.\n\nArguments:\n1. inputs (array of object, required) The inputs which the transaction spends, if any are given then no other inputs are added and they must be enough to pay the outputs and the fee\n[{\n \"txid\": \"value\", (string)  The hash of the transaction which made the output\n \"vout\": n,       (numeric) The index of the output\n \"sequence\": n,   (numeric) The sequence number of the input\n},...]\n2. outputs (object, required) Pairs of payment addresses and the output amount to pay each\n{\n \"Address to pay\": Amount to send to the payment address valued in coins, (object) JSON object using payment addresses as keys and output amounts valued in coins to send to each address\n ...\n}\n3. locktime (numeric, optional, default=0) The lock time of the transaction\n4. options  (object, optional)             Options of the funding\n{\n \"feerate\": n.nnn, (numeric) The fee rate in coins per kilobyte (default: the minimum relay fee)\n \"account\": n,     (numeric) The account which receives the change, it can only be set when inputs are given (default=0)\n}                  \n\nResult:\n{\n \"psbt\": \"value\", (string)  The base64 encoded PSBT\n \"fee\": n.nnn,    (numeric) The fee paid by the transaction in coins\n \"changepos\": n,  (numeric) The index of the change output, or -1 if there is none\n}                 \n",
 		"walletlock":              "walletlock\n\nLock the wallet.\n\nArguments:\nNone\n\nResult:\nNothing\n",
 		"walletpassphrase":        "walletpassphrase \"passphrase\" timeout\n\nUnlock the wallet.\n\nArguments:\n1. passphrase (string, required)  The wallet passphrase\n2. timeout    (numeric, required) The number of seconds to wait before the wallet automatically locks\n\nResult:\nNothing\n",
 		"walletpassphrasechange":  "walletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\n\nChange the wallet passphrase.\n\nArguments:\n1. oldpassphrase (string, required) The old wallet passphrase\n2. newpassphrase (string, required) The new wallet passphrase\n\nResult:\nNothing\n",
+		"walletprocesspsbt":       "walletprocesspsbt \"psbt\" (sign=true)\n\nAttaches the outputs spent by the inputs of a PSBT which belong to the wallet along with their scripts and key derivations, signs those inputs and finalizes every input which can be finalized\n\nArguments:\n1. psbt (string, required)                The base64 encoded PSBT\n2. sign (boolean, optional, default=true) Whether to sign the inputs of the wallet, which requires the wallet to be unlocked\n\nResult:\n{\n \"psbt\": \"value\",        (string)  The base64 encoded PSBT\n \"complete\": true|false, (boolean) Whether every input is finalized\n}                        \n",
 		"walletmempool":           "walletmempool\n\nShow the unconfirmed transactions which are being broadcasted by the wallet\n\nArguments:\nNone\n\nResult:\n[{\n \"txid\": \"value\",     (string) Transaction id\n \"received\": \"value\", (string) The time when the transaction was first seen/made\n},...]\n",
 		"exportwatchingwallet":    "exportwatchingwallet (\"account\" download=false)\n\nCreates and returns a duplicate of the wallet database without any private keys to be used as a watching-only wallet.\n\nArguments:\n1. account  (string, optional)                 Unused (must be unset or \"*\")\n2. download (boolean, optional, default=false) Unused\n\nResult:\n\"value\" (string) The watching-only database encoded as a base64 string\n",
 		"getbestblock":            "getbestblock\n\nReturns the hash and height of the newest block in the best chain that wallet has finished syncing with.\n\nArguments:\nNone\n\nResult:\n{\n \"hash\": \"value\", (string)  The hash of the block\n \"height\": n,     (numeric) The blockchain height of the block\n}                 \n",
@@ -56,4 +67,4 @@ var localeHelpDescs = map[string]func() map[string]string{
 	"en_US": helpDescsEnUS,
 }
 
-var requestUsages = "addmultisigaddress nrequired [\"key\",...]\ncreatemultisig nrequired [\"key\",...]\ncreatetransaction \"toaddress\" amount ([\"fromaddress\",...] electrumformat \"changeaddress\" inputminheight minconf=1 vote maxinputs \"autolock\")\ngetaddressbalances (minconf=1 showzerobalance)\nsetnetworkstewardvote (\"votefor\" \"voteagainst\")\ngetnetworkstewardvote\nresync (fromheight toheight [\"address\",...] dropdb)\nstopresync\naddp2shscript \"script\" segwit\ndumpprivkey \"address\"\ngetbalance (minconf=1)\ngetbestblockhash\ngetblockcount\ngetinfo\ngetnewaddress (legacy)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettransaction \"txid\" (includewatchonly=false)\ngetwalletseed\ngetsecret \"name\"\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true legacy=false)\nlistlockunspent\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (count=10 from=0)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n},...] (\"lockname\")\nsendfrom \"toaddress\" amount ([\"fromaddress\",...] minconf=1 \"comment\" \"commentto\" maxinputs minheight)\nsendmany {\"address\":amount,...} ([\"fromaddress\",...] minconf=1 \"comment\" maxinputs)\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\nwalletmempool\nexportwatchingwallet (\"account\" download=false)\ngetbestblock\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nwalletislocked"
+var requestUsages = "addmultisigaddress nrequired [\"key\",...]\nanalyzepsbt \"psbt\"\nbumpfee \"txid\" ({\"feerate\":feerate})\ncombinepsbt [\"tx\",...]\ncreatemultisig nrequired [\"key\",...]\ncreatetransaction \"toaddress\" amount ([\"fromaddress\",...] electrumformat \"changeaddress\" inputminheight minconf=1 vote maxinputs \"autolock\")\ndecodepsbt \"psbt\"\nfinalizepsbt \"psbt\" (extract=true)\nfoldaddress \"toaddress\" ([\"fromaddress\",...] maxamount minconf=1 maxinputs maxtransactions maxfee dryrun=false)\ngetaddressbalances (minconf=1 showzerobalance)\nsetnetworkstewardvote (\"votefor\" \"voteagainst\")\ngetnetworkstewardvote\nresync (fromheight toheight [\"address\",...] dropdb)\nstopresync\naddp2shscript \"script\" segwit\ndumpprivkey \"address\"\ngetbalance (minconf=1)\ngetbestblockhash\ngetblockcount\ngetinfo\ngetnewaddress (legacy)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettransaction \"txid\" (includewatchonly=false)\ngetwalletseed\ngetsecret \"name\"\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true legacy=false)\nimportdescriptors [{\"desc\":\"value\",\"range\":[range,...],\"rescanfrom\":rescanfrom},...] (rescan=true)\nimportxpub \"xpub\" \"accountname\" (rescan=true rescanfrom=0)\nlistdescriptors\nlistlockunspent\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (count=10 from=0)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n},...] (\"lockname\")\nsendfrom \"toaddress\" amount ([\"fromaddress\",...] minconf=1 \"comment\" \"commentto\" maxinputs minheight)\nsendmany {\"address\":amount,...} ([\"fromaddress\",...] minconf=1 \"comment\" maxinputs)\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletcreatefundedpsbt [{\"txid\":\"value\",\"vout\":n,\"sequence\":sequence},...] {\"address\":amount,...} (locktime=0 {\"feerate\":feerate,\"account\":account})\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\nwalletprocesspsbt \"psbt\" (sign=true)\nwalletmempool\nexportwatchingwallet (\"account\" download=false)\ngetbestblock\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nwalletislocked"
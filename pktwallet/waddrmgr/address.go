@@ -291,11 +291,23 @@ func (a *managedAddress) PrivKey() (*btcec.PrivateKey, er.R) {
 	a.manager.mtx.Lock()
 	defer a.manager.mtx.Unlock()
 
+	// Imported public keys have no private key, whether or not the address
+	// manager is locked.
+	if a.imported && len(a.privKeyEncrypted) == 0 {
+		return nil, ErrWatchingOnly.Default()
+	}
+
 	// Account manager must be unlocked to decrypt the private key.
 	if a.manager.rootManager.IsLocked() {
 		return nil, ErrLocked.Default()
 	}
 
+	// Addresses of watch-only accounts have no private key, even when the
+	// address manager is unlocked.
+	if len(a.privKeyEncrypted) == 0 {
+		return nil, ErrWatchingOnly.Default()
+	}
+
 	// Decrypt the key as needed.  Also, make sure it's a copy since the
 	// private key stored in memory can be cleared at any time.  Otherwise
 	// the returned private key could be invalidated from under the caller.
@@ -279,6 +279,12 @@ var (
 
 	// bucket containing dbNetworkStewardVote
 	networkStewardVoteName = []byte("nsvote")
+
+	// descriptorBucketName is the name of the bucket that stores the
+	// output descriptors imported into the manager, it maps the descriptor
+	// string to the range of indexes which were imported.  It is created
+	// when the first descriptor is imported.
+	descriptorBucketName = []byte("descriptors")
 )
 
 // uint32ToBytes converts a 32 bit unsigned integer into a 4-byte slice in
@@ -2213,6 +2219,79 @@ func putBirthdayBlockVerification(ns walletdb.ReadWriteBucket, verified bool) er
 	return nil
 }
 
+// serializeDescriptorRange returns the serialization of the range of an
+// imported descriptor.
+func serializeDescriptorRange(d *ImportedDescriptor) []byte {
+	// The serialized format is:
+	//   <rangestart><rangeend>
+	//
+	// 4 bytes range start + 4 bytes range end
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint32(buf[0:4], d.RangeStart)
+	binary.LittleEndian.PutUint32(buf[4:8], d.RangeEnd)
+	return buf
+}
+
+// deserializeDescriptorRow deserializes an imported descriptor and its range.
+func deserializeDescriptorRow(k, v []byte) (*ImportedDescriptor, er.R) {
+	if len(v) != 8 {
+		str := fmt.Sprintf("malformed serialized descriptor %s", k)
+		return nil, managerError(ErrDatabase, str, nil)
+	}
+	return &ImportedDescriptor{
+		Descriptor: string(k),
+		RangeStart: binary.LittleEndian.Uint32(v[0:4]),
+		RangeEnd:   binary.LittleEndian.Uint32(v[4:8]),
+	}, nil
+}
+
+// fetchDescriptor loads an imported descriptor, it returns nil if the
+// descriptor was never imported.
+func fetchDescriptor(ns walletdb.ReadBucket, desc string) (*ImportedDescriptor, er.R) {
+	bucket := ns.NestedReadBucket(descriptorBucketName)
+	if bucket == nil {
+		return nil, nil
+	}
+	v := bucket.Get([]byte(desc))
+	if v == nil {
+		return nil, nil
+	}
+	return deserializeDescriptorRow([]byte(desc), v)
+}
+
+// putDescriptor stores an imported descriptor, replacing the range of the
+// descriptor if it was already imported.
+func putDescriptor(ns walletdb.ReadWriteBucket, d *ImportedDescriptor) er.R {
+	bucket, err := ns.CreateBucketIfNotExists(descriptorBucketName)
+	if err != nil {
+		str := "failed to create descriptor bucket"
+		return managerError(ErrDatabase, str, err)
+	}
+	err = bucket.Put([]byte(d.Descriptor), serializeDescriptorRange(d))
+	if err != nil {
+		str := fmt.Sprintf("failed to store descriptor %s", d.Descriptor)
+		return managerError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// forEachDescriptor calls fn with each imported descriptor.
+func forEachDescriptor(ns walletdb.ReadBucket,
+	fn func(d *ImportedDescriptor) er.R) er.R {
+
+	bucket := ns.NestedReadBucket(descriptorBucketName)
+	if bucket == nil {
+		return nil
+	}
+	return bucket.ForEach(func(k, v []byte) er.R {
+		d, err := deserializeDescriptorRow(k, v)
+		if err != nil {
+			return err
+		}
+		return fn(d)
+	})
+}
+
 // managerExists returns whether or not the manager has already been created
 // in the given database namespace.
 func managerExists(ns walletdb.ReadBucket) bool {
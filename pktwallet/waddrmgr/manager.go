@@ -7,6 +7,7 @@ package waddrmgr
 import (
 	"crypto/rand"
 	"crypto/sha512"
+	"encoding/binary"
 	"fmt"
 	"sync"
 	"time"
@@ -149,7 +150,9 @@ type accountInfo struct {
 
 	// The account key is used to derive the branches which in turn derive
 	// the internal and external addresses.  The accountKeyPriv will be nil
-	// when the address manager is locked.
+	// when the address manager is locked.  The acctKeyEncrypted is empty
+	// for watch-only accounts which were imported from an extended public
+	// key.
 	acctKeyEncrypted []byte
 	acctKeyPriv      *hdkeychain.ExtendedKey
 	acctKeyPub       *hdkeychain.ExtendedKey
@@ -165,6 +168,12 @@ type accountInfo struct {
 	lastInternalAddr  ManagedAddress
 }
 
+// watchOnly returns true if the account has no private extended key, so the
+// private keys of its addresses can never be derived.
+func (a *accountInfo) watchOnly() bool {
+	return len(a.acctKeyEncrypted) == 0
+}
+
 // AccountProperties contains properties associated with each account, such as
 // the account name, number, and the nubmer of derived and imported keys.
 type AccountProperties struct {
@@ -173,6 +182,23 @@ type AccountProperties struct {
 	ExternalKeyCount uint32
 	InternalKeyCount uint32
 	ImportedKeyCount uint32
+
+	// AccountPubKey is the extended public key of the account, it is nil
+	// for the imported account.
+	AccountPubKey *hdkeychain.ExtendedKey
+
+	// WatchOnly is true if the account was imported from an extended
+	// public key and cannot sign.
+	WatchOnly bool
+}
+
+// ImportedDescriptor is an output descriptor which was imported into the
+// manager, together with the range of indexes whose addresses were imported.
+// The range is only meaningful for a ranged descriptor.
+type ImportedDescriptor struct {
+	Descriptor string
+	RangeStart uint32
+	RangeEnd   uint32
 }
 
 // unlockDeriveInfo houses the information needed to derive a private key for a
@@ -718,6 +744,75 @@ func (m *Manager) ChainParams() *chaincfg.Params {
 	return m.chainParams
 }
 
+// MasterFingerprint returns the fingerprint of the master HD public key, which
+// is the first 4 bytes of the hash160 of the public key, as used in BIP0032
+// key origins.  The second return value is false if the manager does not store
+// a master HD public key.
+func (m *Manager) MasterFingerprint(ns walletdb.ReadBucket) (uint32, bool, er.R) {
+	_, masterHDPubEnc, err := fetchMasterHDKeys(ns)
+	if err != nil {
+		return 0, false, err
+	}
+	if masterHDPubEnc == nil {
+		return 0, false, nil
+	}
+
+	m.mtx.RLock()
+	masterHDPub, err := m.cryptoKeyPub.Decrypt(masterHDPubEnc)
+	m.mtx.RUnlock()
+	if err != nil {
+		str := "failed to decrypt master HD public key"
+		return 0, false, managerError(ErrCrypto, str, err)
+	}
+	rootPubKey, err := hdkeychain.NewKeyFromString(string(masterHDPub))
+	if err != nil {
+		str := "failed to parse master HD public key"
+		return 0, false, managerError(ErrKeyChain, str, err)
+	}
+	pubKey, err := rootPubKey.ECPubKey()
+	if err != nil {
+		str := "failed to get master public key"
+		return 0, false, managerError(ErrKeyChain, str, err)
+	}
+	hash := btcutil.Hash160(pubKey.SerializeCompressed())
+	return binary.BigEndian.Uint32(hash[:4]), true, nil
+}
+
+// PutDescriptor records that the addresses of an output descriptor were
+// imported.  If the descriptor was imported before then its range is extended
+// to cover both the old and the new range.
+func (m *Manager) PutDescriptor(ns walletdb.ReadWriteBucket, d *ImportedDescriptor) er.R {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	old, err := fetchDescriptor(ns, d.Descriptor)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		merged := *d
+		if old.RangeStart < merged.RangeStart {
+			merged.RangeStart = old.RangeStart
+		}
+		if old.RangeEnd > merged.RangeEnd {
+			merged.RangeEnd = old.RangeEnd
+		}
+		d = &merged
+	}
+	return putDescriptor(ns, d)
+}
+
+// ForEachDescriptor calls the given function with each output descriptor
+// imported into the manager, breaking early on error.
+func (m *Manager) ForEachDescriptor(ns walletdb.ReadBucket,
+	fn func(d *ImportedDescriptor) er.R) er.R {
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return forEachDescriptor(ns, fn)
+}
+
 // ChangePassphrase changes either the public or private passphrase to the
 // provided value depending on the private flag.  In order to change the
 // private password, the address manager must not be watching-only.  The new
@@ -1081,6 +1176,9 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) er.R {
 	// extended keys.
 	for _, manager := range m.scopedManagers {
 		for account, acctInfo := range manager.acctInfo {
+			if acctInfo.watchOnly() {
+				continue
+			}
 			decrypted, err := m.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
 			if err != nil {
 				m.lock()
@@ -1103,6 +1201,21 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) er.R {
 		// We'll also derive any private keys that are pending due to
 		// them being created while the address manager was locked.
 		for _, info := range manager.deriveOnUnlock {
+			// There is no private key to derive for the addresses of
+			// a watch-only account.
+			acctInfo, err := manager.loadAccountInfo(
+				ns, info.managedAddr.Account(),
+			)
+			if err != nil {
+				m.lock()
+				return err
+			}
+			if acctInfo.watchOnly() {
+				manager.deriveOnUnlock[0] = nil
+				manager.deriveOnUnlock = manager.deriveOnUnlock[1:]
+				continue
+			}
+
 			addressKey, err := manager.deriveKeyFromPath(
 				ns, info.managedAddr.Account(), info.branch,
 				info.index, true,
@@ -2454,3 +2454,133 @@ func TestNewRawAccount(t *testing.T) {
 			accountTargetAddr.AddrHash())
 	}
 }
+
+// TestNewAccountWatchingOnly tests that an account imported from an extended
+// public key derives the same addresses as the wallet holding the private key,
+// and that it never yields private keys.
+func TestNewAccountWatchingOnly(t *testing.T) {
+	// The BIP0044, BIP0049 and BIP0084 account 0 keys of the test mnemonic
+	// "abandon abandon ... about".
+	const (
+		xpub = "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"
+		ypub = "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP"
+		zpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	)
+	for key, scope := range map[string]KeyScope{
+		xpub: KeyScopeBIP0044,
+		ypub: KeyScopeBIP0049Plus,
+		zpub: KeyScopeBIP0084,
+	} {
+		_, keyScope, err := ParseAccountPubKey(key, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("unable to parse %s: %v", key[:4], err)
+		}
+		if keyScope != scope {
+			t.Fatalf("%s: expected scope %v, got %v", key[:4],
+				scope.String(), keyScope.String())
+		}
+	}
+
+	teardown, db := emptyDB(t)
+	defer teardown()
+
+	// The account is imported while the manager is locked, since no
+	// private key is needed.
+	var mgr *Manager
+	err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns, err := tx.CreateTopLevelBucket(waddrmgrNamespaceKey)
+		if err != nil {
+			return err
+		}
+		err = Create(
+			ns, seed, nil, pubPassphrase, privPassphrase,
+			&chaincfg.MainNetParams, fastScrypt, time.Time{},
+		)
+		if err != nil {
+			return err
+		}
+		mgr, err = Open(ns, pubPassphrase, &chaincfg.MainNetParams)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("create/open: unexpected error: %v", err)
+	}
+	defer mgr.Close()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to fetch scope %v: %v", KeyScopeBIP0084, err)
+	}
+	acctKeyPub, _, err := ParseAccountPubKey(zpub, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to parse zpub: %v", err)
+	}
+
+	var account uint32
+	var addrs []ManagedAddress
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err = scopedMgr.NewAccountWatchingOnly(ns, "cold", acctKeyPub)
+		if err != nil {
+			return err
+		}
+		addrs, err = scopedMgr.NextExternalAddresses(ns, account, 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to import account: %v", err)
+	}
+	if account != 1 {
+		t.Fatalf("expected account 1, got %d", account)
+	}
+	const addr0 = "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"
+	if addrs[0].Address().EncodeAddress() != addr0 {
+		t.Fatalf("expected address %s, got %s", addr0,
+			addrs[0].Address().EncodeAddress())
+	}
+
+	// Unlocking the manager must not try to decrypt or derive the private
+	// keys of the watch-only account.
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		addrs, err = scopedMgr.NextExternalAddresses(ns, account, 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to unlock and derive: %v", err)
+	}
+	const addr1 = "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"
+	if addrs[0].Address().EncodeAddress() != addr1 {
+		t.Fatalf("expected address %s, got %s", addr1,
+			addrs[0].Address().EncodeAddress())
+	}
+	_, err = addrs[0].(ManagedPubKeyAddress).PrivKey()
+	if !ErrWatchingOnly.Is(err) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		props, err := scopedMgr.AccountProperties(ns, account)
+		if err != nil {
+			return err
+		}
+		if !props.WatchOnly || props.ExternalKeyCount != 2 {
+			t.Fatalf("unexpected account properties %+v", props)
+		}
+		props, err = scopedMgr.AccountProperties(ns, DefaultAccountNum)
+		if err != nil {
+			return err
+		}
+		if props.WatchOnly {
+			t.Fatalf("default account should not be watch-only")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to fetch account properties: %v", err)
+	}
+}
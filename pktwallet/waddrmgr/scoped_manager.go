@@ -140,8 +140,51 @@ var (
 			ExternalAddrType: PubKeyHash,
 		},
 	}
+
+	// accountPubKeyScopes maps the version bytes of SLIP-0132 extended
+	// public keys to the key scope of the addresses which they derive.
+	accountPubKeyScopes = map[[4]byte]KeyScope{
+		{0x04, 0x88, 0xb2, 0x1e}: KeyScopeBIP0044,     // xpub
+		{0x04, 0x35, 0x87, 0xcf}: KeyScopeBIP0044,     // tpub
+		{0x04, 0x9d, 0x7c, 0xb2}: KeyScopeBIP0049Plus, // ypub
+		{0x04, 0x4a, 0x52, 0x62}: KeyScopeBIP0049Plus, // upub
+		{0x04, 0xb2, 0x47, 0x46}: KeyScopeBIP0084,     // zpub
+		{0x04, 0x5f, 0x1c, 0xf6}: KeyScopeBIP0084,     // vpub
+	}
 )
 
+// ParseAccountPubKey parses an account extended public key
+// (m/purpose'/cointype'/account') and returns it along with the key scope of
+// the addresses which it derives.  The scope is chosen by the version of the
+// key: xpub and tpub keys derive BIP0044 addresses, ypub and upub keys derive
+// BIP0049 addresses and zpub and vpub keys derive BIP0084 addresses.  Keys
+// with the extended public key version of the network also derive BIP0044
+// addresses.
+func ParseAccountPubKey(key string,
+	chainParams *chaincfg.Params) (*hdkeychain.ExtendedKey, KeyScope, er.R) {
+
+	acctKeyPub, err := hdkeychain.NewKeyFromString(key)
+	if err != nil {
+		return nil, KeyScope{}, managerError(ErrKeyChain,
+			"failed to parse extended public key", err)
+	}
+	if acctKeyPub.IsPrivate() {
+		return nil, KeyScope{}, managerError(ErrInvalidKeyType,
+			"expected an extended public key, got an extended private key", nil)
+	}
+	var version [4]byte
+	copy(version[:], acctKeyPub.Version())
+	if version == chainParams.HDPublicKeyID {
+		return acctKeyPub, KeyScopeBIP0044, nil
+	}
+	scope, ok := accountPubKeyScopes[version]
+	if !ok {
+		str := fmt.Sprintf("unknown extended public key version %x", version)
+		return nil, KeyScope{}, managerError(ErrWrongNet, str, nil)
+	}
+	return acctKeyPub, scope, nil
+}
+
 // ScopedKeyManager is a sub key manager under the main root key manager. The
 // root key manager will handle the root HD key (m/), while each sub scoped key
 // manager will handle the cointype key for a particular key scope
@@ -276,9 +319,10 @@ func (s *ScopedKeyManager) deriveKey(acctInfo *accountInfo, branch,
 
 	// Choose the public or private extended key based on whether or not
 	// the private flag was specified.  This, in turn, allows for public or
-	// private child derivation.
+	// private child derivation.  Watch-only accounts only have the public
+	// extended key.
 	acctKey := acctInfo.acctKeyPub
-	if private {
+	if private && !acctInfo.watchOnly() {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -354,7 +398,7 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 		nextInternalIndex: row.nextInternalIndex,
 	}
 
-	if !s.rootManager.isLocked() {
+	if !s.rootManager.isLocked() && !acctInfo.watchOnly() {
 		// Use the crypto private key to decrypt the account private
 		// extended keys.
 		decrypted, err := s.rootManager.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
@@ -544,6 +588,8 @@ func (s *ScopedKeyManager) AccountProperties(ns walletdb.ReadBucket,
 		props.AccountName = acctInfo.acctName
 		props.ExternalKeyCount = acctInfo.nextExternalIndex
 		props.InternalKeyCount = acctInfo.nextInternalIndex
+		props.AccountPubKey = acctInfo.acctKeyPub
+		props.WatchOnly = acctInfo.watchOnly()
 	} else {
 		props.AccountName = ImportedAddrAccountName // reserved, nonchangable
 
@@ -802,9 +848,9 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 	}
 
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and whether the account is watch-only.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly() {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -959,7 +1005,9 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 			// Add the new managed address to the list of addresses
 			// that need their private keys derived when the
 			// address manager is next unlocked.
-			if s.rootManager.isLocked() && !s.rootManager.watchOnly() {
+			if s.rootManager.isLocked() && !s.rootManager.watchOnly() &&
+				!acctInfo.watchOnly() {
+
 				s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 			}
 		}
@@ -997,9 +1045,9 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 	}
 
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and whether the account is watch-only.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly() {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -1147,7 +1195,9 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 		// Add the new managed address to the list of addresses that
 		// need their private keys derived when the address manager is
 		// next unlocked.
-		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() {
+		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() &&
+			!acctInfo.watchOnly() {
+
 			s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 		}
 	}
@@ -1306,6 +1356,9 @@ func (s *ScopedKeyManager) GetSecret(ns walletdb.ReadBucket, account uint32, nam
 		return nil, err
 	}
 
+	if acctInfo.watchOnly() {
+		return nil, managerError(ErrWatchingOnly, "account is watch-only", nil)
+	}
 	if s.rootManager.IsLocked() {
 		return nil, er.New("You need to enter your wallet passphrase before getting a secret")
 	}
@@ -1452,6 +1505,67 @@ func (s *ScopedKeyManager) newAccount(ns walletdb.ReadWriteBucket,
 	return putLastAccount(ns, &s.scope, account)
 }
 
+// NewAccountWatchingOnly creates and returns a new watch-only account stored
+// in the manager from the given account extended public key.  The addresses
+// of the account are derived from the public key, so the account can track
+// payments but it cannot sign.  Because no private keys are needed, the
+// manager does not need to be unlocked.  If an account with the same name
+// already exists, ErrDuplicateAccount will be returned.
+func (s *ScopedKeyManager) NewAccountWatchingOnly(ns walletdb.ReadWriteBucket,
+	name string, acctKeyPub *hdkeychain.ExtendedKey) (uint32, er.R) {
+
+	if acctKeyPub.IsPrivate() {
+		str := "watch-only accounts require an extended public key"
+		return 0, managerError(ErrInvalidKeyType, str, nil)
+	}
+	if err := checkBranchKeys(acctKeyPub); err != nil {
+		str := "failed to derive branches of the account extended key"
+		return 0, managerError(ErrKeyChain, str, err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Validate the account name.
+	if err := ValidateAccountName(name); err != nil {
+		return 0, err
+	}
+
+	// Check that account with the same name does not exist
+	_, err := s.lookupAccount(ns, name)
+	if err == nil {
+		str := "account with the same name already exists"
+		return 0, managerError(ErrDuplicateAccount, str, err)
+	}
+
+	account, err := fetchLastAccount(ns, &s.scope)
+	if err != nil {
+		return 0, err
+	}
+	account++
+
+	// Only the public key is stored, the empty private key is what marks
+	// the account as watch-only.
+	acctPubEnc, err := s.rootManager.cryptoKeyPub.Encrypt(
+		[]byte(acctKeyPub.String()),
+	)
+	if err != nil {
+		str := "failed to encrypt public key for account"
+		return 0, managerError(ErrCrypto, str, err)
+	}
+	err = putAccountInfo(
+		ns, &s.scope, account, acctPubEnc, nil, 0, 0, name,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := putLastAccount(ns, &s.scope, account); err != nil {
+		return 0, err
+	}
+	return account, nil
+}
+
 // RenameAccount renames an account stored in the manager based on the given
 // account number with the given name.  If an account with the same name
 // already exists, ErrDuplicateAccount will be returned.
@@ -1646,6 +1760,85 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 	return managedAddr, nil
 }
 
+// ImportPublicKey imports a serialized public key into the address manager
+// without its private key, so the imported address can be watched but not
+// spent from.  The type of the imported address is the external address type
+// of the scope.
+//
+// All imported addresses will be part of the account defined by the
+// ImportedAddrAccount constant.
+//
+// This function will return an error if the public key is invalid or the
+// address already exists.  Unlike ImportPrivateKey, it does not require the
+// address manager to be unlocked.
+func (s *ScopedKeyManager) ImportPublicKey(ns walletdb.ReadWriteBucket,
+	serializedPubKey []byte, bs *BlockStamp) (ManagedPubKeyAddress, er.R) {
+
+	pubKey, err := btcec.ParsePubKey(serializedPubKey, btcec.S256())
+	if err != nil {
+		str := fmt.Sprintf("invalid public key %x", serializedPubKey)
+		return nil, managerError(ErrCrypto, str, err)
+	}
+
+	managedAddr, err := newManagedAddressWithoutPrivKey(
+		s, DerivationPath{Account: ImportedAddrAccount}, pubKey,
+		len(serializedPubKey) == btcec.PubKeyBytesLenCompressed,
+		s.addrSchema.ExternalAddrType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	managedAddr.imported = true
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Prevent duplicates.  The address is stored under the same id as it
+	// is looked up by, which is the script hash for a nested witness
+	// address.
+	addressID := managedAddr.Address().ScriptAddress()
+	if s.existsAddress(ns, addressID) {
+		str := fmt.Sprintf("address for public key %x already exists",
+			serializedPubKey)
+		return nil, managerError(ErrDuplicateAddress, str, nil)
+	}
+
+	encryptedPubKey, err := s.rootManager.cryptoKeyPub.Encrypt(
+		serializedPubKey,
+	)
+	if err != nil {
+		str := fmt.Sprintf("failed to encrypt public key for %x",
+			serializedPubKey)
+		return nil, managerError(ErrCrypto, str, err)
+	}
+
+	// The start block needs to be updated when the newly imported address
+	// is before the current one.
+	s.rootManager.mtx.Lock()
+	updateStartBlock := bs.Height < s.rootManager.syncState.startBlock.Height
+	s.rootManager.mtx.Unlock()
+
+	err = putImportedAddress(
+		ns, &s.scope, addressID, ImportedAddrAccount, ssNone,
+		encryptedPubKey, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateStartBlock {
+		if err := putStartBlock(ns, bs); err != nil {
+			return nil, err
+		}
+		s.rootManager.mtx.Lock()
+		s.rootManager.syncState.startBlock = *bs
+		s.rootManager.mtx.Unlock()
+	}
+
+	s.addrs[addrKey(addressID)] = managedAddr
+	return managedAddr, nil
+}
+
 func (s *ScopedKeyManager) ImportWitnessScript(ns walletdb.ReadWriteBucket,
 	script []byte, bs *BlockStamp) (ManagedScriptAddress, er.R) {
 
@@ -0,0 +1,424 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/internal/txsizes"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
+)
+
+// bumpFeeSequence is the sequence number of the inputs of the transactions
+// made by BumpFee, it signals that they can be replaced in turn.
+const bumpFeeSequence = constants.MaxTxInSequenceNum - 2
+
+// BumpFeeResult is the result of BumpFee.
+type BumpFeeResult struct {
+	// Tx is the transaction which was sent to speed up the original one.
+	Tx *wire.MsgTx
+
+	// Replaced is true if Tx replaces the original transaction, and false
+	// if Tx is a child which spends the change of the original transaction
+	// to pay for it.
+	Replaced bool
+
+	// OrigFee is the fee of the original transaction and Fee is the fee
+	// of Tx.
+	OrigFee btcutil.Amount
+	Fee     btcutil.Amount
+}
+
+// bumpFeeTx is an unmined transaction of the wallet whose fee is bumped.
+type bumpFeeTx struct {
+	details *wtxmgr.TxDetails
+
+	// credits are the outputs spent by the inputs of the transaction, in
+	// the order of the inputs.
+	credits    []*wtxmgr.Credit
+	inputTotal btcutil.Amount
+
+	fee   btcutil.Amount
+	vsize int
+
+	// changeIndex is the index of the output which pays the change back
+	// to the wallet, or -1 if there is none.
+	changeIndex int
+}
+
+// replaceable returns true if the transaction signals that it can be replaced
+// as described in BIP0125.
+func (b *bumpFeeTx) replaceable() bool {
+	for _, in := range b.details.MsgTx.TxIn {
+		if in.Sequence < constants.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// txVirtualSize returns the virtual size of a signed transaction.
+func txVirtualSize(tx *wire.MsgTx) int {
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx))
+	return int((weight + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor)
+}
+
+// BumpFee speeds up the confirmation of the unmined transaction txid, which
+// was sent by the wallet, by paying a fee rate of feeSatPerKB.  If feeSatPerKB
+// is 0 then the fee rate of the original transaction is raised by the default
+// relay fee.
+//
+// If the original transaction signals that it can be replaced, as described in
+// BIP0125, then it is replaced by a transaction which pays the same outputs
+// from the same inputs, plus more confirmed outputs of the wallet if they are
+// needed to pay the fee, and it is marked as replaced in the transaction store.
+// Otherwise a child transaction which spends the change of the original back
+// to the wallet is sent, so that both transactions together pay feeSatPerKB.
+func (w *Wallet) BumpFee(txid *chainhash.Hash, feeSatPerKB btcutil.Amount) (*BumpFeeResult, er.R) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the current block first so that the database is not held open for
+	// writing while waiting on the chain backend.
+	bs, err := chainClient.BlockStamp()
+	if err != nil {
+		return nil, err
+	}
+
+	dbtx, err := w.db.BeginReadWriteTx()
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback()
+
+	orig, err := w.fetchBumpFeeTx(dbtx.ReadBucket(wtxmgrNamespaceKey), txid)
+	if err != nil {
+		return nil, err
+	}
+	if feeSatPerKB == 0 {
+		feeSatPerKB = orig.fee*1000/btcutil.Amount(orig.vsize) +
+			txrules.DefaultRelayFeePerKb
+	}
+
+	res := &BumpFeeResult{
+		Replaced: orig.replaceable(),
+		OrigFee:  orig.fee,
+	}
+	if res.Replaced {
+		res.Tx, res.Fee, err = w.replaceTx(dbtx, orig, feeSatPerKB, bs)
+	} else {
+		res.Tx, res.Fee, err = w.childPaysForParent(dbtx, orig, feeSatPerKB)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbtx.Commit(); err != nil {
+		return nil, err
+	}
+
+	hash, err := w.reliablyPublishTransaction(res.Tx, orig.details.Label)
+	if err != nil {
+		return nil, err
+	}
+	if res.Replaced {
+		err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+			ns := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+			return w.TxStore.ReplaceUnminedTx(ns, &orig.details.TxRecord, hash)
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Bumped fee of transaction [%s] from [%s] to [%s] by replacing it with [%s]",
+			log.Txid(txid.String()), orig.fee.String(), res.Fee.String(),
+			log.Txid(hash.String()))
+	} else {
+		log.Infof("Bumped fee of transaction [%s] by [%s] with child transaction [%s]",
+			log.Txid(txid.String()), res.Fee.String(), log.Txid(hash.String()))
+	}
+
+	return res, nil
+}
+
+// fetchBumpFeeTx looks up the unmined transaction txid and the outputs which it
+// spends, all of which must belong to the wallet.
+func (w *Wallet) fetchBumpFeeTx(ns walletdb.ReadBucket, txid *chainhash.Hash) (*bumpFeeTx, er.R) {
+	replacement, err := w.TxStore.ReplacedBy(ns, txid)
+	if err != nil {
+		return nil, err
+	}
+	if replacement != nil {
+		return nil, er.Errorf("transaction [%s] was already replaced by [%s]",
+			txid, replacement)
+	}
+
+	details, err := w.TxStore.TxDetails(ns, txid)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, er.Errorf("transaction [%s] is not known to the wallet", txid)
+	}
+	if details.Block.Height != -1 {
+		return nil, er.Errorf("transaction [%s] is already mined", txid)
+	}
+	msgTx := &details.MsgTx
+	if len(details.Debits) != len(msgTx.TxIn) {
+		return nil, er.Errorf("transaction [%s] spends outputs which do not "+
+			"belong to the wallet", txid)
+	}
+
+	orig := &bumpFeeTx{
+		details:     details,
+		credits:     make([]*wtxmgr.Credit, 0, len(msgTx.TxIn)),
+		changeIndex: -1,
+	}
+	for _, in := range msgTx.TxIn {
+		prevOut := in.PreviousOutPoint
+		prev, err := w.TxStore.TxDetails(ns, &prevOut.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if prev == nil || int(prevOut.Index) >= len(prev.MsgTx.TxOut) {
+			return nil, er.Errorf("output [%s] spent by transaction [%s] "+
+				"is not known to the wallet", prevOut.String(), txid)
+		}
+		txOut := prev.MsgTx.TxOut[prevOut.Index]
+		orig.credits = append(orig.credits, &wtxmgr.Credit{
+			OutPoint: prevOut,
+			Amount:   btcutil.Amount(txOut.Value),
+			PkScript: txOut.PkScript,
+		})
+		orig.inputTotal += btcutil.Amount(txOut.Value)
+	}
+	orig.fee = orig.inputTotal
+	for _, txOut := range msgTx.TxOut {
+		orig.fee -= btcutil.Amount(txOut.Value)
+	}
+	orig.vsize = txVirtualSize(msgTx)
+
+	// Spending any output would make the descendants of the transaction
+	// invalid once it is replaced, and with a child spending the change
+	// the fee should be bumped by bumping the fee of the child.
+	for _, c := range details.Credits {
+		if c.Spent {
+			return nil, er.Errorf("output [%d] of transaction [%s] is already "+
+				"spent by an unmined transaction", c.Index, txid)
+		}
+	}
+
+	// The change is sent back to the address of an input unless the
+	// transaction was made with a change address, so an output which pays
+	// to the script of an input is change too.
+	for _, c := range details.Credits {
+		if c.Change {
+			orig.changeIndex = int(c.Index)
+			break
+		}
+	}
+	for _, c := range details.Credits {
+		if orig.changeIndex >= 0 {
+			break
+		}
+		pkScript := msgTx.TxOut[c.Index].PkScript
+		for _, credit := range orig.credits {
+			if bytes.Equal(credit.PkScript, pkScript) {
+				orig.changeIndex = int(c.Index)
+				break
+			}
+		}
+	}
+
+	return orig, nil
+}
+
+// replaceTx makes and signs a transaction which replaces orig as described in
+// BIP0125.  It returns the transaction and its fee.
+func (w *Wallet) replaceTx(dbtx walletdb.ReadWriteTx, orig *bumpFeeTx,
+	feeSatPerKB btcutil.Amount, bs *waddrmgr.BlockStamp) (*wire.MsgTx, btcutil.Amount, er.R) {
+
+	addrmgrNs, newChangeSource := w.addrMgrWithChangeSource(
+		dbtx, waddrmgr.DefaultAccountNum,
+	)
+	changeSource := newChangeSource
+	if orig.changeIndex >= 0 {
+		changeScript := orig.details.MsgTx.TxOut[orig.changeIndex].PkScript
+		changeSource = func() ([]byte, er.R) {
+			return changeScript, nil
+		}
+	}
+
+	// The replacement pays the same outputs, but for the change which pays
+	// the increase of the fee.  If all outputs are change then all of the
+	// inputs are swept back to the wallet.
+	var outputs []*wire.TxOut
+	var outputTotal btcutil.Amount
+	for i, txOut := range orig.details.MsgTx.TxOut {
+		if i == orig.changeIndex {
+			continue
+		}
+		outputs = append(outputs, wire.NewTxOut(txOut.Value, txOut.PkScript))
+		outputTotal += btcutil.Amount(txOut.Value)
+	}
+	if len(outputs) == 0 {
+		changeScript, err := changeSource()
+		if err != nil {
+			return nil, 0, err
+		}
+		outputs = append(outputs, wire.NewTxOut(0, changeScript))
+	}
+
+	// More inputs are only added if the original inputs cannot pay for the
+	// outputs with a fee twice as large as the original transaction at the
+	// new fee rate, and they must be confirmed since a replacement may not
+	// spend unconfirmed outputs which the original did not spend.
+	credits := append([]*wtxmgr.Credit{}, orig.credits...)
+	needAmount := outputTotal - orig.inputTotal +
+		txrules.FeeForSerializeSize(feeSatPerKB, 2*orig.vsize)
+	if outputTotal > 0 && needAmount > 0 {
+		eligibleOuts, err := w.findEligibleOutputs(
+			dbtx, needAmount, nil, 1, bs, 0, 0, nil, 0, false, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		credits = append(credits, eligibleOuts.credits...)
+	}
+
+	// All of the inputs of the original are spent by the replacement,
+	// otherwise the original could still be mined.
+	fetchInputs := makeInputSource(credits)
+	inputSource := func(target btcutil.Amount) (btcutil.Amount, []*wire.TxIn, []wire.TxInAdditional, er.R) {
+		if target < orig.inputTotal {
+			target = orig.inputTotal
+		}
+		return fetchInputs(target)
+	}
+	tx, err := txauthor.NewUnsignedTransaction(
+		outputs, feeSatPerKB, inputSource, changeSource, false)
+	if err != nil {
+		if txauthor.ImpossibleTxError.Is(err) {
+			return nil, 0, InsufficientFundsError.New("wallet does not have "+
+				"enough confirmed balance to pay the fee of the replacement", err)
+		}
+		return nil, 0, err
+	}
+	for _, in := range tx.Tx.TxIn {
+		in.Sequence = bumpFeeSequence
+	}
+	if tx.ChangeIndex >= 0 {
+		tx.RandomizeChangePosition()
+	}
+
+	err = tx.AddAllInputScripts(secretSource{w.Manager, addrmgrNs})
+	if err != nil {
+		return nil, 0, err
+	}
+	err = validateMsgTx1(tx.Tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The replacement must pay more than the original, and the increase
+	// must pay for its own relay.
+	fee := tx.TotalInput
+	for _, txOut := range tx.Tx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	minFee := orig.fee + txrules.FeeForSerializeSize(
+		txrules.DefaultRelayFeePerKb, txVirtualSize(tx.Tx))
+	if fee < minFee {
+		return nil, 0, er.Errorf("fee of [%s] is less than the [%s] needed to "+
+			"replace transaction [%s], use a higher fee rate", fee.String(),
+			minFee.String(), orig.details.Hash.String())
+	}
+
+	return tx.Tx, fee, nil
+}
+
+// childPaysForParent makes and signs a transaction which spends the change of
+// orig back to the wallet with a fee such that both transactions together pay
+// feeSatPerKB.  It returns the transaction and its fee.
+func (w *Wallet) childPaysForParent(dbtx walletdb.ReadWriteTx, orig *bumpFeeTx,
+	feeSatPerKB btcutil.Amount) (*wire.MsgTx, btcutil.Amount, er.R) {
+
+	if orig.changeIndex < 0 {
+		return nil, 0, er.Errorf("transaction [%s] cannot be replaced and has "+
+			"no change to pay for it", orig.details.Hash.String())
+	}
+	change := orig.details.MsgTx.TxOut[orig.changeIndex]
+
+	addrmgrNs, changeSource := w.addrMgrWithChangeSource(
+		dbtx, waddrmgr.DefaultAccountNum,
+	)
+	changeScript, err := changeSource()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nested, p2wpkh, p2pkh int
+	switch {
+	case txscript.IsPayToScriptHash(change.PkScript):
+		nested++
+	case txscript.IsPayToWitnessPubKeyHash(change.PkScript):
+		p2wpkh++
+	default:
+		p2pkh++
+	}
+	out := wire.NewTxOut(0, changeScript)
+	vsize := txsizes.EstimateVirtualSize(p2pkh, p2wpkh, nested,
+		[]*wire.TxOut{out}, false)
+
+	fee := txrules.FeeForSerializeSize(feeSatPerKB, orig.vsize+vsize) - orig.fee
+	if minFee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, vsize); fee < minFee {
+		fee = minFee
+	}
+	out.Value = change.Value - int64(fee)
+	if txrules.IsDustAmount(btcutil.Amount(out.Value), len(changeScript),
+		txrules.DefaultRelayFeePerKb) {
+
+		return nil, 0, InsufficientFundsError.New(fmt.Sprintf("change of [%s] "+
+			"cannot pay a fee of [%s]", btcutil.Amount(change.Value).String(),
+			fee.String()), nil)
+	}
+
+	outPoint := wire.OutPoint{
+		Hash:  orig.details.Hash,
+		Index: uint32(orig.changeIndex),
+	}
+	in := wire.NewTxIn(&outPoint, nil, nil)
+	in.Sequence = bumpFeeSequence
+	value := change.Value
+	tx := &wire.MsgTx{
+		Version: constants.TxVersion,
+		TxIn:    []*wire.TxIn{in},
+		TxOut:   []*wire.TxOut{out},
+		Additional: []wire.TxInAdditional{{
+			PkScript: change.PkScript,
+			Value:    &value,
+		}},
+	}
+	err = txauthor.AddAllInputScripts(tx, secretSource{w.Manager, addrmgrNs})
+	if err != nil {
+		return nil, 0, err
+	}
+	err = validateMsgTx1(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tx, fee, nil
+}
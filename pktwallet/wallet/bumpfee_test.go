@@ -0,0 +1,173 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestBumpFee checks that the fee of a transaction which does not signal
+// replaceability is bumped with a child spending its change, and that a
+// transaction which does is replaced using more inputs when needed.
+func TestBumpFee(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.CurrentAddress(0, waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to get current address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to convert wallet address to p2wkh: %v", err)
+	}
+	destAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), w.chainParams)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		t.Fatalf("unable to convert address to p2wkh: %v", err)
+	}
+
+	addUtxo(t, w, &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{PreviousOutPoint: wire.OutPoint{Index: 0}}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(100000000, pkScript)},
+	})
+	sent, err := w.SendOutputs(CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(50000000, destScript)},
+		Minconf:     1,
+		FeeSatPerKB: 1000,
+	})
+	if err != nil {
+		t.Fatalf("SendOutputs: %v", err)
+	}
+	parentHash := sent.Tx.TxHash()
+
+	// The transaction does not signal replaceability so a child pays for
+	// it.
+	res, err := w.BumpFee(&parentHash, 10000)
+	if err != nil {
+		t.Fatalf("BumpFee: %v", err)
+	}
+	if res.Replaced || len(res.Tx.TxIn) != 1 || len(res.Tx.TxOut) != 1 {
+		t.Fatalf("expected a child transaction, got %+v", res)
+	}
+	if in := res.Tx.TxIn[0].PreviousOutPoint; in.Hash != parentHash ||
+		sent.Tx.TxOut[in.Index].Value == 50000000 {
+
+		t.Fatalf("child spends %v instead of the change", in)
+	}
+	if res.Fee <= res.OrigFee {
+		t.Fatalf("child pays %v which is less than the parent's %v",
+			res.Fee, res.OrigFee)
+	}
+	if err := validateMsgTx1(res.Tx); err != nil {
+		t.Fatalf("invalid transaction: %v", err)
+	}
+
+	// Once the change is spent the fee of the parent cannot be bumped.
+	if _, err := w.BumpFee(&parentHash, 10000); err == nil {
+		t.Fatalf("bumped the fee of a transaction whose change is spent")
+	}
+
+	// A transaction which signals replaceability and whose change cannot
+	// pay the new fee.
+	addUtxo(t, w, &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{PreviousOutPoint: wire.OutPoint{Index: 1}}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(1000000, pkScript)},
+	})
+	orig, err := w.CreateSimpleTx(CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(999000, destScript)},
+		Minconf:     1,
+		FeeSatPerKB: 1000,
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateSimpleTx: %v", err)
+	}
+	for _, in := range orig.Tx.TxIn {
+		in.Sequence = bumpFeeSequence
+	}
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		return orig.AddAllInputScripts(secretSource{w.Manager, addrmgrNs})
+	})
+	if err != nil {
+		t.Fatalf("unable to sign transaction: %v", err)
+	}
+	origHash, err := w.reliablyPublishTransaction(orig.Tx, "")
+	if err != nil {
+		t.Fatalf("unable to publish transaction: %v", err)
+	}
+	addUtxo(t, w, &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{PreviousOutPoint: wire.OutPoint{Index: 2}}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(100000000, pkScript)},
+	})
+
+	res, err = w.BumpFee(origHash, 100000)
+	if err != nil {
+		t.Fatalf("BumpFee: %v", err)
+	}
+	if !res.Replaced || len(res.Tx.TxIn) != 2 || res.Fee <= res.OrigFee {
+		t.Fatalf("expected a replacement with 2 inputs, got %+v", res)
+	}
+	spent := false
+	for _, in := range res.Tx.TxIn {
+		if in.PreviousOutPoint == orig.Tx.TxIn[0].PreviousOutPoint {
+			spent = true
+		}
+		if in.Sequence != bumpFeeSequence {
+			t.Fatalf("replacement input has sequence %d", in.Sequence)
+		}
+	}
+	paid := false
+	for _, out := range res.Tx.TxOut {
+		if out.Value == 999000 && bytes.Equal(out.PkScript, destScript) {
+			paid = true
+		}
+	}
+	if !spent || !paid {
+		t.Fatalf("replacement does not spend the input and pay the " +
+			"output of the original")
+	}
+	if err := validateMsgTx1(res.Tx); err != nil {
+		t.Fatalf("invalid transaction: %v", err)
+	}
+
+	replHash := res.Tx.TxHash()
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket(wtxmgrNamespaceKey)
+		replacedBy, err := w.TxStore.ReplacedBy(ns, origHash)
+		if err != nil {
+			return err
+		}
+		if replacedBy == nil || *replacedBy != replHash {
+			t.Fatalf("expected %v to be replaced by %v, got %v",
+				origHash, replHash, replacedBy)
+		}
+		details, err := w.TxStore.TxDetails(ns, origHash)
+		if err != nil {
+			return err
+		}
+		if details != nil {
+			t.Fatalf("replaced transaction is still in the store")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to query the store: %v", err)
+	}
+
+	// A replaced transaction cannot be bumped again.
+	if _, err := w.BumpFee(origHash, 0); err == nil {
+		t.Fatalf("bumped the fee of a replaced transaction")
+	}
+}
@@ -128,6 +128,9 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord,
 				if err != nil {
 					return err
 				}
+				if err := w.extendWatchOnlyGap(addrmgrNs, ma); err != nil {
+					return err
+				}
 				txOutAmt := btcutil.Amount(rec.MsgTx.TxOut[i].Value)
 				if !isNew {
 					// don't log when we see the same money again
@@ -42,6 +42,12 @@ var TooManyInputsError = er.GenericErrorType.CodeWithDetail("TooManyInputsError"
 var UnconfirmedCoinsError = er.GenericErrorType.CodeWithDetail("UnconfirmedCoinsError",
 	"unable to construct transaction, there are coins but they are not yet confirmed")
 
+var TooFewInputsError = er.GenericErrorType.CodeWithDetail("TooFewInputsError",
+	"unable to construct transaction because there are not enough eligible inputs")
+
+var FeeTooHighError = er.GenericErrorType.CodeWithDetail("FeeTooHighError",
+	"unable to construct transaction because the fee exceeds the maximum allowed")
+
 func makeInputSource(eligible []*wtxmgr.Credit) txauthor.InputSource {
 	// Current inputs and their total value.  These are closed over by the
 	// returned input source and reused across multiple calls.
@@ -148,9 +154,13 @@ func (w *Wallet) txToOutputs(txr CreateTxReq) (tx *txauthor.AuthoredTx, err er.R
 	if sweepOutput != nil {
 		needAmount = 0
 	}
+	if txr.WatchOnly && !txr.DryRun {
+		return nil, er.New("outputs of watch-only accounts can only be spent in a dry run")
+	}
 	eligibleOuts, err := w.findEligibleOutputs(
 		dbtx, needAmount, txr.InputAddresses, txr.Minconf, bs,
-		txr.InputMinHeight, txr.InputComparator, txr.MaxInputs)
+		txr.InputMinHeight, txr.InputMaxAmount, txr.InputComparator, txr.MaxInputs,
+		txr.Fold, txr.WatchOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -196,8 +206,11 @@ func (w *Wallet) txToOutputs(txr CreateTxReq) (tx *txauthor.AuthoredTx, err er.R
 		}
 		return txscript.PayToAddrScript(changeAddr)
 	}
+	// A fold which cannot pay its fee is done, rather than retried as a partial
+	// spend.
 	tx, err = txauthor.NewUnsignedTransaction(
-		txr.Outputs, txr.FeeSatPerKB, inputSource, changeSource, txr.MaxInputs > -1)
+		txr.Outputs, txr.FeeSatPerKB, inputSource, changeSource,
+		txr.MaxInputs > -1 && !txr.Fold)
 	if err != nil {
 		if !txauthor.ImpossibleTxError.Is(err) {
 			return nil, err
@@ -220,6 +233,23 @@ func (w *Wallet) txToOutputs(txr CreateTxReq) (tx *txauthor.AuthoredTx, err er.R
 		}
 	}
 
+	if len(tx.Tx.TxIn) < txr.MinInputs {
+		return nil, TooFewInputsError.New(
+			fmt.Sprintf("transaction has [%d] inputs but at least [%d] are required",
+				len(tx.Tx.TxIn), txr.MinInputs), nil)
+	}
+	if txr.MaxFee > 0 {
+		fee := tx.TotalInput
+		for _, out := range tx.Tx.TxOut {
+			fee -= btcutil.Amount(out.Value)
+		}
+		if fee > txr.MaxFee {
+			return nil, FeeTooHighError.New(
+				fmt.Sprintf("fee of [%s] is more than the maximum of [%s]",
+					fee.String(), txr.MaxFee.String()), nil)
+		}
+	}
+
 	// Randomize change position, if change exists, before signing.  This
 	// doesn't affect the serialize size, so the change amount will still
 	// be valid.
@@ -292,10 +322,14 @@ type amountCount struct {
 	credits *redblacktree.Tree
 }
 
-func (a *amountCount) overLimit(maxInputs int) bool {
+func (a *amountCount) overLimit(maxInputs int, fold bool) bool {
 	count := a.credits.Size()
-	if maxInputs > 0 {
+	if maxInputs > 0 && !fold {
 		return count > maxInputs
+	} else if maxInputs > 0 && count > maxInputs {
+		// When folding, an explicit limit can only lower the number of inputs,
+		// the defaults keep the transaction within the standard weight.
+		return true
 	} else if count < MaxInputsPerTxLegacy {
 	} else if a.isSegwit && count < MaxInputsPerTx {
 	} else {
@@ -399,19 +433,30 @@ func (w *Wallet) findEligibleOutputs(
 	minconf int32,
 	bs *waddrmgr.BlockStamp,
 	inputMinHeight int,
+	inputMaxAmount btcutil.Amount,
 	inputComparator utils.Comparator,
 	maxInputs int,
+	fold bool,
+	watchOnly bool,
 ) (eligibleOutputs, er.R) {
 	out := eligibleOutputs{}
 	chainClient, err := w.requireChainClient()
 	if err != nil {
 		return out, err
 	}
+	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
 
 	haveAmounts := make(map[string]*amountCount)
 	var winner *amountCount
 
+	// Outputs of watch-only accounts cannot be signed so they are only
+	// eligible when a watch-only transaction is requested, and then they
+	// are the only eligible outputs.  Whether a script belongs to a
+	// watch-only account is remembered since all outputs of one script
+	// belong to the same account.
+	skipScripts := make(map[string]bool)
+
 	if err := w.TxStore.ForEachUnspentOutput(txmgrNs, nil, func(_ []byte, output *wtxmgr.Credit) er.R {
 
 		// Verify that the output is coming from one of the addresses which we accept to spend from
@@ -423,12 +468,30 @@ func (w *Wallet) findEligibleOutputs(
 			return nil
 		}
 
+		str := hex.EncodeToString(output.PkScript)
+		skip, ok := skipScripts[str]
+		if !ok {
+			wo, err := w.watchOnlyScript(addrmgrNs, output.PkScript)
+			if err != nil {
+				return err
+			}
+			skip = wo != watchOnly
+			skipScripts[str] = skip
+		}
+		if skip {
+			return nil
+		}
+
 		if output.Height >= 0 && output.Height < int32(inputMinHeight) {
 			log.Debugf("Skipping output %s at height %d because it is below minimum %d",
 				output.String(), output.Height, inputMinHeight)
 			return nil
 		}
 
+		if inputMaxAmount > 0 && output.Amount >= inputMaxAmount {
+			return nil
+		}
+
 		if output.FromCoinBase {
 			if !confirmed(int32(w.chainParams.CoinbaseMaturity), output.Height, bs.Height) {
 				log.Debugf("Skipping immature coinbase output [%s] at height %d",
@@ -468,7 +531,6 @@ func (w *Wallet) findEligibleOutputs(
 			return nil
 		}
 
-		str := hex.EncodeToString(output.PkScript)
 		ha := haveAmounts[str]
 		if ha == nil {
 			haa := amountCount{}
@@ -520,9 +582,9 @@ func (w *Wallet) findEligibleOutputs(
 			}
 		}
 
-		if !ha.overLimit(maxInputs) {
+		if !ha.overLimit(maxInputs, fold) {
 			// We don't have too many inputs
-		} else if needAmount == 0 && inputComparator == nil {
+		} else if needAmount == 0 && inputComparator == nil && !fold {
 			// We're sweeping the wallet with no ordering specified
 			// This means we should just short-circuit with a winner
 			winner = ha
@@ -537,6 +599,13 @@ func (w *Wallet) findEligibleOutputs(
 			ha.amount -= worst.Amount
 			out.unusedAmt += worst.Amount
 			out.unusedCount++
+
+			if needAmount == 0 && inputComparator == nil {
+				// When folding, the winner is only taken once it is
+				// back within the limit
+				winner = ha
+				return er.LoopBreak
+			}
 		}
 		return nil
 	}); err != nil && !er.IsLoopBreak(err) {
@@ -587,7 +656,7 @@ func (w *Wallet) findEligibleOutputs(
 		outAc.isSegwit = outAc.isSegwit && ac.isSegwit
 
 		wasOver := false
-		for outAc.overLimit(maxInputs) {
+		for outAc.overLimit(maxInputs, fold) {
 			// Too many inputs, we will remove the worst
 			worst := outAc.credits.Right().Key.(*wtxmgr.Credit)
 			if worst == nil {
@@ -0,0 +1,123 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// inputCharset is the set of characters which may appear in a descriptor,
+// ordered so that the characters which are most common in descriptors fall in
+// the same group of 32 characters.
+const inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+// checksumCharset is the set of characters the checksum is encoded with, it is
+// the same as the bech32 character set.
+const checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// checksumLength is the number of characters of a descriptor checksum.
+const checksumLength = 8
+
+// polyMod computes the BCH code over GF(32) which the descriptor checksum is
+// made from.
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// Checksum returns the 8 character checksum of a descriptor, as defined by
+// BIP-0380.  The descriptor must not already carry a checksum.
+func Checksum(desc string) (string, er.R) {
+	c := uint64(1)
+	cls := 0
+	clsCount := 0
+	for i, ch := range desc {
+		pos := strings.IndexRune(inputCharset, ch)
+		if pos < 0 {
+			return "", ErrInvalidCharacter.New(
+				fmt.Sprintf("character [%c] at position [%d]", ch, i), nil)
+		}
+		// Emit a symbol for the position inside the group, for every
+		// character.
+		c = polyMod(c, pos&31)
+		// Accumulate the group numbers.
+		cls = cls*3 + (pos >> 5)
+		clsCount++
+		if clsCount == 3 {
+			// Emit an extra symbol representing the group numbers,
+			// for every 3 characters.
+			c = polyMod(c, cls)
+			cls = 0
+			clsCount = 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	for i := 0; i < checksumLength; i++ {
+		// Shift further to determine the checksum.
+		c = polyMod(c, 0)
+	}
+	// Prevent appending zeroes from not affecting the checksum.
+	c ^= 1
+
+	var ret [checksumLength]byte
+	for i := 0; i < checksumLength; i++ {
+		ret[i] = checksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(ret[:]), nil
+}
+
+// AddChecksum returns the descriptor followed by "#" and its checksum.
+func AddChecksum(desc string) (string, er.R) {
+	sum, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + sum, nil
+}
+
+// splitChecksum separates a descriptor from its checksum, and verifies the
+// checksum if there is one.
+func splitChecksum(desc string) (string, er.R) {
+	i := strings.LastIndexByte(desc, '#')
+	if i < 0 {
+		if _, err := Checksum(desc); err != nil {
+			return "", err
+		}
+		return desc, nil
+	}
+	body, sum := desc[:i], desc[i+1:]
+	if len(sum) != checksumLength {
+		return "", ErrChecksum.New(fmt.Sprintf("expected %d checksum "+
+			"characters, got [%s]", checksumLength, sum), nil)
+	}
+	expected, err := Checksum(body)
+	if err != nil {
+		return "", err
+	}
+	if sum != expected {
+		return "", ErrChecksum.New(fmt.Sprintf("expected [%s], got [%s]",
+			expected, sum), nil)
+	}
+	return body, nil
+}
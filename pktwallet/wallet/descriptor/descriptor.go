@@ -0,0 +1,540 @@
+// Package descriptor parses output script descriptors, as defined by BIP-0380
+// and the following BIPs, and derives the addresses which they describe.
+//
+// The supported descriptors are pkh(KEY), wpkh(KEY), sh(wpkh(KEY)),
+// sh(MULTI), wsh(MULTI) and sh(wsh(MULTI)) where MULTI is either
+// multi(k,KEY,...) or sortedmulti(k,KEY,...).  A KEY is a hex encoded public
+// key or an extended public key followed by unhardened derivation steps and
+// optionally a final /* which makes the descriptor ranged.  Either form of key
+// may be preceded by its origin, [fingerprint/path], which is kept but not
+// otherwise used.
+//
+// Descriptors which contain private keys are rejected, a descriptor is only
+// used to watch addresses.
+package descriptor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// Err is the type of the errors returned by this package.
+var Err er.ErrorType = er.NewErrorType("descriptor.Err")
+
+var (
+	// ErrInvalidCharacter is returned when a descriptor contains a
+	// character which is not allowed in descriptors.
+	ErrInvalidCharacter = Err.CodeWithDetail("ErrInvalidCharacter",
+		"invalid character in descriptor")
+
+	// ErrChecksum is returned when the checksum of a descriptor does not
+	// match.
+	ErrChecksum = Err.CodeWithDetail("ErrChecksum",
+		"invalid descriptor checksum")
+
+	// ErrSyntax is returned when a descriptor cannot be parsed.
+	ErrSyntax = Err.CodeWithDetail("ErrSyntax",
+		"invalid descriptor")
+
+	// ErrUnsupported is returned for a descriptor which is valid but is not
+	// supported by this package.
+	ErrUnsupported = Err.CodeWithDetail("ErrUnsupported",
+		"unsupported descriptor")
+
+	// ErrKey is returned when a key expression of a descriptor is invalid,
+	// is a private key or belongs to another network.
+	ErrKey = Err.CodeWithDetail("ErrKey",
+		"invalid key in descriptor")
+)
+
+// maxMultiKeys is the maximum number of keys of a multi() or sortedmulti()
+// expression, which is the limit of OP_CHECKMULTISIG in standard scripts.
+const maxMultiKeys = 16
+
+// maxScriptElementSize is the maximum size of a P2SH redeem script.
+const maxScriptElementSize = 520
+
+// Type is the kind of output script described by a descriptor.
+type Type int
+
+const (
+	// PKH is a pkh(KEY) descriptor.
+	PKH Type = iota
+
+	// WPKH is a wpkh(KEY) descriptor.
+	WPKH
+
+	// SHWPKH is a sh(wpkh(KEY)) descriptor.
+	SHWPKH
+
+	// SH is a sh(multi(...)) or sh(sortedmulti(...)) descriptor.
+	SH
+
+	// WSH is a wsh(multi(...)) or wsh(sortedmulti(...)) descriptor.
+	WSH
+
+	// SHWSH is a sh(wsh(multi(...))) or sh(wsh(sortedmulti(...)))
+	// descriptor.
+	SHWSH
+)
+
+// key is a key expression of a descriptor.
+type key struct {
+	// origin is the key origin, including its brackets, or empty.
+	origin string
+
+	// pubKey is the serialized public key when the key is not an extended
+	// key.
+	pubKey []byte
+
+	// extKey is the extended public key, and path are the derivation steps
+	// which follow it.  If ranged is true then the final step is the index
+	// of the derived address.
+	extKey *hdkeychain.ExtendedKey
+	path   []uint32
+	ranged bool
+}
+
+// String returns the key expression as it appears in a descriptor.
+func (k *key) String() string {
+	if k.extKey == nil {
+		return k.origin + hex.EncodeToString(k.pubKey)
+	}
+	var b strings.Builder
+	b.WriteString(k.origin)
+	b.WriteString(k.extKey.String())
+	for _, i := range k.path {
+		b.WriteString("/")
+		b.WriteString(strconv.FormatUint(uint64(i), 10))
+	}
+	if k.ranged {
+		b.WriteString("/*")
+	}
+	return b.String()
+}
+
+// derive returns the serialized public key of the key expression at index,
+// index is ignored when the key is not ranged.
+func (k *key) derive(index uint32) ([]byte, er.R) {
+	if k.extKey == nil {
+		return k.pubKey, nil
+	}
+	ek := k.extKey
+	for _, i := range k.path {
+		var err er.R
+		if ek, err = ek.Derive(i); err != nil {
+			return nil, err
+		}
+	}
+	if k.ranged {
+		var err er.R
+		if ek, err = ek.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	pub, err := ek.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// compressed returns false if the key expression is an uncompressed public
+// key, extended keys always derive compressed keys.
+func (k *key) compressed() bool {
+	return k.extKey != nil || len(k.pubKey) == btcec.PubKeyBytesLenCompressed
+}
+
+// Descriptor is a parsed output script descriptor.
+type Descriptor struct {
+	typ       Type
+	keys      []*key
+	threshold int
+	sorted    bool
+	params    *chaincfg.Params
+}
+
+// Output is an output script described by a descriptor at one index.
+type Output struct {
+	// Address is the address of the output.
+	Address btcutil.Address
+
+	// PkScript is the output script.
+	PkScript []byte
+
+	// PubKey is the serialized public key of a PKH, WPKH or SHWPKH output.
+	PubKey []byte
+
+	// RedeemScript is the redeem script of a SH, SHWPKH or SHWSH output.
+	RedeemScript []byte
+
+	// WitnessScript is the witness script of a WSH or SHWSH output.
+	WitnessScript []byte
+}
+
+// Parse parses a descriptor for the network params.  If the descriptor ends
+// with a checksum then the checksum is verified.
+func Parse(desc string, params *chaincfg.Params) (*Descriptor, er.R) {
+	body, err := splitChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	d := &Descriptor{params: params}
+	name, args, err := splitCall(body)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "pkh":
+		d.typ = PKH
+		err = d.parseSingleKey(args, true)
+	case "wpkh":
+		d.typ = WPKH
+		err = d.parseSingleKey(args, false)
+	case "sh":
+		inner, innerArgs, err := splitCall(args)
+		if err != nil {
+			return nil, err
+		}
+		switch inner {
+		case "wpkh":
+			d.typ = SHWPKH
+			err = d.parseSingleKey(innerArgs, false)
+		case "wsh":
+			d.typ = SHWSH
+			err = d.parseMultiCall(innerArgs, false)
+		case "multi", "sortedmulti":
+			d.typ = SH
+			err = d.parseMulti(inner, innerArgs, true)
+		default:
+			err = ErrUnsupported.New("sh("+inner+"(...))", nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case "wsh":
+		d.typ = WSH
+		err = d.parseMultiCall(args, false)
+	default:
+		err = ErrUnsupported.New(name+"(...)", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the scripts at the first index can be made, which catches
+	// redeem scripts that are too large.
+	if _, err := d.Derive(0); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// splitCall splits an expression of the form name(args).
+func splitCall(s string) (string, string, er.R) {
+	open := strings.IndexByte(s, '(')
+	if open < 1 || !strings.HasSuffix(s, ")") {
+		return "", "", ErrSyntax.New("expected name(...), got ["+s+"]", nil)
+	}
+	return s[:open], s[open+1 : len(s)-1], nil
+}
+
+// parseSingleKey parses the argument of pkh() or wpkh().
+func (d *Descriptor) parseSingleKey(args string, allowUncompressed bool) er.R {
+	k, err := parseKey(args, d.params, allowUncompressed)
+	if err != nil {
+		return err
+	}
+	d.keys = []*key{k}
+	return nil
+}
+
+// parseMultiCall parses the argument of wsh(), which must be multi() or
+// sortedmulti().
+func (d *Descriptor) parseMultiCall(args string, allowUncompressed bool) er.R {
+	name, innerArgs, err := splitCall(args)
+	if err != nil {
+		return err
+	}
+	if name != "multi" && name != "sortedmulti" {
+		return ErrUnsupported.New("wsh("+name+"(...))", nil)
+	}
+	return d.parseMulti(name, innerArgs, allowUncompressed)
+}
+
+// parseMulti parses the arguments of multi() or sortedmulti().
+func (d *Descriptor) parseMulti(name, args string, allowUncompressed bool) er.R {
+	parts := strings.Split(args, ",")
+	if len(parts) < 2 {
+		return ErrSyntax.New(name+"() requires a threshold and keys", nil)
+	}
+	threshold, errr := strconv.Atoi(parts[0])
+	if errr != nil {
+		return ErrSyntax.New("invalid threshold ["+parts[0]+"]", er.E(errr))
+	}
+	keys := parts[1:]
+	if len(keys) > maxMultiKeys {
+		return ErrSyntax.New(fmt.Sprintf("%s() has %d keys, no more "+
+			"than %d are allowed", name, len(keys), maxMultiKeys), nil)
+	}
+	if threshold < 1 || threshold > len(keys) {
+		return ErrSyntax.New(fmt.Sprintf("threshold of %s() must be "+
+			"between 1 and %d, got %d", name, len(keys), threshold), nil)
+	}
+	d.threshold = threshold
+	d.sorted = name == "sortedmulti"
+	for _, s := range keys {
+		k, err := parseKey(s, d.params, allowUncompressed)
+		if err != nil {
+			return err
+		}
+		d.keys = append(d.keys, k)
+	}
+	return nil
+}
+
+// parseKey parses a key expression.
+func parseKey(s string, params *chaincfg.Params, allowUncompressed bool) (*key, er.R) {
+	k := &key{}
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, ErrKey.New("unterminated key origin in ["+s+"]", nil)
+		}
+		if err := checkOrigin(s[1:end]); err != nil {
+			return nil, err
+		}
+		k.origin = s[:end+1]
+		s = s[end+1:]
+	}
+
+	// A hex encoded public key.
+	if b, errr := hex.DecodeString(s); errr == nil {
+		if !allowUncompressed && len(b) != btcec.PubKeyBytesLenCompressed {
+			return nil, ErrKey.New("uncompressed public keys are only "+
+				"allowed in pkh() and sh()", nil)
+		}
+		if _, err := btcec.ParsePubKey(b, btcec.S256()); err != nil {
+			return nil, ErrKey.New("invalid public key ["+s+"]", err)
+		}
+		k.pubKey = b
+		return k, nil
+	}
+
+	// An extended public key followed by derivation steps.
+	steps := strings.Split(s, "/")
+	extKey, err := hdkeychain.NewKeyFromString(steps[0])
+	if err != nil {
+		if _, errWif := btcutil.DecodeWIF(steps[0]); errWif == nil {
+			return nil, ErrKey.New("private keys are not supported", nil)
+		}
+		return nil, ErrKey.New("invalid key ["+steps[0]+"]", err)
+	}
+	if extKey.IsPrivate() {
+		return nil, ErrKey.New("private keys are not supported", nil)
+	}
+	if !extKey.IsForNet(params) {
+		return nil, ErrKey.New("key ["+steps[0]+"] is not for the "+
+			params.Name+" network", nil)
+	}
+	k.extKey = extKey
+	steps = steps[1:]
+	if len(steps) > 0 && steps[len(steps)-1] == "*" {
+		k.ranged = true
+		steps = steps[:len(steps)-1]
+	}
+	for _, step := range steps {
+		i, hardened, err := parseStep(step)
+		if err != nil {
+			return nil, err
+		}
+		if hardened {
+			return nil, ErrKey.New("hardened derivation step ["+step+
+				"] requires a private key", nil)
+		}
+		k.path = append(k.path, i)
+	}
+	return k, nil
+}
+
+// checkOrigin checks the contents of a key origin, which is a fingerprint of
+// 8 hex characters followed by derivation steps.
+func checkOrigin(origin string) er.R {
+	steps := strings.Split(origin, "/")
+	if b, errr := hex.DecodeString(steps[0]); errr != nil || len(b) != 4 {
+		return ErrKey.New("invalid key origin fingerprint ["+steps[0]+"]", nil)
+	}
+	for _, step := range steps[1:] {
+		if _, _, err := parseStep(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseStep parses a derivation step, a number which is hardened if it is
+// followed by ' or h.
+func parseStep(step string) (uint32, bool, er.R) {
+	hardened := strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h")
+	if hardened {
+		step = step[:len(step)-1]
+	}
+	i, errr := strconv.ParseUint(step, 10, 32)
+	if errr != nil || i >= hdkeychain.HardenedKeyStart {
+		return 0, false, ErrKey.New("invalid derivation step ["+step+"]", nil)
+	}
+	return uint32(i), hardened, nil
+}
+
+// Type returns the kind of output script the descriptor describes.
+func (d *Descriptor) Type() Type {
+	return d.typ
+}
+
+// IsRange returns true if the descriptor describes a different output script
+// at every index, which is the case if any of its keys ends with /*.
+func (d *Descriptor) IsRange() bool {
+	for _, k := range d.keys {
+		if k.ranged {
+			return true
+		}
+	}
+	return false
+}
+
+// body returns the descriptor without a checksum.
+func (d *Descriptor) body() string {
+	keys := make([]string, len(d.keys))
+	for i, k := range d.keys {
+		keys[i] = k.String()
+	}
+	multi := "multi"
+	if d.sorted {
+		multi = "sortedmulti"
+	}
+	multi = fmt.Sprintf("%s(%d,%s)", multi, d.threshold,
+		strings.Join(keys, ","))
+
+	switch d.typ {
+	case PKH:
+		return "pkh(" + keys[0] + ")"
+	case WPKH:
+		return "wpkh(" + keys[0] + ")"
+	case SHWPKH:
+		return "sh(wpkh(" + keys[0] + "))"
+	case SH:
+		return "sh(" + multi + ")"
+	case WSH:
+		return "wsh(" + multi + ")"
+	default:
+		return "sh(wsh(" + multi + "))"
+	}
+}
+
+// String returns the descriptor followed by its checksum.
+func (d *Descriptor) String() string {
+	// A parsed descriptor only contains characters of the input
+	// character set, so making the checksum cannot fail.
+	s, _ := AddChecksum(d.body())
+	return s
+}
+
+// Derive returns the output script which the descriptor describes at index,
+// index is ignored when the descriptor is not ranged.
+func (d *Descriptor) Derive(index uint32) (*Output, er.R) {
+	pubKeys := make([][]byte, len(d.keys))
+	for i, k := range d.keys {
+		pub, err := k.derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys[i] = pub
+	}
+
+	out := &Output{}
+	var err er.R
+	switch d.typ {
+	case PKH:
+		out.PubKey = pubKeys[0]
+		out.Address, err = btcutil.NewAddressPubKeyHash(
+			btcutil.Hash160(out.PubKey), d.params)
+	case WPKH, SHWPKH:
+		out.PubKey = pubKeys[0]
+		out.Address, err = btcutil.NewAddressWitnessPubKeyHash(
+			btcutil.Hash160(out.PubKey), d.params)
+		if err != nil || d.typ == WPKH {
+			break
+		}
+		if out.RedeemScript, err = txscript.PayToAddrScript(out.Address); err != nil {
+			break
+		}
+		out.Address, err = btcutil.NewAddressScriptHash(out.RedeemScript, d.params)
+	default:
+		var script []byte
+		if script, err = d.multiSigScript(pubKeys); err != nil {
+			break
+		}
+		if d.typ == SH {
+			if len(script) > maxScriptElementSize {
+				return nil, ErrUnsupported.New(fmt.Sprintf("redeem script "+
+					"of %d bytes is larger than %d bytes", len(script),
+					maxScriptElementSize), nil)
+			}
+			out.RedeemScript = script
+			out.Address, err = btcutil.NewAddressScriptHash(script, d.params)
+			break
+		}
+		out.WitnessScript = script
+		witnessProg := chainhash.HashB(script)
+		out.Address, err = btcutil.NewAddressWitnessScriptHash(witnessProg, d.params)
+		if err != nil || d.typ == WSH {
+			break
+		}
+		if out.RedeemScript, err = txscript.PayToAddrScript(out.Address); err != nil {
+			break
+		}
+		out.Address, err = btcutil.NewAddressScriptHash(out.RedeemScript, d.params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	out.PkScript, err = txscript.PayToAddrScript(out.Address)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// multiSigScript returns the multisig script of the keys of a multi() or
+// sortedmulti() expression.
+func (d *Descriptor) multiSigScript(pubKeys [][]byte) ([]byte, er.R) {
+	if d.sorted {
+		sorted := make([][]byte, len(pubKeys))
+		copy(sorted, pubKeys)
+		sort.Slice(sorted, func(i, j int) bool {
+			return bytes.Compare(sorted[i], sorted[j]) < 0
+		})
+		pubKeys = sorted
+	}
+	addrs := make([]*btcutil.AddressPubKey, len(pubKeys))
+	for i, pub := range pubKeys {
+		addr, err := btcutil.NewAddressPubKey(pub, d.params)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
+	}
+	return txscript.MultiSigScript(addrs, d.threshold)
+}
@@ -0,0 +1,193 @@
+package descriptor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// bip84Xpub is the BIP0084 account 0 key of the test mnemonic "abandon ...
+// about", encoded as an xpub.
+const bip84Xpub = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3Xyu" +
+	"vPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+
+const (
+	pubKey1 = "03a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56ac1c540c5bd"
+	pubKey2 = "022f8bde4d1a07209355b4a7250a5c5128e88b84bddc619ab7cba8d569b240efe4"
+	pubKey3 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+)
+
+func TestChecksum(t *testing.T) {
+	// Test vector of BIP-0380.
+	sum, err := Checksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	if sum != "89f8spxm" {
+		t.Fatalf("expected checksum 89f8spxm, got %s", sum)
+	}
+
+	if _, err := Checksum("pkh(é)"); !ErrInvalidCharacter.Is(err) {
+		t.Fatalf("expected ErrInvalidCharacter, got %v", err)
+	}
+	if _, err := splitChecksum("raw(deadbeef)#89f8spxn"); !ErrChecksum.Is(err) {
+		t.Fatalf("expected ErrChecksum, got %v", err)
+	}
+	if _, err := splitChecksum("raw(deadbeef)#89f8spx"); !ErrChecksum.Is(err) {
+		t.Fatalf("expected ErrChecksum, got %v", err)
+	}
+	body, err := splitChecksum("raw(deadbeef)#89f8spxm")
+	if err != nil || body != "raw(deadbeef)" {
+		t.Fatalf("unexpected body %q, error %v", body, err)
+	}
+}
+
+func TestDeriveWPKH(t *testing.T) {
+	desc := "wpkh([73c5da0a/84h/0h/0h]" + bip84Xpub + "/0/*)"
+	d, err := Parse(desc, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if d.Type() != WPKH || !d.IsRange() {
+		t.Fatalf("unexpected descriptor type %v, ranged %v", d.Type(), d.IsRange())
+	}
+
+	// The addresses of the BIP0084 test vectors.
+	expected := []string{
+		"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		"bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g",
+	}
+	for i, addr := range expected {
+		out, err := d.Derive(uint32(i))
+		if err != nil {
+			t.Fatalf("Derive(%d): %v", i, err)
+		}
+		if out.Address.EncodeAddress() != addr {
+			t.Fatalf("expected address %d to be %s, got %s", i, addr,
+				out.Address.EncodeAddress())
+		}
+	}
+
+	// The descriptor is printed with its checksum, and parses back to
+	// itself.
+	s := d.String()
+	if s[:len(desc)] != desc || len(s) != len(desc)+1+checksumLength {
+		t.Fatalf("unexpected descriptor string %s", s)
+	}
+	d2, err := Parse(s, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", s, err)
+	}
+	if d2.String() != s {
+		t.Fatalf("expected %s, got %s", s, d2.String())
+	}
+}
+
+func TestDeriveMulti(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	keys := pubKey1 + "," + pubKey2 + "," + pubKey3
+
+	multi, err := Parse("wsh(multi(2,"+keys+"))", params)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sorted, err := Parse("wsh(sortedmulti(2,"+keys+"))", params)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if multi.IsRange() {
+		t.Fatalf("descriptor without /* is ranged")
+	}
+	multiOut, err := multi.Derive(0)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	sortedOut, err := sorted.Derive(0)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+
+	// The witness script of multi() keeps the order of the keys, the one of
+	// sortedmulti() has them sorted.
+	pushes, err := txscript.PushedData(multiOut.WitnessScript)
+	if err != nil {
+		t.Fatalf("PushedData: %v", err)
+	}
+	if len(pushes) != 3 || bytes.Compare(pushes[0], pushes[1]) < 0 {
+		t.Fatalf("unexpected multi() witness script %x", multiOut.WitnessScript)
+	}
+	pushes, err = txscript.PushedData(sortedOut.WitnessScript)
+	if err != nil {
+		t.Fatalf("PushedData: %v", err)
+	}
+	if len(pushes) != 3 || bytes.Compare(pushes[0], pushes[1]) > 0 ||
+		bytes.Compare(pushes[1], pushes[2]) > 0 {
+
+		t.Fatalf("unexpected sortedmulti() witness script %x",
+			sortedOut.WitnessScript)
+	}
+
+	// The same script is used by wsh(), sh() and sh(wsh()).
+	class, _, nRequired, err := txscript.ExtractPkScriptAddrs(
+		multiOut.WitnessScript, params)
+	if err != nil || class != txscript.MultiSigTy || nRequired != 2 {
+		t.Fatalf("unexpected witness script class %v, %d required, error %v",
+			class, nRequired, err)
+	}
+	if _, ok := multiOut.Address.(*btcutil.AddressWitnessScriptHash); !ok {
+		t.Fatalf("unexpected wsh() address type %T", multiOut.Address)
+	}
+	sh, err := Parse("sh(multi(2,"+keys+"))", params)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	shOut, err := sh.Derive(0)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	if !bytes.Equal(shOut.RedeemScript, multiOut.WitnessScript) {
+		t.Fatalf("sh() and wsh() scripts differ")
+	}
+	shwsh, err := Parse("sh(wsh(multi(2,"+keys+")))", params)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	shwshOut, err := shwsh.Derive(0)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	if !bytes.Equal(shwshOut.WitnessScript, multiOut.WitnessScript) ||
+		!bytes.Equal(shwshOut.RedeemScript, multiOut.PkScript) {
+
+		t.Fatalf("unexpected sh(wsh()) scripts")
+	}
+	if _, ok := shwshOut.Address.(*btcutil.AddressScriptHash); !ok {
+		t.Fatalf("unexpected sh(wsh()) address type %T", shwshOut.Address)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+	}{
+		{"private extended key", "wpkh(xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi/0/*)"},
+		{"private key", "pkh(L4rK1yDtCWekvXuE6oXD9jCYfFNV2cWRpVuPLBcCU2z8TrisoyY1)"},
+		{"hardened step", "wpkh(" + bip84Xpub + "/0h/*)"},
+		{"wrong network", "wpkh(tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp/*)"},
+		{"uncompressed key in wpkh", "wpkh(0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8)"},
+		{"threshold too large", "wsh(multi(3," + pubKey1 + "," + pubKey2 + "))"},
+		{"unsupported", "tr(" + pubKey1 + ")"},
+		{"unsupported inside wsh", "wsh(pkh(" + pubKey1 + "))"},
+		{"missing parenthesis", "wpkh(" + pubKey1},
+		{"bad origin", "wpkh([73c5da0/84h]" + pubKey1 + ")"},
+	}
+	for _, test := range tests {
+		if _, err := Parse(test.desc, &chaincfg.MainNetParams); !Err.Is(err) {
+			t.Errorf("%s: expected a descriptor error, got %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/descriptor"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/watcher"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+)
+
+// DefaultDescriptorRangeEnd is the last index of the addresses which are
+// imported from a ranged descriptor when no range is given, so the addresses
+// at indexes 0 to 999 are imported.
+const DefaultDescriptorRangeEnd = 999
+
+// maxDescriptorRangeSize is the largest number of addresses which can be
+// imported from a ranged descriptor at once.
+const maxDescriptorRangeSize = 100000
+
+// descriptorScopes are the key scopes whose accounts are exported by
+// ListDescriptors.
+var descriptorScopes = []waddrmgr.KeyScope{
+	waddrmgr.KeyScopeBIP0044,
+	waddrmgr.KeyScopeBIP0049Plus,
+	waddrmgr.KeyScopeBIP0084,
+}
+
+// descriptorFormats maps the address types of the branches of an account to
+// the descriptor function of their addresses.  The branches of a scope may use
+// different address types, BIP0049Plus pays change to native segwit addresses.
+var descriptorFormats = map[waddrmgr.AddressType]string{
+	waddrmgr.PubKeyHash:          "pkh(%s)",
+	waddrmgr.NestedWitnessPubKey: "sh(wpkh(%s))",
+	waddrmgr.WitnessPubKey:       "wpkh(%s)",
+}
+
+// ImportDescriptorReq is a request to import the addresses of an output
+// descriptor into the wallet.
+type ImportDescriptorReq struct {
+	// Descriptor is the output descriptor, optionally followed by its
+	// checksum.
+	Descriptor string
+
+	// RangeStart and RangeEnd are the first and the last index of the
+	// addresses which are imported from a ranged descriptor, they are
+	// ignored for a descriptor which is not ranged.
+	RangeStart uint32
+	RangeEnd   uint32
+
+	// RescanFrom is the height from which the chain is rescanned for
+	// payments to the imported addresses.
+	RescanFrom int32
+}
+
+// ImportDescriptorResult is the outcome of an ImportDescriptorReq.
+type ImportDescriptorResult struct {
+	// Descriptor is the imported descriptor followed by its checksum, it
+	// is empty if the descriptor could not be parsed.
+	Descriptor string
+
+	// Addresses are the addresses which the descriptor describes.
+	Addresses []btcutil.Address
+
+	// Err is the reason why the descriptor was not imported, or nil.
+	Err er.R
+}
+
+// WalletDescriptor is an output descriptor of the addresses of the wallet.
+type WalletDescriptor struct {
+	// Descriptor is the output descriptor followed by its checksum.
+	Descriptor string
+
+	// Active is true if the descriptor describes the addresses of an
+	// account, which the wallet keeps deriving, false if it was imported.
+	Active bool
+
+	// Account is the name of the account of an active descriptor and
+	// Internal is true if the descriptor describes the change addresses of
+	// the account.
+	Account  string
+	Internal bool
+
+	// IsRange is true if the descriptor is ranged.  The addresses from
+	// RangeStart to RangeEnd of an imported ranged descriptor were
+	// imported, while Next is the index of the next address of an active
+	// descriptor.
+	IsRange    bool
+	RangeStart uint32
+	RangeEnd   uint32
+	Next       uint32
+}
+
+// ImportDescriptors imports the addresses described by output descriptors into
+// the wallet.  The addresses are imported as watch-only addresses, and the
+// wallet begins watching them.  If rescan is true then a resync is started at
+// the lowest RescanFrom of the requests to find past payments to the imported
+// addresses.
+//
+// Each descriptor is imported on its own, so the result of each request
+// carries the error which prevented the import of its descriptor, if any.
+// Descriptors of multisig scripts can only be imported while the wallet is
+// unlocked.
+func (w *Wallet) ImportDescriptors(reqs []ImportDescriptorReq,
+	rescan bool) ([]ImportDescriptorResult, er.R) {
+
+	if rescan {
+		w.rescanJLock.Lock()
+		defer w.rescanJLock.Unlock()
+		if w.rescanJ != nil {
+			return nil, er.Errorf(
+				"You requested a rescan but there is already a rescan job"+
+					" ([%v]) running, use `stopresync` to stop it", w.rescanJ.name)
+		}
+	}
+
+	results := make([]ImportDescriptorResult, len(reqs))
+	var addrs []btcutil.Address
+	rescanFrom := int32(-1)
+	for i, req := range reqs {
+		res := &results[i]
+		res.Addresses, res.Err = w.importDescriptor(req, &res.Descriptor)
+		if res.Err != nil {
+			log.Warnf("Unable to import descriptor [%s]: %v", req.Descriptor, res.Err)
+			continue
+		}
+		log.Infof("Imported [%d] addresses of descriptor [%s]",
+			len(res.Addresses), res.Descriptor)
+		addrs = append(addrs, res.Addresses...)
+		if rescanFrom < 0 || req.RescanFrom < rescanFrom {
+			rescanFrom = req.RescanFrom
+		}
+	}
+	w.watch.WatchAddrs(addrs)
+
+	if rescan && len(addrs) > 0 {
+		watch := watcher.New()
+		watch.WatchAddrs(addrs)
+		w.rescanJ = &rescanJob{
+			name:       "importdescriptors-resync",
+			height:     rescanFrom,
+			stopHeight: -1,
+			watch:      &watch,
+		}
+	}
+	return results, nil
+}
+
+// importDescriptor imports the addresses of one descriptor, and sets desc to
+// the descriptor followed by its checksum.
+func (w *Wallet) importDescriptor(req ImportDescriptorReq,
+	desc *string) ([]btcutil.Address, er.R) {
+
+	d, err := descriptor.Parse(req.Descriptor, w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	*desc = d.String()
+
+	start, end := req.RangeStart, req.RangeEnd
+	if !d.IsRange() {
+		start, end = 0, 0
+	} else if start > end {
+		return nil, er.Errorf("range start [%d] is greater than range end [%d]",
+			start, end)
+	} else if end-start >= maxDescriptorRangeSize {
+		return nil, er.Errorf("a range of more than [%d] addresses cannot "+
+			"be imported at once", maxDescriptorRangeSize)
+	}
+
+	// As with ImportP2SHRedeemScript, the addresses are imported as of the
+	// genesis block.
+	bs := &waddrmgr.BlockStamp{
+		Hash:   *w.ChainParams().GenesisHash,
+		Height: 0,
+	}
+
+	addrs := make([]btcutil.Address, 0, end-start+1)
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		for i := start; ; i++ {
+			out, err := d.Derive(i)
+			if err != nil {
+				return err
+			}
+			if err := w.importDescriptorOutput(addrmgrNs, d.Type(), out, bs); err != nil {
+				return err
+			}
+			addrs = append(addrs, out.Address)
+			if i == end {
+				break
+			}
+		}
+		return w.Manager.PutDescriptor(addrmgrNs, &waddrmgr.ImportedDescriptor{
+			Descriptor: *desc,
+			RangeStart: start,
+			RangeEnd:   end,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+// importDescriptorOutput imports the address of one output of a descriptor.
+// Public keys are imported into the scope of their address type, and scripts
+// into the BIP0084 scope as ImportP2SHRedeemScript does.  An address which is
+// already known to the wallet is left as it is.
+func (w *Wallet) importDescriptorOutput(addrmgrNs walletdb.ReadWriteBucket,
+	typ descriptor.Type, out *descriptor.Output, bs *waddrmgr.BlockStamp) er.R {
+
+	scope := waddrmgr.KeyScopeBIP0084
+	switch typ {
+	case descriptor.PKH:
+		scope = waddrmgr.KeyScopeBIP0044
+	case descriptor.SHWPKH:
+		scope = waddrmgr.KeyScopeBIP0049Plus
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return err
+	}
+
+	switch typ {
+	case descriptor.PKH, descriptor.WPKH, descriptor.SHWPKH:
+		_, err = manager.ImportPublicKey(addrmgrNs, out.PubKey, bs)
+	case descriptor.WSH:
+		_, err = manager.ImportWitnessScript(addrmgrNs, out.WitnessScript, bs)
+	default:
+		_, err = manager.ImportScript(addrmgrNs, out.RedeemScript, bs)
+	}
+	if waddrmgr.ErrDuplicateAddress.Is(err) {
+		return nil
+	}
+	return err
+}
+
+// ListDescriptors returns the output descriptors of the wallet.  These are the
+// descriptors of the receiving and change addresses of each account of the
+// BIP0044, BIP0049 and BIP0084 scopes, followed by the imported descriptors.
+// No private keys are exported, so the descriptors can only be used to watch
+// the addresses of the wallet.
+func (w *Wallet) ListDescriptors() ([]WalletDescriptor, er.R) {
+	var descs []WalletDescriptor
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		fingerprint, haveFingerprint, err := w.Manager.MasterFingerprint(addrmgrNs)
+		if err != nil {
+			return err
+		}
+
+		for _, scope := range descriptorScopes {
+			manager, err := w.Manager.FetchScopedKeyManager(scope)
+			if waddrmgr.ErrScopeNotFound.Is(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			err = manager.ForEachAccount(addrmgrNs, func(account uint32) er.R {
+				props, err := manager.AccountProperties(addrmgrNs, account)
+				if err != nil {
+					return err
+				}
+				if props.AccountPubKey == nil {
+					return nil
+				}
+
+				// The key of an imported account may be encoded as a
+				// ypub or zpub, which descriptors do not allow.
+				acctKey, err := props.AccountPubKey.CloneWithVersion(
+					w.chainParams.HDPublicKeyID[:])
+				if err != nil {
+					return err
+				}
+				origin := ""
+				if haveFingerprint && !props.WatchOnly {
+					origin = fmt.Sprintf("[%08x/%dh/%dh/%dh]", fingerprint,
+						scope.Purpose, scope.Coin, account)
+				}
+				schema := manager.AddrSchema()
+				for _, internal := range []bool{false, true} {
+					branch, next := waddrmgr.ExternalBranch, props.ExternalKeyCount
+					addrType := schema.ExternalAddrType
+					if internal {
+						branch, next = waddrmgr.InternalBranch, props.InternalKeyCount
+						addrType = schema.InternalAddrType
+					}
+					format, ok := descriptorFormats[addrType]
+					if !ok {
+						continue
+					}
+					key := fmt.Sprintf("%s%s/%d/*", origin, acctKey, branch)
+					desc, err := descriptor.AddChecksum(fmt.Sprintf(format, key))
+					if err != nil {
+						return err
+					}
+					descs = append(descs, WalletDescriptor{
+						Descriptor: desc,
+						Active:     true,
+						Account:    props.AccountName,
+						Internal:   internal,
+						IsRange:    true,
+						Next:       next,
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return w.Manager.ForEachDescriptor(addrmgrNs, func(d *waddrmgr.ImportedDescriptor) er.R {
+			parsed, err := descriptor.Parse(d.Descriptor, w.chainParams)
+			if err != nil {
+				return err
+			}
+			descs = append(descs, WalletDescriptor{
+				Descriptor: d.Descriptor,
+				IsRange:    parsed.IsRange(),
+				RangeStart: d.RangeStart,
+				RangeEnd:   d.RangeEnd,
+			})
+			return nil
+		})
+	})
+	return descs, err
+}
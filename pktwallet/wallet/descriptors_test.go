@@ -0,0 +1,217 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/descriptor"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestListDescriptors checks that the descriptors of the accounts of the
+// wallet describe the addresses of the wallet.
+func TestListDescriptors(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	descs, err := w.ListDescriptors()
+	if err != nil {
+		t.Fatalf("ListDescriptors: %v", err)
+	}
+	for _, d := range descs {
+		if !d.Active || !d.IsRange {
+			t.Fatalf("unexpected descriptor %+v", d)
+		}
+	}
+
+	// The next address of each branch of each account is the address
+	// which the descriptor of the branch derives at its next index.
+	for _, scope := range descriptorScopes {
+		origin := fmt.Sprintf("/%dh/%dh/0h]tpub", scope.Purpose, scope.Coin)
+		for _, internal := range []bool{false, true} {
+			var desc *WalletDescriptor
+			for i, d := range descs {
+				if d.Internal == internal &&
+					strings.Contains(d.Descriptor, origin) {
+
+					desc = &descs[i]
+				}
+			}
+			if desc == nil {
+				t.Fatalf("no descriptor of scope %v, internal %v "+
+					"in %+v", scope, internal, descs)
+			}
+
+			var addr btcutil.Address
+			if internal {
+				err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+					addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+					manager, err := w.Manager.FetchScopedKeyManager(scope)
+					if err != nil {
+						return err
+					}
+					addrs, err := manager.NextInternalAddresses(addrmgrNs, 0, 1)
+					if err != nil {
+						return err
+					}
+					addr = addrs[0].Address()
+					return nil
+				})
+			} else {
+				addr, err = w.NewAddress(0, scope)
+			}
+			if err != nil {
+				t.Fatalf("unable to derive address: %v", err)
+			}
+
+			d, err := descriptor.Parse(desc.Descriptor, w.chainParams)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			out, err := d.Derive(desc.Next)
+			if err != nil {
+				t.Fatalf("Derive: %v", err)
+			}
+			if out.Address.EncodeAddress() != addr.EncodeAddress() {
+				t.Fatalf("%s: expected address %s, got %s",
+					desc.Descriptor, addr, out.Address)
+			}
+		}
+	}
+}
+
+// TestImportDescriptors checks that the addresses of imported descriptors are
+// watched but only spent by dry runs, and that imported descriptors are listed.
+func TestImportDescriptors(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.MinSeedBytes)
+	if err != nil {
+		t.Fatalf("unable to create seed: %v", err)
+	}
+	root, err := hdkeychain.NewMaster(seed, w.chainParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	xpub, err := root.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter master key: %v", err)
+	}
+	ranged := "wpkh(" + xpub.String() + "/0/*)"
+	multi := "wsh(sortedmulti(1," + xpub.String() + "/1/0," +
+		xpub.String() + "/1/1))"
+
+	results, err := w.ImportDescriptors([]ImportDescriptorReq{
+		{Descriptor: ranged, RangeStart: 0, RangeEnd: 4},
+		{Descriptor: multi},
+		{Descriptor: "wpkh(" + xpub.String() + "/0h/*)"},
+	}, false)
+	if err != nil {
+		t.Fatalf("ImportDescriptors: %v", err)
+	}
+	if results[0].Err != nil || len(results[0].Addresses) != 5 {
+		t.Fatalf("unexpected result %+v", results[0])
+	}
+	if results[1].Err != nil || len(results[1].Addresses) != 1 {
+		t.Fatalf("unexpected result %+v", results[1])
+	}
+	if !descriptor.ErrKey.Is(results[2].Err) {
+		t.Fatalf("expected ErrKey, got %v", results[2].Err)
+	}
+	multiAddr := results[1].Addresses[0]
+	for _, addr := range append(results[0].Addresses, multiAddr) {
+		watchOnly, err := w.IsWatchOnlyAddress(addr)
+		if err != nil || !watchOnly {
+			t.Fatalf("expected %s to be watch-only, error %v", addr, err)
+		}
+	}
+
+	// Importing an overlapping range extends the imported range.
+	results, err = w.ImportDescriptors([]ImportDescriptorReq{
+		{Descriptor: results[0].Descriptor, RangeStart: 3, RangeEnd: 9},
+	}, false)
+	if err != nil || results[0].Err != nil {
+		t.Fatalf("ImportDescriptors: %v %v", err, results[0].Err)
+	}
+	descs, err := w.ListDescriptors()
+	if err != nil {
+		t.Fatalf("ListDescriptors: %v", err)
+	}
+	var imported []WalletDescriptor
+	for _, d := range descs {
+		if !d.Active {
+			imported = append(imported, d)
+		}
+	}
+	if len(imported) != 2 {
+		t.Fatalf("expected 2 imported descriptors, got %+v", imported)
+	}
+	for _, d := range imported {
+		if d.Descriptor == results[0].Descriptor {
+			if !d.IsRange || d.RangeStart != 0 || d.RangeEnd != 9 {
+				t.Fatalf("unexpected imported descriptor %+v", d)
+			}
+		} else if d.IsRange {
+			t.Fatalf("unexpected imported descriptor %+v", d)
+		}
+	}
+
+	// Payments to imported addresses, including the multisig address which
+	// the wallet cannot sign for, are credited, and spent only by a
+	// watch-only dry run.
+	pkScript, err := txscript.PayToAddrScript(results[0].Addresses[5])
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+	multiScript, err := txscript.PayToAddrScript(multiAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(&wire.MsgTx{
+			TxIn: []*wire.TxIn{{}},
+			TxOut: []*wire.TxOut{
+				wire.NewTxOut(100000000, pkScript),
+				wire.NewTxOut(100000000, multiScript),
+			},
+		}, time.Now())
+		if err != nil {
+			return err
+		}
+		return w.addRelevantTx(tx, rec, &wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{Hash: *testBlockHash, Height: testBlockHeight},
+			Time:  time.Unix(1387737310, 0),
+		})
+	})
+	if err != nil {
+		t.Fatalf("unable to add payment: %v", err)
+	}
+	req := CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(50000000, pkScript)},
+		Minconf:     1,
+		FeeSatPerKB: 1000,
+		DryRun:      true,
+	}
+	if _, err := w.CreateSimpleTx(req); !InsufficientFundsError.Is(err) {
+		t.Fatalf("expected InsufficientFundsError, got %v", err)
+	}
+	req.DryRun = false
+	if _, err := w.CreateSimpleTx(req); !InsufficientFundsError.Is(err) {
+		t.Fatalf("expected InsufficientFundsError, got %v", err)
+	}
+	w.Lock()
+	req.DryRun = true
+	req.WatchOnly = true
+	if _, err := w.CreateSimpleTx(req); err != nil {
+		t.Fatalf("CreateSimpleTx: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package wallet
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// foldLockName returns the name under which the outputs used by FoldCoins are
+// locked while it is folding the passed addresses, so that folds of different
+// addresses do not unlock each other's outputs.
+func foldLockName(addrs *[]btcutil.Address) string {
+	if addrs == nil {
+		return "foldcoins"
+	}
+	names := make([]string, 0, len(*addrs))
+	for _, a := range *addrs {
+		names = append(names, a.EncodeAddress())
+	}
+	sort.Strings(names)
+	return "foldcoins:" + strings.Join(names, ",")
+}
+
+// FoldCoinsReq is a request to consolidate many small outputs of the wallet
+// into a few large ones.
+type FoldCoinsReq struct {
+	// FromAddresses are the addresses whose outputs are folded, nil folds
+	// the outputs of the whole wallet.
+	FromAddresses *[]btcutil.Address
+
+	// ToAddress is the address which receives the folded coins.
+	ToAddress btcutil.Address
+
+	// Minconf is the number of confirmations an output needs to be folded.
+	Minconf int32
+
+	// MaxInputs is the maximum number of inputs in each transaction, if 0
+	// then each transaction is as large as a standard transaction can be.
+	MaxInputs int
+
+	// MaxAmount is the value from which an output is no longer considered
+	// small, if 0 then outputs of any value are folded.
+	MaxAmount btcutil.Amount
+
+	// MaxTransactions is the maximum number of transactions to make, if 0
+	// then folding continues until there is nothing left to fold.
+	MaxTransactions int
+
+	// MaxFee is the maximum total fee of all transactions, if 0 then there
+	// is no limit.
+	MaxFee btcutil.Amount
+
+	// FeeSatPerKB is the fee rate of the transactions.
+	FeeSatPerKB btcutil.Amount
+
+	// DryRun makes the transactions without signing or sending them.
+	DryRun bool
+
+	// Progress, if not nil, is called with each transaction once it is made.
+	Progress func(FoldCoinsTx)
+}
+
+// FoldCoinsTx is one transaction made by FoldCoins.
+type FoldCoinsTx struct {
+	Tx     *wire.MsgTx
+	Inputs int
+	Amount btcutil.Amount
+	Fee    btcutil.Amount
+}
+
+// FoldCoinsResult is the result of FoldCoins.
+type FoldCoinsResult struct {
+	Transactions []FoldCoinsTx
+	Inputs       int
+	Amount       btcutil.Amount
+	Fee          btcutil.Amount
+
+	// StopReason is the error which ended folding, it is nil if folding
+	// ended because MaxTransactions was reached.
+	StopReason er.R
+}
+
+// foldStopError returns true if err means that there is nothing more to be
+// folded within the limits of the request.
+func foldStopError(err er.R) bool {
+	return InsufficientFundsError.Is(err) ||
+		UnconfirmedCoinsError.Is(err) ||
+		TooManyInputsError.Is(err) ||
+		TooFewInputsError.Is(err) ||
+		FeeTooHighError.Is(err)
+}
+
+// FoldCoins repeatedly makes transactions which sweep as many small outputs as
+// will fit in one transaction to r.ToAddress, until there are no more than one
+// eligible output left or one of the limits of the request is reached.
+//
+// If an unexpected error occurs, the transactions which were made before it
+// are returned along with the error.
+func (w *Wallet) FoldCoins(r FoldCoinsReq) (*FoldCoinsResult, er.R) {
+	pkScript, err := txscript.PayToAddrScript(r.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// The inputs of each transaction are locked so they are not chosen again
+	// by a dry run, and the folded outputs are locked so they are not folded
+	// again when they are spent with minconf=0.
+	lockName := foldLockName(r.FromAddresses)
+	defer w.ResetLockedOutpoints(&lockName)
+
+	res := &FoldCoinsResult{}
+	for r.MaxTransactions <= 0 || len(res.Transactions) < r.MaxTransactions {
+		var maxFee btcutil.Amount
+		if r.MaxFee > 0 {
+			maxFee = r.MaxFee - res.Fee
+			if maxFee <= 0 {
+				res.StopReason = FeeTooHighError.New("the maximum fee has been spent", nil)
+				break
+			}
+		}
+		tx, err := w.SendOutputs(CreateTxReq{
+			InputAddresses: r.FromAddresses,
+			Outputs:        []*wire.TxOut{wire.NewTxOut(0, pkScript)},
+			Minconf:        r.Minconf,
+			FeeSatPerKB:    r.FeeSatPerKB,
+			DryRun:         r.DryRun,
+			ChangeAddress:  &r.ToAddress,
+			MaxInputs:      r.MaxInputs,
+			InputMaxAmount: r.MaxAmount,
+			MinInputs:      2,
+			MaxFee:         maxFee,
+			Fold:           true,
+		})
+		if err != nil {
+			if foldStopError(err) {
+				res.StopReason = err
+				break
+			}
+			return res, err
+		}
+		ftx := foldTx(tx)
+		for _, in := range tx.Tx.TxIn {
+			w.LockOutpoint(in.PreviousOutPoint, lockName)
+		}
+		txHash := tx.Tx.TxHash()
+		for i := range tx.Tx.TxOut {
+			w.LockOutpoint(wire.OutPoint{Hash: txHash, Index: uint32(i)}, lockName)
+		}
+
+		res.Transactions = append(res.Transactions, ftx)
+		res.Inputs += ftx.Inputs
+		res.Amount += ftx.Amount
+		res.Fee += ftx.Fee
+		log.Infof("Folded [%d] inputs into [%s] with fee [%s] in transaction [%s], "+
+			"[%d] inputs folded so far", ftx.Inputs, ftx.Amount.String(),
+			ftx.Fee.String(), log.Txid(txHash.String()), res.Inputs)
+		if r.Progress != nil {
+			r.Progress(ftx)
+		}
+	}
+	return res, nil
+}
+
+// foldTx summarizes a transaction made by FoldCoins.
+func foldTx(tx *txauthor.AuthoredTx) FoldCoinsTx {
+	out := FoldCoinsTx{
+		Tx:     tx.Tx,
+		Inputs: len(tx.Tx.TxIn),
+	}
+	for _, txOut := range tx.Tx.TxOut {
+		out.Amount += btcutil.Amount(txOut.Value)
+	}
+	out.Fee = tx.TotalInput - out.Amount
+	return out
+}
@@ -0,0 +1,141 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestFoldCoins checks that FoldCoins sweeps the small outputs of the wallet
+// in transactions of at most MaxInputs inputs and stops at the limits of the
+// request.
+func TestFoldCoins(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.CurrentAddress(0, waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to get current address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to convert wallet address to p2wkh: %v", err)
+	}
+
+	// Ten small outputs which should be folded and one large output which
+	// should not.
+	small := make(map[wire.OutPoint]bool)
+	for i := 0; i < 10; i++ {
+		incomingTx := &wire.MsgTx{
+			TxIn:  []*wire.TxIn{{PreviousOutPoint: wire.OutPoint{Index: uint32(i)}}},
+			TxOut: []*wire.TxOut{wire.NewTxOut(100000, pkScript)},
+		}
+		addUtxo(t, w, incomingTx)
+		small[wire.OutPoint{Hash: incomingTx.TxHash()}] = true
+	}
+	addUtxo(t, w, &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{PreviousOutPoint: wire.OutPoint{Index: 10}}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(100000000, pkScript)},
+	})
+
+	req := FoldCoinsReq{
+		ToAddress:   addr,
+		Minconf:     1,
+		MaxInputs:   4,
+		MaxAmount:   1000000,
+		FeeSatPerKB: 1000,
+		DryRun:      true,
+	}
+
+	// An outpoint locked by a fold of another address stays locked.
+	otherLock := foldLockName(&[]btcutil.Address{addr})
+	if otherLock == foldLockName(nil) {
+		t.Fatalf("folds of an address and of the wallet share a lock name")
+	}
+	otherOp := wire.OutPoint{Index: 99}
+	w.LockOutpoint(otherOp, otherLock)
+
+	// A dry run folds every small output exactly once.
+	progress := 0
+	req.Progress = func(FoldCoinsTx) { progress++ }
+	res, err := w.FoldCoins(req)
+	if err != nil {
+		t.Fatalf("FoldCoins: %v", err)
+	}
+	if len(res.Transactions) != 3 || progress != 3 || res.Inputs != 10 {
+		t.Fatalf("expected 10 inputs in 3 transactions, got %d in %d",
+			res.Inputs, len(res.Transactions))
+	}
+	if !InsufficientFundsError.Is(res.StopReason) {
+		t.Fatalf("unexpected stop reason: %v", res.StopReason)
+	}
+	seen := make(map[wire.OutPoint]bool)
+	var fees btcutil.Amount
+	for _, ftx := range res.Transactions {
+		if len(ftx.Tx.TxIn) > req.MaxInputs || len(ftx.Tx.TxOut) != 1 {
+			t.Fatalf("transaction with %d inputs and %d outputs",
+				len(ftx.Tx.TxIn), len(ftx.Tx.TxOut))
+		}
+		for _, in := range ftx.Tx.TxIn {
+			if !small[in.PreviousOutPoint] || seen[in.PreviousOutPoint] {
+				t.Fatalf("unexpected input %v", in.PreviousOutPoint)
+			}
+			seen[in.PreviousOutPoint] = true
+		}
+		fees += ftx.Fee
+	}
+	if res.Fee != fees || res.Amount+res.Fee != 10*100000 {
+		t.Fatalf("unexpected amount %v and fee %v", res.Amount, res.Fee)
+	}
+	if locked := w.LockedOutpoints(); len(locked) != 1 ||
+		locked[0].Vout != otherOp.Index {
+		t.Fatalf("unexpected outpoints locked after folding: %v", locked)
+	}
+	w.ResetLockedOutpoints(&otherLock)
+
+	// Folding stops at the maximum number of transactions.
+	req.Progress = nil
+	req.MaxTransactions = 1
+	res, err = w.FoldCoins(req)
+	if err != nil {
+		t.Fatalf("FoldCoins: %v", err)
+	}
+	if len(res.Transactions) != 1 || res.StopReason != nil {
+		t.Fatalf("expected 1 transaction, got %d (%v)",
+			len(res.Transactions), res.StopReason)
+	}
+
+	// Folding stops when the next transaction would exceed the fee budget.
+	req.MaxTransactions = 0
+	req.MaxFee = res.Fee + 1
+	res, err = w.FoldCoins(req)
+	if err != nil {
+		t.Fatalf("FoldCoins: %v", err)
+	}
+	if len(res.Transactions) != 1 || !FeeTooHighError.Is(res.StopReason) {
+		t.Fatalf("expected 1 transaction, got %d (%v)",
+			len(res.Transactions), res.StopReason)
+	}
+
+	// Folding for real spends the small outputs and does not fold the
+	// outputs it creates, even when unconfirmed outputs can be spent.
+	req.MaxFee = 0
+	req.Minconf = 0
+	req.DryRun = false
+	res, err = w.FoldCoins(req)
+	if err != nil {
+		t.Fatalf("FoldCoins: %v", err)
+	}
+	if len(res.Transactions) != 3 || res.Inputs != 10 {
+		t.Fatalf("expected 10 inputs in 3 transactions, got %d in %d",
+			res.Inputs, len(res.Transactions))
+	}
+	for _, ftx := range res.Transactions {
+		if err := validateMsgTx1(ftx.Tx); err != nil {
+			t.Fatalf("invalid transaction: %v", err)
+		}
+	}
+}
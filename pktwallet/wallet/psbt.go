@@ -9,9 +9,12 @@ import (
 
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
 	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
 	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/txscript/params"
@@ -332,6 +335,272 @@ func (w *Wallet) FinalizePsbt(packet *psbt.Packet) er.R {
 	return nil
 }
 
+// UnsignedPsbt makes a PSBT packet from an unsigned transaction which spends
+// outputs of the wallet, such as a dry run of CreateSimpleTx spending from a
+// watch-only account.  The previous output of every input is attached, along
+// with the redeem script of nested p2wkh inputs and the BIP0032 derivation of
+// keys derived from an account key, so that the holder of the private keys can
+// sign the transaction.
+//
+// NOTE: The wallet does not know the fingerprint of the master key of an
+// account which was imported from an extended public key, so the derivations
+// have a master key fingerprint of 0.
+func (w *Wallet) UnsignedPsbt(tx *wire.MsgTx) (*psbt.Packet, er.R) {
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, txIn := range tx.TxIn {
+		prevTx, utxo, _, err := w.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err != nil {
+			return nil, er.Errorf("error fetching UTXO: %v", err)
+		}
+		if _, err := w.updatePsbtInput(&packet.Inputs[idx], prevTx, utxo); err != nil {
+			return nil, err
+		}
+	}
+
+	return packet, nil
+}
+
+// ProcessPsbt updates the inputs of a PSBT which spend outputs of the wallet
+// with what is needed to sign them, as UnsignedPsbt does, and if sign is true
+// then it signs them.  The signatures are added as partial signatures so that
+// other signers can still sign the inputs which need more than one signature,
+// and each input which then has all of its signatures is finalized.  Inputs
+// which the wallet does not know of, or which spend watch-only addresses, are
+// left to other signers.  The returned bool is true if every input of the PSBT
+// is finalized, so the transaction can be extracted from it.
+//
+// NOTE: The wallet must be unlocked to sign.
+func (w *Wallet) ProcessPsbt(packet *psbt.Packet, sign bool) (bool, er.R) {
+	err := psbt.VerifyInputOutputLen(packet, true, true)
+	if err != nil {
+		return false, err
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return false, err
+	}
+
+	tx := packet.UnsignedTx
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for idx, txIn := range tx.TxIn {
+		in := &packet.Inputs[idx]
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			continue
+		}
+
+		// Inputs which cannot be mapped to a coin of the wallet belong
+		// to someone else.
+		prevTx, utxo, _, err := w.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err != nil {
+			continue
+		}
+		if in.NonWitnessUtxo != nil &&
+			in.NonWitnessUtxo.TxHash() != txIn.PreviousOutPoint.Hash {
+
+			return false, er.Errorf("previous transaction of input %d "+
+				"doesn't match its outpoint %v", idx,
+				txIn.PreviousOutPoint)
+		}
+		pka, err := w.updatePsbtInput(in, prevTx, utxo)
+		if err != nil {
+			return false, err
+		}
+		if !sign || pka == nil || hasPartialSig(in, pka) {
+			continue
+		}
+
+		sig, pubKey, err := w.signPsbtInput(tx, sigHashes, idx, utxo,
+			in.SighashType, pka)
+		if waddrmgr.ErrWatchingOnly.Is(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if _, err := updater.Sign(idx, sig, pubKey, nil, nil); err != nil {
+			return false, er.Errorf("error adding signature of "+
+				"input %d: %v", idx, err)
+		}
+	}
+
+	// An input which cannot be finalized yet is waiting for the signatures
+	// of other signers, so it is not an error.
+	for idx := range tx.TxIn {
+		_, _ = psbt.MaybeFinalize(packet, idx)
+	}
+
+	return packet.IsComplete(), nil
+}
+
+// updatePsbtInput attaches what the wallet knows about the output spent by an
+// input of a PSBT to the input: the previous transaction, the previous output
+// of a witness input, the redeem script of a nested p2wkh input and the BIP0032
+// derivation of the key, leaving the fields which are already set as they are.
+// The address of the output is returned if it is a public key address,
+// otherwise nil.
+func (w *Wallet) updatePsbtInput(in *psbt.PInput, prevTx *wire.MsgTx,
+	utxo *wire.TxOut) (waddrmgr.ManagedPubKeyAddress, er.R) {
+
+	if in.NonWitnessUtxo == nil {
+		in.NonWitnessUtxo = prevTx
+	}
+	if in.SighashType == 0 {
+		in.SighashType = params.SigHashAll
+	}
+
+	walletAddr, err := w.fetchOutputAddr(utxo.PkScript)
+	if err != nil {
+		return nil, err
+	}
+	pka, ok := walletAddr.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, nil
+	}
+	pubKey := pka.PubKey().SerializeCompressed()
+
+	switch pka.AddrType() {
+	case waddrmgr.NestedWitnessPubKey:
+		if in.RedeemScript == nil {
+			p2wkhAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+				btcutil.Hash160(pubKey), w.chainParams,
+			)
+			if err != nil {
+				return nil, err
+			}
+			in.RedeemScript, err = txscript.PayToAddrScript(p2wkhAddr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if in.WitnessUtxo == nil {
+			in.WitnessUtxo = utxo
+		}
+	case waddrmgr.WitnessPubKey:
+		if in.WitnessUtxo == nil {
+			in.WitnessUtxo = utxo
+		}
+	}
+
+	if len(in.Bip32Derivation) == 0 {
+		path, err := w.bip32Path(pka)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			in.Bip32Derivation = []*psbt.Bip32Derivation{{
+				PubKey:    pubKey,
+				Bip32Path: path,
+			}}
+		}
+	}
+
+	return pka, nil
+}
+
+// hasPartialSig returns true if an input of a PSBT already carries a signature
+// made with the key of a wallet address.
+func hasPartialSig(in *psbt.PInput, pka waddrmgr.ManagedPubKeyAddress) bool {
+	pubKey := pka.PubKey().SerializeCompressed()
+	if !pka.Compressed() {
+		pubKey = pka.PubKey().SerializeUncompressed()
+	}
+	for _, sig := range in.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// signPsbtInput signs an input of a transaction which spends an output to a
+// public key address of the wallet.  It returns the signature followed by the
+// public key it verifies with.
+func (w *Wallet) signPsbtInput(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int,
+	utxo *wire.TxOut, hashType params.SigHashType,
+	pka waddrmgr.ManagedPubKeyAddress) ([]byte, []byte, er.R) {
+
+	privKey, err := pka.PrivKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKey := pka.PubKey().SerializeCompressed()
+	if !pka.Compressed() {
+		pubKey = pka.PubKey().SerializeUncompressed()
+	}
+
+	var sig []byte
+	switch pka.AddrType() {
+	// The signature hash of a p2wkh input, nested or not, commits to its
+	// witness program, which is expanded into a p2pkh script.
+	case waddrmgr.NestedWitnessPubKey, waddrmgr.WitnessPubKey:
+		p2wkhAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+			btcutil.Hash160(pubKey), w.chainParams,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		witnessProgram, err := txscript.PayToAddrScript(p2wkhAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+		sig, err = txscript.RawTxInWitnessSignature(tx, sigHashes, idx,
+			utxo.Value, witnessProgram, hashType, privKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		sig, err = txscript.RawTxInSignature(tx, idx, utxo.PkScript,
+			hashType, privKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return sig, pubKey, nil
+}
+
+// bip32Path returns the full BIP0032 derivation path of the key of a wallet
+// address, or nil if it is an imported key or the depth of its account key is
+// not that of a BIP0044 account.
+func (w *Wallet) bip32Path(pka waddrmgr.ManagedPubKeyAddress) ([]uint32, er.R) {
+	if pka.Imported() {
+		return nil, nil
+	}
+	scope, path, ok := pka.DerivationInfo()
+	if !ok {
+		return nil, nil
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+	var props *waddrmgr.AccountProperties
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err er.R
+		props, err = manager.AccountProperties(addrmgrNs, path.Account)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if props.AccountPubKey == nil || props.AccountPubKey.Depth() != 3 {
+		return nil, nil
+	}
+
+	// The account key says which account it is, which is not necessarily
+	// the number of the account in this wallet.
+	return []uint32{
+		scope.Purpose + hdkeychain.HardenedKeyStart,
+		scope.Coin + hdkeychain.HardenedKeyStart,
+		props.AccountPubKey.ChildIndex(),
+		path.Branch,
+		path.Index,
+	}, nil
+}
+
 // constantInputSource creates an input source function that always returns the
 // static set of user-selected UTXOs.
 func constantInputSource(eligible []wtxmgr.Credit) txauthor.InputSource {
@@ -416,3 +416,130 @@ func TestFinalizePsbt(t *testing.T) {
 		t.Fatalf("error validating tx: %v", err)
 	}
 }
+
+// TestProcessPsbt tests that the inputs of a PSBT which spend outputs of the
+// wallet are updated, signed and finalized, and that AnalyzePsbt follows the
+// progress of the PSBT.
+func TestProcessPsbt(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	// Send coins to a P2PKH, a P2WKH and a nested P2WKH address.
+	var pkScripts [][]byte
+	var keyHashes [][]byte
+	for _, scope := range []waddrmgr.KeyScope{
+		waddrmgr.KeyScopeBIP0044,
+		waddrmgr.KeyScopeBIP0084,
+		waddrmgr.KeyScopeBIP0049Plus,
+	} {
+		addr, err := w.CurrentAddress(0, scope)
+		if err != nil {
+			t.Fatalf("unable to get current address: %v", err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to convert wallet address: %v", err)
+		}
+		ma, err := w.AddressInfo(addr)
+		if err != nil {
+			t.Fatalf("unable to get address info: %v", err)
+		}
+		pubKey := ma.(waddrmgr.ManagedPubKeyAddress).PubKey()
+		pkScripts = append(pkScripts, pkScript)
+		keyHashes = append(keyHashes,
+			btcutil.Hash160(pubKey.SerializeCompressed()))
+	}
+	incomingTx := &wire.MsgTx{TxIn: []*wire.TxIn{{}}}
+	for _, pkScript := range pkScripts {
+		incomingTx.TxOut = append(incomingTx.TxOut,
+			wire.NewTxOut(1000000, pkScript))
+	}
+	addUtxo(t, w, incomingTx)
+
+	tx := &wire.MsgTx{
+		TxOut: []*wire.TxOut{{
+			PkScript: testScriptP2WKH,
+			Value:    2990000,
+		}},
+	}
+	for i := range pkScripts {
+		tx.TxIn = append(tx.TxIn, &wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{
+				Hash:  incomingTx.TxHash(),
+				Index: uint32(i),
+			},
+		})
+	}
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		t.Fatalf("unable to create PSBT: %v", err)
+	}
+
+	analysis, err := AnalyzePsbt(packet)
+	if err != nil {
+		t.Fatalf("unable to analyze PSBT: %v", err)
+	}
+	if analysis.Next != PsbtUpdater || analysis.HasFee ||
+		analysis.EstimatedVSize != 0 {
+
+		t.Fatalf("unexpected analysis of empty PSBT %+v", analysis)
+	}
+
+	// Updating the PSBT attaches the outputs it spends.
+	complete, err := w.ProcessPsbt(packet, false)
+	if err != nil || complete {
+		t.Fatalf("unexpected result of ProcessPsbt %v %v", complete, err)
+	}
+	analysis, err = AnalyzePsbt(packet)
+	if err != nil {
+		t.Fatalf("unable to analyze PSBT: %v", err)
+	}
+	if analysis.Next != PsbtSigner || !analysis.HasFee ||
+		analysis.Fee != 10000 || analysis.EstimatedVSize == 0 {
+
+		t.Fatalf("unexpected analysis of updated PSBT %+v", analysis)
+	}
+	for i, in := range analysis.Inputs {
+		if !in.HasUtxo || len(in.MissingSigs) != 1 ||
+			!bytes.Equal(in.MissingSigs[0], keyHashes[i]) {
+
+			t.Fatalf("unexpected analysis of input %d %+v", i, in)
+		}
+	}
+
+	// Signing it makes it complete.
+	complete, err = w.ProcessPsbt(packet, true)
+	if err != nil || !complete {
+		t.Fatalf("unexpected result of ProcessPsbt %v %v", complete, err)
+	}
+	analysis, err = AnalyzePsbt(packet)
+	if err != nil {
+		t.Fatalf("unable to analyze PSBT: %v", err)
+	}
+	if analysis.Next != PsbtExtractor || analysis.EstimatedVSize == 0 {
+		t.Fatalf("unexpected analysis of signed PSBT %+v", analysis)
+	}
+	finalTx, err := psbt.Extract(packet)
+	if err != nil {
+		t.Fatalf("error extracting final TX from PSBT: %v", err)
+	}
+	err = validateMsgTx(
+		finalTx, pkScripts,
+		[]btcutil.Amount{1000000, 1000000, 1000000},
+	)
+	if err != nil {
+		t.Fatalf("error validating tx: %v", err)
+	}
+
+	// A locked wallet cannot sign.
+	packet, err = psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		t.Fatalf("unable to create PSBT: %v", err)
+	}
+	if err := w.Manager.Lock(); err != nil {
+		t.Fatalf("unable to lock wallet: %v", err)
+	}
+	if _, err := w.ProcessPsbt(packet, true); !waddrmgr.ErrLocked.Is(err) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+}
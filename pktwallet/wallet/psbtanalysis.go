@@ -0,0 +1,272 @@
+package wallet
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/internal/txsizes"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// PsbtRole is one of the roles of BIP0174 which process a PSBT, they are
+// ordered as they take turns.
+type PsbtRole int
+
+const (
+	// PsbtUpdater attaches the outputs spent by the inputs and the scripts
+	// needed to sign them.
+	PsbtUpdater PsbtRole = iota
+
+	// PsbtSigner adds signatures.
+	PsbtSigner
+
+	// PsbtFinalizer makes the final signature scripts and witnesses of the
+	// inputs out of their signatures.
+	PsbtFinalizer
+
+	// PsbtExtractor extracts the signed transaction.
+	PsbtExtractor
+)
+
+var psbtRoleNames = [...]string{"updater", "signer", "finalizer", "extractor"}
+
+// String returns the BIP0174 name of the role.
+func (r PsbtRole) String() string {
+	return psbtRoleNames[r]
+}
+
+// PsbtInputAnalysis is the state of an input of a PSBT.
+type PsbtInputAnalysis struct {
+	// HasUtxo is true if the output spent by the input is attached to it.
+	HasUtxo bool
+
+	// IsFinal is true if the input is finalized.
+	IsFinal bool
+
+	// MissingSigs are the hash160 of the public keys whose signatures the
+	// input still needs.
+	MissingSigs [][]byte
+
+	// MissingRedeemScript is the hash160 of the redeem script and
+	// MissingWitnessScript the sha256 of the witness script of the input,
+	// if it is needed but not attached.
+	MissingRedeemScript  []byte
+	MissingWitnessScript []byte
+
+	// Next is the role which can make progress on the input.
+	Next PsbtRole
+}
+
+// PsbtAnalysis is the state of a PSBT, as returned by AnalyzePsbt.
+type PsbtAnalysis struct {
+	Inputs []PsbtInputAnalysis
+
+	// HasFee is true if the outputs spent by all of the inputs are known,
+	// in which case Fee is the fee which the transaction pays.
+	HasFee bool
+	Fee    btcutil.Amount
+
+	// EstimatedVSize is the estimated virtual size of the signed
+	// transaction, it is 0 if the size of the signatures of an input
+	// cannot be estimated.
+	EstimatedVSize int
+
+	// Next is the role which can make progress on the PSBT.
+	Next PsbtRole
+}
+
+// AnalyzePsbt tells which role of BIP0174 can make progress on each input of
+// a PSBT and on the PSBT as a whole, along with the fee and the estimated size
+// of the transaction when they are known.  The size can only be estimated for
+// a transaction whose inputs are p2pkh, p2wkh or nested p2wkh.
+func AnalyzePsbt(packet *psbt.Packet) (*PsbtAnalysis, er.R) {
+	if err := psbt.VerifyInputOutputLen(packet, true, false); err != nil {
+		return nil, err
+	}
+
+	analysis := &PsbtAnalysis{
+		Inputs: make([]PsbtInputAnalysis, len(packet.Inputs)),
+		HasFee: true,
+		Next:   PsbtExtractor,
+	}
+	var inputSum int64
+	var numP2PKH, numP2WPKH, numNestedP2WPKH int
+	canEstimate := true
+	for idx := range packet.Inputs {
+		in := &packet.Inputs[idx]
+		a := &analysis.Inputs[idx]
+
+		utxo, err := psbtInputUtxo(packet, idx)
+		if err != nil {
+			return nil, err
+		}
+		if utxo == nil {
+			a.Next = PsbtUpdater
+			analysis.HasFee = false
+			canEstimate = false
+		} else {
+			a.HasUtxo = true
+			inputSum += utxo.Value
+			analyzePsbtInput(in, utxo.PkScript, a)
+
+			switch {
+			case txscript.IsPayToWitnessPubKeyHash(utxo.PkScript):
+				numP2WPKH++
+			case txscript.GetScriptClass(utxo.PkScript) == txscript.PubKeyHashTy:
+				numP2PKH++
+			case isNestedP2WKHInput(in, utxo.PkScript):
+				numNestedP2WPKH++
+			default:
+				canEstimate = false
+			}
+		}
+
+		if a.Next < analysis.Next {
+			analysis.Next = a.Next
+		}
+	}
+
+	if analysis.HasFee {
+		fee := inputSum
+		for _, txOut := range packet.UnsignedTx.TxOut {
+			fee -= txOut.Value
+		}
+		if fee < 0 {
+			return nil, er.Errorf("the outputs of the PSBT are worth " +
+				"more than its inputs")
+		}
+		analysis.Fee = btcutil.Amount(fee)
+	}
+	if canEstimate {
+		analysis.EstimatedVSize = txsizes.EstimateVirtualSize(
+			numP2PKH, numP2WPKH, numNestedP2WPKH,
+			packet.UnsignedTx.TxOut, false,
+		)
+	}
+
+	return analysis, nil
+}
+
+// psbtInputUtxo returns the output spent by an input of a PSBT, or nil if it
+// is not attached to the input.
+func psbtInputUtxo(packet *psbt.Packet, idx int) (*wire.TxOut, er.R) {
+	in := &packet.Inputs[idx]
+	switch {
+	case in.WitnessUtxo != nil:
+		return in.WitnessUtxo, nil
+
+	case in.NonWitnessUtxo != nil:
+		prevOut := packet.UnsignedTx.TxIn[idx].PreviousOutPoint
+		if in.NonWitnessUtxo.TxHash() != prevOut.Hash ||
+			int(prevOut.Index) >= len(in.NonWitnessUtxo.TxOut) {
+
+			return nil, er.Errorf("previous transaction of input %d "+
+				"doesn't match its outpoint %v", idx, prevOut)
+		}
+		return in.NonWitnessUtxo.TxOut[prevOut.Index], nil
+	}
+	return nil, nil
+}
+
+// analyzePsbtInput fills in the state of an input of a PSBT which spends an
+// output with the script pkScript.
+func analyzePsbtInput(in *psbt.PInput, pkScript []byte, a *PsbtInputAnalysis) {
+	if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+		a.IsFinal = true
+		a.Next = PsbtExtractor
+		return
+	}
+
+	// Find the script which the signatures are checked against.
+	script := pkScript
+	if txscript.IsPayToScriptHash(script) {
+		if in.RedeemScript == nil {
+			a.MissingRedeemScript = script[2:22]
+			a.Next = PsbtUpdater
+			return
+		}
+		script = in.RedeemScript
+	}
+	if txscript.IsPayToWitnessScriptHash(script) {
+		if in.WitnessScript == nil {
+			a.MissingWitnessScript = script[2:34]
+			a.Next = PsbtUpdater
+			return
+		}
+		script = in.WitnessScript
+	}
+
+	// The keys which can sign the input are known by their hash160, and
+	// required of them must sign.
+	var keyHashes [][]byte
+	required := 1
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		a.Next = PsbtSigner
+		return
+	}
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyHashTy:
+		keyHashes = pushes[:1]
+	case txscript.WitnessV0PubKeyHashTy:
+		// The first push is the witness version.
+		keyHashes = pushes[1:2]
+	case txscript.PubKeyTy:
+		keyHashes = [][]byte{btcutil.Hash160(pushes[0])}
+	case txscript.MultiSigTy:
+		_, required, err = txscript.CalcMultiSigStats(script)
+		if err != nil {
+			a.Next = PsbtSigner
+			return
+		}
+		for _, pubKey := range pushes {
+			keyHashes = append(keyHashes, btcutil.Hash160(pubKey))
+		}
+	default:
+		// The signers of other scripts cannot be told.
+		a.Next = PsbtSigner
+		return
+	}
+
+	signed := 0
+	for _, keyHash := range keyHashes {
+		found := false
+		for _, sig := range in.PartialSigs {
+			if bytes.Equal(btcutil.Hash160(sig.PubKey), keyHash) {
+				found = true
+				break
+			}
+		}
+		if found {
+			signed++
+		} else {
+			a.MissingSigs = append(a.MissingSigs, keyHash)
+		}
+	}
+	if signed < required {
+		a.Next = PsbtSigner
+		return
+	}
+	a.MissingSigs = nil
+	a.Next = PsbtFinalizer
+}
+
+// isNestedP2WKHInput returns true if an input of a PSBT spends a p2wkh output
+// nested in the p2sh output pkScript.
+func isNestedP2WKHInput(in *psbt.PInput, pkScript []byte) bool {
+	if !txscript.IsPayToScriptHash(pkScript) {
+		return false
+	}
+	if in.RedeemScript != nil {
+		return txscript.IsPayToWitnessPubKeyHash(in.RedeemScript)
+	}
+
+	// The redeem script is dropped when the input is finalized, it is
+	// then the only push of the final signature script.
+	pushes, err := txscript.PushedData(in.FinalScriptSig)
+	return err == nil && len(pushes) == 1 &&
+		txscript.IsPayToWitnessPubKeyHash(pushes[0])
+}
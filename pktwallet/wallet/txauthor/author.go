@@ -99,7 +99,9 @@ func NewUnsignedTransaction(outputs []*wire.TxOut, relayFeePerKb btcutil.Amount,
 			return nil, err
 		}
 		if inputAmount < targetAmount+targetFee {
-			if partialOk && len(outputs) == 1 {
+			// Once the output sweeps the inputs, not even the fee
+			// can be paid and retrying would never end.
+			if partialOk && len(outputs) == 1 && sweepTo == nil {
 				targetAmount = 0
 				sweepTo = outputs[0]
 			} else {
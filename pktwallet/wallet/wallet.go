@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/emirpasic/gods/utils"
@@ -135,6 +136,10 @@ type Wallet struct {
 
 	watch watcher.Watcher
 
+	// watchGapExtensions counts the times extendWatchOnlyGap has added
+	// addresses to watch, it is accessed atomically.
+	watchGapExtensions uint32
+
 	rescanJLock sync.Mutex
 	rescanJ     *rescanJob
 }
@@ -621,6 +626,11 @@ type (
 		InputMinHeight  int
 		InputComparator utils.Comparator
 		MaxInputs       int
+		InputMaxAmount  btcutil.Amount
+		MinInputs       int
+		MaxFee          btcutil.Amount
+		Fold            bool
+		WatchOnly       bool
 		Label           string
 	}
 	createTxRequest struct {
@@ -649,6 +659,14 @@ out:
 	for {
 		select {
 		case txr := <-w.createTxRequests:
+			// A dry run of a watch-only account does not sign, and the
+			// wallet has no keys for it, so it does not need the wallet
+			// to be unlocked.
+			if txr.req.DryRun && txr.req.WatchOnly {
+				tx, err := w.txToOutputs(txr.req)
+				txr.resp <- createTxResponse{tx, err}
+				continue
+			}
 			heldUnlock, err := w.holdUnlock()
 			if err != nil {
 				txr.resp <- createTxResponse{nil, err}
@@ -1943,6 +1961,8 @@ func (w *Wallet) LockedOutpoint(op wire.OutPoint) bool {
 // LockOutpoint marks an outpoint as locked, that is, it should not be used as
 // an input for newly created transactions.
 func (w *Wallet) LockOutpoint(op wire.OutPoint, name string) {
+	w.lockedOutpointsMtx.Lock()
+	defer w.lockedOutpointsMtx.Unlock()
 	w.lockedOutpoints[op] = name
 }
 
@@ -1974,6 +1994,8 @@ func (w *Wallet) ResetLockedOutpoints(lockName *string) {
 // intended to be used by marshaling the result as a JSON array for
 // listlockunspent RPC results.
 func (w *Wallet) LockedOutpoints() []btcjson.LockedUnspent {
+	w.lockedOutpointsMtx.Lock()
+	defer w.lockedOutpointsMtx.Unlock()
 	locked := make([]btcjson.LockedUnspent, len(w.lockedOutpoints))
 	i := 0
 	for op, ln := range w.lockedOutpoints {
@@ -2869,12 +2891,16 @@ func rescanStep(
 	})
 }
 
-func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) er.R {
+// connectBlocks stores the transactions of the blocks and returns how many of
+// them were connected. It stops after a block which extends the addresses of
+// a watch-only account because the following blocks were filtered without
+// those addresses.
+func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) (int, er.R) {
 	blk := blks[0]
 	if !isRescan {
 		st := w.Manager.SyncedTo()
 		if blk.height != st.Height+1 || blk.header.PrevBlock != st.Hash {
-			return er.Errorf("Cannot connect block [%s @ %d] because current state is [%s @ %d] and "+
+			return 0, er.Errorf("Cannot connect block [%s @ %d] because current state is [%s @ %d] and "+
 				"block header expects [%s]",
 				blk.header.BlockHash().String(), blk.height,
 				st.Hash.String(), st.Height,
@@ -2882,8 +2908,9 @@ func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) er.R {
 		}
 	}
 	bs := w.Manager.SyncedTo()
-	return walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) er.R {
-		for _, b := range blks {
+	connected := len(blks)
+	err := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) er.R {
+		for i, b := range blks {
 			if b.height > bs.Height+1 {
 				// This happens if we get a resync/dropdb triggered while we're syncing
 				continue
@@ -2896,11 +2923,18 @@ func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) er.R {
 					return err
 				}
 			}
+			extensions := atomic.LoadUint32(&w.watchGapExtensions)
 			if b.filter == nil {
 			} else if err := w.storeTxns(dbtx, b.filter); err != nil {
 				return err
 			}
+			if atomic.LoadUint32(&w.watchGapExtensions) != extensions {
+				connected = i + 1
+			}
 			if isRescan {
+				if connected < len(blks) {
+					return nil
+				}
 				continue
 			}
 			log.Debugf("Syncing %s @ %d", b.header.BlockHash(), b.height)
@@ -2921,9 +2955,13 @@ func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) er.R {
 				},
 				Time: b.header.Timestamp,
 			})
+			if connected < len(blks) {
+				return nil
+			}
 		}
 		return nil
 	})
+	return connected, err
 }
 
 const syncerBatchSz = 8
@@ -2932,10 +2970,29 @@ func (w *Wallet) rescan2(
 	blockMin, blockMax int32,
 	isRescan bool,
 ) er.R {
+	for blockMin < blockMax {
+		next, err := w.rescanBlocks(blockMin, blockMax, isRescan)
+		if err != nil {
+			return err
+		}
+		blockMin = next
+	}
+	return nil
+}
+
+// rescanBlocks filters and connects the blocks from blockMin up to blockMax
+// and returns the height where it stopped. It stops early when the addresses
+// of a watch-only account are extended because the blocks which were filtered
+// before need to be filtered again.
+func (w *Wallet) rescanBlocks(
+	blockMin, blockMax int32,
+	isRescan bool,
+) (int32, er.R) {
 	chainClient, err := w.requireChainClient()
 	if err != nil {
-		return err
+		return 0, err
 	}
+	extensions := atomic.LoadUint32(&w.watchGapExtensions)
 	txStore := w.TxStore
 	watch := &w.watch
 	db := w.db
@@ -2965,12 +3022,13 @@ func (w *Wallet) rescan2(
 			return nil
 		},
 	)
+	defer q.Stop()
 	blockNum := blockMin
 	batch := make([]SyncerResp, 0, syncerBatchSz)
 	for {
 		for ; blockNum < blockMax; blockNum++ {
 			if err := q.Get(uint64(blockNum)); err != nil {
-				return err
+				return 0, err
 			}
 			respLock.Lock()
 			x := responses[int32(blockNum)]
@@ -2986,7 +3044,7 @@ func (w *Wallet) rescan2(
 			}
 		}
 		if len(batch) == 0 {
-			return nil
+			return blockMax, nil
 		}
 		if isRescan {
 			for _, blk := range batch {
@@ -2998,8 +3056,12 @@ func (w *Wallet) rescan2(
 				}
 			}
 		}
-		if err := w.connectBlocks(batch, isRescan); err != nil {
-			return err
+		connected, err := w.connectBlocks(batch, isRescan)
+		if err != nil {
+			return 0, err
+		}
+		if atomic.LoadUint32(&w.watchGapExtensions) != extensions {
+			return batch[connected-1].height + 1, nil
 		}
 		batch = batch[:0]
 	}
@@ -3050,13 +3112,14 @@ func (w *Wallet) block(bm wtxmgr.Block) er.R {
 		if res, err := w.chainClient.FilterBlocks(filterReq); err != nil {
 			return err
 		} else {
-			return w.connectBlocks([]SyncerResp{
+			_, err := w.connectBlocks([]SyncerResp{
 				{
 					filter: res,
 					header: header,
 					height: bm.Height,
 				},
 			}, false)
+			return err
 		}
 	}
 	if err := w.rollbackIfNeeded(); err != nil {
@@ -0,0 +1,249 @@
+package wallet
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// defaultGapLimit is the number of unused addresses which are watched after
+// the last used address of each branch of a watch-only account, when the
+// wallet was opened without a recovery window.
+const defaultGapLimit = 20
+
+// gapLimit returns the number of unused addresses which are watched after the
+// last used address of each branch of a watch-only account.
+func (w *Wallet) gapLimit() uint32 {
+	if w.recoveryWindow > 0 {
+		return w.recoveryWindow
+	}
+	return defaultGapLimit
+}
+
+// ImportAccountPubKey imports an account extended public key as a watch-only
+// account named name.  The key scope of the account is chosen by the version
+// of the key, an xpub is imported under KeyScopeBIP0044, a ypub under
+// KeyScopeBIP0049Plus and a zpub under KeyScopeBIP0084.
+//
+// The first gap limit addresses of both branches of the account are derived
+// and watched, and more addresses are derived as payments to them are found,
+// so a resync discovers the whole history of the account.  If rescan is true
+// then a resync is started at rescanFrom.
+func (w *Wallet) ImportAccountPubKey(name, key string, rescan bool,
+	rescanFrom int32) (waddrmgr.KeyScope, *waddrmgr.AccountProperties, er.R) {
+
+	acctKeyPub, scope, err := waddrmgr.ParseAccountPubKey(key, w.chainParams)
+	if err != nil {
+		return scope, nil, err
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return scope, nil, err
+	}
+
+	if rescan {
+		w.rescanJLock.Lock()
+		defer w.rescanJLock.Unlock()
+		if w.rescanJ != nil {
+			return scope, nil, er.Errorf(
+				"You requested a rescan but there is already a rescan job"+
+					" ([%v]) running, use `stopresync` to stop it", w.rescanJ.name)
+		}
+	}
+
+	var props *waddrmgr.AccountProperties
+	var addrs []btcutil.Address
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err := manager.NewAccountWatchingOnly(addrmgrNs, name, acctKeyPub)
+		if err != nil {
+			return err
+		}
+		lastIndex := w.gapLimit() - 1
+		if err := manager.ExtendExternalAddresses(addrmgrNs, account, lastIndex); err != nil {
+			return err
+		}
+		if err := manager.ExtendInternalAddresses(addrmgrNs, account, lastIndex); err != nil {
+			return err
+		}
+		err = manager.ForEachAccountAddress(addrmgrNs, account,
+			func(maddr waddrmgr.ManagedAddress) er.R {
+				addrs = append(addrs, maddr.Address())
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		props, err = manager.AccountProperties(addrmgrNs, account)
+		return err
+	})
+	if err != nil {
+		return scope, nil, err
+	}
+	w.watch.WatchAddrs(addrs)
+	atomic.AddUint32(&w.watchGapExtensions, 1)
+
+	if rescan {
+		w.rescanJ = &rescanJob{
+			name:       fmt.Sprintf("import-%s-resync", name),
+			height:     rescanFrom,
+			stopHeight: -1,
+			watch:      &w.watch,
+		}
+	}
+
+	log.Infof("Imported watch-only account [%s] number [%d] in scope [%s]",
+		name, props.AccountNumber, scope.String())
+	return scope, props, nil
+}
+
+// extendWatchOnlyGap derives and watches more addresses of a watch-only
+// account when a payment is found to one of the last gap limit addresses of
+// its branch, so that payments to the following addresses are found by the
+// rest of the resync.
+func (w *Wallet) extendWatchOnlyGap(addrmgrNs walletdb.ReadWriteBucket,
+	ma waddrmgr.ManagedAddress) er.R {
+
+	pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+	if !ok || ma.Imported() {
+		return nil
+	}
+	scope, path, ok := pka.DerivationInfo()
+	if !ok {
+		return nil
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return err
+	}
+	props, err := manager.AccountProperties(addrmgrNs, path.Account)
+	if err != nil {
+		return err
+	}
+	if !props.WatchOnly {
+		return nil
+	}
+
+	count := props.ExternalKeyCount
+	if ma.Internal() {
+		count = props.InternalKeyCount
+	}
+	lastIndex := path.Index + w.gapLimit()
+	if lastIndex < count {
+		return nil
+	}
+	if ma.Internal() {
+		err = manager.ExtendInternalAddresses(addrmgrNs, path.Account, lastIndex)
+	} else {
+		err = manager.ExtendExternalAddresses(addrmgrNs, path.Account, lastIndex)
+	}
+	if err != nil {
+		return err
+	}
+
+	addrs := make([]btcutil.Address, 0, lastIndex-count+1)
+	for i := count; i <= lastIndex; i++ {
+		path.Index = i
+		maddr, err := manager.DeriveFromKeyPath(addrmgrNs, path)
+		if err != nil {
+			return err
+		}
+		addrs = append(addrs, maddr.Address())
+	}
+	w.watch.WatchAddrs(addrs)
+	atomic.AddUint32(&w.watchGapExtensions, 1)
+	log.Debugf("Watching [%d] more addresses of watch-only account [%s]",
+		len(addrs), props.AccountName)
+	return nil
+}
+
+// watchOnlyScript returns true if the output script pays to an address of a
+// watch-only account.
+func (w *Wallet) watchOnlyScript(addrmgrNs walletdb.ReadBucket,
+	pkScript []byte) (bool, er.R) {
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, w.chainParams)
+	if err != nil {
+		return false, nil
+	}
+	for _, addr := range addrs {
+		manager, account, err := w.Manager.AddrAccount(addrmgrNs, addr)
+		if waddrmgr.ErrAddressNotFound.Is(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if account == waddrmgr.ImportedAddrAccount {
+			ma, err := manager.Address(addrmgrNs, addr)
+			if err != nil {
+				return false, err
+			}
+			switch ma := ma.(type) {
+			case waddrmgr.ManagedPubKeyAddress:
+				// Public keys imported without their private
+				// key, such as the keys of descriptors, are
+				// watch-only.
+				_, err = ma.PrivKey()
+				return waddrmgr.ErrWatchingOnly.Is(err), nil
+			case waddrmgr.ManagedScriptAddress:
+				// The wallet only signs for single keys, so
+				// imported scripts, such as the multisig
+				// scripts of descriptors, are watch-only.
+				return true, nil
+			}
+			return false, nil
+		}
+		props, err := manager.AccountProperties(addrmgrNs, account)
+		if err != nil {
+			return false, err
+		}
+		return props.WatchOnly, nil
+	}
+	return false, nil
+}
+
+// IsWatchOnlyAddress returns true if the address belongs to a watch-only
+// account, which the wallet can watch but cannot spend from.
+func (w *Wallet) IsWatchOnlyAddress(a btcutil.Address) (bool, er.R) {
+	pkScript, err := txscript.PayToAddrScript(a)
+	if err != nil {
+		return false, err
+	}
+	var watchOnly bool
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err er.R
+		watchOnly, err = w.watchOnlyScript(addrmgrNs, pkScript)
+		return err
+	})
+	return watchOnly, err
+}
+
+// SpendsWatchOnly returns true if any input of the transaction spends an
+// output of a watch-only account, so the wallet cannot sign it alone.
+func (w *Wallet) SpendsWatchOnly(tx *wire.MsgTx) (bool, er.R) {
+	for _, txIn := range tx.TxIn {
+		_, utxo, _, err := w.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err != nil {
+			return false, err
+		}
+		var watchOnly bool
+		err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+			addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+			var err er.R
+			watchOnly, err = w.watchOnlyScript(addrmgrNs, utxo.PkScript)
+			return err
+		})
+		if err != nil || watchOnly {
+			return watchOnly, err
+		}
+	}
+	return false, nil
+}
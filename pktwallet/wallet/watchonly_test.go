@@ -0,0 +1,279 @@
+package wallet
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktwallet/chain"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestImportAccountPubKey checks that a watch-only account discovers its
+// addresses past the gap limit and that its outputs are only spent by dry
+// runs, which can be made into an unsigned PSBT.  Dry runs of other coins
+// still need the wallet to be unlocked.
+func TestImportAccountPubKey(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	// The BIP0084 account 0 key of the test mnemonic "abandon ... about".
+	const zpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	scope, props, err := w.ImportAccountPubKey("cold", zpub, false, 0)
+	if err != nil {
+		t.Fatalf("ImportAccountPubKey: %v", err)
+	}
+	gap := w.gapLimit()
+	if scope != waddrmgr.KeyScopeBIP0084 || !props.WatchOnly ||
+		props.ExternalKeyCount != gap || props.InternalKeyCount != gap {
+
+		t.Fatalf("unexpected account %v %+v", scope.String(), props)
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		t.Fatalf("unable to fetch scope: %v", err)
+	}
+
+	// A payment to the last watched address extends the watched addresses.
+	path := waddrmgr.DerivationPath{
+		Account: props.AccountNumber,
+		Branch:  waddrmgr.ExternalBranch,
+		Index:   gap - 1,
+	}
+	var pkScript []byte
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		ma, err := manager.DeriveFromKeyPath(addrmgrNs, path)
+		if err != nil {
+			return err
+		}
+		pkScript, err = txscript.PayToAddrScript(ma.Address())
+		if err != nil {
+			return err
+		}
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(&wire.MsgTx{
+			TxIn:  []*wire.TxIn{{}},
+			TxOut: []*wire.TxOut{wire.NewTxOut(100000000, pkScript)},
+		}, time.Now())
+		if err != nil {
+			return err
+		}
+		return w.addRelevantTx(tx, rec, &wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{Hash: *testBlockHash, Height: testBlockHeight},
+			Time:  time.Unix(1387737310, 0),
+		})
+	})
+	if err != nil {
+		t.Fatalf("unable to add payment: %v", err)
+	}
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		props, err = manager.AccountProperties(addrmgrNs, props.AccountNumber)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to fetch account properties: %v", err)
+	}
+	if props.ExternalKeyCount != 2*gap || props.InternalKeyCount != gap {
+		t.Fatalf("expected %d external and %d internal addresses, got %d and %d",
+			2*gap, gap, props.ExternalKeyCount, props.InternalKeyCount)
+	}
+
+	// The watch-only output is not spent by a transaction which the wallet
+	// signs, but it is spent by a dry run even when the wallet is locked.
+	req := CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(50000000, pkScript)},
+		Minconf:     1,
+		FeeSatPerKB: 1000,
+		DryRun:      true,
+	}
+	if _, err := w.CreateSimpleTx(req); !InsufficientFundsError.Is(err) {
+		t.Fatalf("expected InsufficientFundsError, got %v", err)
+	}
+	req.WatchOnly = true
+	req.DryRun = false
+	if _, err := w.CreateSimpleTx(req); err == nil {
+		t.Fatalf("spent a watch-only output without a dry run")
+	}
+	w.Lock()
+	req.DryRun = true
+	req.WatchOnly = false
+	if _, err := w.CreateSimpleTx(req); !waddrmgr.ErrLocked.Is(err) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	req.WatchOnly = true
+	tx, err := w.CreateSimpleTx(req)
+	if err != nil {
+		t.Fatalf("CreateSimpleTx: %v", err)
+	}
+	if watchOnly, err := w.SpendsWatchOnly(tx.Tx); err != nil || !watchOnly {
+		t.Fatalf("expected the transaction to spend a watch-only output, "+
+			"got %v, %v", watchOnly, err)
+	}
+
+	packet, err := w.UnsignedPsbt(tx.Tx)
+	if err != nil {
+		t.Fatalf("UnsignedPsbt: %v", err)
+	}
+	if len(packet.Inputs) != 1 || packet.Inputs[0].WitnessUtxo == nil ||
+		len(packet.Inputs[0].Bip32Derivation) != 1 {
+
+		t.Fatalf("unexpected PSBT inputs %+v", packet.Inputs)
+	}
+	expected := []uint32{
+		84 + hdkeychain.HardenedKeyStart,
+		hdkeychain.HardenedKeyStart,
+		hdkeychain.HardenedKeyStart,
+		0,
+		gap - 1,
+	}
+	if got := packet.Inputs[0].Bip32Derivation[0].Bip32Path; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected derivation path %v, got %v", expected, got)
+	}
+}
+
+// rescanChainClient serves a short chain of blocks and filters their
+// transactions by the imported addresses of the request.
+type rescanChainClient struct {
+	mockChainClient
+	headers []wire.BlockHeader
+	txns    map[int32][]*wire.MsgTx
+}
+
+func (c *rescanChainClient) GetBlockHash(height int64) (*chainhash.Hash, er.R) {
+	hash := c.headers[height].BlockHash()
+	return &hash, nil
+}
+
+func (c *rescanChainClient) GetBlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, er.R) {
+	for i := range c.headers {
+		if c.headers[i].BlockHash() == *hash {
+			return &c.headers[i], nil
+		}
+	}
+	return nil, er.Errorf("unknown block [%s]", hash)
+}
+
+func (c *rescanChainClient) FilterBlocks(
+	req *chain.FilterBlocksRequest) (*chain.FilterBlocksResponse, er.R) {
+
+	watched := make(map[string]struct{})
+	for _, addr := range req.ImportedAddrs {
+		watched[addr.EncodeAddress()] = struct{}{}
+	}
+	var relevant []*wire.MsgTx
+	for _, tx := range c.txns[req.Blocks[0].Height] {
+		for _, out := range tx.TxOut {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript,
+				&chaincfg.TestNet3Params)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+			if _, ok := watched[addrs[0].EncodeAddress()]; ok {
+				relevant = append(relevant, tx)
+				break
+			}
+		}
+	}
+	if len(relevant) == 0 {
+		return nil, nil
+	}
+	return &chain.FilterBlocksResponse{
+		BlockMeta:    req.Blocks[0],
+		RelevantTxns: relevant,
+	}, nil
+}
+
+// TestRescanWatchOnlyGap checks that a resync finds a payment past the gap
+// limit of a watch-only account when the payment which extends the watched
+// addresses is only a few blocks before it.
+func TestRescanWatchOnlyGap(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	const zpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	scope, props, err := w.ImportAccountPubKey("cold", zpub, false, 0)
+	if err != nil {
+		t.Fatalf("ImportAccountPubKey: %v", err)
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		t.Fatalf("unable to fetch scope: %v", err)
+	}
+	gap := w.gapLimit()
+
+	// Pay the last watched address and the last address of the next gap.
+	chainClient := &rescanChainClient{txns: make(map[int32][]*wire.MsgTx)}
+	payments := map[int32]uint32{3: gap - 1, 6: 2*gap - 1}
+	for height, index := range payments {
+		var pkScript []byte
+		err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+			addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+			ma, err := manager.DeriveFromKeyPath(addrmgrNs, waddrmgr.DerivationPath{
+				Account: props.AccountNumber,
+				Branch:  waddrmgr.ExternalBranch,
+				Index:   index,
+			})
+			if err != nil {
+				return err
+			}
+			pkScript, err = txscript.PayToAddrScript(ma.Address())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("unable to derive address %d: %v", index, err)
+		}
+		chainClient.txns[height] = []*wire.MsgTx{{
+			TxIn:  []*wire.TxIn{{}},
+			TxOut: []*wire.TxOut{wire.NewTxOut(100000000, pkScript)},
+		}}
+	}
+	var prev chainhash.Hash
+	for i := 0; i < 12; i++ {
+		chainClient.headers = append(chainClient.headers, wire.BlockHeader{
+			PrevBlock: prev,
+			Timestamp: time.Unix(1387737310+int64(i)*60, 0),
+			Nonce:     uint32(i),
+		})
+		prev = chainClient.headers[i].BlockHash()
+	}
+	w.chainClient = chainClient
+
+	top := int32(len(chainClient.headers))
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return w.Manager.SetSyncedTo(addrmgrNs, &waddrmgr.BlockStamp{
+			Height:    top - 1,
+			Hash:      prev,
+			Timestamp: chainClient.headers[top-1].Timestamp,
+		})
+	})
+	if err != nil {
+		t.Fatalf("unable to set synced block: %v", err)
+	}
+	if err := w.rescan2(1, top, true); err != nil {
+		t.Fatalf("rescan2: %v", err)
+	}
+
+	// Both payments extended the watched addresses.
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		props, err = manager.AccountProperties(addrmgrNs, props.AccountNumber)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to fetch account properties: %v", err)
+	}
+	if props.ExternalKeyCount != 3*gap {
+		t.Fatalf("expected %d external addresses, got %d",
+			3*gap, props.ExternalKeyCount)
+	}
+}
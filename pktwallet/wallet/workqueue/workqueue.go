@@ -21,6 +21,7 @@ type WorkQueue struct {
 	maxNum      uint64
 	resultCache map[uint64]er.R
 	resultChan  chan struct{}
+	stop        chan struct{}
 
 	threads []threadCtx
 }
@@ -33,7 +34,11 @@ func work(tctx threadCtx, nextNum uint64) {
 }
 
 func task(tctx threadCtx) (bool, uint64) {
-	tctx.ff.resultChan <- struct{}{}
+	select {
+	case tctx.ff.resultChan <- struct{}{}:
+	case <-tctx.ff.stop:
+		return true, 0
+	}
 	tctx.ff.lock.Lock()
 	defer tctx.ff.lock.Unlock()
 	num := tctx.ff.nextNum
@@ -75,6 +80,12 @@ func (ff *WorkQueue) Get(
 	}
 }
 
+// Stop makes the workers exit without taking any more jobs, it must be called
+// at most once and Get must not be called after it.
+func (ff *WorkQueue) Stop() {
+	close(ff.stop)
+}
+
 func New(workerCount,
 	maxResults int,
 	rangeMin uint64,
@@ -84,6 +95,7 @@ func New(workerCount,
 	out := WorkQueue{
 		resultCache: make(map[uint64]er.R),
 		resultChan:  make(chan struct{}, maxResults),
+		stop:        make(chan struct{}),
 		threads:     make([]threadCtx, workerCount),
 		nextNum:     rangeMin,
 		maxNum:      rangeMax,
@@ -72,6 +72,7 @@ var (
 	bucketUnminedCredits = []byte("mc")
 	bucketUnminedInputs  = []byte("mi")
 	bucketLockedOutputs  = []byte("lo")
+	bucketReplacedTxs    = []byte("r")
 )
 
 // Root (namespace) bucket keys
@@ -1305,6 +1306,53 @@ func forEachLockedOutput(ns walletdb.ReadBucket,
 	})
 }
 
+// putReplacedTx records that the unmined transaction txHash was replaced by
+// the transaction replacement.
+func putReplacedTx(ns walletdb.ReadWriteBucket, txHash,
+	replacement *chainhash.Hash) er.R {
+
+	// Create the corresponding bucket if necessary.
+	replacedTxs, err := ns.CreateBucketIfNotExists(bucketReplacedTxs)
+	if err != nil {
+		str := "failed to create replaced txs bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	if err := replacedTxs.Put(txHash[:], replacement[:]); err != nil {
+		str := fmt.Sprintf("%s: put failed for %v", bucketReplacedTxs,
+			txHash)
+		return storeError(ErrDatabase, str, err)
+	}
+
+	return nil
+}
+
+// fetchReplacedTx returns the hash of the transaction which replaced the
+// transaction txHash, or nil if it was not replaced.
+func fetchReplacedTx(ns walletdb.ReadBucket,
+	txHash *chainhash.Hash) (*chainhash.Hash, er.R) {
+
+	// The bucket may not exist, indicating that no transaction has ever
+	// been replaced, so we can just return now.
+	replacedTxs := ns.NestedReadBucket(bucketReplacedTxs)
+	if replacedTxs == nil {
+		return nil, nil
+	}
+
+	v := replacedTxs.Get(txHash[:])
+	if v == nil {
+		return nil, nil
+	}
+	if len(v) != chainhash.HashSize {
+		str := fmt.Sprintf("%s: short read for %v", bucketReplacedTxs,
+			txHash)
+		return nil, storeError(ErrData, str, nil)
+	}
+	var replacement chainhash.Hash
+	copy(replacement[:], v)
+	return &replacement, nil
+}
+
 // openStore opens an existing transaction store from the passed namespace.
 func openStore(ns walletdb.ReadBucket) er.R {
 	version, err := fetchVersion(ns)
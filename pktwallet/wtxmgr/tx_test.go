@@ -1445,6 +1445,117 @@ func TestRemoveUnminedTx(t *testing.T) {
 	checkBalance(btcutil.Amount(initialBalance), true)
 }
 
+// TestReplaceUnminedTx ensures that an unmined transaction which is replaced
+// is removed along with its descendants, and that its replacement is recorded.
+func TestReplaceUnminedTx(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	// Create a confirmed coinbase output which is spent by an unmined
+	// transaction, whose change is spent by an unmined child.
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origTx := spendOutput(&cbRec.Hash, 0, 5e7, 4e7)
+	origRec, err := NewTxRecordFromMsgTx(origTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	childTx := spendOutput(&origRec.Hash, 1, 3e7)
+	childRec, err := NewTxRecordFromMsgTx(childTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddCredit(ns, cbRec, b100, 0, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, origRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddCredit(ns, origRec, nil, 1, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, childRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddCredit(ns, childRec, nil, 0, false); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// The replacement spends the same output and pays a higher fee.
+	replTx := spendOutput(&cbRec.Hash, 0, 5e7, 3e7)
+	replRec, err := NewTxRecordFromMsgTx(replTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, replRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddCredit(ns, replRec, nil, 1, true); err != nil {
+			t.Fatal(err)
+		}
+		err := store.ReplaceUnminedTx(ns, origRec, &replRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A transaction which is no longer unmined cannot be replaced.
+		err = store.ReplaceUnminedTx(ns, origRec, &replRec.Hash)
+		if !ErrInput.Is(err) {
+			t.Fatalf("expected ErrInput, got %v", err)
+		}
+	})
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		unminedTxs, err := store.UnminedTxs(ns)
+		if err != nil {
+			t.Fatalf("unable to query for unmined txs: %v", err)
+		}
+		if len(unminedTxs) != 1 || unminedTxs[0].TxHash() != replRec.Hash {
+			t.Fatalf("expected only the replacement to be unmined, "+
+				"got %v", unminedTxs)
+		}
+
+		replacedBy, err := store.ReplacedBy(ns, &origRec.Hash)
+		if err != nil {
+			t.Fatalf("unable to query for replacement: %v", err)
+		}
+		if replacedBy == nil || *replacedBy != replRec.Hash {
+			t.Fatalf("expected %v to be replaced by %v, got %v",
+				origRec.Hash, replRec.Hash, replacedBy)
+		}
+		replacedBy, err = store.ReplacedBy(ns, &replRec.Hash)
+		if err != nil || replacedBy != nil {
+			t.Fatalf("expected %v not to be replaced, got %v %v",
+				replRec.Hash, replacedBy, err)
+		}
+	})
+
+	// Only the change of the replacement is left in the balance.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		maturityHeight := b100.Block.Height +
+			int32(chaincfg.TestNet3Params.CoinbaseMaturity)
+		assertBalance(t, store, ns, false, maturityHeight, 3e7)
+	})
+}
+
 // TestInsertMempoolTxAlreadyConfirmed ensures that transactions that already
 // exist within the store as confirmed cannot be added as unconfirmed.
 func TestInsertMempoolTxAlreadyConfirmed(t *testing.T) {
@@ -6,6 +6,8 @@
 package wtxmgr
 
 import (
+	"fmt"
+
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/pktlog/log"
@@ -167,6 +169,34 @@ func removeConflict(ns walletdb.ReadWriteBucket, rec *TxRecord) er.R {
 	return deleteRawUnmined(ns, rec.Hash[:])
 }
 
+// ReplaceUnminedTx removes an unmined transaction which was replaced by
+// another transaction spending the same outputs, as allowed by BIP0125, along
+// with all transactions which spend it.  The hash of the replacement is
+// recorded so ReplacedBy can tell what became of the removed transaction.
+func (s *Store) ReplaceUnminedTx(ns walletdb.ReadWriteBucket, rec *TxRecord,
+	replacement *chainhash.Hash) er.R {
+
+	if existsRawUnmined(ns, rec.Hash[:]) == nil {
+		str := fmt.Sprintf("transaction %v is not unmined", rec.Hash)
+		return storeError(ErrInput, str, nil)
+	}
+
+	log.Infof("Transaction [%s] was replaced by [%s]",
+		log.Txid(rec.Hash.String()), log.Txid(replacement.String()))
+	if err := putReplacedTx(ns, &rec.Hash, replacement); err != nil {
+		return err
+	}
+	return removeConflict(ns, rec)
+}
+
+// ReplacedBy returns the hash of the transaction which replaced the unmined
+// transaction txHash, or nil if it was not replaced.
+func (s *Store) ReplacedBy(ns walletdb.ReadBucket,
+	txHash *chainhash.Hash) (*chainhash.Hash, er.R) {
+
+	return fetchReplacedTx(ns, txHash)
+}
+
 // UnminedTxs returns the underlying transactions for all unmined transactions
 // which are not known to have been mined in a block.  Transactions are
 // guaranteed to be sorted by their dependency order.
@@ -0,0 +1,776 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/annpool"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// defaultPoolTarget is the default work target of the announcements
+	// and block shares accepted by the pool server.
+	defaultPoolTarget = 0x207fffff
+
+	// maxPoolAnnUpload is the maximum number of announcements which can be
+	// uploaded with a single request.
+	maxPoolAnnUpload = 4096
+
+	// maxPoolAnns is the maximum number of announcements the pool server
+	// stores, further uploads are rejected until old announcements are
+	// pruned.
+	maxPoolAnns = 1 << 18
+
+	// maxPoolWorks is the number of block templates which the pool server
+	// keeps, shares for older templates are rejected.
+	maxPoolWorks = 16
+
+	// maxPoolShareSize is the maximum size of a share submission, a share
+	// has a header, a PacketCrypt proof and the coinbase.
+	maxPoolShareSize = 1 << 20
+
+	// poolWorkRefreshSecs is the minimum number of seconds between block
+	// templates when only the memory pool has changed.
+	poolWorkRefreshSecs = 5
+)
+
+// poolServerConfig is a descriptor containing the pool server configuration.
+type poolServerConfig struct {
+	// ChainParams are the parameters of the chain which the pool mines.
+	ChainParams *chaincfg.Params
+
+	// Chain is the chain which the work is built on and the announcements
+	// are validated against.
+	Chain *blockchain.BlockChain
+
+	// Generator generates the block templates which are served as work.
+	Generator *mining.BlkTmplGenerator
+
+	// TimeSource is the median time source which the timestamps of the
+	// shares are checked against.
+	TimeSource blockchain.MedianTimeSource
+
+	// Payouts are the addresses which the blocks pay.
+	Payouts *mining.PayoutSchedule
+
+	// ProcessBlock is called with the shares which are good enough to be
+	// blocks.
+	ProcessBlock func(*btcutil.Block, blockchain.BehaviorFlags) (bool, er.R)
+
+	// AnnTarget is the highest work target of the accepted announcements.
+	AnnTarget uint32
+
+	// ShareTarget is the work target of the accepted block shares.
+	ShareTarget uint32
+}
+
+// poolShareKey identifies a share, shares of the same work differ by their
+// header or the nonce of their PacketCrypt proof.
+type poolShareKey struct {
+	header   chainhash.Hash
+	pcpNonce uint32
+}
+
+// poolWork is a block template served by the pool server, along with the
+// shares which were accepted for it.
+type poolWork struct {
+	id           uint64
+	height       int32
+	block        *wire.MsgBlock
+	minTimestamp time.Time
+	merkleBranch []*chainhash.Hash
+	generated    time.Time
+	lastTxUpdate time.Time
+	result       *poolWorkResult
+	shares       map[poolShareKey]struct{}
+}
+
+// poolWorkResult is the response to a /work request.  The coinbase contains a
+// placeholder PacketCrypt commitment which the block miner replaces with its
+// own, the merkle root is then computed with the merkle branch.
+type poolWorkResult struct {
+	WorkID       uint64   `json:"workid"`
+	Height       int32    `json:"height"`
+	Header       string   `json:"header"`
+	Coinbase     string   `json:"coinbase"`
+	MerkleBranch []string `json:"merklebranch"`
+	Transactions []string `json:"transactions"`
+	AnnTarget    string   `json:"anntarget"`
+	ShareTarget  string   `json:"sharetarget"`
+}
+
+// poolAnnsResult is the response to an announcement upload.
+type poolAnnsResult struct {
+	Accepted int      `json:"accepted"`
+	Rejected int      `json:"rejected"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// poolAnnHeight is the number of announcements stored for a parent height, in
+// the response to a /anns request.
+type poolAnnHeight struct {
+	ParentHeight uint32 `json:"parentheight"`
+	Count        int    `json:"count"`
+}
+
+// poolShareRequest is a block share submission.  The block is hex encoded
+// without transactions, the coinbase is hex encoded separately.
+type poolShareRequest struct {
+	WorkID   uint64 `json:"workid"`
+	HexBlock string `json:"hexblock"`
+	Coinbase string `json:"coinbase"`
+}
+
+// poolShareResult is the response to a block share submission, the result is
+// OK, or BLOCK when the share was submitted as a block.
+type poolShareResult struct {
+	Result    string `json:"result"`
+	BlockHash string `json:"blockhash,omitempty"`
+}
+
+// poolCredits are the credits of a miner, the work is the sum of the work of
+// the targets of the accepted announcements and shares.
+type poolCredits struct {
+	Anns      uint64  `json:"anns"`
+	AnnWork   float64 `json:"annwork"`
+	Shares    uint64  `json:"shares"`
+	ShareWork float64 `json:"sharework"`
+	Blocks    uint64  `json:"blocks"`
+}
+
+// poolServer serves work to PacketCrypt miners, collects their announcements
+// and block shares and accounts their credits.
+type poolServer struct {
+	cfg        poolServerConfig
+	httpServer *http.Server
+
+	workLock   sync.Mutex
+	works      []*poolWork
+	nextWorkID uint64
+
+	annLock     sync.Mutex
+	anns        map[uint32][]*wire.PacketCryptAnn
+	annHashes   map[[32]byte]struct{}
+	annCount    int
+	annsAgedFor int32
+
+	creditLock sync.Mutex
+	credits    map[string]*poolCredits
+}
+
+// newPoolServer returns a pool server for the passed configuration.
+func newPoolServer(cfg *poolServerConfig) *poolServer {
+	return &poolServer{
+		cfg:       *cfg,
+		anns:      make(map[uint32][]*wire.PacketCryptAnn),
+		annHashes: make(map[[32]byte]struct{}),
+		credits:   make(map[string]*poolCredits),
+	}
+}
+
+// targetWork returns the work of the passed compact target.
+func targetWork(target uint32) float64 {
+	work, _ := difficulty.WorkForTarget(difficulty.CompactToBig(target)).Float64()
+	return work
+}
+
+// credit adds to the credits of a miner.
+func (p *poolServer) credit(miner string, f func(c *poolCredits)) {
+	p.creditLock.Lock()
+	defer p.creditLock.Unlock()
+	c := p.credits[miner]
+	if c == nil {
+		c = &poolCredits{}
+		p.credits[miner] = c
+	}
+	f(c)
+}
+
+// miner returns the miner which the request is from, identified by the
+// address its credits are paid to.
+func (p *poolServer) miner(r *http.Request) (string, er.R) {
+	miner := r.URL.Query().Get("miner")
+	if miner == "" {
+		return "", er.New("missing miner address")
+	}
+	addr, err := btcutil.DecodeAddress(miner, p.cfg.ChainParams)
+	if err != nil {
+		return "", er.Errorf("invalid miner address: %v", err)
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// newWork generates a block template and makes it the current work.
+//
+// This function MUST be called with the work lock held.
+func (p *poolServer) newWork() (*poolWork, er.R) {
+	lastTxUpdate := p.cfg.Generator.TxSource().LastUpdated()
+	template, err := p.cfg.Generator.NewBlockTemplate(p.cfg.Payouts,
+		wire.NewPcCoinbaseCommit())
+	if err != nil {
+		return nil, err
+	}
+	msgBlock := template.Block
+	block := btcutil.NewBlock(msgBlock)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	w := &poolWork{
+		id:           p.nextWorkID,
+		height:       template.Height,
+		block:        msgBlock,
+		minTimestamp: mining.MinimumMedianTime(p.cfg.Chain.BestSnapshot()),
+		merkleBranch: blockchain.GetMerkleBranch(0, merkles),
+		generated:    time.Now(),
+		lastTxUpdate: lastTxUpdate,
+		shares:       make(map[poolShareKey]struct{}),
+	}
+	p.nextWorkID++
+
+	var headerBuf bytes.Buffer
+	if err := msgBlock.Header.Serialize(&headerBuf); err != nil {
+		return nil, err
+	}
+	var coinbaseBuf bytes.Buffer
+	if err := msgBlock.Transactions[0].BtcEncode(&coinbaseBuf, 0,
+		wire.WitnessEncoding); err != nil {
+		return nil, err
+	}
+	w.result = &poolWorkResult{
+		WorkID:       w.id,
+		Height:       w.height,
+		Header:       hex.EncodeToString(headerBuf.Bytes()),
+		Coinbase:     hex.EncodeToString(coinbaseBuf.Bytes()),
+		MerkleBranch: make([]string, 0, len(w.merkleBranch)),
+		Transactions: make([]string, 0, len(msgBlock.Transactions)-1),
+		AnnTarget:    strconv.FormatUint(uint64(p.cfg.AnnTarget), 16),
+		ShareTarget:  strconv.FormatUint(uint64(p.cfg.ShareTarget), 16),
+	}
+	for _, hash := range w.merkleBranch {
+		w.result.MerkleBranch = append(w.result.MerkleBranch,
+			hex.EncodeToString(hash[:]))
+	}
+	for _, tx := range msgBlock.Transactions[1:] {
+		var txBuf bytes.Buffer
+		if err := tx.BtcEncode(&txBuf, 0, wire.WitnessEncoding); err != nil {
+			return nil, err
+		}
+		w.result.Transactions = append(w.result.Transactions,
+			hex.EncodeToString(txBuf.Bytes()))
+	}
+
+	p.works = append(p.works, w)
+	if len(p.works) > maxPoolWorks {
+		p.works = p.works[1:]
+	}
+	return w, nil
+}
+
+// currentWork returns the current work, a new block template is generated
+// when the best block has changed or when the memory pool has changed and
+// the current template is old enough.
+func (p *poolServer) currentWork() (*poolWork, er.R) {
+	p.workLock.Lock()
+	defer p.workLock.Unlock()
+
+	if len(p.works) > 0 {
+		w := p.works[len(p.works)-1]
+		best := p.cfg.Chain.BestSnapshot()
+		if w.block.Header.PrevBlock.IsEqual(&best.Hash) &&
+			(w.lastTxUpdate == p.cfg.Generator.TxSource().LastUpdated() ||
+				time.Since(w.generated) < time.Second*poolWorkRefreshSecs) {
+			return w, nil
+		}
+	}
+	return p.newWork()
+}
+
+// work returns the work with the passed id, or nil if it is unknown or expired.
+func (p *poolServer) work(id uint64) *poolWork {
+	p.workLock.Lock()
+	defer p.workLock.Unlock()
+	for _, w := range p.works {
+		if w.id == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// pruneAnns removes the announcements which have expired for the next block.
+//
+// This function MUST be called with the announcement lock held.
+func (p *poolServer) pruneAnns() {
+	nextHeight := p.cfg.Chain.BestSnapshot().Height + 1
+	if nextHeight == p.annsAgedFor {
+		return
+	}
+	p.annsAgedFor = nextHeight
+	for height, anns := range p.anns {
+		kept := anns[:0]
+		for _, ann := range anns {
+			if !annpool.IsAnnExpired(ann, nextHeight) {
+				kept = append(kept, ann)
+				continue
+			}
+			var hash [32]byte
+			pcutil.HashCompress(hash[:], ann.Header[:])
+			delete(p.annHashes, hash)
+			p.annCount--
+		}
+		if len(kept) == 0 {
+			delete(p.anns, height)
+		} else {
+			p.anns[height] = kept
+		}
+	}
+}
+
+// checkAnn validates an uploaded announcement and returns its hash.
+func (p *poolServer) checkAnn(ann *wire.PacketCryptAnn, nextHeight int32) ([32]byte, er.R) {
+	var hash [32]byte
+	if ann.HasSigningKey() {
+		return hash, er.New("signed announcements are not accepted")
+	}
+	if ann.GetWorkTarget() > p.cfg.AnnTarget {
+		return hash, er.Errorf("work target [%08x] is above the pool target "+
+			"[%08x]", ann.GetWorkTarget(), p.cfg.AnnTarget)
+	}
+	parentHeight := ann.GetParentBlockHeight()
+	if parentHeight >= uint32(nextHeight) {
+		return hash, er.Errorf("unknown parent block height [%d]",
+			parentHeight)
+	}
+	if annpool.IsAnnExpired(ann, nextHeight) {
+		return hash, er.Errorf("announcement with parent block height [%d] "+
+			"has expired", parentHeight)
+	}
+	parentHash, err := p.cfg.Chain.BlockHashByHeight(int32(parentHeight))
+	if err != nil {
+		return hash, err
+	}
+	workHash, err := packetcrypt.ValidatePcAnn(ann, parentHash,
+		annpool.PacketCryptVersion(nextHeight))
+	if err != nil {
+		return hash, err
+	}
+	if !difficulty.IsOk(workHash[:], ann.GetWorkTarget()) {
+		return hash, er.New("work hash does not meet the work target")
+	}
+	pcutil.HashCompress(hash[:], ann.Header[:])
+	return hash, nil
+}
+
+// addAnns validates the passed announcements in parallel and stores those
+// which are valid.
+func (p *poolServer) addAnns(miner string, anns []*wire.PacketCryptAnn) *poolAnnsResult {
+	nextHeight := p.cfg.Chain.BestSnapshot().Height + 1
+	hashes := make([][32]byte, len(anns))
+	errs := make([]er.R, len(anns))
+
+	var wg sync.WaitGroup
+	threads := runtime.NumCPU()
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			for i := t; i < len(anns); i += threads {
+				hashes[i], errs[i] = p.checkAnn(anns[i], nextHeight)
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	res := &poolAnnsResult{}
+	var work float64
+	p.annLock.Lock()
+	p.pruneAnns()
+	for i, ann := range anns {
+		err := errs[i]
+		if err == nil {
+			if _, ok := p.annHashes[hashes[i]]; ok {
+				err = er.New("duplicate announcement")
+			} else if p.annCount >= maxPoolAnns {
+				err = er.New("announcement storage is full")
+			}
+		}
+		if err != nil {
+			res.Rejected++
+			if len(res.Errors) < 10 {
+				res.Errors = append(res.Errors, "announcement "+
+					strconv.Itoa(i)+": "+err.Message())
+			}
+			continue
+		}
+		height := ann.GetParentBlockHeight()
+		p.anns[height] = append(p.anns[height], ann)
+		p.annHashes[hashes[i]] = struct{}{}
+		p.annCount++
+		res.Accepted++
+		work += targetWork(ann.GetWorkTarget())
+	}
+	p.annLock.Unlock()
+
+	if res.Accepted > 0 {
+		p.credit(miner, func(c *poolCredits) {
+			c.Anns += uint64(res.Accepted)
+			c.AnnWork += work
+		})
+	}
+	return res
+}
+
+// announcements returns the stored announcements which can be used to mine
+// the block at the passed height.
+func (p *poolServer) announcements(height int32) []blockminer.Announcement {
+	p.annLock.Lock()
+	defer p.annLock.Unlock()
+	p.pruneAnns()
+	var out []blockminer.Announcement
+	for _, anns := range p.anns {
+		for _, ann := range anns {
+			if !difficulty.IsAnnMinDiffOk(annpool.AnnTarget(ann, height),
+				annpool.PacketCryptVersion(height)) {
+				continue
+			}
+			out = append(out, blockminer.Announcement{Ann: ann})
+		}
+	}
+	return out
+}
+
+// checkShare validates a block share and returns the block it makes, with
+// all the transactions of the work, along with whether it is good enough to
+// be submitted as a block.  A share is only accepted once for its work.
+func (p *poolServer) checkShare(req *poolShareRequest) (*wire.MsgBlock, bool, er.R) {
+	w := p.work(req.WorkID)
+	if w == nil {
+		return nil, false, er.Errorf("unknown or expired work [%d]", req.WorkID)
+	}
+
+	blockBytes, errr := hex.DecodeString(req.HexBlock)
+	if errr != nil {
+		return nil, false, er.E(errr)
+	}
+	mb := &wire.MsgBlock{}
+	if err := mb.BtcDecode(bytes.NewReader(blockBytes), 0,
+		wire.PacketCryptEncoding); err != nil {
+		return nil, false, err
+	}
+	if mb.Pcp == nil || len(mb.Transactions) != 0 {
+		return nil, false, er.New("share must have a PacketCrypt proof " +
+			"and no transactions")
+	}
+	coinbaseBytes, errr := hex.DecodeString(req.Coinbase)
+	if errr != nil {
+		return nil, false, er.E(errr)
+	}
+	coinbase := &wire.MsgTx{}
+	if err := coinbase.BtcDecode(bytes.NewReader(coinbaseBytes), 0,
+		wire.WitnessEncoding); err != nil {
+		return nil, false, err
+	}
+
+	// The share must be based on the work, with the same outputs in the
+	// coinbase other than the PacketCrypt commitment.
+	if mb.Header.PrevBlock != w.block.Header.PrevBlock ||
+		mb.Header.Bits != w.block.Header.Bits {
+		return nil, false, er.New("share header does not match the work")
+	}
+	if packetcrypt.ExtractCoinbaseCommit(coinbase) == nil {
+		return nil, false, er.New("missing PacketCrypt commitment")
+	}
+	if !sameCoinbaseOutputs(coinbase, w.block.Transactions[0]) {
+		return nil, false, er.New("share coinbase outputs do not match " +
+			"the work")
+	}
+	root := coinbase.TxHash()
+	for _, hash := range w.merkleBranch {
+		root = chainhash.DoubleHashH(append(root[:], hash[:]...))
+	}
+	if root != mb.Header.MerkleRoot {
+		return nil, false, er.New("share merkle root mismatch")
+	}
+	maxTimestamp := p.cfg.TimeSource.AdjustedTime().Add(
+		time.Second * globalcfg.GetMaxTimeOffset())
+	if mb.Header.Timestamp.Before(w.minTimestamp) ||
+		mb.Header.Timestamp.After(maxTimestamp) {
+		return nil, false, er.New("share timestamp out of range")
+	}
+
+	key := poolShareKey{header: mb.Header.BlockHash(), pcpNonce: mb.Pcp.Nonce}
+	p.workLock.Lock()
+	_, dup := w.shares[key]
+	p.workLock.Unlock()
+	if dup {
+		return nil, false, er.New("duplicate share")
+	}
+
+	parentHashes := make([]*chainhash.Hash, len(mb.Pcp.Announcements))
+	for i := range mb.Pcp.Announcements {
+		height := mb.Pcp.Announcements[i].GetParentBlockHeight()
+		hash, err := p.cfg.Chain.BlockHashByHeight(int32(height))
+		if err != nil {
+			return nil, false, er.Errorf("could not get parent hash at "+
+				"height [%d] for announcement [%d]", height, i)
+		}
+		parentHashes[i] = hash
+	}
+
+	mb.Transactions = append([]*wire.MsgTx{coinbase}, w.block.Transactions[1:]...)
+	blockOk, err := packetcrypt.ValidatePcBlock(mb, w.height,
+		p.cfg.ShareTarget, parentHashes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The share is only recorded once it is valid, the same share may be
+	// submitted at the same time in several requests.
+	p.workLock.Lock()
+	_, dup = w.shares[key]
+	w.shares[key] = struct{}{}
+	p.workLock.Unlock()
+	if dup {
+		return nil, false, er.New("duplicate share")
+	}
+	return mb, blockOk, nil
+}
+
+// sameCoinbaseOutputs returns whether the passed coinbases have the same
+// outputs, other than their PacketCrypt commitments.
+func sameCoinbaseOutputs(a, b *wire.MsgTx) bool {
+	outputs := func(tx *wire.MsgTx) []*wire.TxOut {
+		var out []*wire.TxOut
+		for _, txOut := range tx.TxOut {
+			if len(txOut.PkScript) > 2 && txOut.PkScript[0] == 0x6a &&
+				txOut.PkScript[1] == 0x30 {
+				continue
+			}
+			out = append(out, txOut)
+		}
+		return out
+	}
+	ao := outputs(a)
+	bo := outputs(b)
+	if len(ao) != len(bo) {
+		return false
+	}
+	for i := range ao {
+		if ao[i].Value != bo[i].Value ||
+			!bytes.Equal(ao[i].PkScript, bo[i].PkScript) {
+			return false
+		}
+	}
+	return true
+}
+
+// poolError writes an error response.
+func poolError(w http.ResponseWriter, status int, err er.R) {
+	http.Error(w, err.Message(), status)
+}
+
+// poolJSON writes a JSON response.
+func poolJSON(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if errr := json.NewEncoder(w).Encode(result); errr != nil {
+		log.Errorf("Failed to write pool response: %v", errr)
+	}
+}
+
+// handleWork serves the current work.
+func (p *poolServer) handleWork(w http.ResponseWriter, r *http.Request) {
+	work, err := p.currentWork()
+	if err != nil {
+		poolError(w, http.StatusInternalServerError, err)
+		return
+	}
+	poolJSON(w, work.result)
+}
+
+// handleAnns accepts uploads of announcements, which are posted as their
+// binary encodings back to back, and lists the stored announcements by parent
+// height.
+func (p *poolServer) handleAnns(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		p.annLock.Lock()
+		p.pruneAnns()
+		heights := make([]poolAnnHeight, 0, len(p.anns))
+		for height, anns := range p.anns {
+			heights = append(heights, poolAnnHeight{
+				ParentHeight: height,
+				Count:        len(anns),
+			})
+		}
+		p.annLock.Unlock()
+		sort.Slice(heights, func(i, j int) bool {
+			return heights[i].ParentHeight < heights[j].ParentHeight
+		})
+		poolJSON(w, heights)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	miner, err := p.miner(r)
+	if err != nil {
+		poolError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, errr := ioutil.ReadAll(http.MaxBytesReader(w, r.Body,
+		maxPoolAnnUpload*wire.PcAnnSerializeSize))
+	if errr != nil {
+		poolError(w, http.StatusBadRequest, er.E(errr))
+		return
+	}
+	if len(body) == 0 || len(body)%wire.PcAnnSerializeSize != 0 {
+		poolError(w, http.StatusBadRequest, er.Errorf("upload size [%d] is "+
+			"not a multiple of the announcement size", len(body)))
+		return
+	}
+	anns := make([]*wire.PacketCryptAnn, len(body)/wire.PcAnnSerializeSize)
+	for i := range anns {
+		anns[i] = &wire.PacketCryptAnn{}
+		copy(anns[i].Header[:], body[i*wire.PcAnnSerializeSize:])
+	}
+	poolJSON(w, p.addAnns(miner, anns))
+}
+
+// handleAnnsAtHeight serves the stored announcements with the parent height
+// following /anns/ in the path, as their binary encodings back to back.
+func (p *poolServer) handleAnnsAtHeight(w http.ResponseWriter, r *http.Request) {
+	height, errr := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/anns/"),
+		10, 32)
+	if errr != nil {
+		poolError(w, http.StatusBadRequest, er.E(errr))
+		return
+	}
+	p.annLock.Lock()
+	p.pruneAnns()
+	anns := p.anns[uint32(height)]
+	out := make([]byte, 0, len(anns)*wire.PcAnnSerializeSize)
+	for _, ann := range anns {
+		out = append(out, ann.Header[:]...)
+	}
+	p.annLock.Unlock()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(out)
+}
+
+// handleShare accepts block shares, the shares which are good enough are
+// submitted as blocks.
+func (p *poolServer) handleShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	miner, err := p.miner(r)
+	if err != nil {
+		poolError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req poolShareRequest
+	if errr := json.NewDecoder(http.MaxBytesReader(w, r.Body,
+		maxPoolShareSize)).Decode(&req); errr != nil {
+		poolError(w, http.StatusBadRequest, er.E(errr))
+		return
+	}
+	mb, blockOk, err := p.checkShare(&req)
+	if err != nil {
+		poolError(w, http.StatusBadRequest, err)
+		return
+	}
+	p.credit(miner, func(c *poolCredits) {
+		c.Shares++
+		c.ShareWork += targetWork(p.cfg.ShareTarget)
+	})
+	if !blockOk {
+		poolJSON(w, &poolShareResult{Result: "OK"})
+		return
+	}
+
+	block := btcutil.NewBlock(mb)
+	isOrphan, err := p.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil || isOrphan {
+		log.Infof("Pool block %s from %s rejected: orphan %v, %v",
+			block.Hash(), miner, isOrphan, err)
+		poolJSON(w, &poolShareResult{Result: "OK"})
+		return
+	}
+	log.Infof("Pool block %s from %s accepted", block.Hash(), miner)
+	p.credit(miner, func(c *poolCredits) { c.Blocks++ })
+	poolJSON(w, &poolShareResult{
+		Result:    "BLOCK",
+		BlockHash: block.Hash().String(),
+	})
+}
+
+// handleCredits serves the credits of all the miners.
+func (p *poolServer) handleCredits(w http.ResponseWriter, r *http.Request) {
+	p.creditLock.Lock()
+	credits := make(map[string]poolCredits, len(p.credits))
+	for miner, c := range p.credits {
+		credits[miner] = *c
+	}
+	p.creditLock.Unlock()
+	poolJSON(w, credits)
+}
+
+// handler returns the HTTP handler of the pool server.
+func (p *poolServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/work", p.handleWork)
+	mux.HandleFunc("/anns", p.handleAnns)
+	mux.HandleFunc("/anns/", p.handleAnnsAtHeight)
+	mux.HandleFunc("/share", p.handleShare)
+	mux.HandleFunc("/credits", p.handleCredits)
+	return mux
+}
+
+// Start starts serving on the address configured with --poollisten.
+func (p *poolServer) Start() er.R {
+	listener, errr := net.Listen("tcp", cfg.PoolListen)
+	if errr != nil {
+		return er.E(errr)
+	}
+	p.httpServer = &http.Server{
+		Handler:     p.handler(),
+		ReadTimeout: time.Second * rpcAuthTimeoutSeconds,
+	}
+	log.Infof("Pool server listening on %s", listener.Addr())
+	go func() {
+		if errr := p.httpServer.Serve(listener); errr != http.ErrServerClosed {
+			log.Errorf("Pool server: %v", errr)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the pool server.
+func (p *poolServer) Stop() {
+	if p.httpServer != nil {
+		p.httpServer.Close()
+	}
+}
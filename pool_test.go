@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// poolRequest issues a request to the passed pool server and decodes the JSON
+// response into result, if it is not nil.
+func poolRequest(t *testing.T, p *poolServer, method, path string, body []byte,
+	result interface{}) int {
+	w := httptest.NewRecorder()
+	p.handler().ServeHTTP(w, httptest.NewRequest(method, path,
+		bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Logf("%s %s: %s", method, path, w.Body.String())
+	}
+	if result != nil && w.Code == http.StatusOK {
+		if errr := json.Unmarshal(w.Body.Bytes(), result); errr != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, errr, w.Body.String())
+		}
+	}
+	return w.Code
+}
+
+// TestPoolServer ensures the pool server serves work, stores valid
+// announcements, accepts block shares made from them and submits those which
+// are blocks.
+func TestPoolServer(t *testing.T) {
+	rs, _, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	params := &chaincfg.SimNetParams
+	chain := rs.cfg.Chain
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	miner := addr.EncodeAddress()
+
+	var submitted []*btcutil.Block
+	timeSource := blockchain.NewMedianTime()
+	p := newPoolServer(&poolServerConfig{
+		ChainParams: params,
+		Chain:       chain,
+		Generator: mining.NewBlkTmplGenerator(&mining.Policy{
+			BlockMaxWeight: defaultBlockMaxWeight,
+			BlockMaxSize:   defaultBlockMaxSize,
+		}, params, rs.cfg.TxMemPool, chain, timeSource,
+			txscript.NewSigCache(10), txscript.NewHashCache(10)),
+		TimeSource: timeSource,
+		Payouts:    newTestPayouts(t, "", addr),
+		ProcessBlock: func(b *btcutil.Block, _ blockchain.BehaviorFlags) (bool, er.R) {
+			submitted = append(submitted, b)
+			return false, nil
+		},
+		AnnTarget:   defaultPoolTarget,
+		ShareTarget: defaultPoolTarget,
+	})
+
+	var work poolWorkResult
+	if code := poolRequest(t, p, http.MethodGet, "/work", nil, &work); code != http.StatusOK {
+		t.Fatalf("/work: status %d", code)
+	}
+	if work.Height != 2 {
+		t.Fatalf("/work: height %d, want 2", work.Height)
+	}
+
+	// Upload announcements along with a tampered one and one whose parent
+	// block is unknown.
+	m, err := annminer.New(&annminer.Request{
+		ParentBlockHash: *params.GenesisHash,
+		WorkTarget:      defaultPoolTarget,
+		Content:         []byte("pool"),
+	}, 0)
+	if err != nil {
+		t.Fatalf("annminer.New: %v", err)
+	}
+	anns, err := m.Mine(8, nil)
+	if err != nil {
+		t.Fatalf("Mine: %v", err)
+	}
+	var upload []byte
+	for _, ann := range anns {
+		upload = append(upload, ann.Header[:]...)
+	}
+	tampered := *anns[0]
+	tampered.Header[100] ^= 1
+	upload = append(upload, tampered.Header[:]...)
+	unknown, err := annminer.New(&annminer.Request{
+		ParentBlockHash:   *params.GenesisHash,
+		ParentBlockHeight: 5,
+		WorkTarget:        defaultPoolTarget,
+	}, 0)
+	if err != nil {
+		t.Fatalf("annminer.New: %v", err)
+	}
+	unknownAnns, err := unknown.Mine(1, nil)
+	if err != nil {
+		t.Fatalf("Mine: %v", err)
+	}
+	upload = append(upload, unknownAnns[0].Header[:]...)
+
+	var annsRes poolAnnsResult
+	if code := poolRequest(t, p, http.MethodPost, "/anns?miner="+miner,
+		upload, &annsRes); code != http.StatusOK {
+		t.Fatalf("/anns: status %d", code)
+	}
+	if annsRes.Accepted != 8 || annsRes.Rejected != 2 {
+		t.Fatalf("/anns: accepted %d, rejected %d: %v", annsRes.Accepted,
+			annsRes.Rejected, annsRes.Errors)
+	}
+
+	// Uploading them again only yields duplicates.
+	if code := poolRequest(t, p, http.MethodPost, "/anns?miner="+miner,
+		upload[:wire.PcAnnSerializeSize], &annsRes); code != http.StatusOK ||
+		annsRes.Accepted != 0 {
+		t.Fatalf("/anns: duplicate accepted: %d %v", code, annsRes)
+	}
+	if code := poolRequest(t, p, http.MethodPost, "/anns", upload, nil); code != http.StatusBadRequest {
+		t.Fatalf("/anns: status %d without a miner", code)
+	}
+	if code := poolRequest(t, p, http.MethodPost, "/anns?miner="+miner,
+		upload[:100], nil); code != http.StatusBadRequest {
+		t.Fatalf("/anns: status %d with a partial announcement", code)
+	}
+
+	var heights []poolAnnHeight
+	poolRequest(t, p, http.MethodGet, "/anns", nil, &heights)
+	if len(heights) != 1 || heights[0].ParentHeight != 0 ||
+		heights[0].Count != 8 {
+		t.Fatalf("/anns: unexpected heights %v", heights)
+	}
+	w := httptest.NewRecorder()
+	p.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/anns/0", nil))
+	if !bytes.Equal(w.Body.Bytes(), upload[:8*wire.PcAnnSerializeSize]) {
+		t.Fatalf("/anns/0: unexpected announcements")
+	}
+
+	// Mine a share with the stored announcements, the pool target is the
+	// block target so it is submitted as a block.
+	headerBytes, _ := hex.DecodeString(work.Header)
+	var header wire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	coinbaseBytes, _ := hex.DecodeString(work.Coinbase)
+	coinbase := &wire.MsgTx{}
+	if err := coinbase.BtcDecode(bytes.NewReader(coinbaseBytes), 0,
+		wire.WitnessEncoding); err != nil {
+		t.Fatalf("BtcDecode: %v", err)
+	}
+	bm, err := blockminer.New(p.announcements(work.Height), work.Height, 1)
+	if err != nil {
+		t.Fatalf("blockminer.New: %v", err)
+	}
+	bm.InsertCommit(coinbase)
+	root := coinbase.TxHash()
+	for _, branch := range work.MerkleBranch {
+		hash, errr := hex.DecodeString(branch)
+		if errr != nil {
+			t.Fatalf("DecodeString: %v", errr)
+		}
+		root = chainhash.DoubleHashH(append(root[:], hash...))
+	}
+	header.MerkleRoot = root
+	mb := wire.NewMsgBlock(&header)
+	for {
+		ok, err := bm.Mine(mb, nil)
+		if err != nil {
+			t.Fatalf("Mine: %v", err)
+		}
+		if ok {
+			break
+		}
+		// The header selects an announcement which cannot be proven.
+		mb.Header.Nonce++
+	}
+	var blockBuf, coinbaseBuf bytes.Buffer
+	if err := mb.BtcEncode(&blockBuf, 0, wire.PacketCryptEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	if err := coinbase.BtcEncode(&coinbaseBuf, 0, wire.WitnessEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	share, _ := json.Marshal(&poolShareRequest{
+		WorkID:   work.WorkID,
+		HexBlock: hex.EncodeToString(blockBuf.Bytes()),
+		Coinbase: hex.EncodeToString(coinbaseBuf.Bytes()),
+	})
+
+	badShare, _ := json.Marshal(&poolShareRequest{
+		WorkID:   work.WorkID + 1,
+		HexBlock: hex.EncodeToString(blockBuf.Bytes()),
+		Coinbase: hex.EncodeToString(coinbaseBuf.Bytes()),
+	})
+	if code := poolRequest(t, p, http.MethodPost, "/share?miner="+miner,
+		badShare, nil); code != http.StatusBadRequest {
+		t.Fatalf("/share: status %d with unknown work", code)
+	}
+
+	var shareRes poolShareResult
+	if code := poolRequest(t, p, http.MethodPost, "/share?miner="+miner,
+		share, &shareRes); code != http.StatusOK {
+		t.Fatalf("/share: status %d", code)
+	}
+	if shareRes.Result != "BLOCK" || len(submitted) != 1 {
+		t.Fatalf("/share: result %v, %d blocks submitted", shareRes,
+			len(submitted))
+	}
+	if submitted[0].Hash().String() != shareRes.BlockHash ||
+		len(submitted[0].Transactions()) != len(work.Transactions)+1 {
+		t.Fatalf("/share: unexpected block submitted")
+	}
+
+	// The same share is not accepted twice.
+	if code := poolRequest(t, p, http.MethodPost, "/share?miner="+miner,
+		share, nil); code != http.StatusBadRequest {
+		t.Fatalf("/share: status %d with a duplicate share", code)
+	}
+
+	// Nor is a share with a timestamp too far in the future.
+	future := *mb
+	future.Header.Timestamp = time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	blockBuf.Reset()
+	if err := future.BtcEncode(&blockBuf, 0, wire.PacketCryptEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	futureShare, _ := json.Marshal(&poolShareRequest{
+		WorkID:   work.WorkID,
+		HexBlock: hex.EncodeToString(blockBuf.Bytes()),
+		Coinbase: hex.EncodeToString(coinbaseBuf.Bytes()),
+	})
+	if code := poolRequest(t, p, http.MethodPost, "/share?miner="+miner,
+		futureShare, nil); code != http.StatusBadRequest {
+		t.Fatalf("/share: status %d with a future timestamp", code)
+	}
+
+	var credits map[string]poolCredits
+	poolRequest(t, p, http.MethodGet, "/credits", nil, &credits)
+	c := credits[miner]
+	if c.Anns != 8 || c.Shares != 1 || c.Blocks != 1 || c.AnnWork <= 0 ||
+		c.ShareWork <= 0 {
+		t.Fatalf("/credits: unexpected credits %v", credits)
+	}
+}
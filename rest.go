@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/protocol"
+)
+
+const (
+	// maxRESTHeaders is the maximum number of headers which can be
+	// requested with a single /rest/headers request.
+	maxRESTHeaders = 2000
+
+	// maxRESTOutpoints is the maximum number of outpoints which can be
+	// queried with a single /rest/getutxos request.
+	maxRESTOutpoints = 15
+
+	// restMempoolHeight is the height reported by /rest/getutxos for
+	// outputs of transactions which are in the memory pool.
+	restMempoolHeight = 0x7fffffff
+)
+
+// restFormat is the format of the response to a REST request, which is
+// selected by the extension of the requested path.
+type restFormat int
+
+const (
+	restFormatJSON restFormat = iota
+	restFormatBinary
+	restFormatHex
+)
+
+// restFormats maps the extensions of REST paths to their response format.
+var restFormats = map[string]restFormat{
+	"json": restFormatJSON,
+	"bin":  restFormatBinary,
+	"hex":  restFormatHex,
+}
+
+// restHandler is the type of the functions which serve REST requests.  The
+// arguments are the components of the path following the name of the
+// endpoint.  The result is marshalled as JSON when the JSON format is requested
+// and must be a byte slice otherwise.
+type restHandler func(*rpcServer, []string, restFormat) (interface{}, er.R)
+
+// restHandlers maps the name of each REST endpoint to its handler.
+var restHandlers = map[string]restHandler{
+	"block":     handleRESTBlock,
+	"headers":   handleRESTHeaders,
+	"tx":        handleRESTTx,
+	"getutxos":  handleRESTGetUTXOs,
+	"chaininfo": handleRESTChainInfo,
+	"mempool":   handleRESTMempool,
+}
+
+// restUTXO is an unspent output in the response to a /rest/getutxos request.
+type restUTXO struct {
+	Height       int32   `json:"height"`
+	ValueCoins   float64 `json:"value"`
+	Svalue       string  `json:"svalue"`
+	Address      string  `json:"address"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+}
+
+// restUTXOsResult is the response to a /rest/getutxos request.  The bitmap has
+// a '1' for each requested outpoint which is unspent and a '0' for the others,
+// only the unspent outputs are included in UTXOs.
+type restUTXOsResult struct {
+	ChainHeight  int32      `json:"chainHeight"`
+	ChainTipHash string     `json:"chaintipHash"`
+	Bitmap       string     `json:"bitmap"`
+	UTXOs        []restUTXO `json:"utxos"`
+}
+
+// restInvalidError is a convenience function for returning the error for a
+// malformed REST request.
+func restInvalidError(str string) er.R {
+	return btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter, str, nil)
+}
+
+// restErrorStatus returns the HTTP status code for the passed error returned by
+// a REST handler.
+func restErrorStatus(err er.R) int {
+	switch {
+	case btcjson.ErrRPCInvalidParameter.Is(err),
+		btcjson.ErrRPCDecodeHexString.Is(err):
+		return http.StatusBadRequest
+	case btcjson.ErrRPCBlockNotFound.Is(err),
+		btcjson.ErrRPCNoTxInfo.Is(err),
+		btcjson.ErrRPCMisc.Is(err):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// restRawResult decodes the hex string returned by an RPC handler when the
+// verbose flag is not set.
+func restRawResult(result interface{}) ([]byte, er.R) {
+	b, errr := hex.DecodeString(result.(string))
+	if errr != nil {
+		return nil, internalRPCError(er.E(errr), "Failed to decode result")
+	}
+	return b, nil
+}
+
+// handleREST serves the unauthenticated, read-only REST interface.  Paths have
+// the form /rest/<endpoint>/<arguments>.<format> where the format is one of
+// json, bin or hex.
+func (s *rpcServer) handleREST(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "405 Method not allowed.",
+			http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest/")
+	dot := strings.LastIndex(path, ".")
+	if dot == -1 {
+		http.Error(w, "400 Bad Request: no output format, expected "+
+			".json, .bin or .hex", http.StatusBadRequest)
+		return
+	}
+	format, ok := restFormats[path[dot+1:]]
+	if !ok {
+		http.Error(w, "400 Bad Request: unknown output format, "+
+			"expected .json, .bin or .hex", http.StatusBadRequest)
+		return
+	}
+	parts := strings.Split(path[:dot], "/")
+	handler, ok := restHandlers[parts[0]]
+	if !ok {
+		http.Error(w, "404 Not Found.", http.StatusNotFound)
+		return
+	}
+
+	result, err := handler(s, parts[1:], format)
+	if err != nil {
+		http.Error(w, err.Message(), restErrorStatus(err))
+		return
+	}
+
+	switch format {
+	case restFormatJSON:
+		b, errr := json.Marshal(result)
+		if errr != nil {
+			log.Errorf("Failed to marshal REST reply: %v", errr)
+			http.Error(w, "500 Internal Server Error.",
+				http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(b, '\n'))
+
+	case restFormatBinary:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(result.([]byte))
+
+	case restFormatHex:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(hex.EncodeToString(result.([]byte)) + "\n"))
+	}
+}
+
+// handleRESTBlock serves /rest/block/<hash> and
+// /rest/block/notxdetails/<hash>.  The JSON format is the same as the result of
+// getblock with verbose transactions, which are omitted for notxdetails.
+func handleRESTBlock(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	verboseTx := true
+	if len(args) == 2 && args[0] == "notxdetails" {
+		verboseTx = false
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return nil, restInvalidError("Usage: /rest/block/<hash> or " +
+			"/rest/block/notxdetails/<hash>")
+	}
+
+	verbose := format == restFormatJSON
+	result, err := handleGetBlock(s, &btcjson.GetBlockCmd{
+		Hash:      args[0],
+		Verbose:   &verbose,
+		VerboseTx: &verboseTx,
+	}, nil)
+	if err != nil || verbose {
+		return result, err
+	}
+	return restRawResult(result)
+}
+
+// handleRESTHeaders serves /rest/headers/<count>/<hash>, which returns up to
+// count headers of the main chain starting with the block with the passed hash.
+// The JSON format is a list of results of getblockheader.
+func handleRESTHeaders(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	if len(args) != 2 {
+		return nil, restInvalidError("Usage: /rest/headers/<count>/<hash>")
+	}
+	count, errr := strconv.Atoi(args[0])
+	if errr != nil || count < 1 || count > maxRESTHeaders {
+		return nil, restInvalidError("Header count out of range: " +
+			args[0])
+	}
+	hash, err := chainhash.NewHashFromStr(args[1])
+	if err != nil {
+		return nil, rpcDecodeHexError(args[1])
+	}
+
+	chain := s.cfg.Chain
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCBlockNotFound,
+			"Block not found", nil)
+	}
+	last := height + int32(count) - 1
+	if best := chain.BestSnapshot(); best.Height < last {
+		last = best.Height
+	}
+
+	var headers []interface{}
+	var buf bytes.Buffer
+	for ; height <= last; height++ {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			return nil, internalRPCError(err, "No next block")
+		}
+		if format == restFormatJSON {
+			verbose := true
+			header, err := handleGetBlockHeader(s,
+				&btcjson.GetBlockHeaderCmd{
+					Hash:    hash.String(),
+					Verbose: &verbose,
+				}, nil)
+			if err != nil {
+				return nil, err
+			}
+			headers = append(headers, header)
+			continue
+		}
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			return nil, internalRPCError(err,
+				"Failed to fetch block header")
+		}
+		if err := header.Serialize(&buf); err != nil {
+			return nil, internalRPCError(err,
+				"Failed to serialize block header")
+		}
+	}
+
+	if format == restFormatJSON {
+		return headers, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// handleRESTTx serves /rest/tx/<txid>.  Transactions are found in the memory
+// pool or, when it is enabled, the transaction index.  The JSON format is the
+// same as the result of getrawtransaction with the verbose flag set.
+func handleRESTTx(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	if len(args) != 1 {
+		return nil, restInvalidError("Usage: /rest/tx/<txid>")
+	}
+
+	verbose := format == restFormatJSON
+	result, err := handleGetRawTransaction(s, &btcjson.GetRawTransactionCmd{
+		Txid:    args[0],
+		Verbose: &verbose,
+	}, nil)
+	if err != nil || verbose {
+		return result, err
+	}
+	return restRawResult(result)
+}
+
+// handleRESTGetUTXOs serves /rest/getutxos/<txid>-<n>/... which returns which
+// of the passed outpoints are unspent in the main chain.  When the first
+// argument is checkmempool, spends by and outputs of transactions in the memory
+// pool are considered as well.
+//
+// The binary format is the chain height, the hash of the tip, the bitmap as
+// bytes with one bit for each outpoint and the list of unspent outputs, each
+// encoded as a zero version, its height and the output.
+func handleRESTGetUTXOs(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	checkMempool := len(args) > 0 && args[0] == "checkmempool"
+	if checkMempool {
+		args = args[1:]
+	}
+	if len(args) == 0 || len(args) > maxRESTOutpoints {
+		return nil, restInvalidError("Usage: /rest/getutxos/" +
+			"[checkmempool/]<txid>-<n>/... with at most " +
+			strconv.Itoa(maxRESTOutpoints) + " outpoints")
+	}
+
+	outpoints := make([]wire.OutPoint, 0, len(args))
+	for _, arg := range args {
+		dash := strings.LastIndex(arg, "-")
+		if dash == -1 {
+			return nil, restInvalidError("Invalid outpoint: " + arg)
+		}
+		hash, err := chainhash.NewHashFromStr(arg[:dash])
+		if err != nil {
+			return nil, rpcDecodeHexError(arg[:dash])
+		}
+		index, errr := strconv.ParseUint(arg[dash+1:], 10, 32)
+		if errr != nil {
+			return nil, restInvalidError("Invalid outpoint: " + arg)
+		}
+		outpoints = append(outpoints, wire.OutPoint{
+			Hash:  *hash,
+			Index: uint32(index),
+		})
+	}
+
+	mp := s.cfg.TxMemPool
+	best := s.cfg.Chain.BestSnapshot()
+	result := restUTXOsResult{
+		ChainHeight:  best.Height,
+		ChainTipHash: best.Hash.String(),
+		UTXOs:        []restUTXO{},
+	}
+	bitmap := make([]byte, (len(outpoints)+7)/8)
+	var bitmapStr strings.Builder
+	var outs []*wire.TxOut
+	var heights []int32
+	for i, op := range outpoints {
+		var txOut *wire.TxOut
+		var height int32
+		var tx *btcutil.Tx
+		if checkMempool {
+			tx, _ = mp.FetchTransaction(&op.Hash)
+		}
+		switch {
+		case checkMempool && mp.CheckSpend(op) != nil:
+			// The output is spent by a transaction in the memory
+			// pool.
+
+		case tx != nil:
+			if int(op.Index) < len(tx.MsgTx().TxOut) {
+				txOut = tx.MsgTx().TxOut[op.Index]
+				height = restMempoolHeight
+			}
+
+		default:
+			entry, err := s.cfg.Chain.FetchUtxoEntry(op)
+			if err != nil {
+				return nil, internalRPCError(err,
+					"Failed to fetch unspent output")
+			}
+			if entry != nil && !entry.IsSpent() {
+				txOut = wire.NewTxOut(entry.Amount(),
+					entry.PkScript())
+				height = entry.BlockHeight()
+			}
+		}
+
+		if txOut == nil {
+			bitmapStr.WriteByte('0')
+			continue
+		}
+		bitmapStr.WriteByte('1')
+		bitmap[i/8] |= 1 << uint(i%8)
+		outs = append(outs, txOut)
+		heights = append(heights, height)
+		result.UTXOs = append(result.UTXOs, restUTXO{
+			Height:     height,
+			ValueCoins: btcutil.Amount(txOut.Value).ToBTC(),
+			Svalue:     strconv.FormatInt(txOut.Value, 10),
+			Address: txscript.PkScriptToAddress(txOut.PkScript,
+				s.cfg.ChainParams).EncodeAddress(),
+			ScriptPubKey: hex.EncodeToString(txOut.PkScript),
+		})
+	}
+	result.Bitmap = bitmapStr.String()
+
+	if format == restFormatJSON {
+		return result, nil
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(best.Height))
+	buf.Write(best.Hash[:])
+	err := wire.WriteVarBytes(&buf, protocol.ProtocolVersion, bitmap)
+	if err != nil {
+		return nil, internalRPCError(err, "Failed to serialize result")
+	}
+	err = wire.WriteVarInt(&buf, protocol.ProtocolVersion, uint64(len(outs)))
+	if err != nil {
+		return nil, internalRPCError(err, "Failed to serialize result")
+	}
+	for i, txOut := range outs {
+		binary.Write(&buf, binary.LittleEndian, uint32(0))
+		binary.Write(&buf, binary.LittleEndian, uint32(heights[i]))
+		err := wire.WriteTxOut(&buf, protocol.ProtocolVersion, 0, txOut)
+		if err != nil {
+			return nil, internalRPCError(err,
+				"Failed to serialize result")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// handleRESTChainInfo serves /rest/chaininfo, which is only available in the
+// JSON format and is the same as the result of getblockchaininfo.
+func handleRESTChainInfo(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	if len(args) != 0 || format != restFormatJSON {
+		return nil, restInvalidError("Usage: /rest/chaininfo.json")
+	}
+	return handleGetBlockChainInfo(s, nil, nil)
+}
+
+// handleRESTMempool serves /rest/mempool/info, which is only available in the
+// JSON format and is the same as the result of getmempoolinfo.
+func handleRESTMempool(s *rpcServer, args []string, format restFormat) (interface{}, er.R) {
+	if len(args) != 1 || args[0] != "info" || format != restFormatJSON {
+		return nil, restInvalidError("Usage: /rest/mempool/info.json")
+	}
+	return handleGetMempoolInfo(s, nil, nil)
+}
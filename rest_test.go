@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/genesis"
+	"github.com/pkt-cash/pktd/database"
+	_ "github.com/pkt-cash/pktd/database/ffldb"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/txscript/opcode"
+	"github.com/pkt-cash/pktd/txscript/scriptbuilder"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
+)
+
+// newRESTTestServer returns an RPC server backed by a new simnet chain with a
+// transaction index and one block on top of the genesis block, which is
+// returned along with it.
+func newRESTTestServer(t *testing.T) (*rpcServer, *btcutil.Block, func()) {
+	dir, errr := ioutil.TempDir("", "resttest")
+	if errr != nil {
+		t.Fatalf("TempDir: %v", errr)
+	}
+	params := chaincfg.SimNetParams
+	db, err := database.Create("ffldb", filepath.Join(dir, "db"), params.Net)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Create: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	txIndex := indexers.NewTxIndex(db)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:           db,
+		ChainParams:  &params,
+		TimeSource:   blockchain.NewMedianTime(),
+		IndexManager: indexers.NewManager(db, []indexers.Indexer{txIndex}),
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("New: %v", err)
+	}
+
+	// Mine a block paying to an anyone-can-spend script.
+	coinbaseScript, err := scriptbuilder.NewScriptBuilder().AddInt64(1).
+		AddInt64(0).Script()
+	if err != nil {
+		teardown()
+		t.Fatalf("NewScriptBuilder: %v", err)
+	}
+	coinbase := wire.NewMsgTx(constants.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		math.MaxUint32), coinbaseScript, nil))
+	coinbase.AddTxOut(wire.NewTxOut(blockchain.CalcBlockSubsidy(1, &params),
+		[]byte{opcode.OP_TRUE}))
+	msgBlock := wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   4,
+			PrevBlock: *params.GenesisHash,
+			Timestamp: genesis.Block(params.GenesisHash).Header.Timestamp.Add(time.Minute),
+			Bits:      params.PowLimitBits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+	merkles := blockchain.BuildMerkleTreeStore(
+		[]*btcutil.Tx{btcutil.NewTx(coinbase)}, false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	target := blockchain.CompactToBig(params.PowLimitBits)
+	for {
+		hash := msgBlock.Header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		msgBlock.Header.Nonce++
+	}
+	block := btcutil.NewBlock(&msgBlock)
+	_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNone)
+	if err != nil || isOrphan {
+		teardown()
+		t.Fatalf("ProcessBlock: orphan %v, %v", isOrphan, err)
+	}
+
+	s := &rpcServer{
+		cfg: rpcserverConfig{
+			Chain:        chain,
+			ChainParams:  &params,
+			DB:           db,
+			TxMemPool:    mempool.New(&mempool.Config{ChainParams: &params}),
+			TxIndexOrNil: txIndex,
+		},
+	}
+	return s, block, teardown
+}
+
+// restGet issues a GET request for the passed path to the REST handler of the
+// passed server and returns the response.
+func restGet(s *rpcServer, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.handleREST(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// TestREST ensures the REST endpoints serve data from the chain in each of the
+// supported formats and reject malformed requests.
+func TestREST(t *testing.T) {
+	s, block, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	var blockBuf bytes.Buffer
+	if err := block.MsgBlock().Serialize(&blockBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	var headerBuf bytes.Buffer
+	genesisBlock := genesis.Block(chaincfg.SimNetParams.GenesisHash)
+	if err := genesisBlock.Header.Serialize(&headerBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if err := block.MsgBlock().Header.Serialize(&headerBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	coinbase := block.Transactions()[0]
+	var txBuf bytes.Buffer
+	if err := coinbase.MsgTx().Serialize(&txBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	blockHash := block.Hash().String()
+	genesisHash := chaincfg.SimNetParams.GenesisHash.String()
+	txid := coinbase.Hash().String()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   []byte
+	}{
+		{
+			name:   "block bin",
+			path:   "/rest/block/" + blockHash + ".bin",
+			status: http.StatusOK,
+			body:   blockBuf.Bytes(),
+		},
+		{
+			name:   "block hex",
+			path:   "/rest/block/notxdetails/" + blockHash + ".hex",
+			status: http.StatusOK,
+			body:   []byte(hex.EncodeToString(blockBuf.Bytes()) + "\n"),
+		},
+		{
+			name:   "headers bin",
+			path:   "/rest/headers/5/" + genesisHash + ".bin",
+			status: http.StatusOK,
+			body:   headerBuf.Bytes(),
+		},
+		{
+			name:   "tx bin",
+			path:   "/rest/tx/" + txid + ".bin",
+			status: http.StatusOK,
+			body:   txBuf.Bytes(),
+		},
+		{
+			name:   "unknown block",
+			path:   "/rest/block/" + txid + ".json",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unknown tx",
+			path:   "/rest/tx/" + blockHash + ".hex",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "bad hash",
+			path:   "/rest/block/xyz.json",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "bad format",
+			path:   "/rest/block/" + blockHash + ".xml",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "bad header count",
+			path:   "/rest/headers/0/" + genesisHash + ".bin",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "bad outpoint",
+			path:   "/rest/getutxos/" + txid + ".json",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown endpoint",
+			path:   "/rest/blocks/" + blockHash + ".json",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		w := restGet(s, test.path)
+		if w.Code != test.status {
+			t.Errorf("%s: unexpected status - got %d, want %d (%s)",
+				test.name, w.Code, test.status, w.Body.String())
+			continue
+		}
+		if test.body != nil && !bytes.Equal(w.Body.Bytes(), test.body) {
+			t.Errorf("%s: unexpected body - got %x, want %x",
+				test.name, w.Body.Bytes(), test.body)
+		}
+	}
+
+	// The JSON formats are the same as the results of the RPCs.
+	w := restGet(s, "/rest/block/"+blockHash+".json")
+	var blockResult btcjson.GetBlockVerboseResult
+	if err := json.Unmarshal(w.Body.Bytes(), &blockResult); err != nil {
+		t.Fatalf("block json: %v (%s)", err, w.Body.String())
+	}
+	if blockResult.Hash != blockHash || blockResult.Height != 1 ||
+		len(blockResult.RawTx) != 1 || blockResult.RawTx[0].Txid != txid {
+
+		t.Errorf("block json: unexpected result %s", w.Body.String())
+	}
+
+	w = restGet(s, "/rest/headers/5/"+genesisHash+".json")
+	var headersResult []btcjson.GetBlockHeaderVerboseResult
+	if err := json.Unmarshal(w.Body.Bytes(), &headersResult); err != nil {
+		t.Fatalf("headers json: %v (%s)", err, w.Body.String())
+	}
+	if len(headersResult) != 2 || headersResult[0].Hash != genesisHash ||
+		headersResult[1].Hash != blockHash {
+
+		t.Errorf("headers json: unexpected result %s", w.Body.String())
+	}
+
+	w = restGet(s, "/rest/tx/"+txid+".json")
+	var txResult btcjson.TxRawResult
+	if err := json.Unmarshal(w.Body.Bytes(), &txResult); err != nil {
+		t.Fatalf("tx json: %v (%s)", err, w.Body.String())
+	}
+	if txResult.Txid != txid || txResult.BlockHash != blockHash {
+		t.Errorf("tx json: unexpected result %s", w.Body.String())
+	}
+
+	// The coinbase output of the block is unspent but the second output
+	// does not exist.
+	w = restGet(s, "/rest/getutxos/checkmempool/"+txid+"-0/"+txid+"-1.json")
+	var utxosResult restUTXOsResult
+	if err := json.Unmarshal(w.Body.Bytes(), &utxosResult); err != nil {
+		t.Fatalf("getutxos json: %v (%s)", err, w.Body.String())
+	}
+	if utxosResult.ChainHeight != 1 || utxosResult.ChainTipHash != blockHash ||
+		utxosResult.Bitmap != "10" || len(utxosResult.UTXOs) != 1 ||
+		utxosResult.UTXOs[0].Height != 1 ||
+		utxosResult.UTXOs[0].ScriptPubKey != "51" {
+
+		t.Errorf("getutxos json: unexpected result %s", w.Body.String())
+	}
+
+	w = restGet(s, "/rest/getutxos/"+txid+"-0.hex")
+	want := "01000000" + hex.EncodeToString(block.Hash()[:]) + "0101" +
+		"01" + "00000000" + "01000000"
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, uint64(coinbase.MsgTx().TxOut[0].Value))
+	want += hex.EncodeToString(value) + "0151" + "\n"
+	if w.Body.String() != want {
+		t.Errorf("getutxos hex: unexpected result - got %s, want %s",
+			w.Body.String(), want)
+	}
+}
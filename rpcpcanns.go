@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkt-cash/pktd/annpool"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// maxPcAnnsBatch is the maximum number of announcements which can be
+	// validated with a single checkpcanns command or stream message.
+	maxPcAnnsBatch = 4096
+
+	// pcAnnsResultSize is the size of the result of an announcement in the
+	// announcement stream, a 4 byte code followed by the work hash.
+	pcAnnsResultSize = 4 + chainhash.HashSize
+)
+
+// pcAnnResult is the result of the validation of an announcement.  The work
+// hash is set when the announcement is valid, and also when its only fault is
+// that the work hash does not meet its work target.
+type pcAnnResult struct {
+	workHash *chainhash.Hash
+	err      er.R
+}
+
+// code returns the number of the announce error code of the result, 0 if the
+// announcement is valid or -1 if the error is not an announcement error.
+func (r *pcAnnResult) code() int {
+	if r.err == nil {
+		return 0
+	}
+	if code := announce.Err.Decode(r.err); code != nil {
+		return code.Number
+	}
+	return -1
+}
+
+// pcAnnJob is an announcement queued for validation by the workers of a
+// pcAnnValidator.
+type pcAnnJob struct {
+	ann        *wire.PacketCryptAnn
+	parentHash *chainhash.Hash
+	version    int
+	result     *pcAnnResult
+	wg         *sync.WaitGroup
+}
+
+// pcAnnValidator validates PacketCrypt announcements with a pool of workers
+// which is shared by all the RPC clients, so that the validation of large
+// batches uses all the CPUs without starving the rest of the server.
+type pcAnnValidator struct {
+	chain     *blockchain.BlockChain
+	quit      <-chan int
+	startOnce sync.Once
+	jobs      chan pcAnnJob
+}
+
+// newPcAnnValidator returns an announcement validator for the passed chain,
+// its workers are started on first use and stop when quit is closed.
+func newPcAnnValidator(chain *blockchain.BlockChain, quit <-chan int) *pcAnnValidator {
+	return &pcAnnValidator{
+		chain: chain,
+		quit:  quit,
+	}
+}
+
+// worker validates the queued announcements until the validator is stopped.
+func (v *pcAnnValidator) worker() {
+	for {
+		select {
+		case job := <-v.jobs:
+			job.result.workHash, job.result.err = packetcrypt.ValidatePcAnn(
+				job.ann, job.parentHash, job.version)
+			job.wg.Done()
+		case <-v.quit:
+			return
+		}
+	}
+}
+
+// start starts the workers, one per CPU.
+func (v *pcAnnValidator) start() {
+	threads := runtime.NumCPU()
+	v.jobs = make(chan pcAnnJob, threads)
+	for i := 0; i < threads; i++ {
+		go v.worker()
+	}
+}
+
+// defaultVersion returns the PacketCrypt version of the next block, which is
+// the version the announcements are validated with by default.
+func (v *pcAnnValidator) defaultVersion() int {
+	return annpool.PacketCryptVersion(v.chain.BestSnapshot().Height + 1)
+}
+
+// validate validates the passed announcements with the passed PacketCrypt
+// version and returns their results in the same order.  The nil
+// announcements are reported as malformed.  The parent block hashes are looked
+// up once per height.  It returns nil if quit, which may be nil, or the quit
+// channel of the validator is closed before all the announcements are queued.
+func (v *pcAnnValidator) validate(anns []*wire.PacketCryptAnn, version int,
+	quit <-chan struct{}) []pcAnnResult {
+
+	v.startOnce.Do(v.start)
+
+	results := make([]pcAnnResult, len(anns))
+	parents := make(map[uint32]*chainhash.Hash)
+	var wg sync.WaitGroup
+	for i, ann := range anns {
+		if ann == nil {
+			results[i].err = announce.ErrMalformed.Default()
+			continue
+		}
+		height := ann.GetParentBlockHeight()
+		parentHash, ok := parents[height]
+		if !ok {
+			// An unknown parent is cached as nil.
+			parentHash, _ = v.chain.BlockHashByHeight(int32(height))
+			parents[height] = parentHash
+		}
+		if parentHash == nil {
+			results[i].err = announce.ErrUnknownParent.New(
+				fmt.Sprintf("height [%d]", height), nil)
+			continue
+		}
+
+		wg.Add(1)
+		select {
+		case v.jobs <- pcAnnJob{
+			ann:        ann,
+			parentHash: parentHash,
+			version:    version,
+			result:     &results[i],
+			wg:         &wg,
+		}:
+		case <-quit:
+			wg.Done()
+			wg.Wait()
+			return nil
+		case <-v.quit:
+			return nil
+		}
+	}
+	wg.Wait()
+	return results
+}
+
+// decodePcAnn decodes an announcement, it returns nil if the announcement is
+// malformed.
+func decodePcAnn(annBytes []byte) *wire.PacketCryptAnn {
+	if len(annBytes) != wire.PcAnnSerializeSize {
+		return nil
+	}
+	ann := &wire.PacketCryptAnn{}
+	if err := ann.BtcDecode(bytes.NewReader(annBytes), 0, 0); err != nil {
+		return nil
+	}
+	return ann
+}
+
+// handleCheckPcAnns implements the checkpcanns command.
+func handleCheckPcAnns(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.CheckPcAnnsCmd)
+	if len(c.AnnsHex) > maxPcAnnsBatch {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			fmt.Sprintf("Too many announcements, the maximum is %d",
+				maxPcAnnsBatch), nil)
+	}
+	version := s.pcAnns.defaultVersion()
+	if c.PcVersion != nil {
+		version = *c.PcVersion
+	}
+
+	anns := make([]*wire.PacketCryptAnn, len(c.AnnsHex))
+	for i, annHex := range c.AnnsHex {
+		if annBytes, errr := hex.DecodeString(annHex); errr == nil {
+			anns[i] = decodePcAnn(annBytes)
+		}
+	}
+	results := s.pcAnns.validate(anns, version, closeChan)
+	if results == nil {
+		return nil, ErrClientQuit.Default()
+	}
+
+	out := make([]btcjson.CheckPcAnnsResult, len(results))
+	for i := range results {
+		r := &results[i]
+		out[i].Code = r.code()
+		if r.workHash != nil {
+			out[i].WorkHash = r.workHash.String()
+		}
+		if r.err != nil {
+			out[i].Error = r.err.Message()
+		}
+	}
+	return out, nil
+}
+
+// handleGetAnnPool implements the getannpool command.
+func handleGetAnnPool(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	if s.cfg.AnnPool == nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCMisc,
+			"Announcement relay must be enabled (--relayanns)", nil)
+	}
+	c := cmd.(*btcjson.GetAnnPoolCmd)
+
+	var anns []*wire.PacketCryptAnn
+	if c.ParentHeight != nil {
+		if *c.ParentHeight < 0 || *c.ParentHeight > math.MaxUint32 {
+			return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+				"Parent height out of range", nil)
+		}
+		anns = s.cfg.AnnPool.AnnsAtHeight(uint32(*c.ParentHeight))
+	} else {
+		anns = s.cfg.AnnPool.Anns()
+	}
+
+	if c.Verbose == nil || !*c.Verbose {
+		hashes := make([]string, len(anns))
+		for i, ann := range anns {
+			hash := annpool.AnnHash(ann)
+			hashes[i] = hash.String()
+		}
+		return hashes, nil
+	}
+
+	result := make([]btcjson.GetAnnPoolVerboseResult, len(anns))
+	for i, ann := range anns {
+		hash := annpool.AnnHash(ann)
+		result[i] = btcjson.GetAnnPoolVerboseResult{
+			Hash:         hash.String(),
+			ParentHeight: ann.GetParentBlockHeight(),
+			WorkTarget:   fmt.Sprintf("%08x", ann.GetWorkTarget()),
+			Version:      ann.GetVersion(),
+			Hex:          hex.EncodeToString(ann.Header[:]),
+		}
+	}
+	return result, nil
+}
+
+// handlePcAnnsStream validates announcements which are streamed over a
+// websocket.  Each binary message from the client is a batch of announcements
+// back to back, the server answers each with a binary message which has the
+// result of each announcement in order: the code as a 4 byte little endian
+// integer followed by the work hash, which is zero if there is none.  The
+// PacketCrypt version may be passed with the pcversion query parameter.
+func (s *rpcServer) handlePcAnnsStream(w http.ResponseWriter, r *http.Request) {
+	version := s.pcAnns.defaultVersion()
+	if v := r.URL.Query().Get("pcversion"); v != "" {
+		var errr error
+		if version, errr = strconv.Atoi(v); errr != nil {
+			http.Error(w, "400 Bad Request.", http.StatusBadRequest)
+			return
+		}
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  wire.PcAnnSerializeSize,
+		WriteBufferSize: 1024,
+	}
+	ws, errr := upgrader.Upgrade(w, r, nil)
+	if errr != nil {
+		if _, ok := errr.(websocket.HandshakeError); !ok {
+			log.Errorf("Unexpected websocket error: %v", errr)
+		}
+		http.Error(w, "400 Bad Request.", http.StatusBadRequest)
+		return
+	}
+	defer ws.Close()
+	ws.SetReadLimit(maxPcAnnsBatch * wire.PcAnnSerializeSize)
+
+	for {
+		msgType, msg, errr := ws.ReadMessage()
+		if errr != nil {
+			if !websocket.IsCloseError(errr, websocket.CloseNormalClosure) {
+				log.Debugf("Announcement stream from %s: %v", r.RemoteAddr,
+					errr)
+			}
+			return
+		}
+		if msgType != websocket.BinaryMessage ||
+			len(msg)%wire.PcAnnSerializeSize != 0 {
+			ws.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseUnsupportedData,
+					"expected announcements"))
+			return
+		}
+
+		anns := make([]*wire.PacketCryptAnn, len(msg)/wire.PcAnnSerializeSize)
+		for i := range anns {
+			anns[i] = decodePcAnn(msg[i*wire.PcAnnSerializeSize:][:wire.PcAnnSerializeSize])
+		}
+		results := s.pcAnns.validate(anns, version, nil)
+		if results == nil {
+			return
+		}
+		out := make([]byte, len(results)*pcAnnsResultSize)
+		for i := range results {
+			res := out[i*pcAnnsResultSize:]
+			binary.LittleEndian.PutUint32(res, uint32(results[i].code()))
+			if results[i].workHash != nil {
+				copy(res[4:pcAnnsResultSize], results[i].workHash[:])
+			}
+		}
+		if errr := ws.WriteMessage(websocket.BinaryMessage, out); errr != nil {
+			return
+		}
+	}
+}
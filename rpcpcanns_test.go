@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkt-cash/pktd/annpool"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/annminer"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// mineTestPcAnns mines announcements on the passed parent block height of the
+// simnet chain.
+func mineTestPcAnns(t *testing.T, parentHeight uint32, count int) []*wire.PacketCryptAnn {
+	m, err := annminer.New(&annminer.Request{
+		ParentBlockHash:   *chaincfg.SimNetParams.GenesisHash,
+		ParentBlockHeight: parentHeight,
+		WorkTarget:        0x207fffff,
+	}, 0)
+	if err != nil {
+		t.Fatalf("annminer.New: %v", err)
+	}
+	anns, err := m.Mine(count, nil)
+	if err != nil {
+		t.Fatalf("Mine: %v", err)
+	}
+	return anns
+}
+
+// TestCheckPcAnns ensures the checkpcanns command and the announcement stream
+// report the result of each announcement with its error code.
+func TestCheckPcAnns(t *testing.T) {
+	s, _, teardown := newRESTTestServer(t)
+	defer teardown()
+	quit := make(chan int)
+	defer close(quit)
+	s.pcAnns = newPcAnnValidator(s.cfg.Chain, quit)
+
+	anns := mineTestPcAnns(t, 0, 2)
+	tampered := *anns[0]
+	tampered.Header[100] ^= 1
+	unknown := mineTestPcAnns(t, 5, 1)[0]
+
+	annsHex := []string{
+		hex.EncodeToString(anns[0].Header[:]),
+		hex.EncodeToString(anns[1].Header[:]),
+		hex.EncodeToString(tampered.Header[:]),
+		"zz",
+		hex.EncodeToString(anns[0].Header[:100]),
+		hex.EncodeToString(unknown.Header[:]),
+	}
+	res, err := handleCheckPcAnns(s, btcjson.NewCheckPcAnnsCmd(annsHex,
+		btcjson.Int(1)), nil)
+	if err != nil {
+		t.Fatalf("handleCheckPcAnns: %v", err)
+	}
+	results := res.([]btcjson.CheckPcAnnsResult)
+	if len(results) != len(annsHex) {
+		t.Fatalf("got %d results, want %d", len(results), len(annsHex))
+	}
+	for i := 0; i < 2; i++ {
+		if results[i].Code != 0 || results[i].WorkHash == "" ||
+			results[i].Error != "" {
+			t.Fatalf("valid announcement %d: %+v", i, results[i])
+		}
+	}
+	if results[2].Code <= 0 || results[2].Error == "" {
+		t.Fatalf("tampered announcement: %+v", results[2])
+	}
+	wantCodes := []int{
+		3: announce.ErrMalformed.Number,
+		4: announce.ErrMalformed.Number,
+		5: announce.ErrUnknownParent.Number,
+	}
+	for i := 3; i < len(results); i++ {
+		if results[i].Code != wantCodes[i] {
+			t.Fatalf("announcement %d: code %d, want %d", i,
+				results[i].Code, wantCodes[i])
+		}
+	}
+
+	tooMany := make([]string, maxPcAnnsBatch+1)
+	if _, err := handleCheckPcAnns(s, btcjson.NewCheckPcAnnsCmd(tooMany, nil),
+		nil); err == nil {
+		t.Fatalf("handleCheckPcAnns succeeded with too many announcements")
+	}
+
+	// Stream the valid and the tampered announcement.
+	server := httptest.NewServer(http.HandlerFunc(s.handlePcAnnsStream))
+	defer server.Close()
+	ws, _, errr := websocket.DefaultDialer.Dial(
+		"ws"+strings.TrimPrefix(server.URL, "http")+"?pcversion=1", nil)
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	defer ws.Close()
+	msg := append(append([]byte{}, anns[0].Header[:]...), tampered.Header[:]...)
+	if errr := ws.WriteMessage(websocket.BinaryMessage, msg); errr != nil {
+		t.Fatalf("WriteMessage: %v", errr)
+	}
+	msgType, out, errr := ws.ReadMessage()
+	if errr != nil || msgType != websocket.BinaryMessage ||
+		len(out) != 2*pcAnnsResultSize {
+		t.Fatalf("ReadMessage: %v %d %d", errr, msgType, len(out))
+	}
+	workHash, err := chainhash.NewHashFromStr(results[0].WorkHash)
+	if err != nil {
+		t.Fatalf("NewHashFromStr: %v", err)
+	}
+	if binary.LittleEndian.Uint32(out) != 0 ||
+		!bytes.Equal(out[4:pcAnnsResultSize], workHash[:]) {
+		t.Fatalf("valid announcement: unexpected stream result %x",
+			out[:pcAnnsResultSize])
+	}
+	if int(binary.LittleEndian.Uint32(out[pcAnnsResultSize:])) != results[2].Code {
+		t.Fatalf("tampered announcement: unexpected stream result %x",
+			out[pcAnnsResultSize:])
+	}
+
+	// A message which is not a batch of announcements ends the stream.
+	if errr := ws.WriteMessage(websocket.TextMessage, []byte("hello")); errr != nil {
+		t.Fatalf("WriteMessage: %v", errr)
+	}
+	if _, _, errr := ws.ReadMessage(); !websocket.IsCloseError(errr,
+		websocket.CloseUnsupportedData) {
+		t.Fatalf("ReadMessage: %v", errr)
+	}
+}
+
+// TestGetAnnPool ensures the getannpool command returns the announcements in
+// the pool, optionally filtered by parent block height.
+func TestGetAnnPool(t *testing.T) {
+	s, _, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	cmd := &btcjson.GetAnnPoolCmd{Verbose: btcjson.Bool(false)}
+	if _, err := handleGetAnnPool(s, cmd, nil); err == nil {
+		t.Fatalf("getannpool succeeded without announcement relay")
+	}
+
+	chain := s.cfg.Chain
+	s.cfg.AnnPool = annpool.New(&annpool.Config{
+		MaxAnns: annpool.DefaultMaxAnns,
+		BestHeight: func() int32 {
+			return chain.BestSnapshot().Height
+		},
+		BlockHashByHeight: chain.BlockHashByHeight,
+	})
+	anns := mineTestPcAnns(t, 0, 2)
+	if _, errs := s.cfg.AnnPool.ProcessAnns(anns); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("ProcessAnns: %v", errs)
+	}
+
+	result, err := handleGetAnnPool(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("getannpool: %v", err)
+	}
+	hashes := result.([]string)
+	if len(hashes) != 2 {
+		t.Fatalf("getannpool: %d announcements, want 2", len(hashes))
+	}
+
+	cmd = &btcjson.GetAnnPoolCmd{
+		Verbose:      btcjson.Bool(true),
+		ParentHeight: btcjson.Int64(0),
+	}
+	result, err = handleGetAnnPool(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("getannpool: %v", err)
+	}
+	verbose := result.([]btcjson.GetAnnPoolVerboseResult)
+	if len(verbose) != 2 {
+		t.Fatalf("getannpool: %d announcements, want 2", len(verbose))
+	}
+	for _, r := range verbose {
+		if r.ParentHeight != 0 || r.WorkTarget != "207fffff" ||
+			len(r.Hex) != 2*wire.PcAnnSerializeSize ||
+			(r.Hash != hashes[0] && r.Hash != hashes[1]) {
+
+			t.Fatalf("getannpool: unexpected result %v", r)
+		}
+	}
+
+	cmd.ParentHeight = btcjson.Int64(1)
+	result, err = handleGetAnnPool(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("getannpool: %v", err)
+	}
+	if len(result.([]btcjson.GetAnnPoolVerboseResult)) != 0 {
+		t.Fatalf("getannpool: unexpected announcements at height 1")
+	}
+}
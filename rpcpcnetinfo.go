@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// pcMinTargetBits is the easiest PacketCrypt target, the difficulties are
+// relative to it, which makes them the estimated number of encryptions needed.
+const pcMinTargetBits = 0x207fffff
+
+// maxPcNetworkInfoRetargets is the maximum number of difficulty retarget
+// periods which getpcnetworkinfo computes the statistics over, since every
+// block of the range is loaded from the database.
+const maxPcNetworkInfoRetargets = 4
+
+// pcNetworkStats accumulates the PacketCrypt statistics of a range of blocks.
+type pcNetworkStats struct {
+	blocks   int
+	annCount uint64
+
+	// The work of the targets, they are averaged as work since the targets
+	// themselves do not add up.
+	annWork       *big.Int
+	headerWork    *big.Int
+	effectiveWork *big.Int
+
+	annDifficulty       float64
+	annEncryptions      float64
+	headerDifficulty    float64
+	effectiveDifficulty float64
+
+	anns   int
+	ageSum uint64
+	ages   map[uint32]int
+}
+
+func newPcNetworkStats() *pcNetworkStats {
+	return &pcNetworkStats{
+		annWork:       new(big.Int),
+		headerWork:    new(big.Int),
+		effectiveWork: new(big.Int),
+		ages:          make(map[uint32]int),
+	}
+}
+
+// addBlock adds the statistics of a block at the passed height, blocks without
+// a PacketCrypt proof are skipped.
+func (st *pcNetworkStats) addBlock(mb *wire.MsgBlock, height int32) {
+	if mb.Pcp == nil || len(mb.Transactions) == 0 {
+		return
+	}
+	commit := packetcrypt.ExtractCoinbaseCommit(mb.Transactions[0])
+	if commit == nil {
+		return
+	}
+	annTarget := commit.AnnMinDifficulty()
+	annCount := commit.AnnCount()
+	effectiveTarget := difficulty.GetEffectiveTarget(mb.Header.Bits, annTarget,
+		annCount, mb.Pcp.Version)
+
+	st.blocks++
+	st.annCount += annCount
+	st.annWork.Add(st.annWork, compactWork(annTarget))
+	st.headerWork.Add(st.headerWork, compactWork(mb.Header.Bits))
+	st.effectiveWork.Add(st.effectiveWork, compactWork(effectiveTarget))
+
+	annDifficulty := getDifficultyRatio0(annTarget, pcMinTargetBits)
+	st.annDifficulty += annDifficulty
+	st.annEncryptions += annDifficulty * float64(annCount)
+	st.headerDifficulty += getDifficultyRatio0(mb.Header.Bits, pcMinTargetBits)
+	st.effectiveDifficulty += getDifficultyRatio0(effectiveTarget, pcMinTargetBits)
+
+	for i := range mb.Pcp.Announcements {
+		parentHeight := mb.Pcp.Announcements[i].GetParentBlockHeight()
+		if parentHeight > uint32(height) {
+			continue
+		}
+		age := uint32(height) - parentHeight
+		st.anns++
+		st.ageSum += uint64(age)
+		st.ages[age]++
+	}
+}
+
+// compactWork returns the work which is needed to meet the compact target,
+// unlike targetWork it is exact.
+func compactWork(bits uint32) *big.Int {
+	return difficulty.WorkForTarget(difficulty.CompactToBig(bits))
+}
+
+// avgTarget returns the target, in compact form, of the average of the work.
+func (st *pcNetworkStats) avgTarget(work *big.Int) string {
+	avg := new(big.Int).Div(work, big.NewInt(int64(st.blocks)))
+	return fmt.Sprintf("%08x", difficulty.BigToCompact(difficulty.TargetForWork(avg)))
+}
+
+// result returns the statistics, the rates are over timeDiff seconds.
+func (st *pcNetworkStats) result(timeDiff int64) *btcjson.GetPcNetworkInfoResult {
+	r := &btcjson.GetPcNetworkInfoResult{
+		Blocks:  st.blocks,
+		AnnAges: []btcjson.PcAnnAgeResult{},
+	}
+	if st.blocks == 0 {
+		return r
+	}
+	blocks := float64(st.blocks)
+	r.AvgAnnCount = float64(st.annCount) / blocks
+	r.AvgAnnMinTarget = st.avgTarget(st.annWork)
+	r.AvgAnnDifficulty = st.annDifficulty / blocks
+	r.AvgHeaderTarget = st.avgTarget(st.headerWork)
+	r.AvgHeaderDifficulty = st.headerDifficulty / blocks
+	r.AvgEffectiveTarget = st.avgTarget(st.effectiveWork)
+	r.AvgEffectiveDifficulty = st.effectiveDifficulty / blocks
+	if timeDiff > 0 {
+		r.AnnEncryptionsPerSec = st.annEncryptions / float64(timeDiff)
+		r.BlkEncryptionsPerSec = st.effectiveDifficulty / float64(timeDiff)
+	}
+
+	if st.anns > 0 {
+		r.AvgAnnAge = float64(st.ageSum) / float64(st.anns)
+	}
+	for age, count := range st.ages {
+		r.AnnAges = append(r.AnnAges, btcjson.PcAnnAgeResult{Age: age, Count: count})
+	}
+	sort.Slice(r.AnnAges, func(i, j int) bool {
+		return r.AnnAges[i].Age < r.AnnAges[j].Age
+	})
+	if len(r.AnnAges) > 0 {
+		r.MinAnnAge = r.AnnAges[0].Age
+		r.MaxAnnAge = r.AnnAges[len(r.AnnAges)-1].Age
+	}
+	return r
+}
+
+// handleGetPcNetworkInfo implements the getpcnetworkinfo command.
+func handleGetPcNetworkInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.GetPcNetworkInfoCmd)
+
+	// The range of blocks is chosen the same way as for getnetworkhashps.
+	best := s.cfg.Chain.BestSnapshot()
+	endHeight := int32(-1)
+	if c.Height != nil {
+		endHeight = int32(*c.Height)
+	}
+	if endHeight > best.Height || endHeight == 0 {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"Block height out of range", nil)
+	}
+	if endHeight < 0 {
+		endHeight = best.Height
+	}
+	blocksPerRetarget := int32(s.cfg.ChainParams.TargetTimespan /
+		s.cfg.ChainParams.TargetTimePerBlock)
+	numBlocks := int32(120)
+	if c.Blocks != nil {
+		if *c.Blocks <= 0 {
+			return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+				"Number of blocks must be positive", nil)
+		}
+		numBlocks = int32(*c.Blocks)
+		if *c.Blocks > int(maxPcNetworkInfoRetargets*blocksPerRetarget) {
+			numBlocks = maxPcNetworkInfoRetargets * blocksPerRetarget
+		}
+	}
+	startHeight := endHeight - numBlocks
+	if startHeight < 0 {
+		startHeight = 0
+	}
+	log.Debugf("Calculating PacketCrypt network info from %d to %d",
+		startHeight, endHeight)
+
+	// The first block only gives the time when the range starts.
+	var minTimestamp, maxTimestamp time.Time
+	stats := newPcNetworkStats()
+	for curHeight := startHeight; curHeight <= endHeight; curHeight++ {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit.Default()
+		default:
+		}
+		block, err := s.cfg.Chain.BlockByHeight(curHeight)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err, context)
+		}
+		header := &block.MsgBlock().Header
+
+		if curHeight == startHeight {
+			minTimestamp = header.Timestamp
+			maxTimestamp = minTimestamp
+			continue
+		}
+		stats.addBlock(block.MsgBlock(), curHeight)
+		if minTimestamp.After(header.Timestamp) {
+			minTimestamp = header.Timestamp
+		}
+		if maxTimestamp.Before(header.Timestamp) {
+			maxTimestamp = header.Timestamp
+		}
+	}
+
+	result := stats.result(int64(maxTimestamp.Sub(minTimestamp) / time.Second))
+	result.StartHeight = startHeight
+	result.EndHeight = endHeight
+	return result, nil
+}
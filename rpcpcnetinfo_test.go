@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestGetPcNetworkInfo ensures the PacketCrypt statistics of blocks are
+// computed, and that blocks without a PacketCrypt proof are skipped.
+func TestGetPcNetworkInfo(t *testing.T) {
+	s, _, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	// The blocks of the simnet chain have no PacketCrypt proof.
+	cmd := btcjson.NewGetPcNetworkInfoCmd(nil, nil)
+	result, err := handleGetPcNetworkInfo(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("getpcnetworkinfo: %v", err)
+	}
+	r := result.(*btcjson.GetPcNetworkInfoResult)
+	if r.StartHeight != 0 || r.EndHeight != 1 || r.Blocks != 0 {
+		t.Fatalf("getpcnetworkinfo: unexpected result %+v", r)
+	}
+	cmd = btcjson.NewGetPcNetworkInfoCmd(nil, btcjson.Int(2))
+	if _, err := handleGetPcNetworkInfo(s, cmd, nil); err == nil {
+		t.Fatalf("getpcnetworkinfo: succeeded past the best block")
+	}
+	cmd = btcjson.NewGetPcNetworkInfoCmd(btcjson.Int(-1), nil)
+	if _, err := handleGetPcNetworkInfo(s, cmd, nil); err == nil {
+		t.Fatalf("getpcnetworkinfo: succeeded with a negative number " +
+			"of blocks")
+	}
+	cmd = btcjson.NewGetPcNetworkInfoCmd(btcjson.Int(1<<30), nil)
+	if _, err := handleGetPcNetworkInfo(s, cmd, nil); err != nil {
+		t.Fatalf("getpcnetworkinfo: %v", err)
+	}
+
+	stats := newPcNetworkStats()
+	mb := mineTestPcBlock(t, &chainhash.Hash{}, 2)
+	stats.addBlock(mb, 2)
+	stats.addBlock(mb, 3)
+	r = stats.result(60)
+	if r.Blocks != 2 || r.AvgAnnCount != 8 || r.AvgAnnMinTarget != "207fffff" ||
+		r.AvgAnnDifficulty != 1 || r.AnnEncryptionsPerSec != 16.0/60 {
+
+		t.Fatalf("unexpected announcement statistics %+v", r)
+	}
+	if r.AvgEffectiveDifficulty > r.AvgHeaderDifficulty ||
+		r.BlkEncryptionsPerSec != 2*r.AvgEffectiveDifficulty/60 {
+
+		t.Fatalf("unexpected block statistics %+v", r)
+	}
+	if r.MinAnnAge != 2 || r.MaxAnnAge != 3 || r.AvgAnnAge != 2.5 ||
+		len(r.AnnAges) != 2 || r.AnnAges[0].Count != 4 ||
+		r.AnnAges[1].Count != 4 {
+
+		t.Fatalf("unexpected announcement ages %+v", r.AnnAges)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/annpool"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// fetchPcProofBlock returns the block which is passed to decodepcproof, either
+// by hash or as a hex-encoded serialized block.
+func fetchPcProofBlock(s *rpcServer, blk string) (*btcutil.Block, er.R) {
+	if len(blk) != chainhash.MaxHashStringSize {
+		blkBytes, errr := hex.DecodeString(blk)
+		if errr != nil {
+			return nil, rpcDecodeHexError(blk)
+		}
+		// The proof is decoded even when the chain does not use PacketCrypt.
+		var msgBlock wire.MsgBlock
+		err := msgBlock.BtcDecode(bytes.NewReader(blkBytes), 0,
+			wire.WitnessEncoding|wire.PacketCryptEncoding)
+		if err != nil {
+			return nil, btcjson.NewRPCError(btcjson.ErrRPCDeserialization,
+				"Block decode failed", err)
+		}
+		return btcutil.NewBlock(&msgBlock), nil
+	}
+
+	hash, err := chainhash.NewHashFromStr(blk)
+	if err != nil {
+		return nil, rpcDecodeHexError(blk)
+	}
+	var blkBytes []byte
+	err = s.cfg.DB.View(func(dbTx database.Tx) er.R {
+		var err er.R
+		blkBytes, err = dbTx.FetchBlock(hash)
+		return err
+	})
+	if database.ErrBlockPruned.Is(err) {
+		return nil, rpcPrunedBlockError()
+	}
+	if err != nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCBlockNotFound,
+			"Block not found", nil)
+	}
+	block, err := btcutil.NewBlockFromBytes(blkBytes)
+	if err != nil {
+		return nil, internalRPCError(err, "Failed to deserialize block")
+	}
+	return block, nil
+}
+
+// handleDecodePcProof implements the decodepcproof command.
+func handleDecodePcProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.DecodePcProofCmd)
+	block, err := fetchPcProofBlock(s, c.Block)
+	if err != nil {
+		return nil, err
+	}
+	mb := block.MsgBlock()
+	if mb.Pcp == nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"Block has no PacketCrypt proof", nil)
+	}
+	if len(mb.Transactions) == 0 || !blockchain.IsCoinBaseTx(mb.Transactions[0]) {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"Block has no coinbase", nil)
+	}
+
+	// The height and the parent blocks of the announcements are found the
+	// same way as when the block is validated.
+	height, err := blockchain.ExtractCoinbaseHeight(btcutil.NewTx(mb.Transactions[0]))
+	if err != nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"Block height cannot be found in the coinbase", err)
+	}
+	parentHashes := make([]*chainhash.Hash, len(mb.Pcp.Announcements))
+	for i := range mb.Pcp.Announcements {
+		ph := mb.Pcp.Announcements[i].GetParentBlockHeight()
+		if ph > 0x7fffffff {
+			continue
+		}
+		parentHashes[i], _ = s.cfg.Chain.BlockHashByHeightContextual(int32(ph),
+			&mb.Header.PrevBlock)
+	}
+
+	info, err := packetcrypt.InspectPcBlock(mb, height, parentHashes)
+	if err != nil {
+		return nil, internalRPCError(err, "Failed to inspect PacketCrypt proof")
+	}
+
+	result := &btcjson.DecodePcProofResult{
+		Hash:            block.Hash().String(),
+		Height:          height,
+		Bits:            fmt.Sprintf("%08x", mb.Header.Bits),
+		Version:         mb.Pcp.Version,
+		Nonce:           mb.Pcp.Nonce,
+		ContentProofIdx: info.ContentProofIdx,
+		AnnProofSize:    len(mb.Pcp.AnnProof),
+		Valid:           info.Ok(),
+	}
+	if info.Commit != nil {
+		result.AnnCount = info.Commit.AnnCount()
+		result.AnnMinTarget = fmt.Sprintf("%08x", info.Commit.AnnMinDifficulty())
+		result.MerkleRoot = hex.EncodeToString(info.Commit.MerkleRoot())
+	}
+	if info.Proof != nil {
+		result.EffectiveTarget = fmt.Sprintf("%08x", info.Proof.EffectiveTarget)
+		result.WorkHash = hex.EncodeToString(info.Proof.WorkHash[:])
+		layout, size, err := proof.Layout(info.Commit.AnnCount(), &info.Proof.AnnIndexes)
+		if err == nil {
+			result.AnnProofExpected = size
+			for _, e := range layout {
+				result.AnnProofTree = append(result.AnnProofTree,
+					btcjson.PcProofTreeEntryResult{
+						Number: e.Number,
+						Depth:  e.Depth,
+						Flags:  e.Flags.String(),
+						Offset: e.Offset,
+						Range:  e.Range,
+						Hash:   e.Hash,
+					})
+			}
+		}
+	}
+
+	for i := range mb.Pcp.Announcements {
+		ann := &mb.Pcp.Announcements[i]
+		hash := annpool.AnnHash(ann)
+		r := btcjson.PcProofAnnResult{
+			Hash:          hash.String(),
+			Version:       ann.GetVersion(),
+			ParentHeight:  ann.GetParentBlockHeight(),
+			WorkTarget:    fmt.Sprintf("%08x", ann.GetWorkTarget()),
+			ContentLength: ann.GetContentLength(),
+			ContentHash:   hex.EncodeToString(ann.GetContentHash()),
+		}
+		if parentHashes[i] != nil {
+			r.ParentHash = parentHashes[i].String()
+		}
+		if info.Proof != nil {
+			if info.Commit.AnnCount() > 0 {
+				r.Index = info.Proof.AnnIndexes[i] % info.Commit.AnnCount()
+			}
+			r.EffectiveTarget = fmt.Sprintf("%08x", info.Proof.AnnTargets[i])
+		}
+		if ann.HasSigningKey() {
+			r.SigningKey = hex.EncodeToString(ann.GetSigningKey())
+			r.Signature = hex.EncodeToString(mb.Pcp.Signatures[i])
+		}
+		if i < len(info.ContentProofs) {
+			r.ContentProof = hex.EncodeToString(info.ContentProofs[i])
+		}
+		result.Announcements = append(result.Announcements, r)
+	}
+
+	for _, check := range info.Checks {
+		r := btcjson.PcProofCheckResult{Name: check.Name, Ok: check.Err == nil}
+		if check.Err != nil {
+			r.Error = check.Err.Message()
+		}
+		result.Checks = append(result.Checks, r)
+	}
+	return result, nil
+}
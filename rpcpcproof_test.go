@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript/opcode"
+	"github.com/pkt-cash/pktd/txscript/scriptbuilder"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// mineTestPcBlock mines a block at the passed height after the passed block,
+// with a PacketCrypt proof of announcements mined on the simnet genesis block.
+func mineTestPcBlock(t *testing.T, prevHash *chainhash.Hash, height int64) *wire.MsgBlock {
+	var anns []blockminer.Announcement
+	for _, ann := range mineTestPcAnns(t, 0, 8) {
+		anns = append(anns, blockminer.Announcement{Ann: ann})
+	}
+	m, err := blockminer.New(anns, int32(height), 1)
+	if err != nil {
+		t.Fatalf("blockminer.New: %v", err)
+	}
+
+	coinbaseScript, err := scriptbuilder.NewScriptBuilder().AddInt64(height).
+		AddInt64(0).Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: %v", err)
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		0xffffffff), coinbaseScript, nil))
+	coinbase.AddTxOut(wire.NewTxOut(1, []byte{opcode.OP_TRUE}))
+	m.InsertCommit(coinbase)
+	mb := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:    1,
+		PrevBlock:  *prevHash,
+		MerkleRoot: coinbase.TxHash(),
+		Bits:       0x207fffff,
+	})
+	mb.AddTransaction(coinbase)
+	if ok, err := m.Mine(mb, nil); err != nil || !ok {
+		t.Fatalf("Mine: %v %v", ok, err)
+	}
+	return mb
+}
+
+// TestDecodePcProof ensures the decodepcproof command describes a PacketCrypt
+// proof and reports the check which fails for an invalid one.
+func TestDecodePcProof(t *testing.T) {
+	s, tip, teardown := newRESTTestServer(t)
+	defer teardown()
+	mb := mineTestPcBlock(t, tip.Hash(), 2)
+
+	decode := func() *btcjson.DecodePcProofResult {
+		var buf bytes.Buffer
+		err := mb.BtcEncode(&buf, 0, wire.WitnessEncoding|wire.PacketCryptEncoding)
+		if err != nil {
+			t.Fatalf("BtcEncode: %v", err)
+		}
+		cmd := btcjson.NewDecodePcProofCmd(hex.EncodeToString(buf.Bytes()))
+		result, err := handleDecodePcProof(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("decodepcproof: %v", err)
+		}
+		return result.(*btcjson.DecodePcProofResult)
+	}
+
+	r := decode()
+	if !r.Valid || r.Height != 2 || r.AnnCount != 8 || r.Nonce != mb.Pcp.Nonce ||
+		len(r.Announcements) != 4 || r.AnnProofSize != r.AnnProofExpected ||
+		len(r.AnnProofTree) == 0 {
+
+		t.Fatalf("decodepcproof: unexpected result %+v", r)
+	}
+	for _, ann := range r.Announcements {
+		if ann.ParentHash != chaincfg.SimNetParams.GenesisHash.String() ||
+			ann.WorkTarget != "207fffff" || ann.Index >= 8 {
+
+			t.Fatalf("decodepcproof: unexpected announcement %+v", ann)
+		}
+	}
+	for _, check := range r.Checks {
+		if !check.Ok {
+			t.Fatalf("decodepcproof: check %s fails: %s", check.Name,
+				check.Error)
+		}
+	}
+
+	// Tampering with the announcement proof only fails its check.
+	mb.Pcp.AnnProof[len(mb.Pcp.AnnProof)-1] ^= 1
+	r = decode()
+	if r.Valid {
+		t.Fatalf("decodepcproof: tampered proof is valid")
+	}
+	for _, check := range r.Checks {
+		if check.Ok != (check.Name != "annproof") {
+			t.Fatalf("decodepcproof: tampered proof: check %s: %v %s",
+				check.Name, check.Ok, check.Error)
+		}
+	}
+
+	// A block whose first transaction is not a coinbase, such as one
+	// without inputs, is rejected.
+	mb.Transactions[0].TxIn = nil
+	var buf bytes.Buffer
+	if err := mb.BtcEncode(&buf, 0, wire.PacketCryptEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	cmd := btcjson.NewDecodePcProofCmd(hex.EncodeToString(buf.Bytes()))
+	_, err := handleDecodePcProof(s, cmd, nil)
+	if !btcjson.ErrRPCInvalidParameter.Is(err) {
+		t.Fatalf("decodepcproof: block without a coinbase: %v", err)
+	}
+
+	// The blocks of the chain have no PacketCrypt proof.
+	cmd = btcjson.NewDecodePcProofCmd(tip.Hash().String())
+	if _, err := handleDecodePcProof(s, cmd, nil); err == nil {
+		t.Fatalf("decodepcproof: block without a proof decoded")
+	}
+}
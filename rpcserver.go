@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
@@ -29,6 +30,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/gorilla/websocket"
+	"github.com/pkt-cash/pktd/annpool"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/blockchain/indexers"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
@@ -53,6 +55,7 @@ import (
 	"github.com/pkt-cash/pktd/wire/constants"
 	"github.com/pkt-cash/pktd/wire/protocol"
 	"github.com/pkt-cash/pktd/wire/ruleerror"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // API version constants
@@ -122,6 +125,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"createrawtransaction":   handleCreateRawTransaction,
 	"debuglevel":             handleDebugLevel,
 	"decoderawtransaction":   handleDecodeRawTransaction,
+	"decodepcproof":          handleDecodePcProof,
 	"decodescript":           handleDecodeScript,
 	"estimatefee":            handleEstimateFee,
 	"estimatesmartfee":       handleEstimateSmartFee,
@@ -137,6 +141,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getblocktemplate":       handleGetBlockTemplate,
 	"getcfilter":             handleGetCFilter,
 	"getcfilterheader":       handleGetCFilterHeader,
+	"getchaintips":           handleGetChainTips,
 	"getconnectioncount":     handleGetConnectionCount,
 	"getcurrentnet":          handleGetCurrentNet,
 	"getdifficulty":          handleGetDifficulty,
@@ -144,11 +149,15 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"gethashespersec":        handleGetHashesPerSec,
 	"getheaders":             handleGetHeaders,
 	"getinfo":                handleGetInfo,
+	"getmempoolancestors":    handleGetMempoolAncestors,
+	"getmempooldescendants":  handleGetMempoolDescendants,
+	"getmempoolentry":        handleGetMempoolEntry,
 	"getmempoolinfo":         handleGetMempoolInfo,
 	"getmininginfo":          handleGetMiningInfo,
 	"getminingpayouts":       handleGetMiningPayouts,
 	"getnettotals":           handleGetNetTotals,
 	"getnetworkhashps":       handleGetNetworkHashPS,
+	"getpcnetworkinfo":       handleGetPcNetworkInfo,
 	"getnetworkinfo":         handleGetNetworkInfo,
 	"getnetworksteward":      handleGetNetworkSteward,
 	"getpeerinfo":            handleGetPeerInfo,
@@ -156,11 +165,17 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getrawblocktemplate":    handleGetRawBlockTemplate,
 	"checkpcshare":           handleCheckPcShare,
 	"checkpcann":             handleCheckPcAnn,
+	"checkpcanns":            handleCheckPcAnns,
+	"getannpool":             handleGetAnnPool,
 	"getrawtransaction":      handleGetRawTransaction,
 	"gettxout":               handleGetTxOut,
 	"help":                   handleHelp,
+	"invalidateblock":        handleInvalidateBlock,
 	"node":                   handleNode,
 	"ping":                   handlePing,
+	"preciousblock":          handlePreciousBlock,
+	"reconsiderblock":        handleReconsiderBlock,
+	"savemempool":            handleSaveMempool,
 	"echo":                   handleEcho,
 	"searchrawtransactions":  handleSearchRawTransactions,
 	"sendrawtransaction":     handleSendRawTransaction,
@@ -180,9 +195,15 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 var rpcAskWallet = map[string]struct{}{
 	"addmultisigaddress":     {},
 	"addp2shscript":          {},
+	"analyzepsbt":            {},
+	"bumpfee":                {},
+	"combinepsbt":            {},
 	"createencryptedwallet":  {},
 	"createmultisig":         {},
+	"decodepsbt":             {},
 	"dumpprivkey":            {},
+	"finalizepsbt":           {},
+	"foldaddress":            {},
 	"getbalance":             {},
 	"getnewaddress":          {},
 	"getreceivedbyaddress":   {},
@@ -190,6 +211,9 @@ var rpcAskWallet = map[string]struct{}{
 	"gettxoutsetinfo":        {},
 	"getunconfirmedbalance":  {},
 	"importprivkey":          {},
+	"importdescriptors":      {},
+	"importxpub":             {},
+	"listdescriptors":        {},
 	"listlockunspent":        {},
 	"listreceivedbyaddress":  {},
 	"listsinceblock":         {},
@@ -202,21 +226,18 @@ var rpcAskWallet = map[string]struct{}{
 	"settxfee":               {},
 	"signmessage":            {},
 	"signrawtransaction":     {},
+	"walletcreatefundedpsbt": {},
 	"walletlock":             {},
 	"walletpassphrase":       {},
 	"walletpassphrasechange": {},
+	"walletprocesspsbt":      {},
 	"walletmempool":          {},
 }
 
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
-	"getchaintips":    {},
-	"getmempoolentry": {},
-	"getnetworkinfo":  {},
-	"getwork":         {},
-	"invalidateblock": {},
-	"preciousblock":   {},
-	"reconsiderblock": {},
+	"getnetworkinfo": {},
+	"getwork":        {},
 }
 
 // Commands that are available to a limited user
@@ -237,6 +258,7 @@ var rpcLimited = map[string]struct{}{
 	// HTTP/S-only commands
 	"createrawtransaction":  {},
 	"decoderawtransaction":  {},
+	"decodepcproof":         {},
 	"decodescript":          {},
 	"estimatefee":           {},
 	"getbestblock":          {},
@@ -251,8 +273,12 @@ var rpcLimited = map[string]struct{}{
 	"getdifficulty":         {},
 	"getheaders":            {},
 	"getinfo":               {},
+	"getmempoolancestors":   {},
+	"getmempooldescendants": {},
+	"getmempoolentry":       {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
+	"getpcnetworkinfo":      {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
 	"gettxout":              {},
@@ -286,6 +312,14 @@ func internalRPCError(err er.R, context string) er.R {
 	return btcjson.NewRPCError(btcjson.ErrRPCInternal, context, err)
 }
 
+// rpcPrunedBlockError is a convenience function for returning a nicely
+// formatted RPC error which indicates the data for the requested block is not
+// available because it has been pruned.
+func rpcPrunedBlockError() er.R {
+	return btcjson.NewRPCError(btcjson.ErrRPCMisc,
+		"Block not available (pruned data)", nil)
+}
+
 // rpcDecodeHexError is a convenience function for returning a nicely formatted
 // RPC error which indicates the provided hex string failed to decode.
 func rpcDecodeHexError(gotHex string) er.R {
@@ -857,7 +891,7 @@ func handleEstimateSmartFee(s *rpcServer, cmd interface{}, closeChan <-chan stru
 func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	// Respond with an error if there are no addresses to pay the
 	// created blocks to.
-	if len(cfg.miningAddrs) == 0 {
+	if !cfg.miningPayouts.HasPayouts() {
 		return nil, btcjson.NewRPCError(
 			btcjson.ErrRPCInternal,
 			"No payment addresses specified via --miningaddr",
@@ -1058,6 +1092,9 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		blkBytes, err = dbTx.FetchBlock(hash)
 		return err
 	})
+	if database.ErrBlockPruned.Is(err) {
+		return nil, rpcPrunedBlockError()
+	}
 	if err != nil {
 		return nil, btcjson.NewRPCError(
 			btcjson.ErrRPCBlockNotFound,
@@ -1274,9 +1311,17 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		InitialBlockDownload: !chain.IsCurrent(),
 		Difficulty:           getDifficultyRatio(chainSnapshot.Bits, params),
 		MedianTime:           chainSnapshot.MedianTime.Unix(),
-		Pruned:               false,
+		Pruned:               chain.IsPruned(),
 		Bip9SoftForks:        make(map[string]*btcjson.Bip9SoftForkDescription),
 	}
+	if chainInfo.Pruned {
+		pruneHeight, err := chain.PruneHeight()
+		if err != nil {
+			context := "Failed to obtain prune height"
+			return nil, internalRPCError(err, context)
+		}
+		chainInfo.PruneHeight = pruneHeight
+	}
 
 	// Next, populate the response with information describing the current
 	// status of soft-forks deployed via the super-majority block
@@ -1662,9 +1707,9 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		// again.
 		state.prevHash = nil
 
-		var payAddrs map[btcutil.Address]float64
+		var payouts *mining.PayoutSchedule
 		if !useCoinbaseValue {
-			payAddrs = cfg.miningAddrs
+			payouts = cfg.miningPayouts
 		}
 
 		// Create a new block template that has a coinbase which anyone
@@ -1672,7 +1717,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		// block template doesn't include the coinbase, so the caller
 		// will ultimately create their own coinbase which pays to the
 		// appropriate address(es).
-		blkTemplate, err := generator.NewBlockTemplate(payAddrs, nil)
+		blkTemplate, err := generator.NewBlockTemplate(payouts, nil)
 		if err != nil {
 			return internalRPCError(err, "Failed to create new block template")
 		}
@@ -1694,7 +1739,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		state.lastTxUpdate = lastTxUpdate
 		state.prevHash = latestHash
 		state.minTimestamp = minTimestamp
-		state.withPayAddresses = len(payAddrs) > 0
+		state.withPayAddresses = payouts != nil
 
 		log.Debugf("Generated block template (timestamp %v, "+
 			"target %s, merkle root %s)",
@@ -2003,7 +2048,7 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 
 	// When a coinbase transaction has been requested, respond with an error
 	// if there are no addresses to pay the created block template to.
-	if !useCoinbaseValue && len(cfg.miningAddrs) == 0 {
+	if !useCoinbaseValue && !cfg.miningPayouts.HasPayouts() {
 		return nil, btcjson.NewRPCError(
 			btcjson.ErrRPCInternal,
 			"A coinbase transaction has been requested, "+
@@ -2061,26 +2106,11 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 	return state.blockTemplateResult(useCoinbaseValue, nil)
 }
 
-func handleGetRawBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
-
-	if len(cfg.miningAddrs) == 0 {
-		return nil, btcjson.NewRPCError(
-			btcjson.ErrRPCInternal,
-			"A coinbase transaction has been requested, "+
-				"but the server has not been configured with "+
-				"any payment addresses via --miningaddr",
-			nil,
-		)
-	}
-
-	// Protect concurrent access when updating block templates.
-	state := s.gbtWorkState
-	state.Lock()
-	defer state.Unlock()
-
-	if err := state.updateBlockTemplate(s, false); err != nil {
-		return nil, err
-	}
+// rawBlockTemplateResult returns the current block template associated with
+// the state as a btcjson.GetRawBlockTemplateResult.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) rawBlockTemplateResult() (*btcjson.GetRawBlockTemplateResult, er.R) {
 	msgBlock := state.template.Block
 
 	// Mutate the coinbase but then put it back after
@@ -2128,9 +2158,97 @@ func handleGetRawBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan s
 		CoinbaseNoWitness: cbnw,
 		MerkleBranch:      proofStr,
 		Transactions:      transactionsStr,
+		LongPollID:        encodeTemplateID(&msgBlock.Header.PrevBlock, state.lastGenerated),
 	}, nil
 }
 
+// handleGetRawBlockTemplateLongPoll is a helper for handleGetRawBlockTemplate
+// which deals with long polling, in the same way as
+// handleGetBlockTemplateLongPoll.  It returns the current block template if
+// the passed long poll ID is invalid or stale, otherwise it waits until the
+// block template changes.
+func handleGetRawBlockTemplateLongPoll(s *rpcServer, longPollID string, closeChan <-chan struct{}) (*btcjson.GetRawBlockTemplateResult, er.R) {
+	state := s.gbtWorkState
+	state.Lock()
+	// The state unlock is intentionally not deferred here since it needs to
+	// be manually unlocked before waiting for a notification about block
+	// template changes.
+
+	if err := state.updateBlockTemplate(s, false); err != nil {
+		state.Unlock()
+		return nil, err
+	}
+
+	// Return the current block template now if the long poll ID provided
+	// by the caller is invalid or if it no longer matches the current block
+	// template as this means the provided template is stale.
+	prevHash, lastGenerated, err := decodeTemplateID(longPollID)
+	if err != nil || !prevHash.IsEqual(&state.template.Block.Header.PrevBlock) ||
+		lastGenerated != state.lastGenerated.Unix() {
+
+		defer state.Unlock()
+		return state.rawBlockTemplateResult()
+	}
+
+	// Get a channel that will be notified when the template associated with
+	// the provided ID is stale and a new block template should be returned to
+	// the caller.
+	longPollChan := state.templateUpdateChan(prevHash, lastGenerated)
+	state.Unlock()
+
+	select {
+	// When the client closes before it's time to send a reply, just return
+	// now so the goroutine doesn't hang around.
+	case <-closeChan:
+		return nil, ErrClientQuit.Default()
+
+	// Wait until signal received to send the reply.
+	case <-longPollChan:
+		// Fallthrough
+	}
+
+	// Get the lastest block template
+	state.Lock()
+	defer state.Unlock()
+
+	if err := state.updateBlockTemplate(s, false); err != nil {
+		return nil, err
+	}
+	return state.rawBlockTemplateResult()
+}
+
+// handleGetRawBlockTemplate implements the getrawblocktemplate command.
+func handleGetRawBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.GetRawBlockTemplateCmd)
+
+	if !cfg.miningPayouts.HasPayouts() {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCInternal,
+			"A coinbase transaction has been requested, "+
+				"but the server has not been configured with "+
+				"any payment addresses via --miningaddr",
+			nil,
+		)
+	}
+
+	// When a long poll ID was provided, this is a long poll request by the
+	// client to be notified when block template referenced by the ID should
+	// be replaced with a new one.
+	if c != nil && c.LongPollID != nil {
+		return handleGetRawBlockTemplateLongPoll(s, *c.LongPollID, closeChan)
+	}
+
+	// Protect concurrent access when updating block templates.
+	state := s.gbtWorkState
+	state.Lock()
+	defer state.Unlock()
+
+	if err := state.updateBlockTemplate(s, false); err != nil {
+		return nil, err
+	}
+	return state.rawBlockTemplateResult()
+}
+
 func handleCheckPcShare(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	cx := cmd.(*btcjson.CheckPcShareCmd)
 	c := cx.Request
@@ -2176,20 +2294,42 @@ func handleCheckPcShare(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 		return nil, err
 	}
 
-	// Check #1, does the merkle branch match + the coinbase match the merkle root?
-	txHash := mb.Transactions[0].TxHash()
+	merkleBranch := make([]*chainhash.Hash, 0, len(c.MerkleBranch))
 	for _, hashHex := range c.MerkleBranch {
 		hash, err := hex.DecodeString(hashHex)
 		if err != nil {
 			return nil, rpcDecodeHexError(hashHex)
 		}
+		var h chainhash.Hash
+		copy(h[:], hash)
+		merkleBranch = append(merkleBranch, &h)
+	}
+
+	blockOk, err := checkPcShare(s, mb, merkleBranch, c.Height, c.ShareTarget)
+	if err != nil {
+		return nil, err
+	}
+	if blockOk {
+		return "RESUBMIT_AS_BLOCK", nil
+	}
+	return "OK", nil
+}
+
+// checkPcShare validates a block share whose coinbase is the first of its
+// transactions, the coinbase and the merkle branch must make the merkle root
+// of the header.  It returns true if the share is also good enough to be a
+// block.
+func checkPcShare(s *rpcServer, mb *wire.MsgBlock, merkleBranch []*chainhash.Hash, height int32, shareTarget uint32) (bool, er.R) {
+	// Check #1, does the merkle branch match + the coinbase match the merkle root?
+	txHash := mb.Transactions[0].TxHash()
+	for _, hash := range merkleBranch {
 		var buf [64]byte
 		copy(buf[:32], txHash[:])
-		copy(buf[32:], hash)
+		copy(buf[32:], hash[:])
 		txHash = chainhash.DoubleHashH(buf[:])
 	}
 	if !bytes.Equal(txHash[:], mb.Header.MerkleRoot[:]) {
-		return nil, btcjson.NewRPCError(
+		return false, btcjson.NewRPCError(
 			btcjson.ErrRPCVerify,
 			fmt.Sprintf("Share validation failed: merkle root mismatch, expected [%s]"+
 				" but got [%s]", hex.EncodeToString(mb.Header.MerkleRoot[:]),
@@ -2197,31 +2337,32 @@ func handleCheckPcShare(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 			nil,
 		)
 	}
+	if mb.Pcp == nil {
+		return false, btcjson.NewRPCError(
+			btcjson.ErrRPCVerify,
+			"Share validation failed: missing PacketCrypt proof",
+			nil,
+		)
+	}
 
 	// Check #2, do the anns reference real blocks?
 	var parentHashes [4]*chainhash.Hash
 	for i, ann := range mb.Pcp.Announcements {
-		height := ann.GetParentBlockHeight()
-		parentHashes[i], err = s.cfg.Chain.BlockHashByHeight(int32(height))
+		var err er.R
+		parentHeight := ann.GetParentBlockHeight()
+		parentHashes[i], err = s.cfg.Chain.BlockHashByHeight(int32(parentHeight))
 		if err != nil {
-			return nil, btcjson.NewRPCError(
+			return false, btcjson.NewRPCError(
 				btcjson.ErrRPCVerify,
 				fmt.Sprintf("Share validation failed: could not get parent "+
-					"hash at height [%d] for announcement [%d]", height, i),
+					"hash at height [%d] for announcement [%d]", parentHeight, i),
 				nil,
 			)
 		}
 	}
 
 	// Check #3, does it hash?
-	blockOk, err := packetcrypt.ValidatePcBlock(mb, c.Height, c.ShareTarget, parentHashes[:])
-	if err != nil {
-		return nil, err
-	}
-	if blockOk {
-		return "RESUBMIT_AS_BLOCK", nil
-	}
-	return "OK", nil
+	return packetcrypt.ValidatePcBlock(mb, height, shareTarget, parentHashes[:])
 }
 
 func handleCheckPcAnn(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
@@ -2418,6 +2559,21 @@ func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hash.String(), nil
 }
 
+// handleGetChainTips implements the getchaintips command.
+func handleGetChainTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	tips := s.cfg.Chain.ChainTips()
+	reply := make([]btcjson.GetChainTipsResult, 0, len(tips))
+	for _, tip := range tips {
+		reply = append(reply, btcjson.GetChainTipsResult{
+			Height:    tip.Height,
+			Hash:      tip.Hash.String(),
+			BranchLen: tip.BranchLen,
+			Status:    tip.Status.String(),
+		})
+	}
+	return reply, nil
+}
+
 // handleGetConnectionCount implements the getconnectioncount command.
 func handleGetConnectionCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	return s.cfg.ConnMgr.ConnectedCount(), nil
@@ -2506,6 +2662,99 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return ret, nil
 }
 
+// mempoolTxHash parses the passed transaction hash and ensures it refers to a
+// transaction in the memory pool.
+func mempoolTxHash(s *rpcServer, txID string) (*chainhash.Hash, er.R) {
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return nil, rpcDecodeHexError(txID)
+	}
+	if !s.cfg.TxMemPool.IsTransactionInPool(txHash) {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCNoTxInfo,
+			"Transaction not in mempool",
+			nil,
+		)
+	}
+	return txHash, nil
+}
+
+// mempoolRelatives returns the result for the getmempoolancestors and
+// getmempooldescendants commands given the verbose flag and the entries for
+// the related transactions.
+func mempoolRelatives(entries map[string]*btcjson.GetMempoolEntryResult,
+	verbose *bool) interface{} {
+
+	if verbose != nil && *verbose {
+		return entries
+	}
+
+	// The response is simply an array of the transaction hashes if the
+	// verbose flag is not set.
+	hashStrings := make([]string, 0, len(entries))
+	for hash := range entries {
+		hashStrings = append(hashStrings, hash)
+	}
+	return hashStrings
+}
+
+// handleGetMempoolAncestors implements the getmempoolancestors command.
+func handleGetMempoolAncestors(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.GetMempoolAncestorsCmd)
+	txHash, err := mempoolTxHash(s, c.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.cfg.TxMemPool.MempoolAncestors(txHash)
+	if err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCNoTxInfo,
+			"Transaction not in mempool",
+			err,
+		)
+	}
+	return mempoolRelatives(entries, c.Verbose), nil
+}
+
+// handleGetMempoolDescendants implements the getmempooldescendants command.
+func handleGetMempoolDescendants(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.GetMempoolDescendantsCmd)
+	txHash, err := mempoolTxHash(s, c.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.cfg.TxMemPool.MempoolDescendants(txHash)
+	if err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCNoTxInfo,
+			"Transaction not in mempool",
+			err,
+		)
+	}
+	return mempoolRelatives(entries, c.Verbose), nil
+}
+
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.GetMempoolEntryCmd)
+	txHash, err := mempoolTxHash(s, c.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.cfg.TxMemPool.MempoolEntry(txHash)
+	if err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCNoTxInfo,
+			"Transaction not in mempool",
+			err,
+		)
+	}
+	return entry, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	mempoolTxns := s.cfg.TxMemPool.TxDescs()
@@ -2560,6 +2809,7 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return &result, nil
 }
 
+// handleConfigureMiningPayouts implements the configureminingpayouts command.
 func handleConfigureMiningPayouts(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	c := cmd.(*btcjson.ConfigureMiningPayoutsCmd)
 	m := make(map[btcutil.Address]float64)
@@ -2577,6 +2827,22 @@ func handleConfigureMiningPayouts(s *rpcServer, cmd interface{}, closeChan <-cha
 		return nil, er.Errorf("You must specify at least one address to pay to")
 	}
 
+	// Without a height, the payouts apply from the next block on.
+	best := s.cfg.Chain.BestSnapshot()
+	height := best.Height + 1
+	if c.Height != nil {
+		if *c.Height <= int64(best.Height) || *c.Height > math.MaxInt32 {
+			return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+				"Height must be above the best block height", nil)
+		}
+		height = int32(*c.Height)
+	}
+	if height > best.Height+1 && cfg.miningPayouts.SplitAt(best.Height+1) == nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"A change of payouts cannot be scheduled before there are "+
+				"payouts for the next block", nil)
+	}
+
 	state := s.gbtWorkState
 	state.Lock()
 	defer state.Unlock()
@@ -2584,8 +2850,15 @@ func handleConfigureMiningPayouts(s *rpcServer, cmd interface{}, closeChan <-cha
 	// drop the template
 	state.template = nil
 
-	// Set the mining addresses
-	cfg.miningAddrs = m
+	// Set the mining addresses, they are stored so they are used again
+	// after a restart.
+	if err := cfg.miningPayouts.Set(height, m); err != nil {
+		return nil, btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter,
+			"Invalid payouts", err)
+	}
+	if err := cfg.miningPayouts.Prune(best.Height + 1); err != nil {
+		return nil, internalRPCError(err, "Failed to store the payouts")
+	}
 
 	if err := state.updateBlockTemplate(s, true); err != nil {
 		return nil, err
@@ -3050,6 +3323,43 @@ func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return help, nil
 }
 
+// knownBlockHash decodes the passed block hash and ensures the block is known
+// to the chain, returning an appropriate RPC error when it is not.
+func knownBlockHash(s *rpcServer, hashStr string) (*chainhash.Hash, er.R) {
+	hash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hashStr)
+	}
+	if _, err := s.cfg.Chain.HeaderByHash(hash); err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCBlockNotFound,
+			"Block not found",
+			nil,
+		)
+	}
+	return hash, nil
+}
+
+// handleInvalidateBlock implements the invalidateblock command.
+func handleInvalidateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.InvalidateBlockCmd)
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cfg.Chain.InvalidateBlock(hash); err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCDatabase,
+			"Failed to invalidate block",
+			err,
+		)
+	}
+
+	log.Infof("Invalidated block %s via invalidateblock", hash)
+	return nil, nil
+}
+
 // handlePing implements the ping command.
 func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	// Ask server to ping \o_
@@ -3335,6 +3645,59 @@ func fetchMempoolTxnsForAddress(s *rpcServer, addr btcutil.Address, numToSkip, n
 	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
+// handlePreciousBlock implements the preciousblock command.
+func handlePreciousBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.PreciousBlockCmd)
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cfg.Chain.PreciousBlock(hash); err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCDatabase,
+			"Failed to mark block precious",
+			err,
+		)
+	}
+	return nil, nil
+}
+
+// handleReconsiderBlock implements the reconsiderblock command.
+func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	c := cmd.(*btcjson.ReconsiderBlockCmd)
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cfg.Chain.ReconsiderBlock(hash); err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCDatabase,
+			"Failed to reconsider block",
+			err,
+		)
+	}
+
+	log.Infof("Reconsidered block %s via reconsiderblock", hash)
+	return nil, nil
+}
+
+// handleSaveMempool implements the savemempool command.
+func handleSaveMempool(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
+	path := mempoolPersistPath()
+	if err := s.cfg.TxMemPool.DumpFile(path); err != nil {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCMisc,
+			"Unable to dump mempool to disk",
+			err,
+		)
+	}
+
+	log.Infof("Saved mempool to %s via savemempool", path)
+	return nil, nil
+}
+
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, er.R) {
 	// Respond with an error if the address index is not enabled.
@@ -3701,7 +4064,7 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	} else {
 		// Respond with an error if there are no addresses to pay the
 		// created blocks to.
-		if len(cfg.miningAddrs) == 0 {
+		if !cfg.miningPayouts.HasPayouts() {
 			return nil, btcjson.NewRPCError(
 				btcjson.ErrRPCInternal,
 				"No payment addresses specified via --miningaddr",
@@ -3926,6 +4289,7 @@ type rpcServer struct {
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	pcAnns                 *pcAnnValidator
 	helpCacher             *helpCacher
 	requestProcessShutdown chan struct{}
 	quit                   chan int
@@ -4125,6 +4489,7 @@ type parsedRPCCmd struct {
 func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct{}) (interface{}, er.R) {
 	handler, ok := rpcHandlers[cmd.method]
 	if ok {
+		defer prometheus.NewTimer(rpcDuration.WithLabelValues(cmd.method)).ObserveDuration()
 		goto handled
 	}
 	_, ok = rpcAskWallet[cmd.method]
@@ -4441,6 +4806,40 @@ func (s *rpcServer) Start() {
 		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin)
 	})
 
+	// Announcement validation stream endpoint.
+	rpcServeMux.HandleFunc("/pcanns", func(w http.ResponseWriter, r *http.Request) {
+		if _, isAdmin, err := s.checkAuth(r, true); err != nil || !isAdmin {
+			jsonAuthFail(w)
+			return
+		}
+
+		// Limit the number of connections to max allowed.
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+
+		s.incrementClients()
+		defer s.decrementClients()
+		s.handlePcAnnsStream(w, r)
+	})
+
+	// REST endpoint.
+	if cfg.REST {
+		rpcServeMux.HandleFunc("/rest/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Connection", "close")
+			r.Close = true
+
+			// Limit the number of connections to max allowed.
+			if s.limitConnections(w, r.RemoteAddr) {
+				return
+			}
+
+			s.incrementClients()
+			defer s.decrementClients()
+			s.handleREST(w, r)
+		})
+	}
+
 	for _, listener := range s.cfg.Listeners {
 		s.wg.Add(1)
 		go func(listener net.Listener) {
@@ -4621,6 +5020,10 @@ type rpcserverConfig struct {
 	// TxMemPool defines the transaction memory pool to interact with.
 	TxMemPool *mempool.TxPool
 
+	// AnnPool is the PacketCrypt announcement pool, it is nil when the
+	// announcement relay is disabled.
+	AnnPool *annpool.AnnPool
+
 	// These fields allow the RPC server to interface with mining.
 	//
 	// Generator produces block templates and the CPUMiner solves them using
@@ -4663,6 +5066,7 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, er.R) {
 		rpc.limitauthsha = sha256.Sum256([]byte(auth))
 	}
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
+	rpc.pcAnns = newPcAnnValidator(rpc.cfg.Chain, rpc.quit)
 	rpc.cfg.Chain.Subscribe(rpc.handleBlockchainNotification)
 
 	return &rpc, nil
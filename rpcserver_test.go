@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/txscript/opcode"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
+	"github.com/pkt-cash/pktd/zmqpub"
+)
+
+// newTestPayouts returns a payout schedule stored at path which pays all of the
+// simnet blocks to addr.
+func newTestPayouts(t *testing.T, path string, addr btcutil.Address) *mining.PayoutSchedule {
+	payouts := mining.NewPayoutSchedule(path, &chaincfg.SimNetParams, 0)
+	if err := payouts.Seed(map[btcutil.Address]float64{addr: 1}); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	return payouts
+}
+
+// TestGetRawBlockTemplateLongPoll ensures getrawblocktemplate returns a long
+// poll ID which makes a later request wait until the template changes.
+func TestGetRawBlockTemplateLongPoll(t *testing.T) {
+	s, _, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	params := &chaincfg.SimNetParams
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	oldCfg := cfg
+	cfg = &config{miningPayouts: newTestPayouts(t, "", addr)}
+	defer func() { cfg = oldCfg }()
+
+	timeSource := blockchain.NewMedianTime()
+	s.cfg.Generator = mining.NewBlkTmplGenerator(&mining.Policy{
+		BlockMaxWeight: defaultBlockMaxWeight,
+		BlockMaxSize:   defaultBlockMaxSize,
+	}, params, s.cfg.TxMemPool, s.cfg.Chain, timeSource,
+		txscript.NewSigCache(10), txscript.NewHashCache(10))
+	s.gbtWorkState = newGbtWorkState(timeSource)
+
+	res, err := handleGetRawBlockTemplate(s,
+		btcjson.NewGetRawBlockTemplateCmd(nil), nil)
+	if err != nil {
+		t.Fatalf("handleGetRawBlockTemplate: %v", err)
+	}
+	template := res.(*btcjson.GetRawBlockTemplateResult)
+	if template.Height != 2 || template.LongPollID == "" {
+		t.Fatalf("unexpected template: height %d, long poll ID %q",
+			template.Height, template.LongPollID)
+	}
+
+	// An invalid long poll ID returns the current template immediately.
+	res, err = handleGetRawBlockTemplate(s,
+		btcjson.NewGetRawBlockTemplateCmd(btcjson.String("invalid")), nil)
+	if err != nil {
+		t.Fatalf("handleGetRawBlockTemplate: %v", err)
+	}
+	if res.(*btcjson.GetRawBlockTemplateResult).LongPollID != template.LongPollID {
+		t.Fatalf("unexpected long poll ID")
+	}
+
+	// The long poll of the current template returns when the client quits.
+	closeChan := make(chan struct{})
+	close(closeChan)
+	_, err = handleGetRawBlockTemplate(s,
+		btcjson.NewGetRawBlockTemplateCmd(&template.LongPollID), closeChan)
+	if !ErrClientQuit.Is(err) {
+		t.Fatalf("handleGetRawBlockTemplate: %v, want client quit", err)
+	}
+
+	// Otherwise it waits until the template is stale.
+	type result struct {
+		res interface{}
+		err er.R
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := handleGetRawBlockTemplate(s,
+			btcjson.NewGetRawBlockTemplateCmd(&template.LongPollID), nil)
+		done <- result{res, err}
+	}()
+	select {
+	case r := <-done:
+		t.Fatalf("long poll returned before the template changed: %v", r.err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	s.gbtWorkState.NotifyBlockConnected(&chainhash.Hash{1})
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("handleGetRawBlockTemplate: %v", r.err)
+		}
+		if r.res.(*btcjson.GetRawBlockTemplateResult).Height != 2 {
+			t.Fatalf("unexpected template after long poll")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("long poll did not return when the template changed")
+	}
+}
+
+// TestConfigureMiningPayouts ensures configureminingpayouts stores the payouts
+// and their scheduled changes, and that the block templates pay them.
+func TestConfigureMiningPayouts(t *testing.T) {
+	s, _, teardown := newRESTTestServer(t)
+	defer teardown()
+	dir, errr := ioutil.TempDir("", "payouts")
+	if errr != nil {
+		t.Fatalf("TempDir: %v", errr)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &chaincfg.SimNetParams
+	var addrs []btcutil.Address
+	for i := 0; i < 3; i++ {
+		hash := make([]byte, 20)
+		hash[0] = byte(i)
+		addr, err := btcutil.NewAddressPubKeyHash(hash, params)
+		if err != nil {
+			t.Fatalf("NewAddressPubKeyHash: %v", err)
+		}
+		addrs = append(addrs, addr)
+	}
+	path := filepath.Join(dir, miningPayoutsFilename)
+	oldCfg := cfg
+	cfg = &config{miningPayouts: newTestPayouts(t, path, addrs[0])}
+	defer func() { cfg = oldCfg }()
+
+	timeSource := blockchain.NewMedianTime()
+	s.cfg.Generator = mining.NewBlkTmplGenerator(&mining.Policy{
+		BlockMaxWeight: defaultBlockMaxWeight,
+		BlockMaxSize:   defaultBlockMaxSize,
+	}, params, s.cfg.TxMemPool, s.cfg.Chain, timeSource,
+		txscript.NewSigCache(10), txscript.NewHashCache(10))
+	s.gbtWorkState = newGbtWorkState(timeSource)
+
+	payouts := func() btcjson.GetMiningPayoutsResult {
+		res, err := handleGetMiningPayouts(s, &btcjson.GetMiningPayoutsCmd{}, nil)
+		if err != nil {
+			t.Fatalf("handleGetMiningPayouts: %v", err)
+		}
+		return btcjson.GetMiningPayoutsResult(res.(map[string]float64))
+	}
+	if p := payouts(); len(p) != 1 || p[addrs[0].EncodeAddress()] == 0 {
+		t.Fatalf("unexpected payouts %v", p)
+	}
+
+	// The next block is at height 2, so the past blocks cannot be changed.
+	split := map[string]float64{
+		addrs[1].EncodeAddress(): 50,
+		addrs[2].EncodeAddress(): 50,
+	}
+	_, err := handleConfigureMiningPayouts(s,
+		btcjson.NewConfigureMiningPayoutsCmd(split, btcjson.Int64(1)), nil)
+	if err == nil {
+		t.Fatalf("handleConfigureMiningPayouts: changed a past block")
+	}
+	_, err = handleConfigureMiningPayouts(s,
+		btcjson.NewConfigureMiningPayoutsCmd(split, btcjson.Int64(10)), nil)
+	if err != nil {
+		t.Fatalf("handleConfigureMiningPayouts: %v", err)
+	}
+	if p := payouts(); len(p) != 1 || p[addrs[0].EncodeAddress()] == 0 {
+		t.Fatalf("scheduled payouts applied early: %v", p)
+	}
+	_, err = handleConfigureMiningPayouts(s,
+		btcjson.NewConfigureMiningPayoutsCmd(split, nil), nil)
+	if err != nil {
+		t.Fatalf("handleConfigureMiningPayouts: %v", err)
+	}
+	p := payouts()
+	if len(p) != 2 || p[addrs[1].EncodeAddress()] != p[addrs[2].EncodeAddress()] {
+		t.Fatalf("unexpected payouts %v", p)
+	}
+
+	// The payouts are used again after a restart.
+	loaded := mining.NewPayoutSchedule(path, params, 0)
+	if ok, err := loaded.Load(); err != nil || !ok {
+		t.Fatalf("Load: %v %v", ok, err)
+	}
+	if sched := loaded.Payouts(); len(sched) != 2 || sched[0].Height != 2 ||
+		sched[1].Height != 10 {
+
+		t.Fatalf("unexpected stored payouts %v", sched)
+	}
+}
+
+// dialZMQSubscriber connects a minimal ZMTP 3.0 SUB socket to the passed
+// address and subscribes it to the passed topic.
+func dialZMQSubscriber(t *testing.T, addr, topic string) (net.Conn, *bufio.Reader) {
+	conn, errr := net.Dial("tcp", addr)
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	r := bufio.NewReader(conn)
+	greeting := make([]byte, 64)
+	greeting[0], greeting[9], greeting[10] = 0xff, 0x7f, 3
+	copy(greeting[12:], "NULL")
+	ready := append([]byte{5}, "READY"...)
+	ready = append(ready, 11)
+	ready = append(ready, "Socket-Type"...)
+	ready = append(ready, 0, 0, 0, 3)
+	ready = append(ready, "SUB"...)
+	if _, errr := conn.Write(greeting); errr != nil {
+		t.Fatalf("Write: %v", errr)
+	}
+	if _, errr := io.ReadFull(r, greeting); errr != nil {
+		t.Fatalf("ReadFull: %v", errr)
+	}
+	if _, errr := conn.Write(append([]byte{0x04, byte(len(ready))}, ready...)); errr != nil {
+		t.Fatalf("Write: %v", errr)
+	}
+	readZMQFrame(t, conn, r)
+	subscribe := append([]byte{1}, topic...)
+	if _, errr := conn.Write(append([]byte{0, byte(len(subscribe))}, subscribe...)); errr != nil {
+		t.Fatalf("Write: %v", errr)
+	}
+	return conn, r
+}
+
+// readZMQFrame reads a frame and returns its flags and body.
+func readZMQFrame(t *testing.T, conn net.Conn, r *bufio.Reader) (byte, []byte) {
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var hdr [2]byte
+	if _, errr := io.ReadFull(r, hdr[:]); errr != nil {
+		t.Fatalf("ReadFull: %v", errr)
+	}
+	if hdr[0]&0x02 != 0 {
+		t.Fatalf("unexpected long frame")
+	}
+	body := make([]byte, hdr[1])
+	if _, errr := io.ReadFull(r, body); errr != nil {
+		t.Fatalf("ReadFull: %v", errr)
+	}
+	return hdr[0], body
+}
+
+// TestSendRawTransactionZMQ ensures a transaction which is submitted with
+// sendrawtransaction is published to ZMQ subscribers.
+func TestSendRawTransactionZMQ(t *testing.T) {
+	s, block, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	// The coinbase of the block is spent right away, so the mempool sees
+	// it as mature.
+	params := *s.cfg.ChainParams
+	params.CoinbaseMaturity = 1
+	chain := s.cfg.Chain
+	s.cfg.TxMemPool = mempool.New(&mempool.Config{
+		Policy: mempool.Policy{
+			DisableRelayPriority: true,
+			AcceptNonStd:         true,
+			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
+			MaxTxVersion:         2,
+		},
+		ChainParams:   &params,
+		FetchUtxoView: chain.FetchUtxoView,
+		BestHeight:    func() int32 { return chain.BestSnapshot().Height },
+		MedianTimePast: func() time.Time {
+			return chain.BestSnapshot().MedianTime
+		},
+		CalcSequenceLock: func(tx *btcutil.Tx, view *blockchain.UtxoViewpoint) (*blockchain.SequenceLock, er.R) {
+			return chain.CalcSequenceLock(tx, view, true)
+		},
+		IsDeploymentActive: chain.IsDeploymentActive,
+	})
+	s.ntfnMgr = newWsNotificationManager(s)
+	close(s.ntfnMgr.quit)
+	s.gbtWorkState = newGbtWorkState(blockchain.NewMedianTime())
+
+	// The publisher does not report the address it listens on, so find a
+	// free port first.
+	listener, errr := net.Listen("tcp", "127.0.0.1:0")
+	if errr != nil {
+		t.Fatalf("Listen: %v", errr)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	p, err := zmqpub.New(&zmqpub.Config{HashTx: "tcp://" + addr})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p.Start()
+	defer p.Stop()
+	s.cfg.ConnMgr = &rpcConnManager{server: &server{
+		zmqPublisher:         p,
+		relayInv:             make(chan relayMsg, 1),
+		modifyRebroadcastInv: make(chan interface{}, 1),
+	}}
+
+	conn, r := dialZMQSubscriber(t, addr, zmqpub.TopicHashTx)
+	defer conn.Close()
+
+	// Publish transactions until one reaches the subscriber, so that its
+	// subscription is known to be in place.
+	probe := btcutil.NewTx(wire.NewMsgTx(constants.TxVersion))
+	for i := 0; ; i++ {
+		if i == 100 {
+			t.Fatalf("subscriber did not receive any message")
+		}
+		p.NotifyTxAccepted(probe)
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		if _, errr := r.Peek(1); errr == nil {
+			break
+		}
+	}
+
+	coinbase := block.Transactions()[0]
+	tx := wire.NewMsgTx(constants.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(coinbase.Hash(), 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(coinbase.MsgTx().TxOut[0].Value-100000,
+		[]byte{opcode.OP_TRUE}))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	_, err = handleSendRawTransaction(s, btcjson.NewSendRawTransactionCmd(
+		hex.EncodeToString(buf.Bytes()), nil), nil)
+	if err != nil {
+		t.Fatalf("handleSendRawTransaction: %v", err)
+	}
+
+	want, errr := hex.DecodeString(tx.TxHash().String())
+	if errr != nil {
+		t.Fatalf("DecodeString: %v", errr)
+	}
+	for {
+		var parts [][]byte
+		for {
+			flags, body := readZMQFrame(t, conn, r)
+			parts = append(parts, body)
+			if flags&0x01 == 0 {
+				break
+			}
+		}
+		if len(parts) != 3 || string(parts[0]) != zmqpub.TopicHashTx {
+			t.Fatalf("unexpected message %x", parts)
+		}
+		if bytes.Equal(parts[1], want) {
+			break
+		}
+	}
+}
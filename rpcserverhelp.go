@@ -25,6 +25,31 @@ var helpDescsEnUS = map[string]string{
 	"checkpcann-annhex":         "The announcement body as hex",
 	"checkpcannresult-workhash": "The result hash from validating the announcement, this is used to assess difficulty",
 
+	// CheckPcAnnsCmd help.
+	"checkpcanns--synopsis": "Validate a batch of PacketCrypt announcements concurrently.\n" +
+		"The parent block hash of each announcement is looked up in the current chain by its parent block height.\n" +
+		"Announcements can also be streamed as binary websocket messages to the /pcanns endpoint.",
+	"checkpcanns-annshex":        "The announcement bodies as hex",
+	"checkpcanns-pcversion":      "The version of PacketCrypt to consider for these announcements, defaults to the version of the next block",
+	"checkpcanns--result0":       "The result of each announcement, in order",
+	"checkpcannsresult-workhash": "The result hash from validating the announcement, if it could be computed",
+	"checkpcannsresult-code":     "0 if the announcement is valid, otherwise the number of the announcement error code",
+	"checkpcannsresult-error":    "The reason the announcement is not valid",
+
+	// GetAnnPoolCmd help.
+	"getannpool--synopsis":    "Returns the PacketCrypt announcements in the announcement pool, the announcement relay must be enabled (--relayanns).",
+	"getannpool-verbose":      "Returns an array of objects which describe each announcement when true, or an array of announcement hashes when false",
+	"getannpool-parentheight": "Only return the announcements which were mined on the block at this height",
+	"getannpool--condition0":  "verbose=false",
+	"getannpool--condition1":  "verbose=true",
+	"getannpool--result0":     "Array of announcement hashes",
+
+	"getannpoolverboseresult-hash":         "The hash of the announcement",
+	"getannpoolverboseresult-parentheight": "The height of the block which the announcement was mined on",
+	"getannpoolverboseresult-worktarget":   "The work target of the announcement in compact form",
+	"getannpoolverboseresult-version":      "The PacketCrypt version of the announcement",
+	"getannpoolverboseresult-hex":          "The serialized announcement as hex",
+
 	// DebugLevelCmd help.
 	"debuglevel--synopsis": "Dynamically changes the debug logging level.\n" +
 		"The levelspec can either a debug level or of the form:\n" +
@@ -126,6 +151,53 @@ var helpDescsEnUS = map[string]string{
 	"decodescriptresult-p2sh":      "The script hash for use in pay-to-script-hash transactions (only present if the provided redeem script is not already a pay-to-script-hash script)",
 	"decodescriptresult-vote":      "An optional vote structure which shows whether the script contains a vote for and/or against a network steward",
 
+	// DecodePcProofCmd help.
+	"decodepcproof--synopsis": "Decodes the PacketCrypt proof of a block and reports which of the checks made to validate it pass or fail.\n" +
+		"The height of the block is read from its coinbase and the parent blocks of the announcements are looked up in the chain of the block.",
+	"decodepcproof-block": "The hash of a block, or a hex-encoded serialized block",
+
+	"decodepcproofresult-hash":             "The hash of the block",
+	"decodepcproofresult-height":           "The height of the block, from its coinbase",
+	"decodepcproofresult-bits":             "The target of the block header in compact form",
+	"decodepcproofresult-version":          "The version of the PacketCrypt proof, 0 when it is not specified",
+	"decodepcproofresult-nonce":            "The nonce of the PacketCrypt proof",
+	"decodepcproofresult-anncount":         "The number of announcements committed in the coinbase",
+	"decodepcproofresult-annmintarget":     "The target of the announcement with the least work committed in the coinbase",
+	"decodepcproofresult-merkleroot":       "The root of the tree of announcements committed in the coinbase",
+	"decodepcproofresult-effectivetarget":  "The target which the work hash must meet given the committed announcements",
+	"decodepcproofresult-workhash":         "The PacketCrypt hash of the block header and proof",
+	"decodepcproofresult-contentproofidx":  "The number which selects the part of the content of each announcement which the content proofs prove",
+	"decodepcproofresult-announcements":    "The 4 announcements of the proof",
+	"decodepcproofresult-annproofsize":     "The size of the announcement proof",
+	"decodepcproofresult-annproofexpected": "The size which the announcement proof must have",
+	"decodepcproofresult-annprooftree":     "The entries of the tree of the announcement proof, in the order in which their data is read from the proof",
+	"decodepcproofresult-checks":           "The result of each of the checks, in the order in which they are made when the block is validated",
+	"decodepcproofresult-valid":            "Whether all of the checks pass",
+
+	"pcproofannresult-hash":            "The hash of the announcement",
+	"pcproofannresult-index":           "The number of the announcement in the set of committed announcements, which the nonce selects",
+	"pcproofannresult-version":         "The PacketCrypt version of the announcement",
+	"pcproofannresult-parentheight":    "The height of the block which the announcement was mined on",
+	"pcproofannresult-parenthash":      "The hash of the block which the announcement was mined on, if it is known",
+	"pcproofannresult-worktarget":      "The work target of the announcement in compact form",
+	"pcproofannresult-effectivetarget": "The target of the announcement once it is aged for the block",
+	"pcproofannresult-signingkey":      "The key which signs the announcement, if it is signed",
+	"pcproofannresult-signature":       "The signature of the announcement, if it is signed",
+	"pcproofannresult-contentlength":   "The length of the content of the announcement",
+	"pcproofannresult-contenthash":     "The hash of the content of the announcement",
+	"pcproofannresult-contentproof":    "The proof of the content of the announcement, if there is one",
+
+	"pcprooftreeentryresult-number": "The number of the entry in the tree",
+	"pcprooftreeentryresult-depth":  "The distance from the entry to the root of the tree",
+	"pcprooftreeentryresult-flags":  "The flags of the entry",
+	"pcprooftreeentryresult-offset": "The offset of the data of the entry in the proof",
+	"pcprooftreeentryresult-range":  "Whether the proof provides the range of the entry",
+	"pcprooftreeentryresult-hash":   "Whether the proof provides the hash of the entry",
+
+	"pcproofcheckresult-name":  "The name of the check",
+	"pcproofcheckresult-ok":    "Whether the check passes",
+	"pcproofcheckresult-error": "The reason the check fails",
+
 	// DecodeScriptCmd help.
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
@@ -398,6 +470,15 @@ var helpDescsEnUS = map[string]string{
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns information about all known tips in the block tree, including the main chain and orphaned branches.",
+
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the chain tip",
+	"getchaintipsresult-hash":      "The block hash of the chain tip",
+	"getchaintipsresult-branchlen": "The length of the branch connecting the tip to the main chain (zero for the main chain)",
+	"getchaintipsresult-status":    "The status of the chain (active, invalid, valid-fork, valid-headers, headers-only)",
+
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
@@ -455,6 +536,43 @@ var helpDescsEnUS = map[string]string{
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetMempoolAncestorsCmd help.
+	"getmempoolancestors--synopsis":   "Returns all in-mempool ancestors of a transaction in the memory pool.",
+	"getmempoolancestors-txid":        "The hash of the transaction",
+	"getmempoolancestors-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getmempoolancestors--condition0": "verbose=false",
+	"getmempoolancestors--condition1": "verbose=true",
+	"getmempoolancestors--result0":    "Array of transaction hashes",
+
+	// GetMempoolDescendantsCmd help.
+	"getmempooldescendants--synopsis":   "Returns all in-mempool descendants of a transaction in the memory pool.",
+	"getmempooldescendants-txid":        "The hash of the transaction",
+	"getmempooldescendants-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getmempooldescendants--condition0": "verbose=false",
+	"getmempooldescendants--condition1": "verbose=true",
+	"getmempooldescendants--result0":    "Array of transaction hashes",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns information about a single transaction in the memory pool.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":             "Transaction size in bytes",
+	"getmempoolentryresult-vsize":            "The virtual size of the transaction",
+	"getmempoolentryresult-fee":              "Transaction fee in bitcoins",
+	"getmempoolentryresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":           "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority": "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":  "Current priority",
+	"getmempoolentryresult-descendantcount":  "Number of in-mempool descendant transactions, including this one",
+	"getmempoolentryresult-descendantsize":   "Virtual size of in-mempool descendants, including this one",
+	"getmempoolentryresult-descendantfees":   "Fees of in-mempool descendants in bitcoins, including this one",
+	"getmempoolentryresult-ancestorcount":    "Number of in-mempool ancestor transactions, including this one",
+	"getmempoolentryresult-ancestorsize":     "Virtual size of in-mempool ancestors, including this one",
+	"getmempoolentryresult-ancestorfees":     "Fees of in-mempool ancestors in bitcoins, including this one",
+	"getmempoolentryresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
+	"getmempoolentryresult-spentby":          "Unconfirmed transactions spending outputs from this transaction",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
@@ -486,11 +604,12 @@ var helpDescsEnUS = map[string]string{
 	"getminingpayouts--result0--value": "Percent that each address will be paid.",
 
 	// ConfigureMiningPayouts help.
-	"configureminingpayouts--synopsis":             "Configure who to pay out to when making a block template.",
+	"configureminingpayouts--synopsis":             "Configure who to pay out to when making a block template, the payouts are stored in the data directory and apply to getblocktemplate, getrawblocktemplate and the CPU miner.",
 	"configureminingpayouts-payoutpercents":        "Map of who to pay out to when making a block template.",
 	"configureminingpayouts-payoutpercents--desc":  "Which addresses should be paid when making a block template.",
 	"configureminingpayouts-payoutpercents--key":   "Which address to pay.",
 	"configureminingpayouts-payoutpercents--value": "Percent that each address should be paid.",
+	"configureminingpayouts-height":                "The height of the first block which the payouts apply to, a height above the next block schedules a change of the payouts (default: the next block)",
 
 	// GetNetworkSteward help.
 	"getnetworksteward--synopsis":           "Returns information about the network steward, if using a chain with one",
@@ -535,18 +654,46 @@ var helpDescsEnUS = map[string]string{
 	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
 	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
 
+	// GetPcNetworkInfoCmd help.
+	"getpcnetworkinfo--synopsis": "Returns PacketCrypt statistics of the announcements and the block work for the block heights provided by the parameters.\n" +
+		"The difficulties are the estimated number of encryptions needed, relative to the easiest target, and the averages are over the blocks which have a PacketCrypt proof.",
+	"getpcnetworkinfo-blocks": "The number of blocks, at most four difficulty retarget periods",
+	"getpcnetworkinfo-height": "Compute the statistics ending with this height or -1 for current best chain block height",
+
+	"getpcnetworkinforesult-startheight":            "The height of the block which the range starts after",
+	"getpcnetworkinforesult-endheight":              "The height of the last block of the range",
+	"getpcnetworkinforesult-blocks":                 "The number of blocks which have a PacketCrypt proof in the range",
+	"getpcnetworkinforesult-avganncount":            "The average number of announcements committed in the coinbase",
+	"getpcnetworkinforesult-avgannmintarget":        "The target of the average work of the announcement with the least work committed in the coinbase",
+	"getpcnetworkinforesult-avganndifficulty":       "The average difficulty of the announcement with the least work committed in the coinbase",
+	"getpcnetworkinforesult-annencryptionspersec":   "The implied announcement rate: the encryptions of the committed announcements per second",
+	"getpcnetworkinforesult-avgheadertarget":        "The target of the average work of the block header target",
+	"getpcnetworkinforesult-avgheaderdifficulty":    "The average difficulty of the block header target",
+	"getpcnetworkinforesult-avgeffectivetarget":     "The target of the average work of the effective block target, given the committed announcements",
+	"getpcnetworkinforesult-avgeffectivedifficulty": "The average difficulty of the effective block target",
+	"getpcnetworkinforesult-blkencryptionspersec":   "The encryptions of the block miners per second",
+	"getpcnetworkinforesult-minannage":              "The age of the youngest announcement in the proofs",
+	"getpcnetworkinforesult-maxannage":              "The age of the oldest announcement in the proofs",
+	"getpcnetworkinforesult-avgannage":              "The average age of the announcements in the proofs",
+	"getpcnetworkinforesult-annages":                "The number of announcements in the proofs by age in blocks",
+
+	"pcannageresult-age":   "The age of the announcements, in blocks since their parent block",
+	"pcannageresult-count": "The number of announcements of this age",
+
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
 
 	// GetRawBlockTemplate help.
 	"getrawblocktemplate--synopsis": "Return a block to be mined as a hex encoded binary string",
 	"getrawblocktemplate--result0":  "Hex encoded string of the block to be mined",
+	"getrawblocktemplate-longpollid": "The long poll ID of a previous template, the request waits until the template changes",
 
 	// GetRawBlockTemplateResult help.
 	"getrawblocktemplateresult-header":       "Block header as hex",
 	"getrawblocktemplateresult-coinbase":     "Coinbase transaction as hex",
 	"getrawblocktemplateresult-merklebranch": "Merkle branch for proving the coinbase, hex string",
 	"getrawblocktemplateresult-transactions": "Hex string of all transactions other than the coinbase",
+	"getrawblocktemplateresult-longpollid":   "The long poll ID of the template, to be passed to a later getrawblocktemplate to wait until the template changes",
 
 	"checkpcsharecmdstructure-merklebranch": "The merkle branch for proving the coinbase",
 	"checkpcsharecmdstructure-coinbase":     "The hex encoded coinbase transaction",
@@ -607,6 +754,11 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// InvalidateBlockCmd help.
+	"invalidateblock--synopsis": "Permanently marks a block as invalid, as if it violated a consensus rule.\n" +
+		"If the block is in the main chain, the chain is reorganized onto the best remaining valid branch.",
+	"invalidateblock-blockhash": "The hash of the block to mark as invalid",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
@@ -622,6 +774,19 @@ var helpDescsEnUS = map[string]string{
 	"echo-f":         "anything",
 	"echo-g":         "anything",
 
+	// PreciousBlockCmd help.
+	"preciousblock--synopsis": "Treats a block as if it were received before others with the same work.\n" +
+		"A later preciousblock call can override the effect of an earlier one.",
+	"preciousblock-blockhash": "The hash of the block to mark as precious",
+
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Removes invalidity status of a block, its ancestors and its descendants, reconsidering them for activation.\n" +
+		"This can be used to undo the effects of invalidateblock.",
+	"reconsiderblock-blockhash": "The hash of the block to reconsider",
+
+	// SaveMempoolCmd help.
+	"savemempool--synopsis": "Writes the transactions in the memory pool to disk so they are reloaded when the server restarts.",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -702,6 +867,12 @@ var helpDescsEnUS = map[string]string{
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
+	// NotifyRawBlockTemplateCmd help.
+	"notifyrawblocktemplate--synopsis": "Request a rawblocktemplate notification with the current raw block template and with each new one.",
+
+	// StopNotifyRawBlockTemplateCmd help.
+	"stopnotifyrawblocktemplate--synopsis": "Cancel registered notifications for raw block templates.",
+
 	// NotifyNewTransactionsCmd help.
 	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
 	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
@@ -782,8 +953,11 @@ var rpcResultTypes = map[string][]interface{}{
 	"configureminingpayouts": nil,
 	"createrawtransaction":   {(*string)(nil)},
 	"checkpcann":             {(*btcjson.CheckPcAnnResult)(nil)},
+	"checkpcanns":            {(*[]btcjson.CheckPcAnnsResult)(nil)},
+	"getannpool":             {(*[]string)(nil), (*[]btcjson.GetAnnPoolVerboseResult)(nil)},
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
+	"decodepcproof":          {(*btcjson.DecodePcProofResult)(nil)},
 	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
 	"estimatefee":            {(*float64)(nil)},
 	"estimatesmartfee":       {(*btcjson.EstimateSmartFeeResult)(nil)},
@@ -799,6 +973,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":             {(*string)(nil)},
 	"getcfilterheader":       {(*string)(nil)},
+	"getchaintips":           {(*[]btcjson.GetChainTipsResult)(nil)},
 	"getconnectioncount":     {(*int32)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
@@ -806,6 +981,9 @@ var rpcResultTypes = map[string][]interface{}{
 	"gethashespersec":        {(*float64)(nil)},
 	"getheaders":             {(*[]string)(nil)},
 	"getinfo":                {(*btcjson.InfoChainResult)(nil)},
+	"getmempoolancestors":    {(*[]string)(nil), (*btcjson.GetMempoolEntryResult)(nil)},
+	"getmempooldescendants":  {(*[]string)(nil), (*btcjson.GetMempoolEntryResult)(nil)},
+	"getmempoolentry":        {(*btcjson.GetMempoolEntryResult)(nil)},
 	"getmempoolinfo":         {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
 	"getminingpayouts":       {(*btcjson.GetMiningPayoutsResult)(nil)},
@@ -813,6 +991,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"getnetworkinfo":         {(*btcjson.GetNetworkInfoResult)(nil)},
 	"getnetworksteward":      {(*btcjson.GetNetworkStewardResult)(nil)},
 	"getnetworkhashps":       {(*int64)(nil)},
+	"getpcnetworkinfo":       {(*btcjson.GetPcNetworkInfoResult)(nil)},
 	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
 	"getrawblocktemplate":    {(*string)(nil)},
 	"checkpcshare":           {(*string)(nil)},
@@ -821,7 +1000,11 @@ var rpcResultTypes = map[string][]interface{}{
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
+	"invalidateblock":        nil,
 	"ping":                   nil,
+	"preciousblock":          nil,
+	"reconsiderblock":        nil,
+	"savemempool":            nil,
 	"echo":                   {(*[]string)(nil)},
 	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.TxRawResult)(nil)},
 	"sendrawtransaction":     {(*string)(nil)},
@@ -839,6 +1022,8 @@ var rpcResultTypes = map[string][]interface{}{
 	"session":                   {(*btcjson.SessionResult)(nil)},
 	"notifyblocks":              nil,
 	"stopnotifyblocks":          nil,
+	"notifyrawblocktemplate":     nil,
+	"stopnotifyrawblocktemplate": nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
 	"notifyreceived":            nil,
@@ -64,19 +64,21 @@ type wsCommandHandler func(*wsClient, interface{}) (interface{}, er.R)
 // causes a dependency loop.
 var wsHandlers map[string]wsCommandHandler
 var wsHandlersBeforeInit = map[string]wsCommandHandler{
-	"loadtxfilter":              handleLoadTxFilter,
-	"help":                      handleWebsocketHelp,
-	"notifyblocks":              handleNotifyBlocks,
-	"notifynewtransactions":     handleNotifyNewTransactions,
-	"notifyreceived":            handleNotifyReceived,
-	"notifyspent":               handleNotifySpent,
-	"session":                   handleSession,
-	"stopnotifyblocks":          handleStopNotifyBlocks,
-	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
-	"stopnotifyspent":           handleStopNotifySpent,
-	"stopnotifyreceived":        handleStopNotifyReceived,
-	"rescan":                    handleRescan,
-	"rescanblocks":              handleRescanBlocks,
+	"loadtxfilter":               handleLoadTxFilter,
+	"help":                       handleWebsocketHelp,
+	"notifyblocks":               handleNotifyBlocks,
+	"notifynewtransactions":      handleNotifyNewTransactions,
+	"notifyreceived":             handleNotifyReceived,
+	"notifyspent":                handleNotifySpent,
+	"session":                    handleSession,
+	"stopnotifyblocks":           handleStopNotifyBlocks,
+	"stopnotifynewtransactions":  handleStopNotifyNewTransactions,
+	"stopnotifyspent":            handleStopNotifySpent,
+	"stopnotifyreceived":         handleStopNotifyReceived,
+	"rescan":                     handleRescan,
+	"rescanblocks":               handleRescanBlocks,
+	"notifyrawblocktemplate":     handleNotifyRawBlockTemplate,
+	"stopnotifyrawblocktemplate": handleStopNotifyRawBlockTemplate,
 }
 
 // WebsocketHandler handles a new websocket client by creating a new wsClient,
@@ -1240,6 +1242,11 @@ type wsClient struct {
 	// `rescanblocks` methods.
 	filterData *wsClientFilter
 
+	// rawTemplateQuit is closed to stop the raw block template notifications
+	// requested with notifyrawblocktemplate, it is nil when they are not
+	// running.
+	rawTemplateQuit chan struct{}
+
 	// Networking infrastructure.
 	serviceRequestSem semaphore
 	ntfnChan          chan []byte
@@ -1782,6 +1789,87 @@ func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, er.R) {
 	return nil, nil
 }
 
+// handleNotifyRawBlockTemplate implements the notifyrawblocktemplate command
+// extension for websocket connections.
+func handleNotifyRawBlockTemplate(wsc *wsClient, icmd interface{}) (interface{}, er.R) {
+	if !cfg.miningPayouts.HasPayouts() {
+		return nil, btcjson.NewRPCError(
+			btcjson.ErrRPCInternal,
+			"A coinbase transaction has been requested, "+
+				"but the server has not been configured with "+
+				"any payment addresses via --miningaddr",
+			nil,
+		)
+	}
+
+	wsc.Lock()
+	defer wsc.Unlock()
+	if wsc.rawTemplateQuit == nil {
+		wsc.rawTemplateQuit = make(chan struct{})
+		wsc.wg.Add(1)
+		go wsc.rawBlockTemplateNotifier(wsc.rawTemplateQuit)
+	}
+	return nil, nil
+}
+
+// handleStopNotifyRawBlockTemplate implements the stopnotifyrawblocktemplate
+// command extension for websocket connections.
+func handleStopNotifyRawBlockTemplate(wsc *wsClient, icmd interface{}) (interface{}, er.R) {
+	wsc.Lock()
+	defer wsc.Unlock()
+	if wsc.rawTemplateQuit != nil {
+		close(wsc.rawTemplateQuit)
+		wsc.rawTemplateQuit = nil
+	}
+	return nil, nil
+}
+
+// rawBlockTemplateNotifier sends a rawblocktemplate notification to the client
+// with the current raw block template, and then with each new one, until quit
+// is closed or the client disconnects.  It must be run as a goroutine.
+func (c *wsClient) rawBlockTemplateNotifier(quit chan struct{}) {
+	defer c.wg.Done()
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-quit:
+		case <-c.quit:
+		}
+		close(done)
+	}()
+
+	longPollID := ""
+	for {
+		res, err := handleGetRawBlockTemplateLongPoll(c.server, longPollID, done)
+		if err != nil {
+			if ErrClientQuit.Is(err) {
+				return
+			}
+			log.Warnf("Unable to get raw block template for websocket "+
+				"client %s: %v", c.addr, err)
+			select {
+			case <-done:
+				return
+			case <-time.After(gbtRegenerateSeconds * time.Second):
+			}
+			continue
+		}
+		longPollID = res.LongPollID
+
+		marshalledJSON, err := btcjson.MarshalCmd(nil,
+			btcjson.NewRawBlockTemplateNtfn(*res))
+		if err != nil {
+			log.Errorf("Failed to marshal rawblocktemplate notification: "+
+				"%v", err)
+			return
+		}
+		if err := c.QueueNotification(marshalledJSON); ErrClientQuit.Is(err) {
+			return
+		}
+	}
+}
+
 // handleSession implements the session command extension for websocket
 // connections.
 func handleSession(wsc *wsClient, icmd interface{}) (interface{}, er.R) {
@@ -2190,6 +2278,9 @@ func handleRescanBlocks(wsc *wsClient, icmd interface{}) (interface{}, er.R) {
 	var lastBlockHash *chainhash.Hash
 	for i := range blockHashes {
 		block, err := bc.BlockByHash(blockHashes[i])
+		if database.ErrBlockPruned.Is(err) {
+			return nil, rpcPrunedBlockError()
+		}
 		if err != nil {
 			return nil, btcjson.NewRPCError(
 				btcjson.ErrRPCBlockNotFound,
@@ -2359,6 +2450,9 @@ fetchRange:
 	loopHashList:
 		for i := range hashList {
 			blk, err := chain.BlockByHash(&hashList[i])
+			if database.ErrBlockPruned.Is(err) {
+				return nil, nil, rpcPrunedBlockError()
+			}
 			if err != nil {
 				// Only handle reorgs if a block could not be
 				// found for the hash.
@@ -14,6 +14,8 @@ import (
 	"math"
 	mathrand "math/rand"
 	"net"
+	"net/http"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -23,8 +25,11 @@ import (
 	"time"
 
 	"github.com/pkt-cash/pktd/addrmgr"
+	"github.com/pkt-cash/pktd/annpool"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/bloom"
 	"github.com/pkt-cash/pktd/btcutil/er"
@@ -32,6 +37,7 @@ import (
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/connmgr"
 	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/lnd/tor"
 	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/mining/cpuminer"
@@ -42,6 +48,7 @@ import (
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
 	"github.com/pkt-cash/pktd/wire/protocol"
+	"github.com/pkt-cash/pktd/zmqpub"
 )
 
 const (
@@ -65,6 +72,22 @@ const (
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
+
+	// maxCmpctBlockDepth is the maximum depth from the tip of the chain of
+	// a block which is sent as a cmpctblock message when requested, deeper
+	// blocks are sent in full.
+	maxCmpctBlockDepth = 5
+
+	// maxBlockTxnDepth is the maximum depth from the tip of the chain of a
+	// block whose transactions are sent in response to a getblocktxn
+	// message, deeper blocks are sent in full.
+	maxBlockTxnDepth = 10
+
+	// maxKnownAnns is the maximum number of announcement hashes which are
+	// remembered as known to each peer.  The set is cleared when it would
+	// grow larger, which at worst causes some announcements to be
+	// advertised to the peer again.
+	maxKnownAnns = 1 << 17
 )
 
 // simpleAddr implements the net.Addr interface with two struct fields
@@ -107,8 +130,17 @@ type broadcastInventoryDel *wire.InvVect
 // relayMsg packages an inventory vector along with the newly discovered
 // inventory so the relay has access to that information.
 type relayMsg struct {
-	invVect *wire.InvVect
-	data    interface{}
+	invVect    *wire.InvVect
+	data       interface{}
+	cmpctBlock *wire.MsgCmpctBlock
+}
+
+// relayAnnsMsg packages the hashes of announcements which were added to the
+// announcement pool, to be advertised to the peers except the one they came
+// from, which is nil if they did not come from a peer.
+type relayAnnsMsg struct {
+	hashes []chainhash.Hash
+	source *serverPeer
 }
 
 // updatePeerHeightsMsg is a message sent from the blockmanager to the server
@@ -186,18 +218,26 @@ type server struct {
 	syncManager          *netsync.SyncManager
 	chain                *blockchain.BlockChain
 	txMemPool            *mempool.TxPool
+	annPool              *annpool.AnnPool
 	cpuMiner             *cpuminer.CPUMiner
+	zmqPublisher         *zmqpub.Publisher
+	poolServer           *poolServer
+	stratumServer        *stratumServer
+	metricsServer        *http.Server
 	modifyRebroadcastInv chan interface{}
 	newPeers             chan *serverPeer
 	donePeers            chan *serverPeer
 	banPeers             chan *serverPeer
 	query                chan interface{}
 	relayInv             chan relayMsg
+	relayAnns            chan relayAnnsMsg
 	broadcast            chan broadcastMsg
 	peerHeightsUpdate    chan updatePeerHeightsMsg
 	wg                   sync.WaitGroup
 	quit                 chan struct{}
 	nat                  NAT
+	torController        *tor.Controller
+	onionAddr            *tor.OnionAddr
 	db                   database.DB
 	timeSource           blockchain.MedianTimeSource
 	services             protocol.ServiceFlag
@@ -247,6 +287,8 @@ type serverPeer struct {
 	filter         *bloom.Filter
 	addressesMtx   sync.RWMutex
 	knownAddresses map[string]struct{}
+	annsMtx        sync.Mutex
+	knownAnns      map[chainhash.Hash]struct{}
 	banScore       connmgr.DynamicBanScore
 	quit           chan struct{}
 	// The following chans are used to sync blockmanager and server.
@@ -262,6 +304,7 @@ func newServerPeer(s *server, isPersistent bool) *serverPeer {
 		persistent:     isPersistent,
 		filter:         bloom.LoadFilter(nil),
 		knownAddresses: make(map[string]struct{}),
+		knownAnns:      make(map[chainhash.Hash]struct{}),
 		quit:           make(chan struct{}),
 		txProcessed:    make(chan struct{}, 1),
 		blockProcessed: make(chan struct{}, 1),
@@ -449,6 +492,12 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 // to kick start communication with them.
 func (sp *serverPeer) OnVerAck(_ *peer.Peer, _ *wire.MsgVerAck) {
 	sp.server.AddPeer(sp)
+
+	// Advertise the announcement pool to the peers which relay
+	// announcements.
+	if sp.wantsAnns() {
+		sp.pushAnnInv(sp.server.annPool.AnnHashes())
+	}
 }
 
 // OnMemPool is invoked when a peer receives a mempool bitcoin message.
@@ -552,6 +601,193 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	<-sp.blockProcessed
 }
 
+// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin message.
+// Like OnBlock, it blocks until the block has been reconstructed and processed
+// or the missing transactions have been requested.
+func (sp *serverPeer) OnCmpctBlock(_ *peer.Peer, msg *wire.MsgCmpctBlock) {
+	blockHash := msg.BlockHash()
+	iv := wire.NewInvVect(wire.InvTypeBlock, &blockHash)
+	sp.AddKnownInventory(iv)
+
+	sp.server.syncManager.QueueCmpctBlock(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin message.  It
+// blocks until the block which the transactions complete has been processed.
+func (sp *serverPeer) OnBlockTxn(_ *peer.Peer, msg *wire.MsgBlockTxn) {
+	sp.server.syncManager.QueueBlockTxn(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin message.
+// The requested transactions of a recent block are sent in a blocktxn message,
+// the full block is sent when it is too deep in the chain.
+func (sp *serverPeer) OnGetBlockTxn(_ *peer.Peer, msg *wire.MsgGetBlockTxn) {
+	chain := sp.server.chain
+	height, err := chain.BlockHeightByHash(&msg.BlockHash)
+	if err != nil {
+		log.Debugf("Unable to find block %v requested by getblocktxn "+
+			"from %s: %v", msg.BlockHash, sp, err)
+		return
+	}
+	if chain.BestSnapshot().Height-height > maxBlockTxnDepth {
+		err = sp.server.pushBlockMsg(sp, &msg.BlockHash, nil, nil,
+			wire.WitnessEncoding)
+		if err != nil {
+			log.Debugf("Unable to send block %v to %s: %v",
+				msg.BlockHash, sp, err)
+		}
+		return
+	}
+
+	block, err := chain.BlockByHash(&msg.BlockHash)
+	if err != nil {
+		log.Debugf("Unable to fetch block %v requested by getblocktxn "+
+			"from %s: %v", msg.BlockHash, sp, err)
+		return
+	}
+	txns := block.MsgBlock().Transactions
+	reply := wire.NewMsgBlockTxn(&msg.BlockHash,
+		make([]*wire.MsgTx, 0, len(msg.Indexes)))
+	for _, index := range msg.Indexes {
+		if int(index) >= len(txns) {
+			sp.addBanScore(100, 0, "getblocktxn index out of range")
+			sp.Disconnect()
+			return
+		}
+		reply.Transactions = append(reply.Transactions, txns[index])
+	}
+	sp.QueueMessageWithEncoding(reply, nil, wire.WitnessEncoding)
+}
+
+// wantsAnns returns whether announcements are relayed with the peer, which is
+// when the announcement pool is enabled and the peer advertises the
+// SFNodePacketCryptAnns service.
+func (sp *serverPeer) wantsAnns() bool {
+	return sp.server.annPool != nil &&
+		hasServices(sp.Services(), protocol.SFNodePacketCryptAnns)
+}
+
+// addKnownAnns adds the passed announcement hashes to the set of announcements
+// known to the peer, and returns those which were not known yet.
+func (sp *serverPeer) addKnownAnns(hashes []chainhash.Hash) []chainhash.Hash {
+	sp.annsMtx.Lock()
+	defer sp.annsMtx.Unlock()
+
+	if len(sp.knownAnns)+len(hashes) > maxKnownAnns {
+		sp.knownAnns = make(map[chainhash.Hash]struct{})
+	}
+	var unknown []chainhash.Hash
+	for _, hash := range hashes {
+		if _, ok := sp.knownAnns[hash]; !ok {
+			sp.knownAnns[hash] = struct{}{}
+			unknown = append(unknown, hash)
+		}
+	}
+	return unknown
+}
+
+// pushAnnInv advertises the passed announcements to the peer with anninv
+// messages, except those which are already known to it.
+func (sp *serverPeer) pushAnnInv(hashes []chainhash.Hash) {
+	hashes = sp.addKnownAnns(hashes)
+	for len(hashes) > 0 {
+		n := len(hashes)
+		if n > wire.MaxAnnInvPerMsg {
+			n = wire.MaxAnnInvPerMsg
+		}
+		sp.QueueMessage(wire.NewMsgAnnInv(hashes[:n]), nil)
+		hashes = hashes[n:]
+	}
+}
+
+// OnAnnInv is invoked when a peer receives an anninv pktd message.  The
+// advertised announcements which are not in the announcement pool are
+// requested with getanns messages.
+func (sp *serverPeer) OnAnnInv(_ *peer.Peer, msg *wire.MsgAnnInv) {
+	annPool := sp.server.annPool
+	if annPool == nil {
+		log.Debugf("Ignoring anninv from %v - announcement relay "+
+			"disabled", sp)
+		return
+	}
+
+	sp.addKnownAnns(msg.Hashes)
+	var missing []chainhash.Hash
+	for i := range msg.Hashes {
+		if !annPool.HaveAnn(&msg.Hashes[i]) {
+			missing = append(missing, msg.Hashes[i])
+		}
+	}
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > wire.MaxAnnsPerMsg {
+			n = wire.MaxAnnsPerMsg
+		}
+		sp.QueueMessage(wire.NewMsgGetAnns(missing[:n]), nil)
+		missing = missing[n:]
+	}
+}
+
+// OnGetAnns is invoked when a peer receives a getanns pktd message.  The
+// requested announcements which are still in the announcement pool are sent in
+// an anns message, which is sent even if it is empty so that the peer does not
+// wait for it.
+func (sp *serverPeer) OnGetAnns(_ *peer.Peer, msg *wire.MsgGetAnns) {
+	annPool := sp.server.annPool
+	if annPool == nil {
+		log.Debugf("Ignoring getanns from %v - announcement relay "+
+			"disabled", sp)
+		return
+	}
+
+	sp.addKnownAnns(msg.Hashes)
+	reply := wire.NewMsgAnns()
+	for i := range msg.Hashes {
+		if ann := annPool.FetchAnn(&msg.Hashes[i]); ann != nil {
+			reply.Anns = append(reply.Anns, ann)
+		}
+	}
+	sp.QueueMessage(reply, nil)
+}
+
+// OnAnns is invoked when a peer receives an anns pktd message.  It blocks
+// until the announcements have been validated, the valid ones are added to the
+// announcement pool and advertised to the other peers.
+func (sp *serverPeer) OnAnns(_ *peer.Peer, msg *wire.MsgAnns) {
+	annPool := sp.server.annPool
+	if annPool == nil || len(msg.Anns) == 0 {
+		return
+	}
+
+	hashes := make([]chainhash.Hash, len(msg.Anns))
+	for i, ann := range msg.Anns {
+		hashes[i] = annpool.AnnHash(ann)
+	}
+	sp.addKnownAnns(hashes)
+
+	accepted, errs := annPool.ProcessAnns(msg.Anns)
+	invalid := 0
+	for _, err := range errs {
+		// Announcements whose parent block is not known may come from
+		// a peer which is ahead of us, they are not held against it.
+		if announce.Err.Is(err) && !announce.ErrUnknownParent.Is(err) {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		log.Debugf("Rejected %d invalid announcements from %v", invalid,
+			sp)
+		sp.addBanScore(10, 0, "anns with invalid announcements")
+	}
+	if len(accepted) > 0 {
+		log.Debugf("Accepted %d announcements from %v", len(accepted),
+			sp)
+		sp.server.RelayAnns(accepted, sp)
+	}
+}
+
 // OnInv is invoked when a peer receives an inv bitcoin message and is
 // used to examine the inventory being advertised by the remote peer and react
 // accordingly.  We pass the message down to blockmanager which will call
@@ -637,6 +873,8 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
 		case wire.InvTypeBlock:
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
+		case wire.InvTypeCmpctBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, c, waitChan)
 		case wire.InvTypeFilteredWitnessBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
 		case wire.InvTypeFilteredBlock:
@@ -1226,6 +1464,24 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 // OnAddr is invoked when a peer receives an addr bitcoin message and is
 // used to notify the server about advertised addresses.
 func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
+	// Ignore old style addresses which don't include a timestamp.
+	if sp.ProtocolVersion() < protocol.NetAddressTimeVersion {
+		return
+	}
+
+	sp.addAddresses(msg, msg.AddrList)
+}
+
+// OnAddrV2 is invoked when a peer receives an addrv2 bitcoin message and is
+// used to notify the server about advertised addresses, including those on
+// networks which cannot be relayed with addr messages.
+func (sp *serverPeer) OnAddrV2(_ *peer.Peer, msg *wire.MsgAddrV2) {
+	sp.addAddresses(msg, msg.AddrList)
+}
+
+// addAddresses adds the addresses advertised by the peer in the passed addr or
+// addrv2 message to the known addresses of the peer and the address manager.
+func (sp *serverPeer) addAddresses(msg wire.Message, addrList []*wire.NetAddress) {
 	// Ignore addresses when running on the simulation test network.  This
 	// helps prevent the network from becoming another public test network
 	// since it will not be able to learn about other peers that have not
@@ -1234,18 +1490,13 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
-	// Ignore old style addresses which don't include a timestamp.
-	if sp.ProtocolVersion() < protocol.NetAddressTimeVersion {
-		return
-	}
-
 	// A message that has no addresses produces a warning.
-	if len(msg.AddrList) == 0 {
+	if len(addrList) == 0 {
 		log.Warnf("Command [%s] from %s does not contain any addresses",
 			msg.Command(), sp.Peer)
 	}
 
-	for _, na := range msg.AddrList {
+	for _, na := range addrList {
 		// Don't add more address if we're disconnecting.
 		if !sp.Connected() {
 			return
@@ -1268,7 +1519,7 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 	// addresses, and last seen updates.
 	// XXX bitcoind gives a 2 hour time penalty here, do we want to do the
 	// same?
-	sp.server.addrManager.AddAddresses(msg.AddrList, sp.NA())
+	sp.server.addrManager.AddAddresses(addrList, sp.NA())
 }
 
 // OnRead is invoked when a peer receives a message and it is used to update
@@ -1327,11 +1578,17 @@ func (s *server) RemoveRebroadcastInventory(iv *wire.InvVect) {
 }
 
 // relayTransactions generates and relays inventory vectors for all of the
-// passed transactions to all connected peers.
+// passed transactions to all connected peers and publishes them to ZMQ
+// subscribers.  Every transaction which is accepted to the mempool, from a
+// peer or from the RPC server, is relayed through here.
 func (s *server) relayTransactions(txns []*mempool.TxDesc) {
 	for _, txD := range txns {
 		iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
 		s.RelayInventory(iv, txD)
+
+		if s.zmqPublisher != nil {
+			s.zmqPublisher.NotifyTxAccepted(txD.Tx)
+		}
 	}
 }
 
@@ -1458,6 +1715,47 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 	return nil
 }
 
+// pushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer.  Blocks which are too deep in the chain for the peer to
+// be able to reconstruct them from its memory pool are sent in full.  An error
+// is returned if the block hash is not known.
+func (s *server) pushCmpctBlockMsg(sp *serverPeer, hash *chainhash.Hash,
+	doneChan chan<- struct{}, waitChan <-chan struct{}) er.R {
+
+	height, err := sp.server.chain.BlockHeightByHash(hash)
+	if err == nil && sp.server.chain.BestSnapshot().Height-height > maxCmpctBlockDepth {
+		return s.pushBlockMsg(sp, hash, doneChan, waitChan,
+			wire.WitnessEncoding)
+	}
+
+	blk, err := sp.server.chain.BlockByHash(hash)
+	if err != nil {
+		log.Tracef("Unable to fetch requested block hash %v: %v",
+			hash, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+
+	msg := wire.NewMsgCmpctBlock(blk.MsgBlock(), nonce)
+	sp.QueueMessageWithEncoding(msg, doneChan, wire.WitnessEncoding)
+	return nil
+}
+
 // pushMerkleBlockMsg sends a merkleblock message for the provided block hash to
 // the connected peer.  Since a merkle block requires the peer to have a filter
 // loaded, this call will simply be ignored if there is no filter loaded.  An
@@ -1702,18 +2000,29 @@ func (s *server) sendInvMsgToPeer(sp *serverPeer, msg relayMsg) bool {
 	if !sp.Connected() {
 		return false
 	}
+	// If the inventory is a block and the peer asked for blocks to be
+	// announced with compact blocks, send the compact block directly.
+	if msg.invVect.Type == wire.InvTypeBlock && msg.cmpctBlock != nil &&
+		sp.WantsCmpctBlocks() {
+
+		sp.AddKnownInventory(msg.invVect)
+		sp.QueueMessageWithEncoding(msg.cmpctBlock, nil,
+			wire.WitnessEncoding)
+		return false
+	}
+
 	// If the inventory is a block and the peer prefers headers,
 	// generate and send a headers message instead of an inventory
 	// message.
 	if msg.invVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
-		blockHeader, ok := msg.data.(wire.BlockHeader)
+		block, ok := msg.data.(*btcutil.Block)
 		if !ok {
 			log.Warnf("Underlying data for headers" +
-				" is not a block header")
+				" is not a block")
 			return false
 		}
 		msgHeaders := wire.NewMsgHeaders()
-		if err := msgHeaders.AddBlockHeader(&blockHeader); err != nil {
+		if err := msgHeaders.AddBlockHeader(&block.MsgBlock().Header); err != nil {
 			log.Errorf("Failed to add block"+
 				" header: %v", err)
 			return false
@@ -1763,11 +2072,34 @@ func (s *server) sendInvMsgToPeer(sp *serverPeer, msg relayMsg) bool {
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
 // known to have it.  It is invoked from the peerHandler goroutine.
 func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
+	// Build the compact block once for all of the peers which asked for
+	// new blocks to be announced with one.
+	if block, ok := msg.data.(*btcutil.Block); ok &&
+		msg.invVect.Type == wire.InvTypeBlock {
+
+		nonce, err := wire.RandomUint64()
+		if err == nil {
+			msg.cmpctBlock = wire.NewMsgCmpctBlock(block.MsgBlock(),
+				nonce)
+		}
+	}
+
 	state.forAllPeers(func(sp *serverPeer) {
 		s.sendInvMsgToPeer(sp, msg)
 	})
 }
 
+// handleRelayAnnsMsg deals with advertising announcements to the peers which
+// relay them.  It is invoked from the peerHandler goroutine.
+func (s *server) handleRelayAnnsMsg(state *peerState, msg relayAnnsMsg) {
+	state.forAllPeers(func(sp *serverPeer) {
+		if sp == msg.source || !sp.Connected() || !sp.wantsAnns() {
+			return
+		}
+		sp.pushAnnInv(msg.hashes)
+	})
+}
+
 // handleBroadcastMsg deals with broadcasting messages to peers.  It is invoked
 // from the peerHandler goroutine.
 func (s *server) handleBroadcastMsg(state *peerState, bmsg *broadcastMsg) {
@@ -1964,6 +2296,12 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnMemPool:      sp.OnMemPool,
 			OnTx:           sp.OnTx,
 			OnBlock:        sp.OnBlock,
+			OnCmpctBlock:   sp.OnCmpctBlock,
+			OnGetBlockTxn:  sp.OnGetBlockTxn,
+			OnBlockTxn:     sp.OnBlockTxn,
+			OnAnnInv:       sp.OnAnnInv,
+			OnGetAnns:      sp.OnGetAnns,
+			OnAnns:         sp.OnAnns,
 			OnInv:          sp.OnInv,
 			OnHeaders:      sp.OnHeaders,
 			OnGetData:      sp.OnGetData,
@@ -1978,6 +2316,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnFilterLoad:   sp.OnFilterLoad,
 			OnGetAddr:      sp.OnGetAddr,
 			OnAddr:         sp.OnAddr,
+			OnAddrV2:       sp.OnAddrV2,
 			OnRead:         sp.OnRead,
 			OnWrite:        sp.OnWrite,
 		},
@@ -2140,6 +2479,10 @@ out:
 		case invMsg := <-s.relayInv:
 			s.handleRelayInvMsg(state, invMsg)
 
+		// New announcements to be advertised to other peers.
+		case annsMsg := <-s.relayAnns:
+			s.handleRelayAnnsMsg(state, annsMsg)
+
 		// Message to broadcast to all connected peers except those
 		// which are excluded by the message.
 		case bmsg := <-s.broadcast:
@@ -2174,6 +2517,7 @@ cleanup:
 		case <-s.donePeers:
 		case <-s.peerHeightsUpdate:
 		case <-s.relayInv:
+		case <-s.relayAnns:
 		case <-s.broadcast:
 		case <-s.query:
 		default:
@@ -2200,6 +2544,13 @@ func (s *server) RelayInventory(invVect *wire.InvVect, data interface{}) {
 	s.relayInv <- relayMsg{invVect: invVect, data: data}
 }
 
+// RelayAnns advertises the passed announcements, which were added to the
+// announcement pool, to all connected peers which relay announcements except
+// the source peer, which may be nil.
+func (s *server) RelayAnns(hashes []chainhash.Hash, source *serverPeer) {
+	s.relayAnns <- relayAnnsMsg{hashes: hashes, source: source}
+}
+
 // BroadcastMessage sends msg to all peers currently connected to the server
 // except those in the passed peers to exclude.
 func (s *server) BroadcastMessage(msg wire.Message, exclPeers ...*serverPeer) {
@@ -2330,6 +2681,12 @@ func (s *server) Start() {
 		go s.upnpUpdateThread()
 	}
 
+	if cfg.TorControl != "" {
+		if err := s.startOnionService(); err != nil {
+			log.Warnf("Unable to create Tor hidden service: %v", err)
+		}
+	}
+
 	if !cfg.DisableRPC {
 		s.wg.Add(1)
 
@@ -2340,6 +2697,28 @@ func (s *server) Start() {
 		s.rpcServer.Start()
 	}
 
+	if s.zmqPublisher != nil {
+		s.zmqPublisher.Start()
+	}
+
+	if cfg.MetricsListen != "" {
+		if err := s.startMetricsServer(); err != nil {
+			log.Errorf("Unable to start metrics server: %v", err)
+		}
+	}
+
+	if s.poolServer != nil {
+		if err := s.poolServer.Start(); err != nil {
+			log.Errorf("Unable to start pool server: %v", err)
+		}
+	}
+
+	if s.stratumServer != nil {
+		if err := s.stratumServer.Start(); err != nil {
+			log.Errorf("Unable to start stratum server: %v", err)
+		}
+	}
+
 	// Start the CPU miner if generation is enabled.
 	if cfg.Generate {
 		s.cpuMiner.Start()
@@ -2360,11 +2739,40 @@ func (s *server) Stop() er.R {
 	// Stop the CPU miner if needed
 	s.cpuMiner.Stop()
 
+	if s.stratumServer != nil {
+		s.stratumServer.Stop()
+	}
+
 	// Shutdown the RPC server if it's not disabled.
 	if !cfg.DisableRPC {
 		s.rpcServer.Stop()
 	}
 
+	if s.zmqPublisher != nil {
+		s.zmqPublisher.Stop()
+	}
+
+	if s.metricsServer != nil {
+		s.metricsServer.Close()
+	}
+
+	if s.poolServer != nil {
+		s.poolServer.Stop()
+	}
+
+	// Closing the connection to the Tor control port removes the hidden
+	// service.
+	if s.torController != nil {
+		s.torController.Stop()
+	}
+
+	// Save the mempool so it can be reloaded on the next start.
+	if !cfg.NoPersistMempool {
+		if err := s.txMemPool.DumpFile(mempoolPersistPath()); err != nil {
+			log.Warnf("Unable to save mempool: %v", err)
+		}
+	}
+
 	// Save fee estimator state in the database.
 	s.db.Update(func(tx database.Tx) er.R {
 		metadata := tx.Metadata()
@@ -2383,6 +2791,12 @@ func (s *server) WaitForShutdown() {
 	s.wg.Wait()
 }
 
+// mempoolPersistPath returns the path of the file which holds the contents of
+// the mempool between restarts.
+func mempoolPersistPath() string {
+	return filepath.Join(cfg.DataDir, mempool.PersistFileName)
+}
+
 // parseListeners determines whether each listen address is IPv4 and IPv6 and
 // returns a slice of appropriate net.Addrs to listen on with TCP. It also
 // properly detects addresses which apply to "all interfaces" and adds the
@@ -2552,6 +2966,13 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.NoCFilters {
 		services &^= protocol.SFNodeCF
 	}
+	if cfg.Prune != 0 {
+		services &^= protocol.SFNodeNetwork
+		services |= protocol.SFNodeNetworkLimited
+	}
+	if cfg.RelayAnns {
+		services |= protocol.SFNodePacketCryptAnns
+	}
 
 	amgr := addrmgr.New(cfg.DataDir, pktdLookup)
 
@@ -2584,6 +3005,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
 		query:                make(chan interface{}),
 		relayInv:             make(chan relayMsg, cfg.MaxPeers),
+		relayAnns:            make(chan relayAnnsMsg, cfg.MaxPeers),
 		broadcast:            make(chan broadcastMsg, cfg.MaxPeers),
 		quit:                 make(chan struct{}),
 		modifyRebroadcastInv: make(chan interface{}),
@@ -2654,11 +3076,30 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		SigCache:     s.sigCache,
 		IndexManager: indexManager,
 		HashCache:    s.hashCache,
+		PruneDepth:   cfg.Prune,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Create the ZMQ publisher when any of its topics are enabled.
+	if cfg.ZMQPubRawBlock != "" || cfg.ZMQPubHashBlock != "" ||
+		cfg.ZMQPubRawTx != "" || cfg.ZMQPubHashTx != "" ||
+		cfg.ZMQPubSequence != "" {
+
+		s.zmqPublisher, err = zmqpub.New(&zmqpub.Config{
+			RawBlock:  cfg.ZMQPubRawBlock,
+			HashBlock: cfg.ZMQPubHashBlock,
+			RawTx:     cfg.ZMQPubRawTx,
+			HashTx:    cfg.ZMQPubHashTx,
+			Sequence:  cfg.ZMQPubSequence,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.chain.Subscribe(s.zmqPublisher.HandleBlockchainNotification)
+	}
+
 	// Search for a FeeEstimator state in the database. If none can be found
 	// or if it cannot be loaded, create a new one.
 	db.Update(func(tx database.Tx) er.R {
@@ -2714,8 +3155,25 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		AddrIndex:          s.addrIndex,
 		FeeEstimator:       s.feeEstimator,
 	}
+	if s.zmqPublisher != nil {
+		txC.TxRemoved = s.zmqPublisher.NotifyTxRemoved
+	}
 	s.txMemPool = mempool.New(&txC)
 
+	// Reload the transactions which were in the mempool when the server was
+	// last shut down.
+	if !cfg.NoPersistMempool {
+		stats, err := s.txMemPool.LoadFile(mempoolPersistPath(),
+			mempool.DefaultPersistExpiry)
+		if err != nil {
+			log.Warnf("Unable to load mempool: %v", err)
+		} else if stats.Accepted+stats.Expired+stats.Failed > 0 {
+			log.Infof("Loaded %d mempool transactions (%d expired, "+
+				"%d failed)", stats.Accepted, stats.Expired,
+				stats.Failed)
+		}
+	}
+
 	s.syncManager, err = netsync.New(&netsync.Config{
 		PeerNotifier:       &s,
 		Chain:              s.chain,
@@ -2757,13 +3215,49 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
 		s.sigCache, s.hashCache)
+	if cfg.PoolListen != "" {
+		s.poolServer = newPoolServer(&poolServerConfig{
+			ChainParams:  chainParams,
+			Chain:        s.chain,
+			Generator:    blockTemplateGenerator,
+			TimeSource:   s.timeSource,
+			Payouts:      cfg.miningPayouts,
+			ProcessBlock: s.syncManager.ProcessBlock,
+			AnnTarget:    cfg.PoolAnnTarget,
+			ShareTarget:  cfg.PoolShareTarget,
+		})
+	}
+	if cfg.RelayAnns {
+		s.annPool = annpool.New(&annpool.Config{
+			MaxAnns: cfg.MaxAnnPool,
+			BestHeight: func() int32 {
+				return s.chain.BestSnapshot().Height
+			},
+			BlockHashByHeight: s.chain.BlockHashByHeight,
+		})
+	}
+	var announcements func(height int32) []blockminer.Announcement
+	if s.poolServer != nil {
+		announcements = s.poolServer.announcements
+	} else if s.annPool != nil {
+		announcements = func(height int32) []blockminer.Announcement {
+			anns := s.annPool.AnnsForBlock(height)
+			out := make([]blockminer.Announcement, len(anns))
+			for i, ann := range anns {
+				out[i].Ann = ann
+			}
+			return out
+		}
+	}
 	s.cpuMiner = cpuminer.New(&cpuminer.Config{
 		ChainParams:            chainParams,
 		BlockTemplateGenerator: blockTemplateGenerator,
-		MiningAddrs:            cfg.miningAddrs,
+		Payouts:                cfg.miningPayouts,
 		ProcessBlock:           s.syncManager.ProcessBlock,
 		ConnectedCount:         s.ConnectedCount,
 		IsCurrent:              s.syncManager.IsCurrent,
+		BlockHashByHeight:      s.chain.BlockHashByHeight,
+		Announcements:          announcements,
 	})
 
 	// Only setup a function to return new addresses to connect to when
@@ -2805,6 +3299,16 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 					continue
 				}
 
+				// Skip addresses on networks which cannot be
+				// reached.  Onion services can only be reached
+				// through Tor and I2P is not supported.
+				na := addr.NetAddress()
+				if addrmgr.IsI2P(na) ||
+					(addrmgr.IsTorV3(na) && !onionEnabled()) {
+
+					continue
+				}
+
 				// Mark an attempt for the valid address.
 				s.addrManager.Attempt(addr.NetAddress())
 
@@ -2873,6 +3377,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			ChainParams:  chainParams,
 			DB:           db,
 			TxMemPool:    s.txMemPool,
+			AnnPool:      s.annPool,
 			Generator:    blockTemplateGenerator,
 			CPUMiner:     s.cpuMiner,
 			TxIndexOrNil: s.txIndex,
@@ -2890,6 +3395,16 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			<-s.rpcServer.RequestedProcessShutdown()
 			shutdownRequestChannel <- struct{}{}
 		}()
+
+		if cfg.StratumListen != "" {
+			s.stratumServer = newStratumServer(&stratumServerConfig{
+				RPCServer:    s.rpcServer,
+				ProcessBlock: s.syncManager.ProcessBlock,
+				ShareTarget:  cfg.StratumShareTarget,
+				MaxClients:   cfg.StratumMaxClients,
+				Password:     cfg.StratumPass,
+			})
+		}
 	}
 
 	return &s, nil
@@ -2975,7 +3490,8 @@ func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string, services pro
 
 // addrStringToNetAddr takes an address in the form of 'host:port'
 // and returns a net.Addr which maps to the original address with
-// any host names resolved to IP addresses.
+// any host names resolved to IP addresses.  Tor hidden service
+// addresses are not resolved.
 func addrStringToNetAddr(addr string) (net.Addr, er.R) {
 	host, strPort, errr := net.SplitHostPort(addr)
 	if errr != nil {
@@ -2987,6 +3503,15 @@ func addrStringToNetAddr(addr string) (net.Addr, er.R) {
 		return nil, er.E(errr)
 	}
 
+	// Tor hidden services can only be reached through the onion proxy.
+	if tor.IsOnionHost(host) {
+		if !onionEnabled() {
+			return nil, er.Errorf("tor has been disabled, unable "+
+				"to connect to %s", addr)
+		}
+		return &tor.OnionAddr{OnionService: host, Port: port}, nil
+	}
+
 	// Skip if host is already an IP address.
 	if ip := net.ParseIP(host); ip != nil {
 		return &net.TCPAddr{
@@ -0,0 +1,769 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/txscript/opcode"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// stratumExtraNonce1Size is the size of the extranonce which the
+	// server allocates to each connection.
+	stratumExtraNonce1Size = 4
+
+	// stratumExtraNonce2Size is the size of the extranonce which the miners
+	// roll.
+	stratumExtraNonce2Size = 4
+
+	// stratumExtraNonceSize is the size of both extranonces, which are
+	// pushed together at the end of the coinbase script.
+	stratumExtraNonceSize = stratumExtraNonce1Size + stratumExtraNonce2Size
+
+	// maxStratumJobs is the number of jobs which the stratum server keeps,
+	// shares for older jobs are rejected.
+	maxStratumJobs = 16
+
+	// maxStratumLineSize is the maximum size of a message from a miner, a
+	// share carries a PacketCrypt proof.
+	maxStratumLineSize = 1 << 20
+
+	// stratumWriteTimeoutSeconds is the number of seconds after which a
+	// connection which does not read its messages is dropped.
+	stratumWriteTimeoutSeconds = 10
+
+	// stratumJobQueueLen is the number of jobs which are queued for a
+	// connection, the oldest are dropped when the miner does not read them
+	// fast enough.
+	stratumJobQueueLen = 4
+
+	// stratumRetrySeconds is the number of seconds between attempts to
+	// make a job when the block template cannot be generated.
+	stratumRetrySeconds = 5
+)
+
+// The error codes of the stratum protocol.
+const (
+	stratumErrOther         = 20
+	stratumErrJobNotFound   = 21
+	stratumErrDuplicate     = 22
+	stratumErrLowDifficulty = 23
+	stratumErrUnauthorized  = 24
+	stratumErrNotSubscribed = 25
+)
+
+// stratumServerConfig is a descriptor containing the stratum server
+// configuration.
+type stratumServerConfig struct {
+	// RPCServer is the RPC server whose block template work state the jobs
+	// are made from.
+	RPCServer *rpcServer
+
+	// ProcessBlock is called with the shares which are good enough to be
+	// blocks.
+	ProcessBlock func(*btcutil.Block, blockchain.BehaviorFlags) (bool, er.R)
+
+	// ShareTarget is the easiest work target of the accepted shares, the
+	// miners can only ask for harder ones.
+	ShareTarget uint32
+
+	// MaxClients is the maximum number of connections, further connections
+	// are dropped.
+	MaxClients int
+
+	// Password is the password which the workers must authorize with, any
+	// password is accepted when it is empty.
+	Password string
+}
+
+// stratumRequest is a message from a miner.
+type stratumRequest struct {
+	ID     interface{}       `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// stratumResponse is the response to a stratumRequest, the error is an array
+// of its code, its message and a traceback which is always null.
+type stratumResponse struct {
+	ID     interface{} `json:"id"`
+	Result interface{} `json:"result"`
+	Error  interface{} `json:"error"`
+}
+
+// stratumNotification is a message which the server sends to a miner on its
+// own.
+type stratumNotification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stratumError is an error which is returned to a miner.
+type stratumError struct {
+	code int
+	msg  string
+}
+
+// stratumShareKey identifies a share, the same header may be submitted with
+// different PacketCrypt proofs.
+type stratumShareKey struct {
+	header   chainhash.Hash
+	pcpNonce uint32
+}
+
+// stratumJob is a block template served by the stratum server.  The coinbase
+// without its witness is coinb1, the extranonces and coinb2, its last output
+// is a placeholder PacketCrypt commitment which the miner replaces with its
+// own when it submits a share.
+type stratumJob struct {
+	id           string
+	height       int32
+	header       wire.BlockHeader
+	minTimestamp time.Time
+	coinb1       []byte
+	coinb2       []byte
+	witness      wire.TxWitness
+	transactions []*wire.MsgTx
+	merkleBranch []*chainhash.Hash
+	shares       map[stratumShareKey]struct{}
+}
+
+// stratumJobNotice is a job queued for a connection, or only its difficulty
+// when the job is nil.
+type stratumJobNotice struct {
+	job       *stratumJob
+	cleanJobs bool
+}
+
+// stratumConn is a connection of a miner.
+type stratumConn struct {
+	conn        net.Conn
+	writeLock   sync.Mutex
+	extraNonce1 []byte
+
+	// jobs are sent in order by the jobSender of the connection, which
+	// stops when done is closed.
+	jobs chan stratumJobNotice
+	done chan struct{}
+
+	// workers and afterResponse are only accessed by the goroutine which
+	// reads from the connection, subscribed and shareTarget are also read
+	// when jobs are sent, they are protected by the server lock.
+	workers       map[string]struct{}
+	afterResponse func()
+	subscribed    bool
+	shareTarget   uint32
+}
+
+// stratumServer serves the block template work state to miners with the
+// stratum protocol and submits the shares which are blocks.
+type stratumServer struct {
+	cfg      stratumServerConfig
+	listener net.Listener
+	quit     chan struct{}
+	wg       sync.WaitGroup
+
+	mtx             sync.Mutex
+	jobs            []*stratumJob
+	nextJobID       uint64
+	nextExtraNonce1 uint32
+	conns           map[*stratumConn]struct{}
+}
+
+// newStratumServer returns a stratum server for the passed configuration.
+func newStratumServer(cfg *stratumServerConfig) *stratumServer {
+	return &stratumServer{
+		cfg:   *cfg,
+		quit:  make(chan struct{}),
+		conns: make(map[*stratumConn]struct{}),
+	}
+}
+
+// stratumPrevHash returns the previous block hash as it is sent to miners,
+// which is the hash with the bytes of each of its 32 bit words reversed.
+func stratumPrevHash(hash *chainhash.Hash) string {
+	var b [chainhash.HashSize]byte
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.BigEndian.PutUint32(b[i:], binary.LittleEndian.Uint32(hash[i:]))
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// stratumUint32 returns the hex encoding of a 32 bit field of the header, as
+// it is sent to miners.
+func stratumUint32(v uint32) string {
+	return fmt.Sprintf("%08x", v)
+}
+
+// newStratumJob makes a job from the current block template of the work state.
+//
+// This function MUST be called with the work state locked.
+func newStratumJob(state *gbtWorkState) (*stratumJob, er.R) {
+	template := state.template
+	msgBlock := template.Block
+
+	// The extranonces are pushed at the end of the coinbase script.
+	coinbase := msgBlock.Transactions[0].Copy()
+	script := coinbase.TxIn[0].SignatureScript
+	script = append(script, opcode.OP_DATA_1-1+stratumExtraNonceSize)
+	script = append(script, make([]byte, stratumExtraNonceSize)...)
+	if len(script) > blockchain.MaxCoinbaseScriptLen {
+		return nil, er.Errorf("coinbase script of length [%d] has no room "+
+			"for the extranonces", len(coinbase.TxIn[0].SignatureScript))
+	}
+	coinbase.TxIn[0].SignatureScript = script
+	packetcrypt.InsertCoinbaseCommit(coinbase, wire.NewPcCoinbaseCommit())
+
+	var buf bytes.Buffer
+	if err := coinbase.SerializeNoWitness(&buf); err != nil {
+		return nil, err
+	}
+	// version, input count, previous outpoint, script length and script
+	extraNonceEnd := 4 + 1 + 36 + wire.VarIntSerializeSize(uint64(len(script))) +
+		len(script)
+	extraNonceStart := extraNonceEnd - stratumExtraNonceSize
+	b := buf.Bytes()
+
+	transactions := make([]*wire.MsgTx, len(msgBlock.Transactions))
+	copy(transactions, msgBlock.Transactions)
+	transactions[0] = coinbase
+	block := btcutil.NewBlock(&wire.MsgBlock{Transactions: transactions})
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+
+	return &stratumJob{
+		height:       template.Height,
+		header:       msgBlock.Header,
+		minTimestamp: state.minTimestamp,
+		coinb1:       append([]byte(nil), b[:extraNonceStart]...),
+		coinb2:       append([]byte(nil), b[extraNonceEnd:]...),
+		witness:      coinbase.TxIn[0].Witness,
+		transactions: transactions[1:],
+		merkleBranch: blockchain.GetMerkleBranch(0, merkles),
+		shares:       make(map[stratumShareKey]struct{}),
+	}, nil
+}
+
+// notifyParams returns the parameters of the mining.notify message of the job.
+func (j *stratumJob) notifyParams(cleanJobs bool) []interface{} {
+	branch := make([]string, 0, len(j.merkleBranch))
+	for _, hash := range j.merkleBranch {
+		branch = append(branch, hex.EncodeToString(hash[:]))
+	}
+	return []interface{}{
+		j.id,
+		stratumPrevHash(&j.header.PrevBlock),
+		hex.EncodeToString(j.coinb1),
+		hex.EncodeToString(j.coinb2),
+		branch,
+		stratumUint32(uint32(j.header.Version)),
+		stratumUint32(j.header.Bits),
+		stratumUint32(uint32(j.header.Timestamp.Unix())),
+		cleanJobs,
+	}
+}
+
+// shareDifficulty returns the difficulty of a share target, relative to the
+// easiest PacketCrypt target.
+func shareDifficulty(target uint32) float64 {
+	return getDifficultyRatio0(target, pcMinTargetBits)
+}
+
+// shareTargetForDifficulty returns the share target of the passed difficulty,
+// it is never easier than the configured share target.
+func (s *stratumServer) shareTargetForDifficulty(d float64) uint32 {
+	target, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(difficulty.CompactToBig(pcMinTargetBits)),
+		big.NewFloat(d)).Int(nil)
+	if target.Sign() <= 0 {
+		target.SetInt64(1)
+	}
+	if target.Cmp(difficulty.CompactToBig(s.cfg.ShareTarget)) > 0 {
+		return s.cfg.ShareTarget
+	}
+	return difficulty.BigToCompact(target)
+}
+
+// send writes a message to the connection.
+func (c *stratumConn) send(msg interface{}) {
+	b, errr := json.Marshal(msg)
+	if errr != nil {
+		log.Errorf("Failed to encode stratum message: %v", errr)
+		return
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second *
+		stratumWriteTimeoutSeconds))
+	if _, errr := c.conn.Write(append(b, '\n')); errr != nil {
+		log.Debugf("Stratum client %s: %v", c.conn.RemoteAddr(), errr)
+		c.conn.Close()
+	}
+}
+
+// queueJob queues a job to be sent to the connection.  When the queue is full
+// the oldest job is dropped, and a notice of the difficulty alone is not
+// needed since the queued jobs carry the difficulty.
+//
+// This function MUST be called with the server lock held, so that the jobs are
+// queued in order.
+func (c *stratumConn) queueJob(n stratumJobNotice) {
+	for {
+		select {
+		case c.jobs <- n:
+			return
+		default:
+		}
+		if n.job == nil {
+			return
+		}
+		select {
+		case old := <-c.jobs:
+			// The miner must still drop the jobs of the previous
+			// block when it gets the newer job.
+			n.cleanJobs = n.cleanJobs || old.cleanJobs
+		default:
+		}
+	}
+}
+
+// jobSender sends the queued jobs of a connection, each along with the current
+// difficulty of the shares it must submit.  It must be run as a goroutine.
+func (s *stratumServer) jobSender(c *stratumConn) {
+	defer s.wg.Done()
+	for {
+		select {
+		case n := <-c.jobs:
+			s.mtx.Lock()
+			shareTarget := c.shareTarget
+			s.mtx.Unlock()
+			c.send(&stratumNotification{
+				Method: "mining.set_difficulty",
+				Params: []interface{}{shareDifficulty(shareTarget)},
+			})
+			if n.job != nil {
+				c.send(&stratumNotification{
+					Method: "mining.notify",
+					Params: n.job.notifyParams(n.cleanJobs),
+				})
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// addJob makes the job the current one and sends it to the subscribed
+// connections.  The older jobs are dropped when the job is for a new block.
+func (s *stratumServer) addJob(j *stratumJob) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	cleanJobs := len(s.jobs) == 0 ||
+		s.jobs[len(s.jobs)-1].header.PrevBlock != j.header.PrevBlock
+	if cleanJobs {
+		s.jobs = nil
+	}
+	j.id = strconv.FormatUint(s.nextJobID, 16)
+	s.nextJobID++
+	s.jobs = append(s.jobs, j)
+	if len(s.jobs) > maxStratumJobs {
+		s.jobs = s.jobs[1:]
+	}
+	for c := range s.conns {
+		if c.subscribed {
+			c.queueJob(stratumJobNotice{job: j, cleanJobs: cleanJobs})
+		}
+	}
+}
+
+// jobHandler makes a new job whenever the block template of the work state is
+// stale.  It must be run as a goroutine.
+func (s *stratumServer) jobHandler() {
+	defer s.wg.Done()
+	state := s.cfg.RPCServer.gbtWorkState
+	for {
+		state.Lock()
+		var j *stratumJob
+		var update chan struct{}
+		err := state.updateBlockTemplate(s.cfg.RPCServer, false)
+		if err == nil {
+			j, err = newStratumJob(state)
+			update = state.templateUpdateChan(state.prevHash,
+				state.lastGenerated.Unix())
+		}
+		state.Unlock()
+
+		if err != nil {
+			log.Errorf("Failed to make stratum job: %v", err)
+			select {
+			case <-time.After(time.Second * stratumRetrySeconds):
+				continue
+			case <-s.quit:
+				return
+			}
+		}
+		s.addJob(j)
+
+		select {
+		case <-update:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// submit validates a share and submits it as a block if it is good enough.
+func (s *stratumServer) submit(c *stratumConn, shareTarget uint32, params []json.RawMessage) *stratumError {
+	var args [7]string
+	if len(params) != len(args) {
+		return &stratumError{stratumErrOther, "mining.submit takes the " +
+			"worker, job id, extranonce2, ntime, nonce, PacketCrypt proof " +
+			"and PacketCrypt commitment"}
+	}
+	for i := range args {
+		if errr := json.Unmarshal(params[i], &args[i]); errr != nil {
+			return &stratumError{stratumErrOther, "invalid parameter " +
+				strconv.Itoa(i)}
+		}
+	}
+	worker := args[0]
+	if _, ok := c.workers[worker]; !ok {
+		return &stratumError{stratumErrUnauthorized, "unauthorized worker"}
+	}
+	extraNonce2, errr := hex.DecodeString(args[2])
+	if errr != nil || len(extraNonce2) != stratumExtraNonce2Size {
+		return &stratumError{stratumErrOther, "invalid extranonce2"}
+	}
+	ntime, errr := strconv.ParseUint(args[3], 16, 32)
+	if errr != nil {
+		return &stratumError{stratumErrOther, "invalid ntime"}
+	}
+	nonce, errr := strconv.ParseUint(args[4], 16, 32)
+	if errr != nil {
+		return &stratumError{stratumErrOther, "invalid nonce"}
+	}
+	pcpBytes, errr := hex.DecodeString(args[5])
+	if errr != nil {
+		return &stratumError{stratumErrOther, "invalid PacketCrypt proof"}
+	}
+	pcp := &wire.PacketCryptProof{}
+	if err := pcp.BtcDecode(bytes.NewReader(pcpBytes), 0,
+		wire.WitnessEncoding); err != nil {
+		return &stratumError{stratumErrOther, "invalid PacketCrypt proof"}
+	}
+	commitBytes, errr := hex.DecodeString(args[6])
+	var commit wire.PcCoinbaseCommit
+	if errr != nil || len(commitBytes) != len(commit.Bytes) {
+		return &stratumError{stratumErrOther, "invalid PacketCrypt commitment"}
+	}
+	copy(commit.Bytes[:], commitBytes)
+	if commit.Magic() != wire.PcCoinbaseCommitMagic {
+		return &stratumError{stratumErrOther, "invalid PacketCrypt commitment"}
+	}
+
+	s.mtx.Lock()
+	var j *stratumJob
+	for _, job := range s.jobs {
+		if job.id == args[1] {
+			j = job
+		}
+	}
+	s.mtx.Unlock()
+	if j == nil {
+		return &stratumError{stratumErrJobNotFound, "job not found"}
+	}
+
+	// Rebuild the coinbase with the extranonces and the commitment of the
+	// miner, its commitment is the last output.
+	cb := make([]byte, 0, len(j.coinb1)+stratumExtraNonceSize+len(j.coinb2))
+	cb = append(cb, j.coinb1...)
+	cb = append(cb, c.extraNonce1...)
+	cb = append(cb, extraNonce2...)
+	cb = append(cb, j.coinb2...)
+	coinbase := &wire.MsgTx{}
+	if err := coinbase.DeserializeNoWitness(bytes.NewReader(cb)); err != nil {
+		return &stratumError{stratumErrOther, "invalid coinbase"}
+	}
+	commitScript := coinbase.TxOut[len(coinbase.TxOut)-1].PkScript
+	copy(commitScript[2:], commit.Bytes[:])
+	coinbase.TxIn[0].Witness = j.witness
+
+	header := j.header
+	header.MerkleRoot = coinbase.TxHash()
+	for _, hash := range j.merkleBranch {
+		header.MerkleRoot = chainhash.DoubleHashH(append(
+			header.MerkleRoot[:], hash[:]...))
+	}
+	header.Timestamp = time.Unix(int64(ntime), 0)
+	header.Nonce = uint32(nonce)
+	maxTimestamp := s.cfg.RPCServer.gbtWorkState.timeSource.AdjustedTime().Add(
+		time.Second * globalcfg.GetMaxTimeOffset())
+	if header.Timestamp.Before(j.minTimestamp) ||
+		header.Timestamp.After(maxTimestamp) {
+		return &stratumError{stratumErrOther, "ntime out of range"}
+	}
+
+	key := stratumShareKey{header: header.BlockHash(), pcpNonce: pcp.Nonce}
+	s.mtx.Lock()
+	_, dup := j.shares[key]
+	s.mtx.Unlock()
+	if dup {
+		return &stratumError{stratumErrDuplicate, "duplicate share"}
+	}
+
+	mb := wire.NewMsgBlock(&header)
+	mb.Transactions = append([]*wire.MsgTx{coinbase}, j.transactions...)
+	mb.Pcp = pcp
+	blockOk, err := checkPcShare(s.cfg.RPCServer, mb, j.merkleBranch, j.height,
+		shareTarget)
+	if err != nil {
+		return &stratumError{stratumErrLowDifficulty, err.Message()}
+	}
+
+	// The share is only recorded once it is valid, the same share may be
+	// submitted at the same time on several connections.
+	s.mtx.Lock()
+	_, dup = j.shares[key]
+	j.shares[key] = struct{}{}
+	s.mtx.Unlock()
+	if dup {
+		return &stratumError{stratumErrDuplicate, "duplicate share"}
+	}
+	if !blockOk {
+		return nil
+	}
+
+	block := btcutil.NewBlock(mb)
+	isOrphan, err := s.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil || isOrphan {
+		log.Infof("Stratum block %s from %s rejected: orphan %v, %v",
+			block.Hash(), worker, isOrphan, err)
+		return nil
+	}
+	log.Infof("Stratum block %s from %s accepted", block.Hash(), worker)
+	return nil
+}
+
+// handleRequest handles a request of a miner and returns the result.
+func (s *stratumServer) handleRequest(c *stratumConn, req *stratumRequest) (interface{}, *stratumError) {
+	switch req.Method {
+	case "mining.subscribe":
+		// The jobs are sent after the response, which carries the
+		// extranonce1 that the miner needs to work on them.
+		c.afterResponse = func() {
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+			c.subscribed = true
+			if len(s.jobs) > 0 {
+				c.queueJob(stratumJobNotice{
+					job:       s.jobs[len(s.jobs)-1],
+					cleanJobs: true,
+				})
+			}
+		}
+		en1 := hex.EncodeToString(c.extraNonce1)
+		return []interface{}{
+			[][]string{
+				{"mining.set_difficulty", en1},
+				{"mining.notify", en1},
+			},
+			en1,
+			stratumExtraNonce2Size,
+		}, nil
+
+	case "mining.authorize":
+		var worker string
+		if len(req.Params) == 0 ||
+			json.Unmarshal(req.Params[0], &worker) != nil || worker == "" {
+			return nil, &stratumError{stratumErrOther, "missing worker"}
+		}
+		if s.cfg.Password != "" {
+			var password string
+			if len(req.Params) < 2 ||
+				json.Unmarshal(req.Params[1], &password) != nil ||
+				subtle.ConstantTimeCompare([]byte(password),
+					[]byte(s.cfg.Password)) != 1 {
+				return nil, &stratumError{stratumErrUnauthorized,
+					"invalid password"}
+			}
+		}
+		c.workers[worker] = struct{}{}
+		c.conn.SetReadDeadline(time.Time{})
+		return true, nil
+
+	case "mining.extranonce.subscribe":
+		// The extranonce1 of a connection never changes.
+		return true, nil
+
+	case "mining.suggest_difficulty":
+		var d float64
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &d) != nil ||
+			!(d > 0) || math.IsInf(d, 0) {
+			return nil, &stratumError{stratumErrOther, "invalid difficulty"}
+		}
+		shareTarget := s.shareTargetForDifficulty(d)
+		s.mtx.Lock()
+		c.shareTarget = shareTarget
+		s.mtx.Unlock()
+		c.afterResponse = func() {
+			s.mtx.Lock()
+			c.queueJob(stratumJobNotice{})
+			s.mtx.Unlock()
+		}
+		return true, nil
+
+	case "mining.submit":
+		s.mtx.Lock()
+		subscribed := c.subscribed
+		shareTarget := c.shareTarget
+		s.mtx.Unlock()
+		if !subscribed {
+			return nil, &stratumError{stratumErrNotSubscribed,
+				"not subscribed"}
+		}
+		if err := s.submit(c, shareTarget, req.Params); err != nil {
+			return nil, err
+		}
+		return true, nil
+	}
+	return nil, &stratumError{stratumErrOther, "unknown method " + req.Method}
+}
+
+// handleConn reads the requests of a miner until the connection is closed.  It
+// must be run as a goroutine.
+func (s *stratumServer) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+
+	s.mtx.Lock()
+	if len(s.conns) >= s.cfg.MaxClients {
+		s.mtx.Unlock()
+		log.Infof("Max stratum clients exceeded [%d] - disconnecting "+
+			"client %s", s.cfg.MaxClients, conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+	extraNonce1 := make([]byte, stratumExtraNonce1Size)
+	binary.BigEndian.PutUint32(extraNonce1, s.nextExtraNonce1)
+	s.nextExtraNonce1++
+	c := &stratumConn{
+		conn:        conn,
+		extraNonce1: extraNonce1,
+		jobs:        make(chan stratumJobNotice, stratumJobQueueLen),
+		done:        make(chan struct{}),
+		workers:     make(map[string]struct{}),
+		shareTarget: s.cfg.ShareTarget,
+	}
+	s.conns[c] = struct{}{}
+	s.mtx.Unlock()
+	s.wg.Add(1)
+	go s.jobSender(c)
+	defer func() {
+		s.mtx.Lock()
+		delete(s.conns, c)
+		s.mtx.Unlock()
+		close(c.done)
+		conn.Close()
+	}()
+
+	// Miners which do not authorize in time are dropped.
+	conn.SetReadDeadline(time.Now().Add(time.Second * rpcAuthTimeoutSeconds))
+	log.Debugf("Stratum client %s connected", conn.RemoteAddr())
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxStratumLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var req stratumRequest
+		if errr := json.Unmarshal(line, &req); errr != nil {
+			log.Debugf("Stratum client %s: %v", conn.RemoteAddr(), errr)
+			return
+		}
+		result, err := s.handleRequest(c, &req)
+		res := &stratumResponse{ID: req.ID, Result: result}
+		if err != nil {
+			res.Error = []interface{}{err.code, err.msg, nil}
+		}
+		c.send(res)
+		if c.afterResponse != nil {
+			c.afterResponse()
+			c.afterResponse = nil
+		}
+	}
+	log.Debugf("Stratum client %s disconnected", conn.RemoteAddr())
+}
+
+// serve accepts the connections of miners from the listener until it is
+// closed.  It must be run as a goroutine.
+func (s *stratumServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, errr := s.listener.Accept()
+		if errr != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Errorf("Stratum server: %v", errr)
+			}
+			return
+		}
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// start starts serving the miners on the passed listener.
+func (s *stratumServer) start(listener net.Listener) {
+	s.listener = listener
+	log.Infof("Stratum server listening on %s", listener.Addr())
+	s.wg.Add(2)
+	go s.jobHandler()
+	go s.serve()
+}
+
+// Start starts serving on the address configured with --stratumlisten.
+func (s *stratumServer) Start() er.R {
+	listener, errr := net.Listen("tcp", cfg.StratumListen)
+	if errr != nil {
+		return er.E(errr)
+	}
+	s.start(listener)
+	return nil
+}
+
+// Stop stops the stratum server and disconnects the miners.
+func (s *stratumServer) Stop() {
+	if s.listener == nil {
+		return
+	}
+	close(s.quit)
+	s.listener.Close()
+	s.mtx.Lock()
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mtx.Unlock()
+	s.wg.Wait()
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/blockminer"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// stratumTestClient is a miner connected to a stratum server.
+type stratumTestClient struct {
+	t      *testing.T
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID int
+}
+
+// stratumTestMessage is any message from the stratum server.
+type stratumTestMessage struct {
+	ID     *int              `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  []interface{}     `json:"error"`
+}
+
+// read returns the next message from the server.
+func (c *stratumTestClient) read() *stratumTestMessage {
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	line, errr := c.r.ReadBytes('\n')
+	if errr != nil {
+		c.t.Fatalf("ReadBytes: %v", errr)
+	}
+	var msg stratumTestMessage
+	if errr := json.Unmarshal(line, &msg); errr != nil {
+		c.t.Fatalf("Unmarshal: %v: %s", errr, line)
+	}
+	return &msg
+}
+
+// call sends a request and returns its result, or the code of its error.
+func (c *stratumTestClient) call(method string, params ...interface{}) (json.RawMessage, int) {
+	c.nextID++
+	b, _ := json.Marshal(map[string]interface{}{
+		"id":     c.nextID,
+		"method": method,
+		"params": params,
+	})
+	if _, errr := c.conn.Write(append(b, '\n')); errr != nil {
+		c.t.Fatalf("Write: %v", errr)
+	}
+	for {
+		msg := c.read()
+		if msg.ID == nil || *msg.ID != c.nextID {
+			continue
+		}
+		if msg.Error != nil {
+			return nil, int(msg.Error[0].(float64))
+		}
+		return msg.Result, 0
+	}
+}
+
+// notification returns the next notification of the passed method.
+func (c *stratumTestClient) notification(method string) []json.RawMessage {
+	for {
+		msg := c.read()
+		if msg.ID == nil && msg.Method == method {
+			return msg.Params
+		}
+	}
+}
+
+// TestStratumServer ensures the stratum server sends jobs made from the block
+// template, accepts the shares mined on them and submits those which are
+// blocks.
+func TestStratumServer(t *testing.T) {
+	rs, _, teardown := newRESTTestServer(t)
+	defer teardown()
+
+	params := &chaincfg.SimNetParams
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	oldCfg := cfg
+	cfg = &config{miningPayouts: newTestPayouts(t, "", addr)}
+	defer func() { cfg = oldCfg }()
+
+	timeSource := blockchain.NewMedianTime()
+	rs.cfg.Generator = mining.NewBlkTmplGenerator(&mining.Policy{
+		BlockMaxWeight: defaultBlockMaxWeight,
+		BlockMaxSize:   defaultBlockMaxSize,
+	}, params, rs.cfg.TxMemPool, rs.cfg.Chain, timeSource,
+		txscript.NewSigCache(10), txscript.NewHashCache(10))
+	rs.gbtWorkState = newGbtWorkState(timeSource)
+
+	submitted := make(chan *btcutil.Block, 1)
+	s := newStratumServer(&stratumServerConfig{
+		RPCServer: rs,
+		ProcessBlock: func(b *btcutil.Block, _ blockchain.BehaviorFlags) (bool, er.R) {
+			submitted <- b
+			return false, nil
+		},
+		ShareTarget: defaultPoolTarget,
+		MaxClients:  1,
+		Password:    "x",
+	})
+	listener, errr := net.Listen("tcp", "127.0.0.1:0")
+	if errr != nil {
+		t.Fatalf("Listen: %v", errr)
+	}
+	s.start(listener)
+	defer s.Stop()
+
+	conn, errr := net.Dial("tcp", listener.Addr().String())
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	defer conn.Close()
+	c := &stratumTestClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	// Shares are only accepted from subscribed and authorized workers.
+	if _, code := c.call("mining.submit", "w", "0", "00000000", "00000000",
+		"00000000", "", ""); code != stratumErrNotSubscribed {
+		t.Fatalf("mining.submit: error %d before subscribing", code)
+	}
+	res, code := c.call("mining.subscribe")
+	if code != 0 {
+		t.Fatalf("mining.subscribe: error %d", code)
+	}
+	var sub []json.RawMessage
+	var extraNonce1 string
+	if errr := json.Unmarshal(res, &sub); errr != nil || len(sub) != 3 ||
+		json.Unmarshal(sub[1], &extraNonce1) != nil {
+		t.Fatalf("mining.subscribe: unexpected result %s", res)
+	}
+	var diff float64
+	json.Unmarshal(c.notification("mining.set_difficulty")[0], &diff)
+	if diff != 1 {
+		t.Fatalf("mining.set_difficulty: %v", diff)
+	}
+	notify := c.notification("mining.notify")
+	if len(notify) != 9 {
+		t.Fatalf("mining.notify: unexpected params %v", notify)
+	}
+
+	// Further connections are dropped.
+	conn2, errr := net.Dial("tcp", listener.Addr().String())
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	defer conn2.Close()
+	conn2.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if _, errr := conn2.Read(make([]byte, 1)); errr != io.EOF {
+		t.Fatalf("Read: %v past the maximum number of clients", errr)
+	}
+
+	// Workers must authorize with the password.
+	if _, code := c.call("mining.authorize", "w", "y"); code != stratumErrUnauthorized {
+		t.Fatalf("mining.authorize: error %d with a wrong password", code)
+	}
+	if _, code := c.call("mining.authorize", "w"); code != stratumErrUnauthorized {
+		t.Fatalf("mining.authorize: error %d without a password", code)
+	}
+	if _, code := c.call("mining.authorize", "w", "x"); code != 0 {
+		t.Fatalf("mining.authorize: error %d", code)
+	}
+
+	var jobID, prevHash, coinb1, coinb2, version, bits, ntime string
+	var branch []string
+	for i, v := range []interface{}{&jobID, &prevHash, &coinb1, &coinb2,
+		&branch, &version, &bits, &ntime} {
+		if errr := json.Unmarshal(notify[i], v); errr != nil {
+			t.Fatalf("mining.notify: param %d: %v", i, errr)
+		}
+	}
+
+	// Build the block of the job the way a miner does.
+	parse := func(s string) uint32 {
+		v, errr := strconv.ParseUint(s, 16, 32)
+		if errr != nil {
+			t.Fatalf("ParseUint: %v", errr)
+		}
+		return uint32(v)
+	}
+	prevHashBytes, _ := hex.DecodeString(prevHash)
+	header := wire.BlockHeader{
+		Version:   int32(parse(version)),
+		Bits:      parse(bits),
+		Timestamp: time.Unix(int64(parse(ntime)), 0),
+	}
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.LittleEndian.PutUint32(header.PrevBlock[i:],
+			binary.BigEndian.Uint32(prevHashBytes[i:]))
+	}
+	if header.PrevBlock != rs.cfg.Chain.BestSnapshot().Hash {
+		t.Fatalf("mining.notify: unexpected previous block %s", header.PrevBlock)
+	}
+	const extraNonce2 = "01020304"
+	cb, _ := hex.DecodeString(coinb1 + extraNonce1 + extraNonce2 + coinb2)
+	coinbase := &wire.MsgTx{}
+	if err := coinbase.DeserializeNoWitness(bytes.NewReader(cb)); err != nil {
+		t.Fatalf("DeserializeNoWitness: %v", err)
+	}
+	height, err := blockchain.ExtractCoinbaseHeight(btcutil.NewTx(coinbase))
+	if err != nil || height != 2 {
+		t.Fatalf("ExtractCoinbaseHeight: %v %v", height, err)
+	}
+
+	var anns []blockminer.Announcement
+	for _, ann := range mineTestPcAnns(t, 0, 8) {
+		anns = append(anns, blockminer.Announcement{Ann: ann})
+	}
+	bm, err := blockminer.New(anns, height, 1)
+	if err != nil {
+		t.Fatalf("blockminer.New: %v", err)
+	}
+	bm.InsertCommit(coinbase)
+	header.MerkleRoot = coinbase.TxHash()
+	for _, h := range branch {
+		hash, _ := hex.DecodeString(h)
+		header.MerkleRoot = chainhash.DoubleHashH(append(
+			header.MerkleRoot[:], hash...))
+	}
+	mb := wire.NewMsgBlock(&header)
+	for {
+		ok, err := bm.Mine(mb, nil)
+		if err != nil {
+			t.Fatalf("Mine: %v", err)
+		}
+		if ok {
+			break
+		}
+		mb.Header.Nonce++
+	}
+	var pcpBuf bytes.Buffer
+	if err := mb.Pcp.Serialize(&pcpBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	commit := bm.Commit()
+	share := []interface{}{"w", jobID, extraNonce2, ntime,
+		stratumUint32(mb.Header.Nonce), hex.EncodeToString(pcpBuf.Bytes()),
+		hex.EncodeToString(commit.Bytes[:])}
+
+	withParam := func(i int, v string) []interface{} {
+		out := append([]interface{}(nil), share...)
+		out[i] = v
+		return out
+	}
+	for i, test := range []struct {
+		params []interface{}
+		code   int
+	}{
+		{withParam(0, "other"), stratumErrUnauthorized},
+		{withParam(1, "ffff"), stratumErrJobNotFound},
+		{withParam(2, "0102"), stratumErrOther},
+		{withParam(2, "01020305"), stratumErrLowDifficulty},
+		{withParam(4, stratumUint32(mb.Header.Nonce+1)), stratumErrLowDifficulty},
+		{withParam(3, "00000000"), stratumErrOther},
+	} {
+		if _, code := c.call("mining.submit", test.params...); code != test.code {
+			t.Fatalf("mining.submit #%d: error %d, want %d", i, code, test.code)
+		}
+	}
+
+	// The share meets the block target so it is submitted as a block.
+	if res, code := c.call("mining.submit", share...); code != 0 ||
+		string(res) != "true" {
+		t.Fatalf("mining.submit: %s, error %d", res, code)
+	}
+	select {
+	case b := <-submitted:
+		if b.MsgBlock().Header.BlockHash() != mb.Header.BlockHash() ||
+			len(b.Transactions()) != 1 {
+			t.Fatalf("mining.submit: unexpected block submitted")
+		}
+	default:
+		t.Fatalf("mining.submit: no block submitted")
+	}
+	if _, code := c.call("mining.submit", share...); code != stratumErrDuplicate {
+		t.Fatalf("mining.submit: error %d for a duplicate share", code)
+	}
+
+	// Miners can only ask for harder shares than the configured target.
+	if _, code := c.call("mining.suggest_difficulty", 0.5); code != 0 {
+		t.Fatalf("mining.suggest_difficulty: error %d", code)
+	}
+	json.Unmarshal(c.notification("mining.set_difficulty")[0], &diff)
+	if diff != 1 {
+		t.Fatalf("mining.set_difficulty: %v for an easier difficulty", diff)
+	}
+	if _, code := c.call("mining.suggest_difficulty", 1e6); code != 0 {
+		t.Fatalf("mining.suggest_difficulty: error %d", code)
+	}
+	json.Unmarshal(c.notification("mining.set_difficulty")[0], &diff)
+	if diff < 0.99e6 || diff > 1.01e6 {
+		t.Fatalf("mining.set_difficulty: %v, want 1e6", diff)
+	}
+
+	// Jobs which are made quickly are sent in order, each after the
+	// difficulty of its shares.
+	s.mtx.Lock()
+	last := s.jobs[len(s.jobs)-1]
+	s.mtx.Unlock()
+	for i := 0; i < 10; i++ {
+		j := *last
+		s.addJob(&j)
+	}
+	s.mtx.Lock()
+	lastID := s.jobs[len(s.jobs)-1].id
+	s.mtx.Unlock()
+	prevID, haveDifficulty := int64(-1), false
+	for {
+		msg := c.read()
+		if msg.Method == "mining.set_difficulty" {
+			haveDifficulty = true
+			continue
+		}
+		if msg.Method != "mining.notify" {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		if !haveDifficulty {
+			t.Fatalf("mining.notify: not after mining.set_difficulty")
+		}
+		haveDifficulty = false
+		var id string
+		json.Unmarshal(msg.Params[0], &id)
+		n, errr := strconv.ParseInt(id, 16, 64)
+		if errr != nil || n <= prevID {
+			t.Fatalf("mining.notify: job %s after job %x", id, prevID)
+		}
+		prevID = n
+		if id == lastID {
+			break
+		}
+	}
+}
+
+// TestStratumJobQueue ensures the jobs queued for a connection keep their
+// order, and that the oldest jobs are dropped when the queue is full without
+// losing the request to drop the jobs of the previous block.
+func TestStratumJobQueue(t *testing.T) {
+	c := &stratumConn{jobs: make(chan stratumJobNotice, stratumJobQueueLen)}
+	var jobs []*stratumJob
+	for i := 0; i < stratumJobQueueLen+2; i++ {
+		j := &stratumJob{id: strconv.Itoa(i)}
+		jobs = append(jobs, j)
+		c.queueJob(stratumJobNotice{job: j, cleanJobs: i == 1})
+	}
+
+	// The queued jobs carry the difficulty, so a notice of the difficulty
+	// alone is not queued when the queue is full.
+	c.queueJob(stratumJobNotice{})
+
+	for _, j := range jobs[2:] {
+		n := <-c.jobs
+		if n.job != j {
+			t.Fatalf("job %s queued, want %s", n.job.id, j.id)
+		}
+		if n.cleanJobs != (j == jobs[len(jobs)-1]) {
+			t.Fatalf("job %s: clean jobs %v", j.id, n.cleanJobs)
+		}
+	}
+	select {
+	case n := <-c.jobs:
+		t.Fatalf("unexpected notice %+v", n)
+	default:
+	}
+}
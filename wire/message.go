@@ -59,6 +59,15 @@ const (
 	CmdCFilter      = "cfilter"
 	CmdCFHeaders    = "cfheaders"
 	CmdCFCheckpt    = "cfcheckpt"
+	CmdSendAddrV2   = "sendaddrv2"
+	CmdAddrV2       = "addrv2"
+	CmdSendCmpct    = "sendcmpct"
+	CmdCmpctBlock   = "cmpctblock"
+	CmdGetBlockTxn  = "getblocktxn"
+	CmdBlockTxn     = "blocktxn"
+	CmdAnnInv       = "anninv"
+	CmdGetAnns      = "getanns"
+	CmdAnns         = "anns"
 )
 
 // MessageEncoding represents the wire message encoding format to be used.
@@ -179,6 +188,33 @@ func makeEmptyMessage(command string) (Message, er.R) {
 	case CmdSendHeaders:
 		msg = &MsgSendHeaders{}
 
+	case CmdSendAddrV2:
+		msg = &MsgSendAddrV2{}
+
+	case CmdAddrV2:
+		msg = &MsgAddrV2{}
+
+	case CmdSendCmpct:
+		msg = &MsgSendCmpct{}
+
+	case CmdCmpctBlock:
+		msg = &MsgCmpctBlock{}
+
+	case CmdGetBlockTxn:
+		msg = &MsgGetBlockTxn{}
+
+	case CmdBlockTxn:
+		msg = &MsgBlockTxn{}
+
+	case CmdAnnInv:
+		msg = &MsgAnnInv{}
+
+	case CmdGetAnns:
+		msg = &MsgGetAnns{}
+
+	case CmdAnns:
+		msg = &MsgAnns{}
+
 	case CmdFeeFilter:
 		msg = &MsgFeeFilter{}
 
@@ -0,0 +1,171 @@
+package wire
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkt-cash/pktd/wire/protocol"
+)
+
+// TestAddrV2Wire tests the MsgAddrV2 wire encode and decode for addresses on
+// each of the supported networks.
+func TestAddrV2Wire(t *testing.T) {
+	pver := protocol.ProtocolVersion
+	enc := BaseEncoding
+
+	torV3, err := NewNetAddressHost(
+		"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion",
+		8333, protocol.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("NewNetAddressHost: %v", err)
+	}
+	i2p, err := NewNetAddressHost(
+		"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p",
+		0, protocol.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("NewNetAddressHost: %v", err)
+	}
+
+	ts := time.Unix(0x495fab29, 0)
+	addrs := []*NetAddress{
+		{Timestamp: ts, Services: protocol.SFNodeNetwork,
+			IP: net.ParseIP("127.0.0.1"), Port: 8333},
+		{Timestamp: ts, Services: protocol.SFNodeNetwork,
+			IP: net.ParseIP("2001:db8::1"), Port: 8333},
+		{Timestamp: ts, Services: protocol.SFNodeNetwork,
+			IP:   net.ParseIP("fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa"),
+			Port: 8333},
+		{Timestamp: ts, Services: torV3.Services, Port: torV3.Port,
+			Network: torV3.Network, Addr: torV3.Addr},
+		{Timestamp: ts, Services: i2p.Services, Port: i2p.Port,
+			Network: i2p.Network, Addr: i2p.Addr},
+	}
+	wantNetworks := []NetworkID{NetIPv4, NetIPv6, NetCJDNS, NetTorV3, NetI2P}
+
+	msg := NewMsgAddrV2()
+	if err := msg.AddAddresses(addrs...); err != nil {
+		t.Fatalf("AddAddresses: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, enc); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+
+	// Append an address on an unknown network which must be skipped and
+	// adjust the count accordingly.
+	encoded := buf.Bytes()
+	encoded[0]++
+	encoded = append(encoded,
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01,                // Services
+		0x2a,                // Unknown network
+		0x03, 0x1, 0x2, 0x3, // Address
+		0x20, 0x8d, // Port 8333 in big-endian
+	)
+
+	var readmsg MsgAddrV2
+	if err := readmsg.BtcDecode(bytes.NewReader(encoded), pver, enc); err != nil {
+		t.Fatalf("BtcDecode: %v", err)
+	}
+	if len(readmsg.AddrList) != len(addrs) {
+		t.Fatalf("BtcDecode: got %d addresses, want %d",
+			len(readmsg.AddrList), len(addrs))
+	}
+	for i, na := range readmsg.AddrList {
+		if got := na.NetworkID(); got != wantNetworks[i] {
+			t.Errorf("BtcDecode #%d: wrong network - got %v, want %v",
+				i, got, wantNetworks[i])
+		}
+		if na.Host() != addrs[i].Host() || na.Port != addrs[i].Port ||
+			na.Services != addrs[i].Services ||
+			!na.Timestamp.Equal(addrs[i].Timestamp) {
+
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(na), spew.Sdump(addrs[i]))
+		}
+	}
+
+	// The host names must survive a round trip.
+	for i, na := range addrs {
+		host := na.Host()
+		parsed, err := NewNetAddressHost(host, na.Port, na.Services)
+		if err != nil {
+			t.Errorf("NewNetAddressHost #%d: %v", i, err)
+			continue
+		}
+		if parsed.Host() != host || parsed.NetworkID() != wantNetworks[i] {
+			t.Errorf("NewNetAddressHost #%d: got %s on %v, want %s "+
+				"on %v", i, parsed.Host(), parsed.NetworkID(),
+				host, wantNetworks[i])
+		}
+	}
+}
+
+// TestAddrV2WireErrors performs negative tests against the addrv2 and
+// sendaddrv2 messages and the parsing of address host names.
+func TestAddrV2WireErrors(t *testing.T) {
+	pver := protocol.ProtocolVersion
+	oldPver := protocol.AddrV2Version - 1
+	enc := BaseEncoding
+
+	// Both messages require the addrv2 protocol version.
+	for _, msg := range []Message{NewMsgAddrV2(), NewMsgSendAddrV2()} {
+		var buf bytes.Buffer
+		if err := msg.BtcEncode(&buf, oldPver, enc); err == nil {
+			t.Errorf("BtcEncode of %s passed for old protocol "+
+				"version", msg.Command())
+		}
+		if err := msg.BtcEncode(&buf, pver, enc); err != nil {
+			t.Errorf("BtcEncode of %s: %v", msg.Command(), err)
+		}
+		if err := msg.BtcDecode(&buf, oldPver, enc); err == nil {
+			t.Errorf("BtcDecode of %s passed for old protocol "+
+				"version", msg.Command())
+		}
+	}
+
+	// A known network with the wrong address length is rejected.
+	invalid := []byte{
+		0x01,                   // Count
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01,                // Services
+		byte(NetIPv4),       // Network
+		0x03, 0x1, 0x2, 0x3, // Address
+		0x20, 0x8d, // Port
+	}
+	var readmsg MsgAddrV2
+	if err := readmsg.BtcDecode(bytes.NewReader(invalid), pver, enc); err == nil {
+		t.Errorf("BtcDecode passed with invalid address length")
+	}
+
+	// Addresses must be IPs, onion or I2P names and onion names must have a
+	// valid checksum.
+	for _, host := range []string{
+		"example.com",
+		"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryc.onion",
+		"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnk.b32.i2p",
+	} {
+		if _, err := NewNetAddressHost(host, 0, 0); err == nil {
+			t.Errorf("NewNetAddressHost passed for %s", host)
+		}
+	}
+
+	// Ensure the sendaddrv2 message is as expected.
+	msg := NewMsgSendAddrV2()
+	if cmd := msg.Command(); cmd != CmdSendAddrV2 {
+		t.Errorf("NewMsgSendAddrV2: wrong command - got %v want %v",
+			cmd, CmdSendAddrV2)
+	}
+	if got := msg.MaxPayloadLength(pver); got != 0 {
+		t.Errorf("MaxPayloadLength: wrong max payload length - got "+
+			"%v, want 0", got)
+	}
+	if !reflect.DeepEqual(msg, &MsgSendAddrV2{}) {
+		t.Errorf("NewMsgSendAddrV2: unexpected message %v", msg)
+	}
+}
@@ -0,0 +1,103 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// MaxAnnInvPerMsg is the maximum number of announcement hashes that can be in
+// a single anninv message.
+const MaxAnnInvPerMsg = 50000
+
+// MsgAnnInv implements the Message interface and represents a pktd anninv
+// message.  It is used to advertise the PacketCrypt announcements which a peer
+// has in its announcement pool, by their hashes.  The announcements can then
+// be requested with a getanns message.
+//
+// This message is only sent to peers which advertise the
+// SFNodePacketCryptAnns service flag.
+type MsgAnnInv struct {
+	Hashes []chainhash.Hash
+}
+
+// readAnnHashes reads a list of at most max announcement hashes.
+func readAnnHashes(r io.Reader, pver uint32, max uint64, cmd string) ([]chainhash.Hash, er.R) {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return nil, err
+	}
+	if count > max {
+		str := fmt.Sprintf("too many announcement hashes for message "+
+			"[count %d, max %d]", count, max)
+		return nil, messageError(cmd, str)
+	}
+
+	hashes := make([]chainhash.Hash, count)
+	for i := range hashes {
+		if err := readElement(r, &hashes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// writeAnnHashes writes a list of at most max announcement hashes.
+func writeAnnHashes(w io.Writer, pver uint32, hashes []chainhash.Hash, max int, cmd string) er.R {
+	if len(hashes) > max {
+		str := fmt.Sprintf("too many announcement hashes for message "+
+			"[count %d, max %d]", len(hashes), max)
+		return messageError(cmd, str)
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(hashes))); err != nil {
+		return err
+	}
+	for i := range hashes {
+		if err := writeElement(w, &hashes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgAnnInv) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) er.R {
+	hashes, err := readAnnHashes(r, pver, MaxAnnInvPerMsg, "MsgAnnInv.BtcDecode")
+	if err != nil {
+		return err
+	}
+	msg.Hashes = hashes
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgAnnInv) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) er.R {
+	return writeAnnHashes(w, pver, msg.Hashes, MaxAnnInvPerMsg,
+		"MsgAnnInv.BtcEncode")
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgAnnInv) Command() string {
+	return CmdAnnInv
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgAnnInv) MaxPayloadLength(pver uint32) uint32 {
+	// Num hashes (varInt) + max allowed hashes.
+	return MaxVarIntPayload + MaxAnnInvPerMsg*chainhash.HashSize
+}
+
+// NewMsgAnnInv returns a new pktd anninv message that conforms to the Message
+// interface using the passed announcement hashes.  See MsgAnnInv for details.
+func NewMsgAnnInv(hashes []chainhash.Hash) *MsgAnnInv {
+	return &MsgAnnInv{
+		Hashes: hashes,
+	}
+}
@@ -0,0 +1,100 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// MaxAnnsPerMsg is the maximum number of PacketCrypt announcements that can be
+// requested with a getanns message, and sent in an anns message.
+const MaxAnnsPerMsg = 4096
+
+// MsgAnns implements the Message interface and represents a pktd anns message.
+// It is used to deliver a batch of PacketCrypt announcements, in reply to a
+// getanns message.
+//
+// This message is only sent to peers which advertise the
+// SFNodePacketCryptAnns service flag.
+type MsgAnns struct {
+	Anns []*PacketCryptAnn
+}
+
+// AddAnn adds an announcement to the message.
+func (msg *MsgAnns) AddAnn(ann *PacketCryptAnn) er.R {
+	if len(msg.Anns)+1 > MaxAnnsPerMsg {
+		str := fmt.Sprintf("too many announcements in message [max %v]",
+			MaxAnnsPerMsg)
+		return messageError("MsgAnns.AddAnn", str)
+	}
+
+	msg.Anns = append(msg.Anns, ann)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgAnns) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) er.R {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > MaxAnnsPerMsg {
+		str := fmt.Sprintf("too many announcements for message "+
+			"[count %d, max %d]", count, MaxAnnsPerMsg)
+		return messageError("MsgAnns.BtcDecode", str)
+	}
+
+	// Use a contiguous slice of announcements to avoid a separate
+	// allocation for each one.
+	anns := make([]PacketCryptAnn, count)
+	msg.Anns = make([]*PacketCryptAnn, 0, count)
+	for i := range anns {
+		if err := anns[i].BtcDecode(r, pver, enc); err != nil {
+			return err
+		}
+		msg.Anns = append(msg.Anns, &anns[i])
+	}
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgAnns) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) er.R {
+	count := len(msg.Anns)
+	if count > MaxAnnsPerMsg {
+		str := fmt.Sprintf("too many announcements for message "+
+			"[count %d, max %d]", count, MaxAnnsPerMsg)
+		return messageError("MsgAnns.BtcEncode", str)
+	}
+
+	if err := WriteVarInt(w, pver, uint64(count)); err != nil {
+		return err
+	}
+	for _, ann := range msg.Anns {
+		if err := ann.BtcEncode(w, pver, enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgAnns) Command() string {
+	return CmdAnns
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgAnns) MaxPayloadLength(pver uint32) uint32 {
+	// Num announcements (varInt) + max allowed announcements.
+	return MaxVarIntPayload + MaxAnnsPerMsg*PcAnnSerializeSize
+}
+
+// NewMsgAnns returns a new pktd anns message that conforms to the Message
+// interface.  See MsgAnns for details.
+func NewMsgAnns() *MsgAnns {
+	return &MsgAnns{}
+}
@@ -0,0 +1,97 @@
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire/protocol"
+)
+
+// TestAnnsWire tests the wire encode and decode of the PacketCrypt
+// announcement relay messages.
+func TestAnnsWire(t *testing.T) {
+	pver := protocol.ProtocolVersion
+
+	hashes := []chainhash.Hash{{0x01}, {0x02}, {0x03}}
+	anns := NewMsgAnns()
+	for i := 0; i < 2; i++ {
+		ann := &PacketCryptAnn{}
+		for j := range ann.Header {
+			ann.Header[j] = byte(i + j)
+		}
+		if err := anns.AddAnn(ann); err != nil {
+			t.Fatalf("AddAnn: %v", err)
+		}
+	}
+
+	tests := []struct {
+		in  Message
+		out Message
+	}{
+		{NewMsgAnnInv(hashes), &MsgAnnInv{}},
+		{NewMsgAnnInv([]chainhash.Hash{}), &MsgAnnInv{}},
+		{NewMsgGetAnns(hashes), &MsgGetAnns{}},
+		{anns, &MsgAnns{}},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := test.in.BtcEncode(&buf, pver, BaseEncoding); err != nil {
+			t.Errorf("BtcEncode #%d (%s) error %v", i,
+				test.in.Command(), err)
+			continue
+		}
+		if uint32(buf.Len()) > test.in.MaxPayloadLength(pver) {
+			t.Errorf("BtcEncode #%d (%s) payload of %d bytes exceeds "+
+				"max", i, test.in.Command(), buf.Len())
+		}
+		if err := test.out.BtcDecode(&buf, pver, BaseEncoding); err != nil {
+			t.Errorf("BtcDecode #%d (%s) error %v", i,
+				test.in.Command(), err)
+			continue
+		}
+		if !reflect.DeepEqual(test.out, test.in) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(test.out), spew.Sdump(test.in))
+		}
+	}
+}
+
+// TestAnnsWireLimits ensures the announcement relay messages with too many
+// entries are rejected.
+func TestAnnsWireLimits(t *testing.T) {
+	pver := protocol.ProtocolVersion
+
+	var buf bytes.Buffer
+	tooMany := make([]chainhash.Hash, MaxAnnsPerMsg+1)
+	if err := NewMsgGetAnns(tooMany).BtcEncode(&buf, pver, BaseEncoding); err == nil {
+		t.Errorf("MsgGetAnns.BtcEncode succeeded with too many hashes")
+	}
+	if err := NewMsgAnnInv(tooMany).BtcEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("MsgAnnInv.BtcEncode: %v", err)
+	}
+	if err := (&MsgGetAnns{}).BtcDecode(&buf, pver, BaseEncoding); err == nil {
+		t.Errorf("MsgGetAnns.BtcDecode succeeded with too many hashes")
+	}
+
+	anns := NewMsgAnns()
+	for i := 0; i < MaxAnnsPerMsg; i++ {
+		if err := anns.AddAnn(&PacketCryptAnn{}); err != nil {
+			t.Fatalf("AddAnn #%d: %v", i, err)
+		}
+	}
+	if err := anns.AddAnn(&PacketCryptAnn{}); err == nil {
+		t.Errorf("AddAnn succeeded with too many announcements")
+	}
+
+	buf.Reset()
+	if err := WriteVarInt(&buf, pver, MaxAnnsPerMsg+1); err != nil {
+		t.Fatalf("WriteVarInt: %v", err)
+	}
+	if err := (&MsgAnns{}).BtcDecode(&buf, pver, BaseEncoding); err == nil {
+		t.Errorf("MsgAnns.BtcDecode succeeded with too many announcements")
+	}
+}
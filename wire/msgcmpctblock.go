@@ -0,0 +1,268 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/aead/siphash"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/wire/protocol"
+)
+
+// ShortIDLen is the length in bytes of the short transaction IDs in a
+// cmpctblock message.
+const ShortIDLen = 6
+
+// PrefilledTx is a transaction which is sent in full within a cmpctblock
+// message, along with its index in the block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctBlock implements the Message interface and represents a bitcoin
+// cmpctblock message.  It is used to relay a block using short IDs of its
+// transactions, which the receiver is expected to find in its memory pool,
+// rather than the transactions themselves (BIP0152).  The PacketCrypt proof is
+// sent alongside the header since it is needed to validate the block and
+// cannot be reconstructed.
+//
+// Transactions which the receiver is unlikely to have, such as the coinbase,
+// are sent in full as PrefilledTxs.  The indexes of the prefilled transactions
+// are absolute, the differential encoding used on the wire is handled when the
+// message is encoded and decoded.
+//
+// This message was not added until protocol versions starting with
+// ShortIDsBlocksVersion.
+type MsgCmpctBlock struct {
+	Header       BlockHeader
+	Pcp          *PacketCryptProof
+	Nonce        uint64
+	ShortIDs     []uint64
+	PrefilledTxs []*PrefilledTx
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) er.R {
+	if pver < protocol.ShortIDsBlocksVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	if enc&NoPacketCryptEncoding == NoPacketCryptEncoding {
+	} else if enc&PacketCryptEncoding == PacketCryptEncoding ||
+		globalcfg.GetProofOfWorkAlgorithm() == globalcfg.PowPacketCrypt {
+		if msg.Pcp == nil {
+			msg.Pcp = &PacketCryptProof{}
+		}
+		if err = msg.Pcp.BtcDecode(r, pver, enc); err != nil {
+			return err
+		}
+	}
+
+	if err := readElement(r, &msg.Nonce); err != nil {
+		return err
+	}
+
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many short IDs to fit into a block "+
+			"[count %d, max %d]", count, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+	msg.ShortIDs = make([]uint64, count)
+	var buf [8]byte
+	for i := range msg.ShortIDs {
+		if _, errr := io.ReadFull(r, buf[:ShortIDLen]); errr != nil {
+			return er.E(errr)
+		}
+		msg.ShortIDs[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+
+	count, err = ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count+uint64(len(msg.ShortIDs)) > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", count+uint64(len(msg.ShortIDs)),
+			maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+	msg.PrefilledTxs = make([]*PrefilledTx, 0, count)
+	next := uint64(0)
+	for i := uint64(0); i < count; i++ {
+		diff, err := ReadVarInt(r, pver)
+		if err != nil {
+			return err
+		}
+		index := next + diff
+		if index > maxTxPerBlock {
+			str := fmt.Sprintf("prefilled transaction index %d out "+
+				"of range", index)
+			return messageError("MsgCmpctBlock.BtcDecode", str)
+		}
+		next = index + 1
+
+		tx := MsgTx{}
+		if err := tx.BtcDecode(r, pver, enc); err != nil {
+			return err
+		}
+		msg.PrefilledTxs = append(msg.PrefilledTxs, &PrefilledTx{
+			Index: uint32(index),
+			Tx:    &tx,
+		})
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) er.R {
+	if pver < protocol.ShortIDsBlocksVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcEncode", str)
+	}
+
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	if enc&NoPacketCryptEncoding == NoPacketCryptEncoding {
+	} else if enc&PacketCryptEncoding == PacketCryptEncoding ||
+		globalcfg.GetProofOfWorkAlgorithm() == globalcfg.PowPacketCrypt {
+		if msg.Pcp == nil {
+			return er.Errorf("proof of work is not defined")
+		}
+		if err = msg.Pcp.BtcEncode(w, pver, enc); err != nil {
+			return err
+		}
+	}
+
+	if err := writeElement(w, msg.Nonce); err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.ShortIDs)))
+	if err != nil {
+		return err
+	}
+	var buf [8]byte
+	for _, id := range msg.ShortIDs {
+		binary.LittleEndian.PutUint64(buf[:], id)
+		if _, errr := w.Write(buf[:ShortIDLen]); errr != nil {
+			return er.E(errr)
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.PrefilledTxs)))
+	if err != nil {
+		return err
+	}
+	next := uint32(0)
+	for _, ptx := range msg.PrefilledTxs {
+		if ptx.Index < next {
+			str := fmt.Sprintf("prefilled transaction index %d is "+
+				"not in ascending order", ptx.Index)
+			return messageError("MsgCmpctBlock.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(ptx.Index-next))
+		if err != nil {
+			return err
+		}
+		next = ptx.Index + 1
+
+		if err := ptx.Tx.BtcEncode(w, pver, enc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCmpctBlock) Command() string {
+	return CmdCmpctBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) MaxPayloadLength(pver uint32) uint32 {
+	// A compact block can never be larger than the block it describes.
+	return MaxBlockPayload
+}
+
+// BlockHash computes the block identifier hash for the block described by this
+// message.
+func (msg *MsgCmpctBlock) BlockHash() chainhash.Hash {
+	return msg.Header.BlockHash()
+}
+
+// TxCount returns the number of transactions in the block described by this
+// message.
+func (msg *MsgCmpctBlock) TxCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxs)
+}
+
+// ShortIDKey returns the SipHash key used to compute the short transaction IDs
+// of the message, which is the first 16 bytes of the SHA256 of the block header
+// followed by the nonce.
+func (msg *MsgCmpctBlock) ShortIDKey() [16]byte {
+	var buf bytes.Buffer
+	buf.Grow(blockHeaderLen + 8)
+	_ = writeBlockHeader(&buf, 0, &msg.Header)
+	_ = writeElement(&buf, msg.Nonce)
+
+	var key [16]byte
+	copy(key[:], chainhash.HashB(buf.Bytes()))
+	return key
+}
+
+// ShortTxID returns the short ID of the transaction with the passed witness
+// hash for the passed key, which is the SipHash-2-4 of the hash truncated to
+// ShortIDLen bytes.
+func ShortTxID(key *[16]byte, wtxid *chainhash.Hash) uint64 {
+	return siphash.Sum64(wtxid[:], key) & (1<<(ShortIDLen*8) - 1)
+}
+
+// NewMsgCmpctBlock returns a new bitcoin cmpctblock message that conforms to
+// the Message interface and describes the passed block.  The coinbase is sent
+// in full and every other transaction is sent as a short ID computed with the
+// passed nonce.  See MsgCmpctBlock for details.
+func NewMsgCmpctBlock(block *MsgBlock, nonce uint64) *MsgCmpctBlock {
+	msg := &MsgCmpctBlock{
+		Header: block.Header,
+		Pcp:    block.Pcp,
+		Nonce:  nonce,
+	}
+	if len(block.Transactions) == 0 {
+		return msg
+	}
+
+	msg.PrefilledTxs = []*PrefilledTx{{Index: 0, Tx: block.Transactions[0]}}
+	msg.ShortIDs = make([]uint64, 0, len(block.Transactions)-1)
+	key := msg.ShortIDKey()
+	for _, tx := range block.Transactions[1:] {
+		wtxid := tx.WitnessHash()
+		msg.ShortIDs = append(msg.ShortIDs, ShortTxID(&key, &wtxid))
+	}
+	return msg
+}
@@ -0,0 +1,130 @@
+package wire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkt-cash/pktd/wire/protocol"
+)
+
+// TestCmpctBlockWire tests the wire encode and decode of the compact block
+// relay messages (BIP0152) including the PacketCrypt proof which is sent
+// alongside the short IDs.
+func TestCmpctBlockWire(t *testing.T) {
+	pver := protocol.ProtocolVersion
+
+	block := blockOne
+	block.Transactions = []*MsgTx{blockOne.Transactions[0], multiTx, multiWitnessTx}
+	block.Pcp = &PacketCryptProof{Nonce: 42, AnnProof: []byte{0x01, 0x02}}
+
+	blockHash := block.BlockHash()
+	cmpct := NewMsgCmpctBlock(&block, 0x0102030405060708)
+	cmpct.PrefilledTxs = append(cmpct.PrefilledTxs,
+		&PrefilledTx{Index: 2, Tx: multiWitnessTx})
+	cmpct.ShortIDs = cmpct.ShortIDs[:1]
+
+	tests := []struct {
+		in  Message
+		out Message
+		enc MessageEncoding
+	}{
+		{NewMsgSendCmpct(true, CmpctBlockVersion), &MsgSendCmpct{}, BaseEncoding},
+		{cmpct, &MsgCmpctBlock{}, WitnessEncoding | PacketCryptEncoding},
+		{NewMsgGetBlockTxn(&blockHash, []uint32{1, 2, 5, 6}),
+			&MsgGetBlockTxn{}, BaseEncoding},
+		{NewMsgBlockTxn(&blockHash, []*MsgTx{multiTx, multiWitnessTx}),
+			&MsgBlockTxn{}, WitnessEncoding},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := test.in.BtcEncode(&buf, pver, test.enc); err != nil {
+			t.Errorf("BtcEncode #%d (%s) error %v", i,
+				test.in.Command(), err)
+			continue
+		}
+		if uint32(buf.Len()) > test.in.MaxPayloadLength(pver) {
+			t.Errorf("BtcEncode #%d (%s) payload of %d bytes exceeds "+
+				"max", i, test.in.Command(), buf.Len())
+		}
+		encoded := append([]byte{}, buf.Bytes()...)
+		if err := test.out.BtcDecode(&buf, pver, test.enc); err != nil {
+			t.Errorf("BtcDecode #%d (%s) error %v", i,
+				test.in.Command(), err)
+			continue
+		}
+
+		// Transactions without witness data may decode with empty
+		// rather than nil witnesses, so the decoded message is compared
+		// by encoding it again.
+		var reencoded bytes.Buffer
+		if err := test.out.BtcEncode(&reencoded, pver, test.enc); err != nil {
+			t.Errorf("BtcEncode #%d (%s) error %v", i,
+				test.in.Command(), err)
+			continue
+		}
+		if !bytes.Equal(reencoded.Bytes(), encoded) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(test.out), spew.Sdump(test.in))
+		}
+
+		// The messages are only valid from the compact blocks protocol
+		// version.
+		buf.Reset()
+		err := test.in.BtcEncode(&buf, protocol.FeeFilterVersion, test.enc)
+		if err == nil {
+			t.Errorf("BtcEncode #%d (%s) succeeded for old protocol "+
+				"version", i, test.in.Command())
+		}
+	}
+}
+
+// TestCmpctBlockShortIDs ensures that the short IDs of a compact block are
+// computed from the witness hashes of the transactions with a key which
+// depends on the nonce.
+func TestCmpctBlockShortIDs(t *testing.T) {
+	block := blockOne
+	block.Transactions = []*MsgTx{blockOne.Transactions[0], multiTx, multiWitnessTx}
+
+	msg := NewMsgCmpctBlock(&block, 1)
+	if msg.TxCount() != len(block.Transactions) {
+		t.Fatalf("TxCount: got %d, want %d", msg.TxCount(),
+			len(block.Transactions))
+	}
+	if len(msg.PrefilledTxs) != 1 || msg.PrefilledTxs[0].Index != 0 ||
+		msg.PrefilledTxs[0].Tx != block.Transactions[0] {
+
+		t.Fatalf("NewMsgCmpctBlock: coinbase is not prefilled")
+	}
+
+	key := msg.ShortIDKey()
+	for i, tx := range block.Transactions[1:] {
+		wtxid := tx.WitnessHash()
+		id := ShortTxID(&key, &wtxid)
+		if id>>(ShortIDLen*8) != 0 {
+			t.Errorf("ShortTxID #%d: %x is longer than %d bytes", i,
+				id, ShortIDLen)
+		}
+		if msg.ShortIDs[i] != id {
+			t.Errorf("NewMsgCmpctBlock #%d: got short ID %x, want %x",
+				i, msg.ShortIDs[i], id)
+		}
+	}
+
+	other := NewMsgCmpctBlock(&block, 2)
+	if other.ShortIDKey() == key {
+		t.Errorf("ShortIDKey does not depend on the nonce")
+	}
+
+	// The indexes of prefilled transactions must be in ascending order.
+	msg.PrefilledTxs = append(msg.PrefilledTxs,
+		&PrefilledTx{Index: 0, Tx: multiTx})
+	var buf bytes.Buffer
+	err := msg.BtcEncode(&buf, protocol.ProtocolVersion, WitnessEncoding|
+		NoPacketCryptEncoding)
+	if err == nil {
+		t.Errorf("BtcEncode succeeded with unordered prefilled " +
+			"transactions")
+	}
+}
@@ -0,0 +1,59 @@
+package wire
+
+import (
+	"io"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// MsgGetAnns implements the Message interface and represents a pktd getanns
+// message.  It is used to request the PacketCrypt announcements, which were
+// advertised with an anninv message, by their hashes.  The peer replies with
+// an anns message which has those of the announcements it still has.
+//
+// This message is only sent to peers which advertise the
+// SFNodePacketCryptAnns service flag.
+type MsgGetAnns struct {
+	Hashes []chainhash.Hash
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetAnns) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) er.R {
+	hashes, err := readAnnHashes(r, pver, MaxAnnsPerMsg, "MsgGetAnns.BtcDecode")
+	if err != nil {
+		return err
+	}
+	msg.Hashes = hashes
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetAnns) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) er.R {
+	return writeAnnHashes(w, pver, msg.Hashes, MaxAnnsPerMsg,
+		"MsgGetAnns.BtcEncode")
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetAnns) Command() string {
+	return CmdGetAnns
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetAnns) MaxPayloadLength(pver uint32) uint32 {
+	// Num hashes (varInt) + max allowed hashes.
+	return MaxVarIntPayload + MaxAnnsPerMsg*chainhash.HashSize
+}
+
+// NewMsgGetAnns returns a new pktd getanns message that conforms to the
+// Message interface using the passed announcement hashes.  See MsgGetAnns for
+// details.
+func NewMsgGetAnns(hashes []chainhash.Hash) *MsgGetAnns {
+	return &MsgGetAnns{
+		Hashes: hashes,
+	}
+}
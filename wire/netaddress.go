@@ -47,6 +47,13 @@ type NetAddress struct {
 	// Port the peer is using.  This is encoded in big endian on the wire
 	// which differs from most everything else.
 	Port uint16
+
+	// Network and Addr hold the address of a peer on a network which does
+	// not use IP addresses, such as Tor v3 and I2P, in which case IP is
+	// nil.  Such addresses can only be relayed with addrv2 messages
+	// (BIP0155).  Network is zero for IP addresses.
+	Network NetworkID
+	Addr    []byte
 }
 
 // HasService returns whether the specified service is supported by the address.
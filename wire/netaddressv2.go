@@ -0,0 +1,293 @@
+package wire
+
+import (
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/wire/protocol"
+	"golang.org/x/crypto/sha3"
+)
+
+// NetworkID identifies the network of an address as encoded in an addrv2
+// message (BIP0155).
+type NetworkID uint8
+
+const (
+	// NetIPv4 is the network ID of IPv4 addresses.
+	NetIPv4 NetworkID = 1
+
+	// NetIPv6 is the network ID of IPv6 addresses.
+	NetIPv6 NetworkID = 2
+
+	// NetTorV2 is the network ID of Tor v2 onion service addresses.
+	NetTorV2 NetworkID = 3
+
+	// NetTorV3 is the network ID of Tor v3 onion service addresses.
+	NetTorV3 NetworkID = 4
+
+	// NetI2P is the network ID of I2P addresses.
+	NetI2P NetworkID = 5
+
+	// NetCJDNS is the network ID of CJDNS addresses.
+	NetCJDNS NetworkID = 6
+)
+
+// Map of network IDs back to their names for pretty printing.
+var networkIDStrings = map[NetworkID]string{
+	NetIPv4:  "ipv4",
+	NetIPv6:  "ipv6",
+	NetTorV2: "torv2",
+	NetTorV3: "torv3",
+	NetI2P:   "i2p",
+	NetCJDNS: "cjdns",
+}
+
+// String returns the NetworkID in human-readable form.
+func (n NetworkID) String() string {
+	if s, ok := networkIDStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetworkID (%d)", uint8(n))
+}
+
+// addrV2Sizes maps each known network to the length of its addresses.
+var addrV2Sizes = map[NetworkID]int{
+	NetIPv4:  net.IPv4len,
+	NetIPv6:  net.IPv6len,
+	NetTorV2: 10,
+	NetTorV3: 32,
+	NetI2P:   32,
+	NetCJDNS: net.IPv6len,
+}
+
+// MaxAddrV2Size is the maximum length of an address in an addrv2 message.
+const MaxAddrV2Size = 512
+
+const (
+	// onionSuffix is the suffix of Tor onion service host names.
+	onionSuffix = ".onion"
+
+	// i2pSuffix is the suffix of I2P host names.
+	i2pSuffix = ".b32.i2p"
+
+	// torV3Version is the version byte included in Tor v3 onion service
+	// host names.
+	torV3Version = 0x03
+)
+
+var (
+	// addrBase32 is the base32 encoding used by Tor and I2P host names.
+	addrBase32 = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").
+			WithPadding(base32.NoPadding)
+
+	// onionCatNet is the IPv6 range used to embed Tor v2 onion service
+	// addresses (OnionCat).
+	onionCatNet = net.IPNet{
+		IP:   net.ParseIP("fd87:d87e:eb43::"),
+		Mask: net.CIDRMask(48, 128),
+	}
+
+	// cjdnsNet is the IPv6 range used by CJDNS.
+	cjdnsNet = net.IPNet{
+		IP:   net.ParseIP("fc00::"),
+		Mask: net.CIDRMask(8, 128),
+	}
+)
+
+// NetworkID returns the network of the address.  Addresses with an IP are
+// classified by their range and the rest use the Network field.
+func (na *NetAddress) NetworkID() NetworkID {
+	switch {
+	case na.IP == nil:
+		return na.Network
+	case na.IP.To4() != nil:
+		return NetIPv4
+	case onionCatNet.Contains(na.IP):
+		return NetTorV2
+	case cjdnsNet.Contains(na.IP):
+		return NetCJDNS
+	}
+	return NetIPv6
+}
+
+// addrV2Bytes returns the network and address bytes used to encode the address
+// in an addrv2 message.
+func (na *NetAddress) addrV2Bytes() (NetworkID, []byte) {
+	network := na.NetworkID()
+	switch network {
+	case NetIPv4:
+		return network, na.IP.To4()
+	case NetTorV2:
+		return network, na.IP.To16()[6:]
+	case NetIPv6, NetCJDNS:
+		return network, na.IP.To16()
+	}
+	return network, na.Addr
+}
+
+// torV3Checksum returns the checksum which is included in the host name of the
+// Tor v3 onion service with the passed public key.
+func torV3Checksum(pubKey []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pubKey)
+	h.Write([]byte{torV3Version})
+	return h.Sum(nil)[:2]
+}
+
+// Host returns the host part of the address as used in address strings.  This
+// is the IP address for IP networks, the onion service name for Tor v3 and the
+// base32 name for I2P.
+func (na *NetAddress) Host() string {
+	switch na.NetworkID() {
+	case NetTorV3:
+		b := make([]byte, 0, len(na.Addr)+3)
+		b = append(b, na.Addr...)
+		b = append(b, torV3Checksum(na.Addr)...)
+		b = append(b, torV3Version)
+		return addrBase32.EncodeToString(b) + onionSuffix
+	case NetI2P:
+		return addrBase32.EncodeToString(na.Addr) + i2pSuffix
+	}
+	return na.IP.String()
+}
+
+// NewNetAddressHost returns a new NetAddress using the provided host, port, and
+// supported services with defaults for the remaining fields.  The host may be
+// an IP address, a Tor v3 onion service name or an I2P base32 name.  Host names
+// which must be resolved are not accepted.
+func NewNetAddressHost(host string, port uint16, services protocol.ServiceFlag) (*NetAddress, er.R) {
+	if ip := net.ParseIP(host); ip != nil {
+		return NewNetAddressIPPort(ip, port, services), nil
+	}
+
+	na := NewNetAddressIPPort(nil, port, services)
+	lower := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lower, onionSuffix):
+		b, errr := addrBase32.DecodeString(
+			strings.TrimSuffix(lower, onionSuffix))
+		if errr != nil || len(b) != addrV2Sizes[NetTorV3]+3 {
+			return nil, er.Errorf("invalid onion address %s", host)
+		}
+		pubKey := b[:addrV2Sizes[NetTorV3]]
+		checksum := b[len(pubKey) : len(pubKey)+2]
+		if b[len(b)-1] != torV3Version ||
+			string(checksum) != string(torV3Checksum(pubKey)) {
+
+			return nil, er.Errorf("invalid onion address %s", host)
+		}
+		na.Network = NetTorV3
+		na.Addr = pubKey
+
+	case strings.HasSuffix(lower, i2pSuffix):
+		b, errr := addrBase32.DecodeString(
+			strings.TrimSuffix(lower, i2pSuffix))
+		if errr != nil || len(b) != addrV2Sizes[NetI2P] {
+			return nil, er.Errorf("invalid I2P address %s", host)
+		}
+		na.Network = NetI2P
+		na.Addr = b
+
+	default:
+		return nil, er.Errorf("%s is not an IP, onion or I2P address",
+			host)
+	}
+	return na, nil
+}
+
+// readNetAddressV2 reads an address encoded as in an addrv2 message from r.
+// Addresses on networks which are not known are read and skipped as required
+// by BIP0155, in which case false is returned.
+func readNetAddressV2(r io.Reader, pver uint32, na *NetAddress) (bool, er.R) {
+	err := readElement(r, (*uint32Time)(&na.Timestamp))
+	if err != nil {
+		return false, err
+	}
+	services, err := ReadVarInt(r, pver)
+	if err != nil {
+		return false, err
+	}
+	na.Services = protocol.ServiceFlag(services)
+
+	network, err := binarySerializer.Uint8(r)
+	if err != nil {
+		return false, err
+	}
+	addr, err := ReadVarBytes(r, pver, MaxAddrV2Size, "addrv2 address")
+	if err != nil {
+		return false, err
+	}
+	// Sigh.  Bitcoin protocol mixes little and big endian.
+	na.Port, err = binarySerializer.Uint16(r, bigEndian)
+	if err != nil {
+		return false, err
+	}
+
+	size, ok := addrV2Sizes[NetworkID(network)]
+	if !ok {
+		return false, nil
+	}
+	if len(addr) != size {
+		str := fmt.Sprintf("invalid %v address length %d",
+			NetworkID(network), len(addr))
+		return false, messageError("readNetAddressV2", str)
+	}
+
+	na.IP = nil
+	na.Network = 0
+	na.Addr = nil
+	switch NetworkID(network) {
+	case NetIPv4, NetIPv6, NetCJDNS:
+		na.IP = net.IP(addr)
+	case NetTorV2:
+		na.IP = append(append(net.IP{}, onionCatNet.IP[:6]...), addr...)
+	default:
+		na.Network = NetworkID(network)
+		na.Addr = addr
+	}
+	return true, nil
+}
+
+// writeNetAddressV2 serializes a NetAddress to w as encoded in an addrv2
+// message.
+func writeNetAddressV2(w io.Writer, pver uint32, na *NetAddress) er.R {
+	network, addr := na.addrV2Bytes()
+	if size, ok := addrV2Sizes[network]; !ok || len(addr) != size {
+		str := fmt.Sprintf("invalid %v address length %d", network,
+			len(addr))
+		return messageError("writeNetAddressV2", str)
+	}
+
+	err := writeElement(w, uint32(na.Timestamp.Unix()))
+	if err != nil {
+		return err
+	}
+	err = WriteVarInt(w, pver, uint64(na.Services))
+	if err != nil {
+		return err
+	}
+	err = binarySerializer.PutUint8(w, uint8(network))
+	if err != nil {
+		return err
+	}
+	err = WriteVarBytes(w, pver, addr)
+	if err != nil {
+		return err
+	}
+
+	// Sigh.  Bitcoin protocol mixes little and big endian.
+	return binarySerializer.PutUint16(w, bigEndian, na.Port)
+}
+
+// maxNetAddressV2Payload returns the max payload size of an address encoded as
+// in an addrv2 message.
+func maxNetAddressV2Payload() uint32 {
+	// Timestamp 4 bytes + services varint + network 1 byte + address
+	// varint and bytes + port 2 bytes.
+	return 4 + MaxVarIntPayload + 1 + MaxVarIntPayload + MaxAddrV2Size + 2
+}
@@ -14,7 +14,10 @@ import (
 // XXX pedro: we will probably need to bump this.
 const (
 	// ProtocolVersion is the latest protocol version this package supports.
-	ProtocolVersion uint32 = 70013
+	// It is AddrV2Version, peers which negotiate it send a sendaddrv2
+	// message before their verack and may then relay addresses with
+	// addrv2 messages.
+	ProtocolVersion uint32 = 70016
 
 	// MultipleAddressVersion is the protocol version which added multiple
 	// addresses per message (pver >= MultipleAddressVersion).
@@ -52,6 +55,15 @@ const (
 	// FeeFilterVersion is the protocol version which added a new
 	// feefilter message.
 	FeeFilterVersion uint32 = 70013
+
+	// ShortIDsBlocksVersion is the protocol version which added the
+	// sendcmpct, cmpctblock, getblocktxn and blocktxn messages for compact
+	// block relay (BIP0152).
+	ShortIDsBlocksVersion uint32 = 70014
+
+	// AddrV2Version is the protocol version which added the sendaddrv2 and
+	// addrv2 messages (BIP0155).
+	AddrV2Version uint32 = 70016
 )
 
 // ServiceFlag identifies services supported by a bitcoin peer.
@@ -87,6 +99,15 @@ const (
 	// SFNode2X is a flag used to indicate a peer is running the Segwit2X
 	// software.
 	SFNode2X
+
+	// SFNodeNetworkLimited is a flag used to indicate a peer is a pruned
+	// node which only serves the most recent blocks (BIP0159).
+	SFNodeNetworkLimited ServiceFlag = 1 << 10
+
+	// SFNodePacketCryptAnns is a flag used to indicate a peer relays
+	// PacketCrypt announcements with the anninv, getanns and anns
+	// messages.
+	SFNodePacketCryptAnns ServiceFlag = 1 << 24
 )
 
 // Map of service flags back to their constant names for pretty printing.
@@ -99,6 +120,9 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeBit5:    "SFNodeBit5",
 	SFNodeCF:      "SFNodeCF",
 	SFNode2X:      "SFNode2X",
+
+	SFNodeNetworkLimited:  "SFNodeNetworkLimited",
+	SFNodePacketCryptAnns: "SFNodePacketCryptAnns",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -112,6 +136,8 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeBit5,
 	SFNodeCF,
 	SFNode2X,
+	SFNodeNetworkLimited,
+	SFNodePacketCryptAnns,
 }
 
 // String returns the ServiceFlag in human-readable form.
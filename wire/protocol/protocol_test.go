@@ -25,7 +25,9 @@ func TestServiceFlagStringer(t *testing.T) {
 		{protocol.SFNodeBit5, "SFNodeBit5"},
 		{protocol.SFNodeCF, "SFNodeCF"},
 		{protocol.SFNode2X, "SFNode2X"},
-		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeWitness|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNode2X|0xffffff00"},
+		{protocol.SFNodeNetworkLimited, "SFNodeNetworkLimited"},
+		{protocol.SFNodePacketCryptAnns, "SFNodePacketCryptAnns"},
+		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeWitness|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNode2X|SFNodeNetworkLimited|SFNodePacketCryptAnns|0xfefffb00"},
 	}
 
 	t.Logf("Running %d tests", len(tests))
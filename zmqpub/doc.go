@@ -0,0 +1,30 @@
+/*
+Package zmqpub implements a publisher of block and transaction notifications
+which is compatible with the ZeroMQ notifications of Bitcoin Core.
+
+Overview
+
+Each topic is published on a TCP address, several topics may share the same
+address.  Subscribers connect with a ZeroMQ SUB socket using the ZMTP 3.0
+protocol and the NULL security mechanism, no ZeroMQ library is needed by the
+publisher.  The following topics are supported:
+
+	rawblock   the serialized block, including its PacketCrypt proof
+	hashblock  the hash of the block
+	rawtx      the serialized transaction
+	hashtx     the hash of the transaction
+	sequence   the hash followed by a label, C for a connected block, D for a
+	           disconnected block, and A for a transaction added to or R for
+	           a transaction removed from the memory pool followed by a
+	           mempool sequence number
+
+Every message has three parts, the topic, the body and the sequence number of
+the message within its topic as a 4 byte little endian integer.  Hashes are in
+the byte order they are displayed in.  Transactions are published both when
+they are accepted to the memory pool and when a block containing them is
+connected or disconnected, like Bitcoin Core does.
+
+As with ZeroMQ, messages are dropped for subscribers which do not read them
+fast enough.
+*/
+package zmqpub
@@ -0,0 +1,455 @@
+package zmqpub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+)
+
+// Topics which can be published.
+const (
+	TopicRawBlock  = "rawblock"
+	TopicHashBlock = "hashblock"
+	TopicRawTx     = "rawtx"
+	TopicHashTx    = "hashtx"
+	TopicSequence  = "sequence"
+)
+
+const (
+	// sendQueueLen is the number of messages which are queued for a
+	// subscriber before further messages are dropped.  It matches the
+	// default high water mark of Bitcoin Core.
+	sendQueueLen = 1000
+
+	// handshakeTimeout is the time a subscriber has to complete the ZMTP
+	// handshake after connecting.
+	handshakeTimeout = 30 * time.Second
+)
+
+// Labels of the messages of the sequence topic.
+const (
+	sequenceBlockConnected    = 'C'
+	sequenceBlockDisconnected = 'D'
+	sequenceTxAdded           = 'A'
+	sequenceTxRemoved         = 'R'
+)
+
+// Config is the configuration of a Publisher.  Each field is the address the
+// topic is published on, as tcp://host:port, and is left empty when the topic
+// is not published.
+type Config struct {
+	RawBlock  string
+	HashBlock string
+	RawTx     string
+	HashTx    string
+	Sequence  string
+}
+
+// subscriber is a connected SUB socket.
+type subscriber struct {
+	conn net.Conn
+	send chan [][]byte
+	done chan struct{}
+
+	mtx           sync.Mutex
+	subscriptions map[string]int
+}
+
+// subscribed returns whether the subscriber subscribed to a prefix of the
+// passed topic.
+func (s *subscriber) subscribed(topic string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for prefix := range s.subscriptions {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribe adds or, when the subscribe flag is not set, removes a
+// subscription.  Like ZeroMQ, subscriptions are counted and a prefix is only
+// removed when it was unsubscribed as many times as it was subscribed.
+func (s *subscriber) subscribe(prefix string, subscribe bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if subscribe {
+		s.subscriptions[prefix]++
+		return
+	}
+	if s.subscriptions[prefix] <= 1 {
+		delete(s.subscriptions, prefix)
+		return
+	}
+	s.subscriptions[prefix]--
+}
+
+// endpoint is an address on which a set of topics is published.
+type endpoint struct {
+	addr     string
+	topics   map[string]struct{}
+	listener net.Listener
+
+	mtx         sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// Publisher publishes notifications of blocks and transactions to ZeroMQ
+// subscribers.
+type Publisher struct {
+	started  int32
+	shutdown int32
+
+	endpoints []*endpoint
+
+	// mtx protects the sequence numbers and ensures messages are queued
+	// in the order of their sequence numbers.
+	mtx        sync.Mutex
+	sequences  map[string]uint32
+	mempoolSeq uint64
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// New returns a new Publisher which listens on the addresses of the topics set
+// in the passed configuration.
+func New(cfg *Config) (*Publisher, er.R) {
+	p := Publisher{
+		sequences: make(map[string]uint32),
+		quit:      make(chan struct{}),
+	}
+
+	topics := []struct {
+		topic string
+		addr  string
+	}{
+		{TopicRawBlock, cfg.RawBlock},
+		{TopicHashBlock, cfg.HashBlock},
+		{TopicRawTx, cfg.RawTx},
+		{TopicHashTx, cfg.HashTx},
+		{TopicSequence, cfg.Sequence},
+	}
+	byAddr := make(map[string]*endpoint)
+	for _, t := range topics {
+		if t.addr == "" {
+			continue
+		}
+		if !strings.HasPrefix(t.addr, "tcp://") {
+			p.closeListeners()
+			return nil, er.Errorf("invalid address %s for %s, only "+
+				"tcp:// addresses are supported", t.addr, t.topic)
+		}
+		addr := strings.TrimPrefix(t.addr, "tcp://")
+		ep, ok := byAddr[addr]
+		if !ok {
+			listener, errr := net.Listen("tcp", addr)
+			if errr != nil {
+				p.closeListeners()
+				return nil, er.E(errr)
+			}
+			ep = &endpoint{
+				addr:        addr,
+				topics:      make(map[string]struct{}),
+				listener:    listener,
+				subscribers: make(map[*subscriber]struct{}),
+			}
+			byAddr[addr] = ep
+			p.endpoints = append(p.endpoints, ep)
+		}
+		ep.topics[t.topic] = struct{}{}
+	}
+	return &p, nil
+}
+
+// closeListeners closes the listeners of all of the endpoints.
+func (p *Publisher) closeListeners() {
+	for _, ep := range p.endpoints {
+		ep.listener.Close()
+	}
+}
+
+// Start begins accepting subscribers.
+func (p *Publisher) Start() {
+	if atomic.AddInt32(&p.started, 1) != 1 {
+		return
+	}
+
+	for _, ep := range p.endpoints {
+		log.Infof("ZMQ publisher listening on %s", ep.listener.Addr())
+		p.wg.Add(1)
+		go p.listenHandler(ep)
+	}
+}
+
+// Stop disconnects all of the subscribers and stops listening.
+func (p *Publisher) Stop() {
+	if atomic.AddInt32(&p.shutdown, 1) != 1 {
+		return
+	}
+
+	close(p.quit)
+	p.closeListeners()
+	p.wg.Wait()
+}
+
+// listenHandler accepts the subscribers of an endpoint.  It must be run as a
+// goroutine.
+func (p *Publisher) listenHandler(ep *endpoint) {
+	defer p.wg.Done()
+	for {
+		conn, errr := ep.listener.Accept()
+		if errr != nil {
+			select {
+			case <-p.quit:
+			default:
+				log.Errorf("Unable to accept ZMQ subscriber on "+
+					"%s: %v", ep.addr, errr)
+			}
+			return
+		}
+		p.wg.Add(1)
+		go p.subscriberHandler(ep, conn)
+	}
+}
+
+// subscriberHandler performs the handshake with a new subscriber and reads its
+// subscriptions until it disconnects.  It must be run as a goroutine.
+func (p *Publisher) subscriberHandler(ep *endpoint, conn net.Conn) {
+	defer p.wg.Done()
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := handshake(r, w); err != nil {
+		log.Debugf("ZMQ handshake with %s failed: %v",
+			conn.RemoteAddr(), err)
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	log.Debugf("New ZMQ subscriber %s on %s", conn.RemoteAddr(), ep.addr)
+
+	sub := &subscriber{
+		conn:          conn,
+		send:          make(chan [][]byte, sendQueueLen),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]int),
+	}
+	ep.mtx.Lock()
+	ep.subscribers[sub] = struct{}{}
+	ep.mtx.Unlock()
+	defer func() {
+		ep.mtx.Lock()
+		delete(ep.subscribers, sub)
+		ep.mtx.Unlock()
+		close(sub.done)
+	}()
+
+	p.wg.Add(1)
+	go p.sendHandler(sub, w)
+
+	multipart := false
+	for {
+		flags, body, err := readFrame(r)
+		if err != nil {
+			log.Debugf("ZMQ subscriber %s disconnected: %v",
+				conn.RemoteAddr(), err)
+			return
+		}
+
+		// ZMTP 3.0 subscribers send subscriptions as single part
+		// messages and ZMTP 3.1 subscribers as commands.
+		if flags&flagCommand == 0 {
+			if !multipart && flags&flagMore == 0 && len(body) > 0 {
+				sub.subscribe(string(body[1:]), body[0] == 1)
+			}
+			multipart = flags&flagMore != 0
+			continue
+		}
+		name, data, err := parseCommand(body)
+		if err != nil {
+			log.Debugf("ZMQ subscriber %s sent an invalid "+
+				"command: %v", conn.RemoteAddr(), err)
+			return
+		}
+		switch name {
+		case "SUBSCRIBE":
+			sub.subscribe(string(data), true)
+		case "CANCEL":
+			sub.subscribe(string(data), false)
+		}
+	}
+}
+
+// sendHandler writes the messages queued for a subscriber.  It must be run as
+// a goroutine.
+func (p *Publisher) sendHandler(sub *subscriber, w *bufio.Writer) {
+	defer p.wg.Done()
+	for {
+		select {
+		case msg := <-sub.send:
+			if err := writeMessage(w, msg); err != nil {
+				sub.conn.Close()
+				return
+			}
+		case <-sub.done:
+			return
+		case <-p.quit:
+			sub.conn.Close()
+			return
+		}
+	}
+}
+
+// publish queues a message with the passed topic and body to all of the
+// subscribers of the topic.  The body is only built when the topic is
+// published.
+func (p *Publisher) publish(topic string, body func() []byte) {
+	var b []byte
+	built := false
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, ep := range p.endpoints {
+		if _, ok := ep.topics[topic]; !ok {
+			continue
+		}
+		if !built {
+			b = body()
+			built = true
+		}
+
+		var seq [4]byte
+		binary.LittleEndian.PutUint32(seq[:], p.sequences[topic])
+		msg := [][]byte{[]byte(topic), b, seq[:]}
+
+		ep.mtx.Lock()
+		for sub := range ep.subscribers {
+			if !sub.subscribed(topic) {
+				continue
+			}
+			select {
+			case sub.send <- msg:
+			default:
+				log.Debugf("Dropping ZMQ %s message for slow "+
+					"subscriber %s", topic,
+					sub.conn.RemoteAddr())
+			}
+		}
+		ep.mtx.Unlock()
+	}
+	if built {
+		p.sequences[topic]++
+	}
+}
+
+// reversedHash returns the bytes of the passed hash in the order it is
+// displayed in.
+func reversedHash(hash *chainhash.Hash) []byte {
+	b := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		b[chainhash.HashSize-1-i] = hash[i]
+	}
+	return b
+}
+
+// publishSequence publishes a message of the sequence topic for the passed hash
+// and label, optionally followed by a mempool sequence number.
+func (p *Publisher) publishSequence(hash *chainhash.Hash, label byte, mempoolSeq *uint64) {
+	p.publish(TopicSequence, func() []byte {
+		b := append(reversedHash(hash), label)
+		if mempoolSeq != nil {
+			var seq [8]byte
+			binary.LittleEndian.PutUint64(seq[:], *mempoolSeq)
+			b = append(b, seq[:]...)
+		}
+		return b
+	})
+}
+
+// publishTx publishes the rawtx and hashtx messages of the passed transaction.
+func (p *Publisher) publishTx(tx *btcutil.Tx) {
+	p.publish(TopicHashTx, func() []byte {
+		return reversedHash(tx.Hash())
+	})
+	p.publish(TopicRawTx, func() []byte {
+		var buf bytes.Buffer
+		buf.Grow(tx.MsgTx().SerializeSize())
+		if err := tx.MsgTx().Serialize(&buf); err != nil {
+			log.Errorf("Unable to serialize transaction %v: %v",
+				tx.Hash(), err)
+		}
+		return buf.Bytes()
+	})
+}
+
+// NotifyTxAccepted publishes a transaction which was accepted to the memory
+// pool.
+func (p *Publisher) NotifyTxAccepted(tx *btcutil.Tx) {
+	p.publishTx(tx)
+
+	p.mtx.Lock()
+	p.mempoolSeq++
+	seq := p.mempoolSeq
+	p.mtx.Unlock()
+	p.publishSequence(tx.Hash(), sequenceTxAdded, &seq)
+}
+
+// NotifyTxRemoved publishes the removal of a transaction from the memory pool.
+func (p *Publisher) NotifyTxRemoved(tx *btcutil.Tx) {
+	p.mtx.Lock()
+	p.mempoolSeq++
+	seq := p.mempoolSeq
+	p.mtx.Unlock()
+	p.publishSequence(tx.Hash(), sequenceTxRemoved, &seq)
+}
+
+// HandleBlockchainNotification publishes the blocks which are connected to and
+// disconnected from the main chain along with their transactions.  It is meant
+// to be subscribed to the notifications of the chain.
+func (p *Publisher) HandleBlockchainNotification(notification *blockchain.Notification) {
+	var label byte
+	switch notification.Type {
+	case blockchain.NTBlockConnected:
+		label = sequenceBlockConnected
+	case blockchain.NTBlockDisconnected:
+		label = sequenceBlockDisconnected
+	default:
+		return
+	}
+	block, ok := notification.Data.(*btcutil.Block)
+	if !ok {
+		log.Warnf("Chain notification is not a block.")
+		return
+	}
+
+	if label == sequenceBlockConnected {
+		p.publish(TopicHashBlock, func() []byte {
+			return reversedHash(block.Hash())
+		})
+		p.publish(TopicRawBlock, func() []byte {
+			b, err := block.Bytes()
+			if err != nil {
+				log.Errorf("Unable to serialize block %v: %v",
+					block.Hash(), err)
+			}
+			return b
+		})
+	}
+	for _, tx := range block.Transactions() {
+		p.publishTx(tx)
+	}
+	p.publishSequence(block.Hash(), label, nil)
+}
@@ -0,0 +1,225 @@
+package zmqpub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testSubscriber is a minimal ZMTP SUB socket.
+type testSubscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// dialSubscriber connects a SUB socket to the passed address.
+func dialSubscriber(t *testing.T, addr string) *testSubscriber {
+	conn, errr := net.Dial("tcp", addr)
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	s := &testSubscriber{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+	s.w.Write(greeting())
+	s.w.Flush()
+	if err := readGreeting(s.r); err != nil {
+		t.Fatalf("readGreeting: %v", err)
+	}
+	ready := encodeMetadata(map[string]string{"Socket-Type": "SUB"})
+	if err := writeCommand(s.w, "READY", ready); err != nil {
+		t.Fatalf("writeCommand: %v", err)
+	}
+	flags, body, err := readFrame(s.r)
+	if err != nil || flags&flagCommand == 0 {
+		t.Fatalf("readFrame: flags %x, %v", flags, err)
+	}
+	name, data, err := parseCommand(body)
+	if err != nil || name != "READY" {
+		t.Fatalf("parseCommand: %s, %v", name, err)
+	}
+	props, err := parseMetadata(data)
+	if err != nil || props["socket-type"] != "PUB" {
+		t.Fatalf("parseMetadata: %v, %v", props, err)
+	}
+	return s
+}
+
+// readMessage reads a multipart message.
+func (s *testSubscriber) readMessage(t *testing.T) [][]byte {
+	var parts [][]byte
+	for {
+		flags, body, err := readFrame(s.r)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		parts = append(parts, body)
+		if flags&flagMore == 0 {
+			return parts
+		}
+	}
+}
+
+// waitSubscribed waits until the only subscriber of the endpoint subscribed to
+// all of the passed topics.
+func waitSubscribed(t *testing.T, ep *endpoint, topics ...string) {
+	for i := 0; i < 100; i++ {
+		ep.mtx.Lock()
+		subscribed := len(ep.subscribers) == 1
+		for sub := range ep.subscribers {
+			for _, topic := range topics {
+				subscribed = subscribed && sub.subscribed(topic)
+			}
+		}
+		ep.mtx.Unlock()
+		if subscribed {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("subscriber did not subscribe to %v", topics)
+}
+
+// checkMessage ensures the passed message has the expected topic, body and
+// sequence number.
+func checkMessage(t *testing.T, msg [][]byte, topic string, body []byte, seq uint32) {
+	t.Helper()
+	if len(msg) != 3 {
+		t.Fatalf("message has %d parts, want 3", len(msg))
+	}
+	if string(msg[0]) != topic {
+		t.Fatalf("unexpected topic - got %s, want %s", msg[0], topic)
+	}
+	if !bytes.Equal(msg[1], body) {
+		t.Fatalf("unexpected %s body - got %x, want %x", topic, msg[1],
+			body)
+	}
+	if got := binary.LittleEndian.Uint32(msg[2]); got != seq {
+		t.Fatalf("unexpected %s sequence - got %d, want %d", topic, got,
+			seq)
+	}
+}
+
+// TestPublisher ensures subscribers receive the messages of the topics they
+// subscribed to, with subscriptions sent both as ZMTP 3.0 messages and ZMTP 3.1
+// commands.
+func TestPublisher(t *testing.T) {
+	p, err := New(&Config{
+		HashTx:   "tcp://127.0.0.1:0",
+		RawTx:    "tcp://127.0.0.1:0",
+		Sequence: "tcp://127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(p.endpoints) != 1 {
+		t.Fatalf("topics on the same address use %d endpoints",
+			len(p.endpoints))
+	}
+	p.Start()
+	defer p.Stop()
+
+	ep := p.endpoints[0]
+	sub := dialSubscriber(t, ep.listener.Addr().String())
+	defer sub.conn.Close()
+	writeMessage(sub.w, [][]byte{append([]byte{1}, "hash"...)})
+	writeCommand(sub.w, "SUBSCRIBE", []byte(TopicSequence))
+	waitSubscribed(t, ep, TopicHashTx, TopicSequence)
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, []byte{0x51}, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	utx := btcutil.NewTx(tx)
+	hash := reversedHash(utx.Hash())
+
+	// The transaction is published on hashtx and sequence but not on
+	// rawtx, which was not subscribed to.
+	p.NotifyTxAccepted(utx)
+	checkMessage(t, sub.readMessage(t), TopicHashTx, hash, 0)
+	checkMessage(t, sub.readMessage(t), TopicSequence,
+		append(append([]byte{}, hash...), 'A', 1, 0, 0, 0, 0, 0, 0, 0), 0)
+
+	// Its removal from the memory pool is only published on sequence.
+	p.NotifyTxRemoved(utx)
+	checkMessage(t, sub.readMessage(t), TopicSequence,
+		append(append([]byte{}, hash...), 'R', 2, 0, 0, 0, 0, 0, 0, 0), 1)
+
+	block := btcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{tx},
+	})
+	p.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockConnected,
+		Data: block,
+	})
+	checkMessage(t, sub.readMessage(t), TopicHashTx, hash, 1)
+	checkMessage(t, sub.readMessage(t), TopicSequence,
+		append(reversedHash(block.Hash()), 'C'), 2)
+
+	// After unsubscribing from hashtx only the sequence is published.
+	writeCommand(sub.w, "CANCEL", []byte("hash"))
+	for i := 0; i < 100; i++ {
+		ep.mtx.Lock()
+		subscribed := false
+		for s := range ep.subscribers {
+			subscribed = s.subscribed(TopicHashTx)
+		}
+		ep.mtx.Unlock()
+		if !subscribed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockDisconnected,
+		Data: block,
+	})
+	checkMessage(t, sub.readMessage(t), TopicSequence,
+		append(reversedHash(block.Hash()), 'D'), 3)
+}
+
+// TestHandshakeSocketType ensures only SUB sockets can connect to the
+// publisher.
+func TestHandshakeSocketType(t *testing.T) {
+	listener, errr := net.Listen("tcp", "127.0.0.1:0")
+	if errr != nil {
+		t.Fatalf("Listen: %v", errr)
+	}
+	defer listener.Close()
+	client, errr := net.Dial("tcp", listener.Addr().String())
+	if errr != nil {
+		t.Fatalf("Dial: %v", errr)
+	}
+	defer client.Close()
+	server, errr := listener.Accept()
+	if errr != nil {
+		t.Fatalf("Accept: %v", errr)
+	}
+	defer server.Close()
+
+	go func() {
+		r := bufio.NewReader(client)
+		w := bufio.NewWriter(client)
+		w.Write(greeting())
+		w.Flush()
+		readGreeting(r)
+		ready := encodeMetadata(map[string]string{"Socket-Type": "REQ"})
+		writeCommand(w, "READY", ready)
+		readFrame(r)
+	}()
+
+	err := handshake(bufio.NewReader(server), bufio.NewWriter(server))
+	if err == nil {
+		t.Fatalf("handshake with a REQ socket succeeded")
+	}
+}
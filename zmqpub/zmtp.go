@@ -0,0 +1,225 @@
+package zmqpub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+const (
+	// greetingLen is the length of the greeting which starts a ZMTP
+	// connection.
+	greetingLen = 64
+
+	// Flags of a ZMTP frame.
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	// maxFrameLen is the maximum length of a frame read from a subscriber.
+	// Subscribers only send commands and subscriptions, which are short.
+	maxFrameLen = 1 << 16
+
+	// mechanismNull is the only security mechanism which is supported.
+	mechanismNull = "NULL"
+)
+
+// greeting returns the ZMTP 3.0 greeting of a peer using the NULL mechanism.
+func greeting() []byte {
+	g := make([]byte, greetingLen)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3
+	g[11] = 0
+	copy(g[12:32], mechanismNull)
+	return g
+}
+
+// readGreeting reads the greeting of the remote peer and checks that it uses
+// ZMTP 3 or later and the NULL mechanism.
+func readGreeting(r io.Reader) er.R {
+	var g [greetingLen]byte
+	if _, errr := io.ReadFull(r, g[:]); errr != nil {
+		return er.E(errr)
+	}
+	if g[0] != 0xff || g[9] != 0x7f {
+		return er.New("invalid ZMTP greeting signature")
+	}
+	if g[10] < 3 {
+		return er.Errorf("unsupported ZMTP version %d.%d", g[10], g[11])
+	}
+	if mechanism := string(bytes.TrimRight(g[12:32], "\x00")); mechanism != mechanismNull {
+		return er.Errorf("unsupported ZMTP mechanism %q", mechanism)
+	}
+	return nil
+}
+
+// writeFrame writes a single frame with the passed flags, the long flag is set
+// when needed.
+func writeFrame(w *bufio.Writer, flags byte, body []byte) er.R {
+	if len(body) > 0xff {
+		flags |= flagLong
+	}
+	if errr := w.WriteByte(flags); errr != nil {
+		return er.E(errr)
+	}
+	if flags&flagLong != 0 {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(body)))
+		if _, errr := w.Write(size[:]); errr != nil {
+			return er.E(errr)
+		}
+	} else if errr := w.WriteByte(byte(len(body))); errr != nil {
+		return er.E(errr)
+	}
+	_, errr := w.Write(body)
+	return er.E(errr)
+}
+
+// writeMessage writes a message made of the passed parts and flushes it.
+func writeMessage(w *bufio.Writer, parts [][]byte) er.R {
+	for i, part := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		if err := writeFrame(w, flags, part); err != nil {
+			return err
+		}
+	}
+	return er.E(w.Flush())
+}
+
+// writeCommand writes a command with the passed name and data and flushes it.
+func writeCommand(w *bufio.Writer, name string, data []byte) er.R {
+	body := make([]byte, 0, 1+len(name)+len(data))
+	body = append(body, byte(len(name)))
+	body = append(body, name...)
+	body = append(body, data...)
+	if err := writeFrame(w, flagCommand, body); err != nil {
+		return err
+	}
+	return er.E(w.Flush())
+}
+
+// readFrame reads a frame and returns its flags and body.
+func readFrame(r *bufio.Reader) (byte, []byte, er.R) {
+	flags, errr := r.ReadByte()
+	if errr != nil {
+		return 0, nil, er.E(errr)
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, errr := io.ReadFull(r, b[:]); errr != nil {
+			return 0, nil, er.E(errr)
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, errr := r.ReadByte()
+		if errr != nil {
+			return 0, nil, er.E(errr)
+		}
+		size = uint64(b)
+	}
+	if size > maxFrameLen {
+		return 0, nil, er.Errorf("frame of %d bytes is too long", size)
+	}
+	body := make([]byte, size)
+	if _, errr := io.ReadFull(r, body); errr != nil {
+		return 0, nil, er.E(errr)
+	}
+	return flags, body, nil
+}
+
+// parseCommand splits the body of a command frame into the name and data of
+// the command.
+func parseCommand(body []byte) (string, []byte, er.R) {
+	if len(body) == 0 || int(body[0]) > len(body)-1 {
+		return "", nil, er.New("malformed ZMTP command")
+	}
+	return string(body[1 : 1+body[0]]), body[1+body[0]:], nil
+}
+
+// encodeMetadata encodes the properties of a READY command.
+func encodeMetadata(props map[string]string) []byte {
+	var b []byte
+	for name, value := range props {
+		b = append(b, byte(len(name)))
+		b = append(b, name...)
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(value)))
+		b = append(b, size[:]...)
+		b = append(b, value...)
+	}
+	return b
+}
+
+// parseMetadata decodes the properties of a READY command.  Property names are
+// case insensitive so they are returned in lower case.
+func parseMetadata(b []byte) (map[string]string, er.R) {
+	props := make(map[string]string)
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+4 {
+			return nil, er.New("malformed ZMTP metadata")
+		}
+		name := string(bytes.ToLower(b[1 : 1+nameLen]))
+		b = b[1+nameLen:]
+		valueLen := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		if uint64(len(b)) < uint64(valueLen) {
+			return nil, er.New("malformed ZMTP metadata")
+		}
+		props[name] = string(b[:valueLen])
+		b = b[valueLen:]
+	}
+	return props, nil
+}
+
+// handshake performs the ZMTP handshake of a PUB socket on the passed
+// connection.  The remote peer must be a SUB or XSUB socket.
+func handshake(r *bufio.Reader, w *bufio.Writer) er.R {
+	if _, errr := w.Write(greeting()); errr != nil {
+		return er.E(errr)
+	}
+	if errr := w.Flush(); errr != nil {
+		return er.E(errr)
+	}
+	if err := readGreeting(r); err != nil {
+		return err
+	}
+
+	ready := encodeMetadata(map[string]string{"Socket-Type": "PUB"})
+	if err := writeCommand(w, "READY", ready); err != nil {
+		return err
+	}
+
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return err
+	}
+	if flags&flagCommand == 0 {
+		return er.New("expected ZMTP READY command")
+	}
+	name, data, err := parseCommand(body)
+	if err != nil {
+		return err
+	}
+	if name != "READY" {
+		return er.Errorf("expected ZMTP READY command, got %s", name)
+	}
+	props, err := parseMetadata(data)
+	if err != nil {
+		return err
+	}
+	switch socketType := props["socket-type"]; socketType {
+	case "SUB", "XSUB":
+	default:
+		return er.Errorf("incompatible socket type %q", socketType)
+	}
+	return nil
+}